@@ -0,0 +1,90 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"os"
+
+	"github.com/jparrill/devtrackr/internal/alerts"
+	"github.com/jparrill/devtrackr/internal/models"
+	"github.com/jparrill/devtrackr/internal/report"
+	"github.com/jparrill/devtrackr/internal/services"
+	"github.com/spf13/cobra"
+)
+
+var (
+	alertsFormat   string
+	alertsProjects []string
+	alertsKinds    []string
+	alertsNotify   bool
+
+	alertsCmd = &cobra.Command{
+		Use:   "alerts",
+		Short: "Show stale pull requests and issues",
+		Long: `Show the stale work among tracked issues and their pull requests:
+
+  pull_request_idle  open pull requests without activity
+  status_stuck       issues not done that stayed in one status
+  backport_missing   expected backports still missing after the original merged
+  poll_stale         issues whose last successful poll is old
+
+Thresholds are set under alerts in the configuration, per project if needed.
+With --notify, the alerts are also sent through the notification webhook.`,
+		Args: cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			format, err := report.ParseFormat(alertsFormat)
+			if err != nil {
+				return err
+			}
+			var kinds []alerts.Kind
+			for _, name := range alertsKinds {
+				kind, err := alerts.ParseKind(name)
+				if err != nil {
+					return err
+				}
+				kinds = append(kinds, kind)
+			}
+
+			cfg, err := initConfig()
+			if err != nil {
+				return fmt.Errorf("failed to load configuration: %w", err)
+			}
+
+			trackingService, storage, err := initTrackingService()
+			if err != nil {
+				return err
+			}
+			defer storage.Close()
+
+			ctx := context.Background()
+			found, err := trackingService.StaleAlerts(ctx, models.IssueFilter{Project: alertsProjects})
+			if err != nil {
+				return fmt.Errorf("failed to look for stale work: %w", err)
+			}
+			found = alerts.Filter(found, kinds)
+
+			if alertsNotify {
+				var notifier services.Notifier = services.LogNotifier{}
+				if url := cfg.Notifications.WebhookURL(); url != "" {
+					notifier = services.NewWebhookNotifier(url)
+				}
+				for _, alert := range found {
+					if err := notifier.Notify(ctx, services.AlertNotification(alert)); err != nil {
+						return fmt.Errorf("failed to send %s alert for %s: %w", alert.Kind, alert.Issue, err)
+					}
+				}
+			}
+
+			return alerts.Render(os.Stdout, found, format)
+		},
+	}
+)
+
+func init() {
+	rootCmd.AddCommand(alertsCmd)
+
+	alertsCmd.Flags().StringVarP(&alertsFormat, "format", "f", "table", "Output format (table, json)")
+	alertsCmd.Flags().StringSliceVarP(&alertsProjects, "project", "p", nil, "Only include issues of these Jira projects")
+	alertsCmd.Flags().StringSliceVarP(&alertsKinds, "kind", "k", nil, "Only show alerts of these kinds")
+	alertsCmd.Flags().BoolVar(&alertsNotify, "notify", false, "Also send the alerts through the notification webhook")
+}
@@ -0,0 +1,139 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"os"
+
+	"github.com/jparrill/devtrackr/internal/archive"
+	"github.com/jparrill/devtrackr/internal/models"
+	"github.com/spf13/cobra"
+)
+
+var (
+	exportFormat   string
+	exportProjects []string
+
+	importStrategy string
+	importConflict string
+
+	exportCmd = &cobra.Command{
+		Use:   "export [file]",
+		Short: "Export the tracking database to an archive",
+		Long: `Export tracked issues with their children and links, pull requests and
+backport links, subscriptions, timeline, Jira audit log and commits to a
+versioned archive, written to file or to the standard output.
+
+Archives reference issues by key and pull requests by repository and number,
+so they can be imported into another instance. NDJSON archives hold one issue
+per line after the header. There are no saved queries to export, filters
+are only given on the command line.`,
+		Args: cobra.MaximumNArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			encoding, err := archive.ParseEncoding(exportFormat)
+			if err != nil {
+				return err
+			}
+
+			trackingService, storage, err := initTrackingService()
+			if err != nil {
+				return err
+			}
+			defer storage.Close()
+
+			a, err := trackingService.Export(context.Background(), models.IssueFilter{Project: exportProjects})
+			if err != nil {
+				return fmt.Errorf("failed to export: %w", err)
+			}
+
+			if len(args) == 0 {
+				return archive.Write(os.Stdout, a, encoding)
+			}
+			f, err := os.Create(args[0])
+			if err != nil {
+				return fmt.Errorf("failed to create %s: %w", args[0], err)
+			}
+			if err := archive.Write(f, a, encoding); err != nil {
+				f.Close()
+				return fmt.Errorf("failed to write %s: %w", args[0], err)
+			}
+			if err := f.Close(); err != nil {
+				return err
+			}
+			fmt.Fprintf(os.Stderr, "Exported %d issues to %s\n", len(a.Issues), args[0])
+			return nil
+		},
+	}
+
+	importCmd = &cobra.Command{
+		Use:   "import [file]",
+		Short: "Import an archive into the tracking database",
+		Long: `Import an archive written by export, from file or from the standard input.
+Either encoding is detected.
+
+Issues are matched by key, pull requests by repository and number, and
+history entries by their content, so importing an archive twice is harmless.
+When an issue is already tracked, --on-conflict decides which side is kept:
+
+  newer   the side updated last
+  ours    the tracked issue
+  theirs  the archive
+
+Records missing on either side are always added. With --strategy replace,
+tracked issues missing from the archive are deleted.`,
+		Args: cobra.MaximumNArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			strategy, err := archive.ParseStrategy(importStrategy)
+			if err != nil {
+				return err
+			}
+			conflict, err := archive.ParseConflict(importConflict)
+			if err != nil {
+				return err
+			}
+
+			var r io.Reader = os.Stdin
+			if len(args) == 1 {
+				f, err := os.Open(args[0])
+				if err != nil {
+					return fmt.Errorf("failed to open %s: %w", args[0], err)
+				}
+				defer f.Close()
+				r = f
+			}
+			a, err := archive.Read(r)
+			if err != nil {
+				return err
+			}
+
+			trackingService, storage, err := initTrackingService()
+			if err != nil {
+				return err
+			}
+			defer storage.Close()
+
+			result, err := trackingService.Import(context.Background(), a, strategy, conflict)
+			if err != nil {
+				return fmt.Errorf("failed to import: %w", err)
+			}
+
+			fmt.Printf("Issues: %d created, %d updated, %d unchanged, %d deleted\n",
+				result.Created, result.Updated, result.Unchanged, result.Deleted)
+			fmt.Printf("Added %d pull requests, %d subscriptions, %d events, %d audit entries, %d commits\n",
+				result.PullRequests, result.Subscriptions, result.Events, result.AuditEntries, result.Commits)
+			return nil
+		},
+	}
+)
+
+func init() {
+	rootCmd.AddCommand(exportCmd)
+	rootCmd.AddCommand(importCmd)
+
+	exportCmd.Flags().StringVarP(&exportFormat, "format", "f", "json", "Archive encoding (json, ndjson)")
+	exportCmd.Flags().StringSliceVarP(&exportProjects, "project", "p", nil, "Only export issues of these Jira projects")
+
+	importCmd.Flags().StringVar(&importStrategy, "strategy", "merge", "What to do with tracked issues missing from the archive (merge keeps them, replace deletes them)")
+	importCmd.Flags().StringVar(&importConflict, "on-conflict", "newer", "Which side wins for issues on both sides (newer, ours, theirs)")
+}
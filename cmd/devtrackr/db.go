@@ -0,0 +1,193 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/jparrill/devtrackr/internal/config"
+	"github.com/jparrill/devtrackr/internal/services"
+	"github.com/jparrill/devtrackr/internal/storage"
+	"github.com/spf13/cobra"
+)
+
+var (
+	pruneEvents time.Duration
+	pruneAudit  time.Duration
+
+	dbCmd = &cobra.Command{
+		Use:   "db",
+		Short: "Maintain the tracking database",
+		Long: `Back up, restore, vacuum and prune the tracking database. Only pruning is
+supported on PostgreSQL, which is backed up with its own tools such as
+pg_dump.`,
+	}
+
+	dbBackupCmd = &cobra.Command{
+		Use:   "backup [path]",
+		Short: "Back up the database",
+		Long: `Back up the database to path with the SQLite online backup API. It is safe
+to run while the server is running.
+
+Without path, the backup is written to database.backup.dir of the
+configuration, named after the current time, and the oldest backups beyond
+database.backup.keep are deleted.`,
+		Args: cobra.MaximumNArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			cfg, err := initConfig()
+			if err != nil {
+				return fmt.Errorf("failed to load configuration: %w", err)
+			}
+			if len(args) == 0 && cfg.Database.Backup.Dir == "" {
+				return fmt.Errorf("give a path or set database.backup.dir in the configuration")
+			}
+			databasePath, err := sqlitePath(cfg)
+			if err != nil {
+				return err
+			}
+
+			db, err := storage.NewSQLiteStorage(databasePath)
+			if err != nil {
+				return fmt.Errorf("failed to open database: %w", err)
+			}
+			defer db.Close()
+
+			ctx := context.Background()
+			path := ""
+			if len(args) == 1 {
+				path = args[0]
+				err = db.Backup(ctx, path)
+			} else {
+				path, err = services.RotateBackup(ctx, db, cfg.Database.Backup.Dir, cfg.Database.Backup.Keep, time.Now())
+			}
+			if err != nil {
+				return err
+			}
+
+			fmt.Printf("Backed up %s to %s\n", databasePath, path)
+			return nil
+		},
+	}
+
+	dbRestoreCmd = &cobra.Command{
+		Use:   "restore <path>",
+		Short: "Restore the database from a backup",
+		Long: `Replace the SQLite database with a backup, keeping the current one as
+devtrackr.db.pre-restore. The backup must pass an integrity check and have a
+schema version this build knows; older schemas are migrated on the next start.
+
+Stop the server first: it keeps the database open.`,
+		Args: cobra.ExactArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			cfg, err := initConfig()
+			if err != nil {
+				return fmt.Errorf("failed to load configuration: %w", err)
+			}
+			databasePath, err := sqlitePath(cfg)
+			if err != nil {
+				return err
+			}
+
+			version, err := storage.Restore(args[0], databasePath)
+			if err != nil {
+				return err
+			}
+
+			fmt.Printf("Restored %s from %s (schema version %d)\n", databasePath, args[0], version)
+			return nil
+		},
+	}
+
+	dbVacuumCmd = &cobra.Command{
+		Use:   "vacuum",
+		Short: "Reclaim unused space in the database",
+		Long: `Rebuild the database file, reclaiming the space of deleted rows, e.g. after
+pruning. Writes from the server wait while it runs.`,
+		Args: cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			cfg, err := initConfig()
+			if err != nil {
+				return fmt.Errorf("failed to load configuration: %w", err)
+			}
+			databasePath, err := sqlitePath(cfg)
+			if err != nil {
+				return err
+			}
+
+			db, err := storage.NewSQLiteStorage(databasePath)
+			if err != nil {
+				return fmt.Errorf("failed to open database: %w", err)
+			}
+			defer db.Close()
+
+			return db.Vacuum(context.Background())
+		},
+	}
+
+	dbPruneCmd = &cobra.Command{
+		Use:   "prune",
+		Short: "Delete history past its retention",
+		Long: `Delete the timeline events and the log of changes written to Jira older
+than database.retention of the configuration, or than the flags given. The
+server also prunes daily when a retention is configured.`,
+		Args: cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			cfg, err := initConfig()
+			if err != nil {
+				return fmt.Errorf("failed to load configuration: %w", err)
+			}
+			retention := cfg.Database.Retention
+			if cmd.Flags().Changed("events") {
+				retention.Events = pruneEvents
+			}
+			if cmd.Flags().Changed("audit") {
+				retention.Audit = pruneAudit
+			}
+			if retention.Events <= 0 && retention.Audit <= 0 {
+				return fmt.Errorf("no retention set: use --events, --audit or database.retention in the configuration")
+			}
+
+			store, err := initStorage(cfg)
+			if err != nil {
+				return fmt.Errorf("failed to open database: %w", err)
+			}
+			defer store.Close()
+			db, ok := store.(services.HistoryPruner)
+			if !ok {
+				return fmt.Errorf("the storage backend does not support pruning")
+			}
+
+			result, err := services.PruneHistory(context.Background(), db, retention, time.Now())
+			if err != nil {
+				return err
+			}
+
+			fmt.Printf("Pruned %d events and %d audit entries\n", result.Events, result.AuditEntries)
+			return nil
+		},
+	}
+)
+
+// sqlitePath returns the path of the SQLite database configured, failing for
+// other backends
+func sqlitePath(cfg *config.Config) (string, error) {
+	backend, path, err := storage.ParseDSN(cfg.Database.DataSource())
+	if err != nil {
+		return "", err
+	}
+	if backend != storage.BackendSQLite {
+		return "", fmt.Errorf("this command only supports SQLite, use the %s tools such as pg_dump instead", backend)
+	}
+	return path, nil
+}
+
+func init() {
+	rootCmd.AddCommand(dbCmd)
+	dbCmd.AddCommand(dbBackupCmd)
+	dbCmd.AddCommand(dbRestoreCmd)
+	dbCmd.AddCommand(dbVacuumCmd)
+	dbCmd.AddCommand(dbPruneCmd)
+
+	dbPruneCmd.Flags().DurationVar(&pruneEvents, "events", 0, "Keep timeline events this long, e.g. 2160h")
+	dbPruneCmd.Flags().DurationVar(&pruneAudit, "audit", 0, "Keep the Jira audit log this long")
+}
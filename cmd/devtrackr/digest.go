@@ -0,0 +1,71 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"strconv"
+	"time"
+
+	"github.com/jparrill/devtrackr/internal/report"
+	"github.com/spf13/cobra"
+)
+
+var (
+	digestSince  time.Duration
+	digestUser   string
+	digestFormat string
+
+	digestCmd = &cobra.Command{
+		Use:   "digest",
+		Short: "Summarize what changed on subscribed issues",
+		Long: `Summarize what changed on the issues a user is subscribed to: status
+transitions, pull requests opened, merged or closed and new backports, followed
+by what still blocks each issue. Issues with neither are only counted.
+
+--user takes a user ID, as sent in the X-User-ID header, or "me" for the
+digest.me user of the configuration. The server can also send the digest
+every day, see digest in the configuration.`,
+		Args: cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			format, err := report.ParseFormat(digestFormat)
+			if err != nil {
+				return err
+			}
+
+			cfg, err := initConfig()
+			if err != nil {
+				return fmt.Errorf("failed to load configuration: %w", err)
+			}
+			userID := cfg.Digest.Me
+			if digestUser != "me" {
+				if userID, err = strconv.ParseInt(digestUser, 10, 64); err != nil {
+					return fmt.Errorf("invalid user ID %q", digestUser)
+				}
+			} else if userID == 0 {
+				return fmt.Errorf("set digest.me in the configuration to use --user me")
+			}
+
+			trackingService, storage, err := initTrackingService()
+			if err != nil {
+				return err
+			}
+			defer storage.Close()
+
+			digest, err := trackingService.Digest(context.Background(), userID, time.Now().Add(-digestSince))
+			if err != nil {
+				return fmt.Errorf("failed to build digest: %w", err)
+			}
+
+			return report.RenderDigest(os.Stdout, digest, format)
+		},
+	}
+)
+
+func init() {
+	rootCmd.AddCommand(digestCmd)
+
+	digestCmd.Flags().DurationVarP(&digestSince, "since", "s", 24*time.Hour, "How far back to look")
+	digestCmd.Flags().StringVarP(&digestUser, "user", "u", "me", `User ID, or "me"`)
+	digestCmd.Flags().StringVarP(&digestFormat, "format", "f", "text", "Output format (text, markdown, html, json)")
+}
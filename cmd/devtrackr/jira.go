@@ -0,0 +1,172 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"strings"
+	"text/tabwriter"
+
+	"github.com/jparrill/devtrackr/internal/models"
+	"github.com/jparrill/devtrackr/internal/services"
+	"github.com/spf13/cobra"
+)
+
+var (
+	jiraDryRun       bool
+	jiraLabelsAdd    []string
+	jiraLabelsRemove []string
+
+	jiraCmd = &cobra.Command{
+		Use:   "jira",
+		Short: "Change tracked issues in Jira",
+		Long: `Write changes back to Jira: move issues through their workflow, comment the
+state of their pull requests and edit their labels. Every change is recorded in
+the audit log of the issue, including dry runs and changes Jira rejected.`,
+	}
+
+	jiraTransitionsCmd = &cobra.Command{
+		Use:   "transitions [issue-key]",
+		Short: "List the workflow transitions available on an issue",
+		Args:  cobra.ExactArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			trackingService, storage, err := initTrackingService()
+			if err != nil {
+				return err
+			}
+			defer storage.Close()
+
+			transitions, err := trackingService.ListTransitions(context.Background(), args[0])
+			if err != nil {
+				return fmt.Errorf("failed to list transitions: %w", err)
+			}
+
+			tw := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+			fmt.Fprintln(tw, "ID\tTRANSITION\tTO STATUS")
+			for _, t := range transitions {
+				fmt.Fprintf(tw, "%s\t%s\t%s\n", t.ID, t.Name, t.To)
+			}
+			return tw.Flush()
+		},
+	}
+
+	jiraTransitionCmd = &cobra.Command{
+		Use:   "transition [issue-key] [transition]",
+		Short: "Move an issue through a workflow transition",
+		Long: `Move an issue through the transition with the given name, or the one leading
+to the status with that name, e.g. devtrackr jira transition OCPBUGS-123 "Code Review".`,
+		Args: cobra.ExactArgs(2),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			return runJiraWrite(func(svc *services.TrackingService, opts services.WriteOptions) (*models.AuditEntry, error) {
+				return svc.TransitionIssue(context.Background(), args[0], args[1], opts)
+			})
+		},
+	}
+
+	jiraCommentCmd = &cobra.Command{
+		Use:   "comment [issue-key]",
+		Short: "Comment the state of the linked pull requests on an issue",
+		Args:  cobra.ExactArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			return runJiraWrite(func(svc *services.TrackingService, opts services.WriteOptions) (*models.AuditEntry, error) {
+				return svc.CommentPullRequestSummary(context.Background(), args[0], opts)
+			})
+		},
+	}
+
+	jiraLabelsCmd = &cobra.Command{
+		Use:   "labels [issue-key]",
+		Short: "Add and remove labels of an issue",
+		Args:  cobra.ExactArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			return runJiraWrite(func(svc *services.TrackingService, opts services.WriteOptions) (*models.AuditEntry, error) {
+				return svc.UpdateLabels(context.Background(), args[0], jiraLabelsAdd, jiraLabelsRemove, opts)
+			})
+		},
+	}
+
+	jiraAuditCmd = &cobra.Command{
+		Use:   "audit [issue-key]",
+		Short: "Show the changes made to an issue in Jira",
+		Args:  cobra.ExactArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			trackingService, storage, err := initTrackingService()
+			if err != nil {
+				return err
+			}
+			defer storage.Close()
+
+			entries, err := trackingService.ListAuditEntries(context.Background(), args[0])
+			if err != nil {
+				return fmt.Errorf("failed to list audit entries: %w", err)
+			}
+
+			tw := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+			fmt.Fprintln(tw, "TIME\tACTOR\tACTION\tRESULT\tDETAILS")
+			for _, e := range entries {
+				fmt.Fprintf(tw, "%s\t%s\t%s\t%s\t%s\n",
+					e.CreatedAt.Local().Format("2006-01-02 15:04"), e.Actor, e.Action, auditResult(e), firstLine(e.Details))
+			}
+			return tw.Flush()
+		},
+	}
+)
+
+func init() {
+	rootCmd.AddCommand(jiraCmd)
+	jiraCmd.AddCommand(jiraTransitionsCmd)
+	jiraCmd.AddCommand(jiraTransitionCmd)
+	jiraCmd.AddCommand(jiraCommentCmd)
+	jiraCmd.AddCommand(jiraLabelsCmd)
+	jiraCmd.AddCommand(jiraAuditCmd)
+
+	jiraCmd.PersistentFlags().BoolVarP(&jiraDryRun, "dry-run", "n", false, "Record the change in the audit log without sending it to Jira")
+
+	jiraLabelsCmd.Flags().StringSliceVarP(&jiraLabelsAdd, "add", "a", nil, "Labels to add")
+	jiraLabelsCmd.Flags().StringSliceVarP(&jiraLabelsRemove, "remove", "r", nil, "Labels to remove")
+}
+
+// runJiraWrite runs a write-back on behalf of the local user and prints what
+// was, or would have been, sent to Jira
+func runJiraWrite(write func(*services.TrackingService, services.WriteOptions) (*models.AuditEntry, error)) error {
+	trackingService, storage, err := initTrackingService()
+	if err != nil {
+		return err
+	}
+	defer storage.Close()
+
+	entry, err := write(trackingService, services.WriteOptions{
+		DryRun: jiraDryRun,
+		Actor:  "cli:" + os.Getenv("USER"),
+	})
+	if err != nil {
+		return err
+	}
+
+	if entry.DryRun {
+		fmt.Printf("Dry run, would send %s:\n%s\n", entry.Action, entry.Details)
+		return nil
+	}
+	fmt.Printf("Sent %s:\n%s\n", entry.Action, entry.Details)
+	return nil
+}
+
+// auditResult summarizes the outcome of an audited change
+func auditResult(e *models.AuditEntry) string {
+	switch {
+	case e.DryRun:
+		return "dry-run"
+	case e.Error != "":
+		return "failed: " + e.Error
+	default:
+		return "ok"
+	}
+}
+
+// firstLine returns the first line of s, marking that more was cut off
+func firstLine(s string) string {
+	if line, _, cut := strings.Cut(s, "\n"); cut {
+		return line + " ..."
+	}
+	return s
+}
@@ -0,0 +1,97 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"os"
+	"strings"
+
+	"github.com/jparrill/devtrackr/internal/models"
+	"github.com/jparrill/devtrackr/internal/report"
+	"github.com/spf13/cobra"
+)
+
+var (
+	listOutput     string
+	listColumns    []string
+	listPRColumns  []string
+	listProjects   []string
+	listStatuses   []string
+	listAssignees  []string
+	listFixVersion []string
+
+	listCmd = &cobra.Command{
+		Use:   "list",
+		Short: "List tracked issues",
+		Long: `List tracked issues as a table, CSV, an XLSX workbook or JSON.
+
+--columns selects the issue columns among:
+
+  ` + strings.Join(report.IssueColumns(), ", ") + `
+
+and attr.<name> for custom field attributes. prs, prs_open, prs_merged,
+backports, pull_requests and merged_branches roll up the pull requests of each
+issue. Dates are ISO 8601 in UTC.
+
+XLSX workbooks, written to the standard output, add a sheet of pull requests
+with the --pr-columns selected among:
+
+  ` + strings.Join(report.PullRequestColumns(), ", "),
+		Args: cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			format, err := report.ParseFormat(listOutput)
+			if err != nil {
+				return err
+			}
+
+			trackingService, storage, err := initTrackingService()
+			if err != nil {
+				return err
+			}
+			defer storage.Close()
+
+			filter := models.IssueFilter{
+				Project:    listProjects,
+				Status:     listStatuses,
+				Assignee:   listAssignees,
+				FixVersion: listFixVersion,
+			}
+			rows, err := trackingService.IssueRows(context.Background(), filter)
+			if err != nil {
+				return fmt.Errorf("failed to list issues: %w", err)
+			}
+
+			if format == report.FormatJSON {
+				enc := json.NewEncoder(os.Stdout)
+				enc.SetIndent("", "  ")
+				return enc.Encode(rows)
+			}
+
+			issues, err := report.IssueSheet(rows, listColumns)
+			if err != nil {
+				return err
+			}
+			if format != report.FormatXLSX {
+				return report.RenderSheet(os.Stdout, issues, format)
+			}
+			prs, err := report.PullRequestSheet(report.NewPullRequestRows(rows), listPRColumns)
+			if err != nil {
+				return err
+			}
+			return report.WriteXLSX(os.Stdout, issues, prs)
+		},
+	}
+)
+
+func init() {
+	rootCmd.AddCommand(listCmd)
+
+	listCmd.Flags().StringVarP(&listOutput, "output", "o", "table", "Output format (table, csv, xlsx, json)")
+	listCmd.Flags().StringSliceVar(&listColumns, "columns", nil, "Issue columns to list (default: "+strings.Join(report.DefaultIssueColumns, ",")+")")
+	listCmd.Flags().StringSliceVar(&listPRColumns, "pr-columns", nil, "Pull request columns of XLSX workbooks (default: "+strings.Join(report.DefaultPullRequestColumns, ",")+")")
+	listCmd.Flags().StringSliceVarP(&listProjects, "project", "p", nil, "Only list issues of these Jira projects")
+	listCmd.Flags().StringSliceVarP(&listStatuses, "status", "s", nil, "Only list issues in these statuses")
+	listCmd.Flags().StringSliceVarP(&listAssignees, "assignee", "a", nil, "Only list issues assigned to these users")
+	listCmd.Flags().StringSliceVar(&listFixVersion, "fix-version", nil, "Only list issues targeting these releases")
+}
@@ -1,22 +1,37 @@
 package main
 
 import (
+	"bytes"
 	"context"
+	"encoding/json"
+	"errors"
 	"fmt"
+	"io"
+	"net/http"
+	"net/url"
 	"os"
 	"os/signal"
+	"strings"
 	"syscall"
 	"time"
 
 	"github.com/jparrill/devtrackr/internal/api"
+	"github.com/jparrill/devtrackr/internal/backport"
+	"github.com/jparrill/devtrackr/internal/config"
+	"github.com/jparrill/devtrackr/internal/forge"
 	"github.com/jparrill/devtrackr/internal/jira"
 	"github.com/jparrill/devtrackr/internal/services"
 	"github.com/jparrill/devtrackr/internal/storage"
 	"github.com/spf13/cobra"
 )
 
+// serverAddr is the address the API server listens on
+const serverAddr = ":8080"
+
 var (
 	pollingInterval int
+	configPath      string
+	serverURL       string
 	rootCmd         = &cobra.Command{
 		Use:   "devtrackr",
 		Short: "DevTrackr - Jira issue tracking service",
@@ -30,36 +45,129 @@ var (
 			ctx, cancel := context.WithCancel(context.Background())
 			defer cancel()
 
+			cfg, err := initConfig()
+			if err != nil {
+				return fmt.Errorf("failed to load configuration: %w", err)
+			}
+
 			// Initialize services
-			storage, err := initStorage()
+			storage, err := initStorage(cfg)
 			if err != nil {
 				return fmt.Errorf("failed to initialize storage: %w", err)
 			}
 
-			jira, err := initJira()
+			jira, err := initJira(cfg)
 			if err != nil {
 				return fmt.Errorf("failed to initialize Jira client: %w", err)
 			}
 
-			// Create and start polling service
-			pollingService := services.NewPollingService(storage, jira, time.Duration(pollingInterval)*time.Minute)
-			if err := pollingService.Start(ctx); err != nil {
-				return fmt.Errorf("failed to start polling service: %w", err)
+			detector, err := backport.NewDetector(cfg.Backport)
+			if err != nil {
+				return fmt.Errorf("failed to initialize backport detector: %w", err)
+			}
+
+			forges, err := forge.NewRegistryFromConfig(cfg.Forges)
+			if err != nil {
+				return fmt.Errorf("failed to initialize forges: %w", err)
+			}
+
+			// The flag overrides the default interval of the configuration
+			if cmd.Flags().Changed("poll") {
+				cfg.Polling.Interval = time.Duration(pollingInterval) * time.Minute
+			}
+
+			ruleEngine, err := cfg.RuleEngine()
+			if err != nil {
+				return fmt.Errorf("invalid rules: %w", err)
 			}
 
+			// Create polling service
+			pollingService := services.NewPollingService(storage, jira, cfg.Polling.Interval,
+				services.WithPollingForges(forges),
+				services.WithPollingBackportDetector(detector),
+				services.WithWebhookPollingInterval(cfg.Webhooks.Jira.PollingInterval),
+				services.WithPollingPolicy(cfg.PollingPolicy()),
+			)
+
 			// Create tracking service
-			trackingService := services.NewTrackingService(storage, jira)
+			trackingService := services.NewTrackingService(storage, jira,
+				services.WithConfig(cfg),
+				services.WithBackportDetector(detector),
+				services.WithForges(forges),
+				services.WithIssueChangeNotifier(pollingService),
+			)
+
+			var webhook *services.WebhookNotifier
+			var notifier services.Notifier = services.LogNotifier{}
+			if url := cfg.Notifications.WebhookURL(); url != "" {
+				webhook = services.NewWebhookNotifier(url)
+				notifier = webhook
+			}
+
+			// Run automation rules on the changes found by polling or
+			// delivered by webhooks
+			ruleService := services.NewRuleService(trackingService, ruleEngine, services.WithNotifier(notifier))
+			pollingService.SetEventListener(ruleService)
+			trackingService.SetEventListener(ruleService)
+			go ruleService.Start(ctx)
+			defer ruleService.Stop()
+
+			// Look for stale work, nudging through the notifier if enabled
+			if cfg.Alerts.Interval > 0 {
+				var alertNotifier services.Notifier
+				if cfg.Alerts.Notify {
+					alertNotifier = notifier
+				}
+				alertService := services.NewAlertService(trackingService, cfg.Alerts.Interval, alertNotifier)
+				go alertService.Start(ctx)
+				defer alertService.Stop()
+			}
+
+			// Send the daily digest of subscribed issues, if scheduled
+			if cfg.Digest.At != "" {
+				delivery := services.NewDigestDelivery(cfg.Digest.SMTP, webhook)
+				digestService := services.NewDigestService(trackingService, cfg.Digest, delivery)
+				go func() {
+					if err := digestService.Start(ctx); err != nil {
+						fmt.Printf("Error running digest service: %v\n", err)
+					}
+				}()
+				defer digestService.Stop()
+			}
+
+			// Back up and prune the database, if configured
+			retention := cfg.Database.Retention
+			if db, ok := storage.(services.HistoryPruner); ok &&
+				(cfg.Database.Backup.Dir != "" || retention.Events > 0 || retention.Audit > 0) {
+				maintenanceService := services.NewMaintenanceService(db, cfg.Database)
+				go maintenanceService.Start(ctx)
+				defer maintenanceService.Stop()
+			}
+
+			// Start polling service
+			go func() {
+				if err := pollingService.Start(ctx); err != nil {
+					fmt.Printf("Error running polling service: %v\n", err)
+				}
+			}()
+
+			// Start scanning local git repositories, if any are configured
+			if len(cfg.GitScan.Repositories) > 0 {
+				gitScanService := services.NewGitScanService(trackingService, cfg.GitScan)
+				go gitScanService.Start(ctx)
+				defer gitScanService.Stop()
+			}
 
 			// Start API server
-			api := initAPI(trackingService)
+			api := initAPI(trackingService, cfg)
 			go func() {
-				if err := api.Start(":8080"); err != nil {
+				if err := api.Start(serverAddr); err != nil {
 					fmt.Printf("Error starting API server: %v\n", err)
 					os.Exit(1)
 				}
 			}()
 
-			fmt.Printf("Server started with polling interval of %d minutes\n", pollingInterval)
+			fmt.Printf("Server started with default polling interval of %v\n", cfg.Polling.Interval)
 
 			// Wait for interrupt signal
 			sigChan := make(chan os.Signal, 1)
@@ -76,46 +184,58 @@ var (
 	setPollingCmd = &cobra.Command{
 		Use:   "set-polling [issue-key] [interval]",
 		Short: "Set the polling interval for an issue",
-		Long:  `Set the polling interval (in minutes) for a specific issue. Use 0 to use the default interval.`,
-		Args:  cobra.ExactArgs(2),
+		Long: `Set the polling interval (a duration such as 30s or 10m) for a specific issue. Use 0 to use the default interval.
+
+The interval is set through the API of the server at --server, which polls
+the issue on its new schedule right away. When no server answers there, it is
+written to the database directly and running servers pick it up within ten
+minutes.`,
+		Args: cobra.ExactArgs(2),
 		RunE: func(cmd *cobra.Command, args []string) error {
 			key := args[0]
 			interval, err := time.ParseDuration(args[1])
 			if err != nil {
 				return fmt.Errorf("invalid interval format: %w", err)
 			}
+			seconds := int(interval.Seconds())
 
-			// Initialize services
-			storage, err := initStorage()
-			if err != nil {
-				return fmt.Errorf("failed to initialize storage: %w", err)
+			// A running server reschedules the issue as soon as it is told
+			var unreachable *url.Error
+			err = setPollingOnServer(context.Background(), serverURL, key, seconds)
+			if err == nil {
+				fmt.Printf("Updated polling interval for issue %s to %v\n", key, interval)
+				return nil
+			}
+			if !errors.As(err, &unreachable) {
+				return fmt.Errorf("failed to update polling interval: %w", err)
 			}
 
-			jira, err := initJira()
+			trackingService, storage, err := initTrackingService()
 			if err != nil {
-				return fmt.Errorf("failed to initialize Jira client: %w", err)
+				return err
 			}
+			defer storage.Close()
 
-			// Create tracking service
-			trackingService := services.NewTrackingService(storage, jira)
-
-			// Update polling interval
-			if err := trackingService.UpdateIssuePollingInterval(context.Background(), key, int(interval.Seconds())); err != nil {
+			if err := trackingService.UpdateIssuePollingInterval(context.Background(), key, seconds); err != nil {
 				return fmt.Errorf("failed to update polling interval: %w", err)
 			}
 
-			fmt.Printf("Updated polling interval for issue %s to %v\n", key, interval)
+			fmt.Printf("Updated polling interval for issue %s to %v; no server answered at %s, so running servers pick it up within ten minutes\n",
+				key, interval, serverURL)
 			return nil
 		},
 	}
 )
 
 func init() {
+	rootCmd.PersistentFlags().StringVarP(&configPath, "config", "c", config.DefaultPath, "Path to the configuration file")
+
 	rootCmd.AddCommand(serveCmd)
 	rootCmd.AddCommand(setPollingCmd)
 
 	// Add polling interval flag
-	serveCmd.Flags().IntVarP(&pollingInterval, "poll", "p", 5, "Default polling interval in minutes (default: 5)")
+	serveCmd.Flags().IntVarP(&pollingInterval, "poll", "p", 5, "Default polling interval in minutes, overriding polling.interval from the configuration")
+	setPollingCmd.Flags().StringVar(&serverURL, "server", "http://localhost"+serverAddr, "URL of the running DevTrackr server")
 }
 
 func main() {
@@ -125,19 +245,93 @@ func main() {
 	}
 }
 
-// initStorage initializes the storage service
-func initStorage() (storage.Storage, error) {
-	// TODO: Make this configurable
-	return storage.NewSQLiteStorage("devtrackr.db")
+// initConfig loads the configuration file
+func initConfig() (*config.Config, error) {
+	return config.Load(configPath)
+}
+
+// initStorage opens the storage backend selected by database.dsn of the
+// configuration
+func initStorage(cfg *config.Config) (storage.Storage, error) {
+	return storage.Open(cfg.Database.DataSource())
+}
+
+// initJira initializes a client routing requests to the configured Jira
+// instances
+func initJira(cfg *config.Config) (jira.JiraClient, error) {
+	return jira.NewRouterFromConfig(cfg.Jira)
 }
 
-// initJira initializes the Jira client
-func initJira() (jira.JiraClient, error) {
-	// TODO: Make this configurable
-	return jira.NewClient("https://issues.redhat.com"), nil
+// initTrackingService initializes the configuration, storage and Jira client
+// and returns a tracking service for one-shot commands. Callers must close the
+// returned storage.
+func initTrackingService() (*services.TrackingService, storage.Storage, error) {
+	cfg, err := initConfig()
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to load configuration: %w", err)
+	}
+
+	detector, err := backport.NewDetector(cfg.Backport)
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to initialize backport detector: %w", err)
+	}
+
+	forges, err := forge.NewRegistryFromConfig(cfg.Forges)
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to initialize forges: %w", err)
+	}
+
+	store, err := initStorage(cfg)
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to initialize storage: %w", err)
+	}
+
+	jira, err := initJira(cfg)
+	if err != nil {
+		store.Close()
+		return nil, nil, fmt.Errorf("failed to initialize Jira client: %w", err)
+	}
+
+	trackingService := services.NewTrackingService(store, jira,
+		services.WithConfig(cfg),
+		services.WithBackportDetector(detector),
+		services.WithForges(forges),
+	)
+	return trackingService, store, nil
+}
+
+// setPollingOnServer sets the polling interval of an issue through the API of
+// a running server. Failures to reach the server are *url.Error.
+func setPollingOnServer(ctx context.Context, server, key string, seconds int) error {
+	body, err := json.Marshal(map[string]int{"polling_interval": seconds})
+	if err != nil {
+		return err
+	}
+	endpoint := strings.TrimSuffix(server, "/") + "/api/v1/issues/" + url.PathEscape(key) + "/polling-interval"
+	req, err := http.NewRequestWithContext(ctx, http.MethodPut, endpoint, bytes.NewReader(body))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", "application/json")
+
+	client := &http.Client{Timeout: 5 * time.Second}
+	resp, err := client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		message, _ := io.ReadAll(io.LimitReader(resp.Body, 1024))
+		return fmt.Errorf("server returned %s: %s", resp.Status, strings.TrimSpace(string(message)))
+	}
+	return nil
 }
 
 // initAPI initializes the API server
-func initAPI(trackingService *services.TrackingService) *api.Server {
-	return api.NewServer(trackingService)
+func initAPI(trackingService *services.TrackingService, cfg *config.Config) *api.Server {
+	return api.NewServer(trackingService,
+		api.WithGitHubWebhookSecret(cfg.Webhooks.GitHub.Secret()),
+		api.WithJiraWebhookSecret(cfg.Webhooks.Jira.Secret()),
+	)
 }
@@ -0,0 +1,58 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"strings"
+	"text/tabwriter"
+
+	"github.com/spf13/cobra"
+)
+
+var (
+	mergeStatusSync bool
+
+	mergeStatusCmd = &cobra.Command{
+		Use:   "merge-status [issue-key]",
+		Short: "Explain why the pull requests of an issue are not merged",
+		Long: `Show the pull requests of an issue with their CI rollup, approvals and
+mergeability, and list what keeps each of them from merging: failing checks,
+pending reviewers, missing approvals, merge conflicts or blocking labels.`,
+		Args: cobra.ExactArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			trackingService, storage, err := initTrackingService()
+			if err != nil {
+				return err
+			}
+			defer storage.Close()
+
+			statuses, err := trackingService.MergeStatus(context.Background(), args[0], mergeStatusSync)
+			if err != nil {
+				return fmt.Errorf("failed to get merge status: %w", err)
+			}
+
+			tw := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+			fmt.Fprintln(tw, "PULL REQUEST\tBRANCH\tSTATUS\tCI\tAPPROVALS\tBLOCKERS")
+			for _, st := range statuses {
+				ci := string(st.CIStatus)
+				if ci == "" {
+					ci = "-"
+				}
+				blockers := strings.Join(st.Blockers, "; ")
+				if blockers == "" {
+					blockers = "-"
+				}
+				fmt.Fprintf(tw, "%s#%d\t%s\t%s\t%s\t%d\t%s\n",
+					st.Repository, st.Number, st.TargetBranch, st.Status, ci, st.Approvals, blockers)
+			}
+			return tw.Flush()
+		},
+	}
+)
+
+func init() {
+	rootCmd.AddCommand(mergeStatusCmd)
+
+	mergeStatusCmd.Flags().BoolVarP(&mergeStatusSync, "sync", "s", false, "Refresh the pull requests from their forge first")
+}
@@ -0,0 +1,132 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"os"
+
+	"github.com/jparrill/devtrackr/internal/report"
+	"github.com/spf13/cobra"
+)
+
+var (
+	reportFormat   string
+	reportBranch   string
+	matrixFormat   string
+	matrixBranches []string
+	matrixProject  string
+	clonesFormat   string
+
+	reportCmd = &cobra.Command{
+		Use:   "report",
+		Short: "Generate reports about tracked issues",
+		Long:  `Generate reports about tracked issues and their pull requests.`,
+	}
+
+	reportReleaseCmd = &cobra.Command{
+		Use:   "release [version]",
+		Short: "Show the readiness of a release",
+		Long: `Show every tracked issue whose Jira fix versions target the given release,
+together with its pull requests per target branch and anything blocking it:
+unmerged pull requests, issues not in a done state and missing backports.`,
+		Args: cobra.ExactArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			format, err := report.ParseFormat(reportFormat)
+			if err != nil {
+				return err
+			}
+			if err := report.CheckReleaseFormat(format); err != nil {
+				return err
+			}
+
+			trackingService, storage, err := initTrackingService()
+			if err != nil {
+				return err
+			}
+			defer storage.Close()
+
+			rep, err := trackingService.ReleaseReport(context.Background(), args[0], reportBranch)
+			if err != nil {
+				return fmt.Errorf("failed to build release report: %w", err)
+			}
+
+			return report.RenderRelease(os.Stdout, rep, format)
+		},
+	}
+
+	reportClonesCmd = &cobra.Command{
+		Use:   "clones [issue-key]",
+		Short: "Show a bug across the releases it was cloned for",
+		Long: `Show a bug and its clones for each z-stream release, found through the clone
+links of tracked issues starting at any issue of the chain. Each clone is
+paired with the pull request targeting the release branch of its target
+version, and gaps and mismatches are flagged: clones without a pull request,
+pull requests merged while the clone is still New, clones closed with open
+pull requests and pull requests no clone targets.`,
+		Args: cobra.ExactArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			format, err := report.ParseFormat(clonesFormat)
+			if err != nil {
+				return err
+			}
+
+			trackingService, storage, err := initTrackingService()
+			if err != nil {
+				return err
+			}
+			defer storage.Close()
+
+			chain, err := trackingService.CloneChain(context.Background(), args[0])
+			if err != nil {
+				return fmt.Errorf("failed to build clone chain: %w", err)
+			}
+
+			return report.RenderClones(os.Stdout, chain, format)
+		},
+	}
+
+	reportBackportsCmd = &cobra.Command{
+		Use:   "backports",
+		Short: "Show the backport matrix of tracked issues",
+		Long: `Show a matrix with one row per tracked issue and one column per release
+branch. Each cell shows the pull request targeting that branch and its state,
+or "missing" when the branch is expected but has no pull request. Expected
+branches come from --branches or from the per-project configuration.`,
+		Args: cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			format, err := report.ParseFormat(matrixFormat)
+			if err != nil {
+				return err
+			}
+
+			trackingService, storage, err := initTrackingService()
+			if err != nil {
+				return err
+			}
+			defer storage.Close()
+
+			matrix, err := trackingService.BackportMatrix(context.Background(), matrixBranches, matrixProject)
+			if err != nil {
+				return fmt.Errorf("failed to build backport matrix: %w", err)
+			}
+
+			return report.RenderBackports(os.Stdout, matrix, format)
+		},
+	}
+)
+
+func init() {
+	rootCmd.AddCommand(reportCmd)
+	reportCmd.AddCommand(reportReleaseCmd)
+	reportCmd.AddCommand(reportBackportsCmd)
+	reportCmd.AddCommand(reportClonesCmd)
+
+	reportReleaseCmd.Flags().StringVarP(&reportFormat, "format", "f", "markdown", "Output format (markdown, html, json)")
+	reportReleaseCmd.Flags().StringVarP(&reportBranch, "branch", "b", "", "Release branch (default: release-<version>)")
+
+	reportBackportsCmd.Flags().StringVarP(&matrixFormat, "format", "f", "table", "Output format (table, csv, markdown, json)")
+	reportBackportsCmd.Flags().StringSliceVarP(&matrixBranches, "branches", "b", nil, "Branches to show (default: configured per project)")
+	reportBackportsCmd.Flags().StringVarP(&matrixProject, "project", "p", "", "Only show issues of this Jira project")
+
+	reportClonesCmd.Flags().StringVarP(&clonesFormat, "format", "f", "table", "Output format (table, markdown, json)")
+}
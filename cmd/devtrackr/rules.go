@@ -0,0 +1,76 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"strings"
+	"text/tabwriter"
+
+	"github.com/jparrill/devtrackr/internal/services"
+	"github.com/spf13/cobra"
+)
+
+var (
+	rulesCmd = &cobra.Command{
+		Use:   "rules",
+		Short: "Inspect the automation rules",
+		Long:  `Inspect the automation rules declared under rules in the configuration.`,
+	}
+
+	rulesTestCmd = &cobra.Command{
+		Use:   "test [issue-key]",
+		Short: "Replay recorded timelines through the automation rules",
+		Long: `Replay the recorded timeline of an issue, or of every tracked issue, through
+the automation rules and show which rules would have fired and what they would
+have done. Nothing is sent to Jira and no notification is delivered.
+
+Issue and pull request states are rewound to before the first recorded change;
+other fields keep their current value. Scheduled rules are not replayed.`,
+		Args: cobra.MaximumNArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			cfg, err := initConfig()
+			if err != nil {
+				return fmt.Errorf("failed to load configuration: %w", err)
+			}
+			engine, err := cfg.RuleEngine()
+			if err != nil {
+				return fmt.Errorf("invalid rules: %w", err)
+			}
+
+			trackingService, storage, err := initTrackingService()
+			if err != nil {
+				return err
+			}
+			defer storage.Close()
+
+			var key string
+			if len(args) > 0 {
+				key = args[0]
+			}
+
+			matches, err := services.NewRuleService(trackingService, engine).Replay(context.Background(), key)
+			if err != nil {
+				return fmt.Errorf("failed to replay timeline: %w", err)
+			}
+			if len(matches) == 0 {
+				fmt.Printf("None of the %d rules would have fired\n", len(engine.Rules()))
+				return nil
+			}
+
+			tw := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+			fmt.Fprintln(tw, "TIME\tISSUE\tCHANGE\tRULE\tACTIONS")
+			for _, m := range matches {
+				fmt.Fprintf(tw, "%s\t%s\t%s: %s -> %s\t%s\t%s\n",
+					m.Event.OccurredAt.Local().Format("2006-01-02 15:04"), m.Issue,
+					m.Event.Field, m.Event.From, m.Event.To, m.Rule, strings.Join(m.Actions, "; "))
+			}
+			return tw.Flush()
+		},
+	}
+)
+
+func init() {
+	rootCmd.AddCommand(rulesCmd)
+	rulesCmd.AddCommand(rulesTestCmd)
+}
@@ -0,0 +1,60 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"strings"
+	"text/tabwriter"
+
+	"github.com/spf13/cobra"
+)
+
+var (
+	scanGitBranches []string
+
+	scanGitCmd = &cobra.Command{
+		Use:   "scan-git [path]",
+		Short: "Scan a local git repository for commits referencing tracked issues",
+		Long: `Walk the history of a local git repository and find the commits whose
+messages mention tracked issue keys. For each of them, record which of the
+scanned branches and which tags contain it, so questions such as "is
+OCPBUGS-1234 in release-4.15 yet?" can be answered offline.`,
+		Args: cobra.ExactArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			trackingService, storage, err := initTrackingService()
+			if err != nil {
+				return err
+			}
+			defer storage.Close()
+
+			commits, err := trackingService.ScanGitRepository(context.Background(), args[0], scanGitBranches)
+			if err != nil {
+				return err
+			}
+
+			issues, err := trackingService.ListIssues(context.Background())
+			if err != nil {
+				return err
+			}
+			keys := map[int64]string{}
+			for _, issue := range issues {
+				keys[issue.ID] = issue.Key
+			}
+
+			tw := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+			fmt.Fprintln(tw, "ISSUE\tCOMMIT\tBRANCHES\tTAGS\tSUBJECT")
+			for _, c := range commits {
+				fmt.Fprintf(tw, "%s\t%.12s\t%s\t%s\t%s\n",
+					keys[c.IssueID], c.SHA, strings.Join(c.Branches, ","), strings.Join(c.Tags, ","), c.Subject)
+			}
+			return tw.Flush()
+		},
+	}
+)
+
+func init() {
+	rootCmd.AddCommand(scanGitCmd)
+
+	scanGitCmd.Flags().StringSliceVarP(&scanGitBranches, "branches", "b", nil, "Branch patterns to scan, e.g. main,release-* (default: all branches)")
+}
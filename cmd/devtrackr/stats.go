@@ -0,0 +1,80 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"os"
+
+	"github.com/jparrill/devtrackr/internal/analytics"
+	"github.com/jparrill/devtrackr/internal/models"
+	"github.com/jparrill/devtrackr/internal/report"
+	"github.com/spf13/cobra"
+)
+
+var (
+	statsGroupBy    string
+	statsFormat     string
+	statsProjects   []string
+	statsAssignees  []string
+	statsFixVersion []string
+
+	statsCmd = &cobra.Command{
+		Use:   "stats [metric]",
+		Short: "Show cycle time analytics of tracked issues",
+		Long: `Show percentile summaries of how long tracked issues and their pull requests
+take. The metric is one of:
+
+  time-in-status  time spent in each Jira status, until now for the current one
+  lead-time       time from tracking an issue to its first done status
+  merge-time      time from tracking a pull request to its merge
+  backport-lag    time from the merge of an original pull request to the
+                  merge of its backport
+
+Times are the ones observed while tracking. Use --group-by to summarize per
+issue, project, assignee or release, and --format csv to export.`,
+		Args:      cobra.ExactArgs(1),
+		ValidArgs: []string{"time-in-status", "lead-time", "merge-time", "backport-lag"},
+		RunE: func(cmd *cobra.Command, args []string) error {
+			metric, err := analytics.ParseMetric(args[0])
+			if err != nil {
+				return err
+			}
+			by, err := analytics.ParseGroupBy(statsGroupBy)
+			if err != nil {
+				return err
+			}
+			format, err := report.ParseFormat(statsFormat)
+			if err != nil {
+				return err
+			}
+
+			trackingService, storage, err := initTrackingService()
+			if err != nil {
+				return err
+			}
+			defer storage.Close()
+
+			filter := models.IssueFilter{
+				Project:    statsProjects,
+				Assignee:   statsAssignees,
+				FixVersion: statsFixVersion,
+			}
+			rep, err := trackingService.Analytics(context.Background(), metric, by, filter)
+			if err != nil {
+				return fmt.Errorf("failed to compute %s: %w", metric, err)
+			}
+
+			return analytics.Render(os.Stdout, rep, format)
+		},
+	}
+)
+
+func init() {
+	rootCmd.AddCommand(statsCmd)
+
+	statsCmd.Flags().StringVarP(&statsGroupBy, "group-by", "g", "all", "Group by issue, project, assignee, release or all")
+	statsCmd.Flags().StringVarP(&statsFormat, "format", "f", "table", "Output format (table, csv, json)")
+	statsCmd.Flags().StringSliceVarP(&statsProjects, "project", "p", nil, "Only include issues of these Jira projects")
+	statsCmd.Flags().StringSliceVarP(&statsAssignees, "assignee", "a", nil, "Only include issues assigned to these users")
+	statsCmd.Flags().StringSliceVar(&statsFixVersion, "fix-version", nil, "Only include issues targeting these releases")
+}
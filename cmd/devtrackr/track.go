@@ -0,0 +1,101 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"github.com/jparrill/devtrackr/internal/services"
+	"github.com/spf13/cobra"
+)
+
+var (
+	trackWithChildren bool
+
+	trackCmd = &cobra.Command{
+		Use:   "track [jira-url]",
+		Short: "Start tracking a Jira issue",
+		Long: `Start tracking a Jira issue, or refresh it from Jira if it is already tracked.
+
+With --with-children, the issues of an epic and the sub-tasks of every issue
+are tracked too, all the way down the hierarchy.`,
+		Args: cobra.ExactArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			trackingService, storage, err := initTrackingService()
+			if err != nil {
+				return err
+			}
+			defer storage.Close()
+
+			ctx := context.Background()
+			if !trackWithChildren {
+				issue, err := trackingService.TrackIssue(ctx, args[0])
+				if err != nil {
+					return fmt.Errorf("failed to track issue: %w", err)
+				}
+				fmt.Printf("Tracking %s: %s [%s]\n", issue.QualifiedKey(), issue.Title, issue.Status)
+				return nil
+			}
+
+			tracked, err := trackingService.TrackIssueWithChildren(ctx, args[0])
+			for _, issue := range tracked {
+				fmt.Printf("Tracking %s: %s [%s]\n", issue.QualifiedKey(), issue.Title, issue.Status)
+			}
+			if err != nil {
+				return fmt.Errorf("failed to track issue hierarchy: %w", err)
+			}
+			return nil
+		},
+	}
+
+	treeCmd = &cobra.Command{
+		Use:   "tree [issue-key]",
+		Short: "Show the issues below a tracked issue",
+		Long: `Show the epics' issues, sub-tasks and issue links below a tracked issue, with
+the share of done issues and merged pull requests rolled up to each parent.
+Children that are not tracked are marked with an asterisk and show the status
+seen when their parent was last read from Jira.`,
+		Args: cobra.ExactArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			trackingService, storage, err := initTrackingService()
+			if err != nil {
+				return err
+			}
+			defer storage.Close()
+
+			tree, err := trackingService.IssueTree(context.Background(), args[0])
+			if err != nil {
+				return fmt.Errorf("failed to build issue tree: %w", err)
+			}
+			printTree(tree, 0)
+			return nil
+		},
+	}
+)
+
+// printTree prints a node and the nodes below it, indented by depth
+func printTree(node *services.IssueTreeNode, depth int) {
+	indent := strings.Repeat("  ", depth)
+	marker := ""
+	if !node.Tracked {
+		marker = "*"
+	}
+
+	p := node.Progress
+	fmt.Printf("%s%s%s [%s] %s (%d/%d done %.0f%%, %d/%d PRs merged %.0f%%)\n",
+		indent, node.Key, marker, node.Status, node.Title,
+		p.DoneIssues, p.Issues, p.DonePercent, p.MergedPullRequests, p.PullRequests, p.MergedPercent)
+	for _, link := range node.Links {
+		fmt.Printf("%s  ~ %s %s [%s]\n", indent, link.Relation, link.Key, link.Status)
+	}
+	for _, child := range node.Children {
+		printTree(child, depth+1)
+	}
+}
+
+func init() {
+	rootCmd.AddCommand(trackCmd)
+	rootCmd.AddCommand(treeCmd)
+
+	trackCmd.Flags().BoolVarP(&trackWithChildren, "with-children", "r", false, "Also track the issues of epics and sub-tasks below the issue")
+}
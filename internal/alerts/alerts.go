@@ -0,0 +1,174 @@
+// Package alerts describes stale work found among tracked issues and pull
+// requests, and the thresholds after which work is considered stale.
+package alerts
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"slices"
+	"strings"
+	"text/tabwriter"
+	"time"
+
+	"github.com/jparrill/devtrackr/internal/report"
+)
+
+// Thresholds set how long work may stay idle before it is flagged. Zero
+// disables a check.
+type Thresholds struct {
+	// PullRequestIdle flags open pull requests without activity for this long
+	PullRequestIdle time.Duration `yaml:"pull_request_idle"`
+	// StatusStuck flags issues not done that stayed in one status this long
+	StatusStuck time.Duration `yaml:"status_stuck"`
+	// BackportMissing flags expected backports still missing this long after
+	// the original pull request merged
+	BackportMissing time.Duration `yaml:"backport_missing"`
+	// PollStale flags issues whose last successful poll is older than this
+	PollStale time.Duration `yaml:"poll_stale"`
+}
+
+// Config sets how often stale work is looked for and the thresholds used
+type Config struct {
+	// Interval is how often the detector runs
+	Interval time.Duration `yaml:"interval"`
+	// Notify sends new alerts through the notification channels
+	Notify     bool `yaml:"notify"`
+	Thresholds `yaml:",inline"`
+	// Projects overrides the thresholds of single Jira projects, keyed by
+	// their key prefix (e.g. OCPBUGS). Unset fields keep the global value.
+	Projects map[string]Thresholds `yaml:"projects"`
+}
+
+// DefaultConfig returns the thresholds used when none are configured
+func DefaultConfig() Config {
+	return Config{
+		Interval: time.Hour,
+		Thresholds: Thresholds{
+			PullRequestIdle: 7 * 24 * time.Hour,
+			StatusStuck:     14 * 24 * time.Hour,
+			BackportMissing: 3 * 24 * time.Hour,
+			PollStale:       24 * time.Hour,
+		},
+		Projects: map[string]Thresholds{},
+	}
+}
+
+// ThresholdsFor returns the thresholds of a project, merged with the global ones
+func (c Config) ThresholdsFor(project string) Thresholds {
+	t := c.Thresholds
+	override, ok := c.Projects[project]
+	if !ok {
+		return t
+	}
+
+	for _, f := range []struct {
+		dst *time.Duration
+		src time.Duration
+	}{
+		{&t.PullRequestIdle, override.PullRequestIdle},
+		{&t.StatusStuck, override.StatusStuck},
+		{&t.BackportMissing, override.BackportMissing},
+		{&t.PollStale, override.PollStale},
+	} {
+		if f.src != 0 {
+			*f.dst = f.src
+		}
+	}
+	return t
+}
+
+// Kind identifies what kind of work is stale
+type Kind string
+
+const (
+	KindPullRequestIdle Kind = "pull_request_idle"
+	KindStatusStuck     Kind = "status_stuck"
+	KindBackportMissing Kind = "backport_missing"
+	KindPollStale       Kind = "poll_stale"
+)
+
+// Kinds lists every kind of alert
+var Kinds = []Kind{KindPullRequestIdle, KindStatusStuck, KindBackportMissing, KindPollStale}
+
+// ParseKind converts a user supplied kind name into a Kind
+func ParseKind(name string) (Kind, error) {
+	for _, k := range Kinds {
+		if strings.EqualFold(name, string(k)) {
+			return k, nil
+		}
+	}
+	return "", fmt.Errorf("unknown alert kind %q", name)
+}
+
+// Alert is a single piece of stale work
+type Alert struct {
+	Kind        Kind      `json:"kind"`
+	Issue       string    `json:"issue"` // Qualified key of the issue
+	Title       string    `json:"title"`
+	URL         string    `json:"url"` // The pull request, or else the issue
+	Assignee    string    `json:"assignee,omitempty"`
+	PullRequest string    `json:"pull_request,omitempty"` // e.g. org/repo#12
+	Branch      string    `json:"branch,omitempty"`       // Branch a backport is missing on
+	Since       time.Time `json:"since"`                  // When the work went idle
+	Message     string    `json:"message"`
+}
+
+// ID identifies an alert across runs of the detector, so the same stale work
+// is only notified once
+func (a Alert) ID() string {
+	return fmt.Sprintf("%s/%s/%s/%s", a.Kind, a.Issue, a.PullRequest, a.Branch)
+}
+
+// Age returns how long the work has been idle at now, rounded to the hour
+func (a Alert) Age(now time.Time) time.Duration {
+	return now.Sub(a.Since).Round(time.Hour)
+}
+
+// Filter returns the alerts of the given kinds, or every alert when kinds is
+// empty
+func Filter(alerts []Alert, kinds []Kind) []Alert {
+	if len(kinds) == 0 {
+		return alerts
+	}
+	result := []Alert{}
+	for _, a := range alerts {
+		if slices.Contains(kinds, a.Kind) {
+			result = append(result, a)
+		}
+	}
+	return result
+}
+
+// Render writes alerts in the requested format
+func Render(w io.Writer, alerts []Alert, format report.Format) error {
+	switch format {
+	case report.FormatJSON:
+		enc := json.NewEncoder(w)
+		enc.SetIndent("", "  ")
+		return enc.Encode(alerts)
+	case report.FormatTable:
+		return renderTable(w, alerts)
+	default:
+		return fmt.Errorf("unsupported alerts format: %s", format)
+	}
+}
+
+// renderTable writes one line per alert as an aligned plain text table
+func renderTable(w io.Writer, alerts []Alert) error {
+	tw := tabwriter.NewWriter(w, 0, 0, 2, ' ', 0)
+
+	now := time.Now()
+	fmt.Fprintln(tw, "ISSUE\tKIND\tAGE\tMESSAGE")
+	for _, a := range alerts {
+		fmt.Fprintf(tw, "%s\t%s\t%s\t%s\n", a.Issue, a.Kind, formatAge(a.Age(now)), a.Message)
+	}
+
+	return tw.Flush()
+}
+
+// formatAge formats an age in days and hours, e.g. "9d4h"
+func formatAge(d time.Duration) string {
+	days := d / (24 * time.Hour)
+	return fmt.Sprintf("%dd%dh", days, (d-days*24*time.Hour)/time.Hour)
+}
@@ -0,0 +1,28 @@
+package alerts
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestThresholdsFor(t *testing.T) {
+	cfg := DefaultConfig()
+	cfg.Projects["OCPBUGS"] = Thresholds{StatusStuck: 48 * time.Hour}
+
+	got := cfg.ThresholdsFor("OCPBUGS")
+	assert.Equal(t, 48*time.Hour, got.StatusStuck)
+	assert.Equal(t, cfg.PullRequestIdle, got.PullRequestIdle, "unset fields keep the global value")
+	assert.Equal(t, cfg.Thresholds, cfg.ThresholdsFor("OTHER"))
+}
+
+func TestFilter(t *testing.T) {
+	all := []Alert{{Kind: KindPollStale, Issue: "A-1"}, {Kind: KindStatusStuck, Issue: "A-2"}}
+
+	assert.Equal(t, all, Filter(all, nil))
+	assert.Equal(t, []Alert{{Kind: KindStatusStuck, Issue: "A-2"}}, Filter(all, []Kind{KindStatusStuck}))
+
+	_, err := ParseKind("nope")
+	assert.Error(t, err)
+}
@@ -0,0 +1,203 @@
+// Package analytics summarizes how long tracked issues and their pull requests
+// take, as percentiles grouped by project, assignee or release.
+package analytics
+
+import (
+	"fmt"
+	"math"
+	"sort"
+	"strings"
+	"time"
+)
+
+// Metric identifies what a sample measures
+type Metric string
+
+const (
+	// MetricTimeInStatus is the time an issue spent in each Jira status
+	MetricTimeInStatus Metric = "time-in-status"
+	// MetricLeadTime is the time from tracking an issue to its first done status
+	MetricLeadTime Metric = "lead-time"
+	// MetricMergeTime is the time from a pull request being tracked open to
+	// its merge
+	MetricMergeTime Metric = "merge-time"
+	// MetricBackportLag is the time from the merge of an original pull
+	// request to the merge of its backport
+	MetricBackportLag Metric = "backport-lag"
+)
+
+// Metrics lists every metric in display order
+var Metrics = []Metric{MetricTimeInStatus, MetricLeadTime, MetricMergeTime, MetricBackportLag}
+
+// ParseMetric converts a user supplied metric name into a Metric
+func ParseMetric(name string) (Metric, error) {
+	for _, m := range Metrics {
+		if strings.EqualFold(name, string(m)) {
+			return m, nil
+		}
+	}
+	return "", fmt.Errorf("unknown metric %q, expected one of %s", name, joinMetrics())
+}
+
+// joinMetrics lists the metric names for error messages
+func joinMetrics() string {
+	names := make([]string, 0, len(Metrics))
+	for _, m := range Metrics {
+		names = append(names, string(m))
+	}
+	return strings.Join(names, ", ")
+}
+
+// GroupBy identifies how samples are grouped
+type GroupBy string
+
+const (
+	GroupByNone     GroupBy = ""
+	GroupByIssue    GroupBy = "issue"
+	GroupByProject  GroupBy = "project"
+	GroupByAssignee GroupBy = "assignee"
+	GroupByRelease  GroupBy = "release"
+)
+
+// ParseGroupBy converts a user supplied grouping into a GroupBy
+func ParseGroupBy(name string) (GroupBy, error) {
+	switch g := GroupBy(strings.ToLower(name)); g {
+	case GroupByNone, GroupByIssue, GroupByProject, GroupByAssignee, GroupByRelease:
+		return g, nil
+	case "all":
+		return GroupByNone, nil
+	}
+	return "", fmt.Errorf("unknown grouping %q, expected issue, project, assignee or release", name)
+}
+
+// Sample is a single measured duration, with the issue it belongs to
+type Sample struct {
+	Issue    string // Key of the issue
+	Project  string
+	Assignee string
+	Releases []string // Minor releases the issue targets, e.g. 4.16
+	// Label distinguishes the samples of a metric, e.g. the status for
+	// time in status, or the pull request for merge times
+	Label    string
+	Duration time.Duration
+}
+
+// groups returns the groups a sample counts in. An issue targeting several
+// releases counts in each of them.
+func (s Sample) groups(by GroupBy) []string {
+	var values []string
+	switch by {
+	case GroupByNone:
+		return []string{"all"}
+	case GroupByIssue:
+		values = []string{s.Issue}
+	case GroupByProject:
+		values = []string{s.Project}
+	case GroupByAssignee:
+		values = []string{s.Assignee}
+	case GroupByRelease:
+		values = append([]string{}, s.Releases...)
+	}
+
+	for i, v := range values {
+		if v == "" {
+			values[i] = "none"
+		}
+	}
+	if len(values) == 0 {
+		return []string{"none"}
+	}
+	return values
+}
+
+// Summary holds the distribution of durations, in hours
+type Summary struct {
+	Count int     `json:"count"`
+	Mean  float64 `json:"mean_hours"`
+	P50   float64 `json:"p50_hours"`
+	P90   float64 `json:"p90_hours"`
+	P95   float64 `json:"p95_hours"`
+	Min   float64 `json:"min_hours"`
+	Max   float64 `json:"max_hours"`
+}
+
+// Summarize computes the distribution of durations. Percentiles use the
+// nearest-rank method.
+func Summarize(durations []time.Duration) Summary {
+	if len(durations) == 0 {
+		return Summary{}
+	}
+
+	sorted := append([]time.Duration{}, durations...)
+	sort.Slice(sorted, func(i, j int) bool { return sorted[i] < sorted[j] })
+
+	var total time.Duration
+	for _, d := range sorted {
+		total += d
+	}
+	percentile := func(p float64) float64 {
+		rank := int(math.Ceil(p / 100 * float64(len(sorted))))
+		return hours(sorted[max(rank, 1)-1])
+	}
+
+	return Summary{
+		Count: len(sorted),
+		Mean:  hours(total / time.Duration(len(sorted))),
+		P50:   percentile(50),
+		P90:   percentile(90),
+		P95:   percentile(95),
+		Min:   hours(sorted[0]),
+		Max:   hours(sorted[len(sorted)-1]),
+	}
+}
+
+// hours converts a duration into hours rounded to two decimals
+func hours(d time.Duration) float64 {
+	return math.Round(d.Hours()*100) / 100
+}
+
+// Row is the summary of the samples of one group, and label for metrics
+// with labels
+type Row struct {
+	Group string `json:"group"`
+	Label string `json:"label,omitempty"`
+	Summary
+}
+
+// Report summarizes the samples of a metric by group
+type Report struct {
+	Metric      Metric    `json:"metric"`
+	GroupBy     GroupBy   `json:"group_by,omitempty"`
+	GeneratedAt time.Time `json:"generated_at"`
+	Rows        []Row     `json:"rows"`
+}
+
+// NewReport groups samples and summarizes each group. Time in status is
+// summarized per status within each group; other labels are not split.
+func NewReport(metric Metric, by GroupBy, samples []Sample) *Report {
+	type key struct{ group, label string }
+	durations := map[key][]time.Duration{}
+	for _, s := range samples {
+		label := ""
+		if metric == MetricTimeInStatus {
+			label = s.Label
+		}
+		for _, group := range s.groups(by) {
+			k := key{group, label}
+			durations[k] = append(durations[k], s.Duration)
+		}
+	}
+
+	rep := &Report{Metric: metric, GroupBy: by, GeneratedAt: time.Now(), Rows: []Row{}}
+	for k, d := range durations {
+		rep.Rows = append(rep.Rows, Row{Group: k.group, Label: k.label, Summary: Summarize(d)})
+	}
+	sort.Slice(rep.Rows, func(i, j int) bool {
+		a, b := rep.Rows[i], rep.Rows[j]
+		if a.Group != b.Group {
+			return a.Group < b.Group
+		}
+		return a.Label < b.Label
+	})
+	return rep
+}
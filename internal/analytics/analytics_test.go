@@ -0,0 +1,61 @@
+package analytics
+
+import (
+	"bytes"
+	"testing"
+	"time"
+
+	"github.com/jparrill/devtrackr/internal/report"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestSummarize(t *testing.T) {
+	var durations []time.Duration
+	for i := 10; i >= 1; i-- {
+		durations = append(durations, time.Duration(i)*time.Hour)
+	}
+
+	s := Summarize(durations)
+	assert.Equal(t, Summary{Count: 10, Mean: 5.5, P50: 5, P90: 9, P95: 10, Min: 1, Max: 10}, s)
+	assert.Equal(t, time.Duration(10)*time.Hour, durations[0], "the input is not reordered")
+	assert.Equal(t, Summary{}, Summarize(nil))
+}
+
+func TestNewReport(t *testing.T) {
+	samples := []Sample{
+		{Issue: "A-1", Project: "A", Releases: []string{"4.15", "4.16"}, Label: "New", Duration: time.Hour},
+		{Issue: "A-1", Project: "A", Releases: []string{"4.15", "4.16"}, Label: "Closed", Duration: 2 * time.Hour},
+		{Issue: "B-1", Project: "B", Label: "New", Duration: 3 * time.Hour},
+	}
+
+	rep := NewReport(MetricTimeInStatus, GroupByRelease, samples)
+	var groups []string
+	for _, row := range rep.Rows {
+		groups = append(groups, row.Group+"/"+row.Label)
+	}
+	assert.Equal(t, []string{"4.15/Closed", "4.15/New", "4.16/Closed", "4.16/New", "none/New"}, groups)
+
+	rep = NewReport(MetricLeadTime, GroupByProject, samples)
+	require.Len(t, rep.Rows, 2)
+	assert.Equal(t, Row{Group: "A", Summary: Summary{Count: 2, Mean: 1.5, P50: 1, P90: 2, P95: 2, Min: 1, Max: 2}}, rep.Rows[0])
+}
+
+func TestRenderCSV(t *testing.T) {
+	rep := &Report{
+		Metric:  MetricTimeInStatus,
+		GroupBy: GroupByAssignee,
+		Rows:    []Row{{Group: "alice", Label: "New", Summary: Summary{Count: 1, Mean: 1.5, P50: 1.5, P90: 1.5, P95: 1.5, Min: 1.5, Max: 1.5}}},
+	}
+
+	var buf bytes.Buffer
+	require.NoError(t, Render(&buf, rep, report.FormatCSV))
+	assert.Equal(t, "assignee,status,count,mean_hours,p50_hours,p90_hours,p95_hours,min_hours,max_hours\n"+
+		"alice,New,1,1.50,1.50,1.50,1.50,1.50,1.50\n", buf.String())
+}
+
+func TestFormatHours(t *testing.T) {
+	assert.Equal(t, "35m", FormatHours(35.0/60))
+	assert.Equal(t, "3h5m", FormatHours(3+5.0/60))
+	assert.Equal(t, "2d4h", FormatHours(52))
+}
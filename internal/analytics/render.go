@@ -0,0 +1,114 @@
+package analytics
+
+import (
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+	"text/tabwriter"
+	"time"
+
+	"github.com/jparrill/devtrackr/internal/report"
+)
+
+// Render writes the report in the requested format. CSV durations are in
+// hours, for spreadsheets.
+func Render(w io.Writer, r *Report, format report.Format) error {
+	switch format {
+	case report.FormatJSON:
+		enc := json.NewEncoder(w)
+		enc.SetIndent("", "  ")
+		return enc.Encode(r)
+	case report.FormatTable:
+		return renderTable(w, r)
+	case report.FormatCSV:
+		return renderCSV(w, r)
+	default:
+		return fmt.Errorf("unsupported analytics format: %s", format)
+	}
+}
+
+// renderTable writes the report as an aligned plain text table with
+// readable durations
+func renderTable(w io.Writer, r *Report) error {
+	tw := tabwriter.NewWriter(w, 0, 0, 2, ' ', 0)
+
+	header := []string{strings.ToUpper(groupColumn(r.GroupBy))}
+	if r.Metric == MetricTimeInStatus {
+		header = append(header, "STATUS")
+	}
+	header = append(header, "COUNT", "MEAN", "P50", "P90", "P95", "MIN", "MAX")
+	fmt.Fprintln(tw, strings.Join(header, "\t"))
+
+	for _, row := range r.Rows {
+		values := []string{row.Group}
+		if r.Metric == MetricTimeInStatus {
+			values = append(values, row.Label)
+		}
+		values = append(values, strconv.Itoa(row.Count))
+		for _, h := range []float64{row.Mean, row.P50, row.P90, row.P95, row.Min, row.Max} {
+			values = append(values, FormatHours(h))
+		}
+		fmt.Fprintln(tw, strings.Join(values, "\t"))
+	}
+
+	return tw.Flush()
+}
+
+// renderCSV writes the report as CSV with durations in hours
+func renderCSV(w io.Writer, r *Report) error {
+	cw := csv.NewWriter(w)
+
+	header := []string{groupColumn(r.GroupBy)}
+	if r.Metric == MetricTimeInStatus {
+		header = append(header, "status")
+	}
+	header = append(header, "count", "mean_hours", "p50_hours", "p90_hours", "p95_hours", "min_hours", "max_hours")
+	if err := cw.Write(header); err != nil {
+		return err
+	}
+
+	for _, row := range r.Rows {
+		values := []string{row.Group}
+		if r.Metric == MetricTimeInStatus {
+			values = append(values, row.Label)
+		}
+		values = append(values, strconv.Itoa(row.Count))
+		for _, h := range []float64{row.Mean, row.P50, row.P90, row.P95, row.Min, row.Max} {
+			values = append(values, strconv.FormatFloat(h, 'f', 2, 64))
+		}
+		if err := cw.Write(values); err != nil {
+			return err
+		}
+	}
+
+	cw.Flush()
+	return cw.Error()
+}
+
+// groupColumn names the group column
+func groupColumn(by GroupBy) string {
+	if by == GroupByNone {
+		return "group"
+	}
+	return string(by)
+}
+
+// FormatHours formats a number of hours as a short duration, e.g. "2d4h" or
+// "35m"
+func FormatHours(h float64) string {
+	d := time.Duration(h * float64(time.Hour)).Round(time.Minute)
+	days := d / (24 * time.Hour)
+	d -= days * 24 * time.Hour
+
+	switch {
+	case days > 0:
+		return fmt.Sprintf("%dd%dh", days, d/time.Hour)
+	case d >= time.Hour:
+		return fmt.Sprintf("%dh%dm", d/time.Hour, (d%time.Hour)/time.Minute)
+	default:
+		return fmt.Sprintf("%dm", d/time.Minute)
+	}
+}
@@ -0,0 +1,60 @@
+package handlers
+
+import (
+	"bytes"
+	"net/http"
+	"strings"
+
+	"github.com/jparrill/devtrackr/internal/alerts"
+	"github.com/jparrill/devtrackr/internal/services"
+)
+
+// AlertHandler handles stale work alert HTTP requests
+type AlertHandler struct {
+	trackingService *services.TrackingService
+}
+
+// NewAlertHandler creates a new alert handler
+func NewAlertHandler(trackingService *services.TrackingService) *AlertHandler {
+	return &AlertHandler{
+		trackingService: trackingService,
+	}
+}
+
+// ListAlerts handles GET /api/v1/alerts, over the issues matching the filter
+// parameters described in ParseIssueFilter. The kind parameter keeps alerts
+// of the given kinds only.
+func (h *AlertHandler) ListAlerts(w http.ResponseWriter, r *http.Request) {
+	format, err := requestedFormat(r)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	var kinds []alerts.Kind
+	for _, value := range r.URL.Query()["kind"] {
+		for _, name := range strings.Split(value, ",") {
+			kind, err := alerts.ParseKind(strings.TrimSpace(name))
+			if err != nil {
+				http.Error(w, err.Error(), http.StatusBadRequest)
+				return
+			}
+			kinds = append(kinds, kind)
+		}
+	}
+
+	found, err := h.trackingService.StaleAlerts(r.Context(), ParseIssueFilter(r.URL.Query()))
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	var buf bytes.Buffer
+	if err := alerts.Render(&buf, alerts.Filter(found, kinds), format); err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	w.Header().Set("Content-Type", format.ContentType())
+	w.Write(buf.Bytes())
+}
@@ -3,9 +3,13 @@ package handlers
 import (
 	"encoding/json"
 	"net/http"
+	"net/url"
 	"strconv"
+	"strings"
 
 	"github.com/gorilla/mux"
+	"github.com/jparrill/devtrackr/internal/models"
+	"github.com/jparrill/devtrackr/internal/report"
 	"github.com/jparrill/devtrackr/internal/services"
 )
 
@@ -21,9 +25,65 @@ func NewIssueHandler(trackingService *services.TrackingService) *IssueHandler {
 	}
 }
 
-// ListIssues handles GET /api/v1/issues
+// ParseIssueFilter reads an issue filter from query parameters. Each
+// parameter may be repeated or hold comma-separated values, e.g.
+// ?assignee=alice,bob&priority=Critical. Custom field attributes are
+// filtered with attr.<name>, e.g. ?attr.qa_contact=carol
+func ParseIssueFilter(query url.Values) models.IssueFilter {
+	values := func(name string) []string {
+		var result []string
+		for _, v := range query[name] {
+			for _, part := range strings.Split(v, ",") {
+				if part = strings.TrimSpace(part); part != "" {
+					result = append(result, part)
+				}
+			}
+		}
+		return result
+	}
+
+	attributes := map[string][]string{}
+	for param := range query {
+		if name, ok := strings.CutPrefix(param, "attr."); ok && name != "" {
+			attributes[name] = values(param)
+		}
+	}
+
+	return models.IssueFilter{
+		Attributes:     attributes,
+		Instance:       values("instance"),
+		Project:        values("project"),
+		Status:         values("status"),
+		StatusCategory: values("status_category"),
+		Assignee:       values("assignee"),
+		Reporter:       values("reporter"),
+		Priority:       values("priority"),
+		IssueType:      values("issue_type"),
+		Resolution:     values("resolution"),
+		Sprint:         values("sprint"),
+		EpicLink:       values("epic"),
+		Label:          values("label"),
+		Component:      values("component"),
+		FixVersion:     values("fix_version"),
+	}
+}
+
+// ListIssues handles GET /api/v1/issues, filtered as described in ParseIssueFilter.
+// Asking for CSV or XLSX, through ?format= or the Accept header, lists the
+// issues as a spreadsheet with the ?columns= selected, and XLSX adds a sheet
+// of their pull requests with the ?pr_columns= selected.
 func (h *IssueHandler) ListIssues(w http.ResponseWriter, r *http.Request) {
-	issues, err := h.trackingService.ListIssues(r.Context())
+	format, err := requestedFormat(r)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	if isSheetFormat(format) {
+		h.listIssueSheets(w, r, format)
+		return
+	}
+
+	issues, err := h.trackingService.ListIssuesMatching(r.Context(), ParseIssueFilter(r.URL.Query()))
 	if err != nil {
 		http.Error(w, err.Error(), http.StatusInternalServerError)
 		return
@@ -36,6 +96,28 @@ func (h *IssueHandler) ListIssues(w http.ResponseWriter, r *http.Request) {
 	}
 }
 
+// listIssueSheets serves the issues listing in a spreadsheet friendly format
+func (h *IssueHandler) listIssueSheets(w http.ResponseWriter, r *http.Request, format report.Format) {
+	rows, err := h.trackingService.IssueRows(r.Context(), ParseIssueFilter(r.URL.Query()))
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	issues, err := report.IssueSheet(rows, queryList(r, "columns"))
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	prs, err := report.PullRequestSheet(report.NewPullRequestRows(rows), queryList(r, "pr_columns"))
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	writeSheets(w, format, issues, prs)
+}
+
 // GetIssue handles GET /api/v1/issues/{key}
 func (h *IssueHandler) GetIssue(w http.ResponseWriter, r *http.Request) {
 	vars := mux.Vars(r)
@@ -119,6 +201,9 @@ func (h *IssueHandler) UnsubscribeFromIssue(w http.ResponseWriter, r *http.Reque
 func (h *IssueHandler) TrackIssue(w http.ResponseWriter, r *http.Request) {
 	var req struct {
 		JiraURL string `json:"jira_url"`
+		// WithChildren also tracks every issue below the issue, and returns
+		// the list of tracked issues
+		WithChildren bool `json:"with_children"`
 	}
 	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
 		http.Error(w, "Invalid request body", http.StatusBadRequest)
@@ -130,6 +215,18 @@ func (h *IssueHandler) TrackIssue(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
+	if req.WithChildren {
+		tracked, err := h.trackingService.TrackIssueWithChildren(r.Context(), req.JiraURL)
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusInternalServerError)
+			return
+		}
+
+		w.Header().Set("Content-Type", "application/json")
+		json.NewEncoder(w).Encode(tracked)
+		return
+	}
+
 	issue, err := h.trackingService.TrackIssue(r.Context(), req.JiraURL)
 	if err != nil {
 		http.Error(w, err.Error(), http.StatusInternalServerError)
@@ -226,3 +323,57 @@ func (h *IssueHandler) UpdatePollingInterval(w http.ResponseWriter, r *http.Requ
 
 	w.WriteHeader(http.StatusOK)
 }
+
+// ListCommits handles GET /api/v1/issues/{key}/commits
+func (h *IssueHandler) ListCommits(w http.ResponseWriter, r *http.Request) {
+	vars := mux.Vars(r)
+	key := vars["key"]
+
+	commits, err := h.trackingService.ListCommits(r.Context(), key)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	if err := json.NewEncoder(w).Encode(commits); err != nil {
+		http.Error(w, "Failed to encode response", http.StatusInternalServerError)
+		return
+	}
+}
+
+// ListEvents handles GET /api/v1/issues/{key}/events
+func (h *IssueHandler) ListEvents(w http.ResponseWriter, r *http.Request) {
+	vars := mux.Vars(r)
+	key := vars["key"]
+
+	events, err := h.trackingService.ListEvents(r.Context(), key)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	if err := json.NewEncoder(w).Encode(events); err != nil {
+		http.Error(w, "Failed to encode response", http.StatusInternalServerError)
+		return
+	}
+}
+
+// Tree handles GET /api/v1/issues/{key}/tree
+func (h *IssueHandler) Tree(w http.ResponseWriter, r *http.Request) {
+	vars := mux.Vars(r)
+	key := vars["key"]
+
+	tree, err := h.trackingService.IssueTree(r.Context(), key)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusNotFound)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	if err := json.NewEncoder(w).Encode(tree); err != nil {
+		http.Error(w, "Failed to encode response", http.StatusInternalServerError)
+		return
+	}
+}
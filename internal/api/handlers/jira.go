@@ -0,0 +1,124 @@
+package handlers
+
+import (
+	"encoding/json"
+	"net/http"
+
+	"github.com/gorilla/mux"
+	"github.com/jparrill/devtrackr/internal/services"
+)
+
+// JiraHandler handles HTTP requests that change issues in Jira
+type JiraHandler struct {
+	trackingService *services.TrackingService
+}
+
+// NewJiraHandler creates a new Jira write-back handler
+func NewJiraHandler(trackingService *services.TrackingService) *JiraHandler {
+	return &JiraHandler{
+		trackingService: trackingService,
+	}
+}
+
+// ListTransitions handles GET /api/v1/issues/{key}/jira/transitions
+func (h *JiraHandler) ListTransitions(w http.ResponseWriter, r *http.Request) {
+	transitions, err := h.trackingService.ListTransitions(r.Context(), mux.Vars(r)["key"])
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	writeJSON(w, transitions)
+}
+
+// Transition handles POST /api/v1/issues/{key}/jira/transition
+func (h *JiraHandler) Transition(w http.ResponseWriter, r *http.Request) {
+	var req struct {
+		Transition string `json:"transition"` // Transition or target status name
+		DryRun     bool   `json:"dry_run"`
+	}
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil || req.Transition == "" {
+		http.Error(w, "Invalid request body", http.StatusBadRequest)
+		return
+	}
+
+	entry, err := h.trackingService.TransitionIssue(r.Context(), mux.Vars(r)["key"], req.Transition, writeOptions(r, req.DryRun))
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	writeJSON(w, entry)
+}
+
+// Comment handles POST /api/v1/issues/{key}/jira/comment, which comments the
+// state of the linked pull requests on the issue
+func (h *JiraHandler) Comment(w http.ResponseWriter, r *http.Request) {
+	var req struct {
+		DryRun bool `json:"dry_run"`
+	}
+	if r.ContentLength != 0 {
+		if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+			http.Error(w, "Invalid request body", http.StatusBadRequest)
+			return
+		}
+	}
+
+	entry, err := h.trackingService.CommentPullRequestSummary(r.Context(), mux.Vars(r)["key"], writeOptions(r, req.DryRun))
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	writeJSON(w, entry)
+}
+
+// Labels handles POST /api/v1/issues/{key}/jira/labels
+func (h *JiraHandler) Labels(w http.ResponseWriter, r *http.Request) {
+	var req struct {
+		Add    []string `json:"add"`
+		Remove []string `json:"remove"`
+		DryRun bool     `json:"dry_run"`
+	}
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil || len(req.Add)+len(req.Remove) == 0 {
+		http.Error(w, "Invalid request body", http.StatusBadRequest)
+		return
+	}
+
+	entry, err := h.trackingService.UpdateLabels(r.Context(), mux.Vars(r)["key"], req.Add, req.Remove, writeOptions(r, req.DryRun))
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	writeJSON(w, entry)
+}
+
+// ListAudit handles GET /api/v1/issues/{key}/audit
+func (h *JiraHandler) ListAudit(w http.ResponseWriter, r *http.Request) {
+	entries, err := h.trackingService.ListAuditEntries(r.Context(), mux.Vars(r)["key"])
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	writeJSON(w, entries)
+}
+
+// writeOptions attributes a write to the API caller, identified by the
+// X-User-ID header when present
+func writeOptions(r *http.Request, dryRun bool) services.WriteOptions {
+	actor := "api"
+	if id := r.Header.Get("X-User-ID"); id != "" {
+		actor += ":" + id
+	}
+	return services.WriteOptions{DryRun: dryRun, Actor: actor}
+}
+
+// writeJSON encodes v as the JSON response body
+func writeJSON(w http.ResponseWriter, v any) {
+	w.Header().Set("Content-Type", "application/json")
+	if err := json.NewEncoder(w).Encode(v); err != nil {
+		http.Error(w, "Failed to encode response", http.StatusInternalServerError)
+	}
+}
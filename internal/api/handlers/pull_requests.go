@@ -7,6 +7,7 @@ import (
 
 	"github.com/gorilla/mux"
 	"github.com/jparrill/devtrackr/internal/models"
+	"github.com/jparrill/devtrackr/internal/report"
 	"github.com/jparrill/devtrackr/internal/services"
 )
 
@@ -22,17 +23,41 @@ func NewPullRequestHandler(trackingService *services.TrackingService) *PullReque
 	}
 }
 
-// ListPullRequests handles GET /api/v1/issues/{key}/pull-requests
+// ListPullRequests handles GET /api/v1/issues/{key}/pull-requests. CSV and
+// XLSX, asked for through ?format= or the Accept header, list the pull
+// requests as a spreadsheet with the ?columns= selected.
 func (h *PullRequestHandler) ListPullRequests(w http.ResponseWriter, r *http.Request) {
 	vars := mux.Vars(r)
 	key := vars["key"]
 
+	format, err := requestedFormat(r)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
 	prs, err := h.trackingService.ListPullRequests(r.Context(), key)
 	if err != nil {
 		http.Error(w, err.Error(), http.StatusInternalServerError)
 		return
 	}
 
+	if isSheetFormat(format) {
+		issue, err := h.trackingService.GetIssueByKey(r.Context(), key)
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusInternalServerError)
+			return
+		}
+		rows := report.NewPullRequestRows([]report.IssueRow{{Issue: issue, PullRequests: prs}})
+		sheet, err := report.PullRequestSheet(rows, queryList(r, "columns"))
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+		writeSheets(w, format, sheet)
+		return
+	}
+
 	w.Header().Set("Content-Type", "application/json")
 	if err := json.NewEncoder(w).Encode(prs); err != nil {
 		http.Error(w, "Failed to encode response", http.StatusInternalServerError)
@@ -46,12 +71,15 @@ func (h *PullRequestHandler) AddPullRequest(w http.ResponseWriter, r *http.Reque
 	key := vars["key"]
 
 	var req struct {
-		Number       int    `json:"number"`
-		Repository   string `json:"repository"`
-		Title        string `json:"title"`
-		URL          string `json:"url"`
-		TargetBranch string `json:"target_branch"`
-		IsBackport   bool   `json:"is_backport"`
+		Number       int      `json:"number"`
+		Repository   string   `json:"repository"`
+		Title        string   `json:"title"`
+		Body         string   `json:"body"`
+		Labels       []string `json:"labels"`
+		URL          string   `json:"url"`
+		TargetBranch string   `json:"target_branch"`
+		IsBackport   bool     `json:"is_backport"`
+		OriginalPRID *int64   `json:"original_pr_id"`
 	}
 	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
 		http.Error(w, "Invalid request body", http.StatusBadRequest)
@@ -62,9 +90,12 @@ func (h *PullRequestHandler) AddPullRequest(w http.ResponseWriter, r *http.Reque
 		Number:       req.Number,
 		Repository:   req.Repository,
 		Title:        req.Title,
+		Body:         req.Body,
+		Labels:       req.Labels,
 		URL:          req.URL,
 		TargetBranch: req.TargetBranch,
 		IsBackport:   req.IsBackport,
+		OriginalPRID: req.OriginalPRID,
 	})
 	if err != nil {
 		http.Error(w, err.Error(), http.StatusInternalServerError)
@@ -105,3 +136,40 @@ func (h *PullRequestHandler) UpdatePullRequest(w http.ResponseWriter, r *http.Re
 		return
 	}
 }
+
+// SyncPullRequests handles POST /api/v1/issues/{key}/pull-requests/sync
+func (h *PullRequestHandler) SyncPullRequests(w http.ResponseWriter, r *http.Request) {
+	vars := mux.Vars(r)
+	key := vars["key"]
+
+	prs, err := h.trackingService.SyncPullRequests(r.Context(), key)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	if err := json.NewEncoder(w).Encode(prs); err != nil {
+		http.Error(w, "Failed to encode response", http.StatusInternalServerError)
+		return
+	}
+}
+
+// MergeStatus handles GET /api/v1/issues/{key}/merge-status
+func (h *PullRequestHandler) MergeStatus(w http.ResponseWriter, r *http.Request) {
+	vars := mux.Vars(r)
+	key := vars["key"]
+	sync := r.URL.Query().Get("sync") == "true"
+
+	statuses, err := h.trackingService.MergeStatus(r.Context(), key, sync)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	if err := json.NewEncoder(w).Encode(statuses); err != nil {
+		http.Error(w, "Failed to encode response", http.StatusInternalServerError)
+		return
+	}
+}
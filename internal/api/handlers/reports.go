@@ -0,0 +1,219 @@
+package handlers
+
+import (
+	"bytes"
+	"fmt"
+	"net/http"
+	"strings"
+
+	"github.com/gorilla/mux"
+	"github.com/jparrill/devtrackr/internal/analytics"
+	"github.com/jparrill/devtrackr/internal/report"
+	"github.com/jparrill/devtrackr/internal/services"
+)
+
+// ReportHandler handles report-related HTTP requests
+type ReportHandler struct {
+	trackingService *services.TrackingService
+}
+
+// NewReportHandler creates a new report handler
+func NewReportHandler(trackingService *services.TrackingService) *ReportHandler {
+	return &ReportHandler{
+		trackingService: trackingService,
+	}
+}
+
+// ReleaseReport handles GET /api/v1/reports/releases/{version}
+func (h *ReportHandler) ReleaseReport(w http.ResponseWriter, r *http.Request) {
+	vars := mux.Vars(r)
+	version := vars["version"]
+
+	format, err := requestedFormat(r)
+	if err == nil {
+		err = report.CheckReleaseFormat(format)
+	}
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	rep, err := h.trackingService.ReleaseReport(r.Context(), version, r.URL.Query().Get("branch"))
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	var buf bytes.Buffer
+	if err := report.RenderRelease(&buf, rep, format); err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	w.Header().Set("Content-Type", format.ContentType())
+	w.Write(buf.Bytes())
+}
+
+// BackportMatrix handles GET /api/v1/reports/backports
+func (h *ReportHandler) BackportMatrix(w http.ResponseWriter, r *http.Request) {
+	format, err := requestedFormat(r)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	var branches []string
+	if value := r.URL.Query().Get("branches"); value != "" {
+		for _, branch := range strings.Split(value, ",") {
+			if branch = strings.TrimSpace(branch); branch != "" {
+				branches = append(branches, branch)
+			}
+		}
+	}
+
+	matrix, err := h.trackingService.BackportMatrix(r.Context(), branches, r.URL.Query().Get("project"))
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	var buf bytes.Buffer
+	if err := report.RenderBackports(&buf, matrix, format); err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	w.Header().Set("Content-Type", format.ContentType())
+	w.Write(buf.Bytes())
+}
+
+// CloneChain handles GET /api/v1/reports/clones/{key}
+func (h *ReportHandler) CloneChain(w http.ResponseWriter, r *http.Request) {
+	vars := mux.Vars(r)
+	key := vars["key"]
+
+	format, err := requestedFormat(r)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	chain, err := h.trackingService.CloneChain(r.Context(), key)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusNotFound)
+		return
+	}
+
+	var buf bytes.Buffer
+	if err := report.RenderClones(&buf, chain, format); err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	w.Header().Set("Content-Type", format.ContentType())
+	w.Write(buf.Bytes())
+}
+
+// Analytics handles GET /api/v1/analytics/{metric}, grouped by the group_by
+// parameter and over the issues matching the filter parameters described in
+// ParseIssueFilter
+func (h *ReportHandler) Analytics(w http.ResponseWriter, r *http.Request) {
+	vars := mux.Vars(r)
+
+	metric, err := analytics.ParseMetric(vars["metric"])
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusNotFound)
+		return
+	}
+
+	by, err := analytics.ParseGroupBy(r.URL.Query().Get("group_by"))
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	format, err := requestedFormat(r)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	rep, err := h.trackingService.Analytics(r.Context(), metric, by, ParseIssueFilter(r.URL.Query()))
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	var buf bytes.Buffer
+	if err := analytics.Render(&buf, rep, format); err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	w.Header().Set("Content-Type", format.ContentType())
+	w.Write(buf.Bytes())
+}
+
+// requestedFormat picks the report format from the format query parameter,
+// falling back to the Accept header and finally to JSON
+func requestedFormat(r *http.Request) (report.Format, error) {
+	if name := r.URL.Query().Get("format"); name != "" {
+		return report.ParseFormat(name)
+	}
+
+	accept := r.Header.Get("Accept")
+	switch {
+	case strings.Contains(accept, "text/markdown"):
+		return report.FormatMarkdown, nil
+	case strings.Contains(accept, "text/html"):
+		return report.FormatHTML, nil
+	case strings.Contains(accept, "text/csv"):
+		return report.FormatCSV, nil
+	case strings.Contains(accept, report.FormatXLSX.ContentType()):
+		return report.FormatXLSX, nil
+	default:
+		return report.FormatJSON, nil
+	}
+}
+
+// queryList reads a comma-separated list from a query parameter
+func queryList(r *http.Request, name string) []string {
+	var result []string
+	for _, part := range strings.Split(r.URL.Query().Get(name), ",") {
+		if part = strings.TrimSpace(part); part != "" {
+			result = append(result, part)
+		}
+	}
+	return result
+}
+
+// isSheetFormat reports whether a listing is served as a spreadsheet rather
+// than as JSON. Other formats fall back to JSON, so browsers, which ask for
+// HTML, keep getting JSON.
+func isSheetFormat(format report.Format) bool {
+	return format == report.FormatCSV || format == report.FormatXLSX || format == report.FormatTable
+}
+
+// writeSheets serves listings in a spreadsheet friendly format: every sheet
+// as one XLSX workbook, or else the first sheet alone. Workbooks are sent as
+// an attachment named after the first sheet.
+func writeSheets(w http.ResponseWriter, format report.Format, sheets ...report.Sheet) {
+	var buf bytes.Buffer
+	var err error
+	if format == report.FormatXLSX {
+		err = report.WriteXLSX(&buf, sheets...)
+	} else {
+		err = report.RenderSheet(&buf, sheets[0], format)
+	}
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	w.Header().Set("Content-Type", format.ContentType())
+	if format == report.FormatXLSX {
+		name := strings.ReplaceAll(strings.ToLower(sheets[0].Name), " ", "-")
+		w.Header().Set("Content-Disposition", fmt.Sprintf(`attachment; filename="%s.xlsx"`, name))
+	}
+	w.Write(buf.Bytes())
+}
@@ -0,0 +1,87 @@
+package handlers
+
+import (
+	"io"
+	"log"
+	"net/http"
+
+	"github.com/jparrill/devtrackr/internal/github"
+	"github.com/jparrill/devtrackr/internal/jira"
+	"github.com/jparrill/devtrackr/internal/services"
+)
+
+// maxWebhookBodySize bounds the size of accepted webhook deliveries
+const maxWebhookBodySize = 25 << 20
+
+// WebhookHandler handles inbound webhooks from forges and Jira
+type WebhookHandler struct {
+	trackingService *services.TrackingService
+	githubSecret    string
+	jiraSecret      string
+}
+
+// NewWebhookHandler creates a new webhook handler. Deliveries are rejected
+// unless a secret is configured for their source.
+func NewWebhookHandler(trackingService *services.TrackingService, githubSecret, jiraSecret string) *WebhookHandler {
+	return &WebhookHandler{
+		trackingService: trackingService,
+		githubSecret:    githubSecret,
+		jiraSecret:      jiraSecret,
+	}
+}
+
+// GitHub handles POST /api/v1/webhooks/github
+func (h *WebhookHandler) GitHub(w http.ResponseWriter, r *http.Request) {
+	body, err := io.ReadAll(http.MaxBytesReader(w, r.Body, maxWebhookBodySize))
+	if err != nil {
+		http.Error(w, "Failed to read request body", http.StatusBadRequest)
+		return
+	}
+
+	if err := github.ValidateSignature(h.githubSecret, body, r.Header.Get("X-Hub-Signature-256")); err != nil {
+		http.Error(w, err.Error(), http.StatusUnauthorized)
+		return
+	}
+
+	event, err := github.ParseWebhook(r.Header.Get("X-GitHub-Event"), body)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	if err := h.trackingService.HandleGitHubEvent(r.Context(), event); err != nil {
+		log.Printf("Error handling GitHub %s event %s: %v", event.Type, r.Header.Get("X-GitHub-Delivery"), err)
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// Jira handles POST /api/v1/webhooks/jira
+func (h *WebhookHandler) Jira(w http.ResponseWriter, r *http.Request) {
+	body, err := io.ReadAll(http.MaxBytesReader(w, r.Body, maxWebhookBodySize))
+	if err != nil {
+		http.Error(w, "Failed to read request body", http.StatusBadRequest)
+		return
+	}
+
+	if err := jira.VerifyWebhook(h.jiraSecret, r, body); err != nil {
+		http.Error(w, err.Error(), http.StatusUnauthorized)
+		return
+	}
+
+	event, err := jira.ParseWebhook(body)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	if err := h.trackingService.HandleJiraEvent(r.Context(), event); err != nil {
+		log.Printf("Error handling Jira %s event for %s: %v", event.Type, event.Key, err)
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	w.WriteHeader(http.StatusNoContent)
+}
@@ -6,21 +6,44 @@ import (
 	"net/http"
 
 	"github.com/gorilla/mux"
+	"github.com/jparrill/devtrackr/internal/api/handlers"
 	"github.com/jparrill/devtrackr/internal/services"
 )
 
 // Server represents the API server
 type Server struct {
-	router          *mux.Router
-	trackingService *services.TrackingService
+	router              *mux.Router
+	trackingService     *services.TrackingService
+	githubWebhookSecret string
+	jiraWebhookSecret   string
+}
+
+// ServerOption configures optional settings of the API server
+type ServerOption func(*Server)
+
+// WithGitHubWebhookSecret sets the secret GitHub webhook deliveries are signed with
+func WithGitHubWebhookSecret(secret string) ServerOption {
+	return func(s *Server) {
+		s.githubWebhookSecret = secret
+	}
+}
+
+// WithJiraWebhookSecret sets the secret Jira webhook deliveries are authenticated with
+func WithJiraWebhookSecret(secret string) ServerOption {
+	return func(s *Server) {
+		s.jiraWebhookSecret = secret
+	}
 }
 
 // NewServer creates a new API server
-func NewServer(trackingService *services.TrackingService) *Server {
+func NewServer(trackingService *services.TrackingService, opts ...ServerOption) *Server {
 	s := &Server{
 		router:          mux.NewRouter(),
 		trackingService: trackingService,
 	}
+	for _, opt := range opts {
+		opt(s)
+	}
 
 	s.setupRoutes()
 	return s
@@ -37,6 +60,42 @@ func (s *Server) setupRoutes() {
 	v1.HandleFunc("/issues/{key}", s.getIssue).Methods("GET")
 	v1.HandleFunc("/issues/{key}", s.deleteIssue).Methods("DELETE")
 	v1.HandleFunc("/issues/{key}/polling-interval", s.updatePollingInterval).Methods("PUT")
+
+	issueHandler := handlers.NewIssueHandler(s.trackingService)
+	v1.HandleFunc("/issues/{key}/commits", issueHandler.ListCommits).Methods("GET")
+	v1.HandleFunc("/issues/{key}/events", issueHandler.ListEvents).Methods("GET")
+	v1.HandleFunc("/issues/{key}/tree", issueHandler.Tree).Methods("GET")
+
+	prHandler := handlers.NewPullRequestHandler(s.trackingService)
+	v1.HandleFunc("/issues/{key}/pull-requests", prHandler.ListPullRequests).Methods("GET")
+	v1.HandleFunc("/issues/{key}/pull-requests", prHandler.AddPullRequest).Methods("POST")
+	v1.HandleFunc("/issues/{key}/pull-requests/sync", prHandler.SyncPullRequests).Methods("POST")
+	v1.HandleFunc("/issues/{key}/pull-requests/{number}", prHandler.UpdatePullRequest).Methods("PUT")
+	v1.HandleFunc("/issues/{key}/merge-status", prHandler.MergeStatus).Methods("GET")
+
+	// Jira write-back routes
+	jiraHandler := handlers.NewJiraHandler(s.trackingService)
+	v1.HandleFunc("/issues/{key}/jira/transitions", jiraHandler.ListTransitions).Methods("GET")
+	v1.HandleFunc("/issues/{key}/jira/transition", jiraHandler.Transition).Methods("POST")
+	v1.HandleFunc("/issues/{key}/jira/comment", jiraHandler.Comment).Methods("POST")
+	v1.HandleFunc("/issues/{key}/jira/labels", jiraHandler.Labels).Methods("POST")
+	v1.HandleFunc("/issues/{key}/audit", jiraHandler.ListAudit).Methods("GET")
+
+	// Report routes
+	reportHandler := handlers.NewReportHandler(s.trackingService)
+	v1.HandleFunc("/reports/releases/{version}", reportHandler.ReleaseReport).Methods("GET")
+	v1.HandleFunc("/reports/backports", reportHandler.BackportMatrix).Methods("GET")
+	v1.HandleFunc("/reports/clones/{key}", reportHandler.CloneChain).Methods("GET")
+	v1.HandleFunc("/analytics/{metric}", reportHandler.Analytics).Methods("GET")
+
+	// Alert routes
+	alertHandler := handlers.NewAlertHandler(s.trackingService)
+	v1.HandleFunc("/alerts", alertHandler.ListAlerts).Methods("GET")
+
+	// Webhook routes
+	webhookHandler := handlers.NewWebhookHandler(s.trackingService, s.githubWebhookSecret, s.jiraWebhookSecret)
+	v1.HandleFunc("/webhooks/github", webhookHandler.GitHub).Methods("POST")
+	v1.HandleFunc("/webhooks/jira", webhookHandler.Jira).Methods("POST")
 }
 
 // Start starts the API server
@@ -45,9 +104,9 @@ func (s *Server) Start(addr string) error {
 	return http.ListenAndServe(addr, s.router)
 }
 
-// listIssues returns all tracked issues
+// listIssues returns the tracked issues matching the query filters
 func (s *Server) listIssues(w http.ResponseWriter, r *http.Request) {
-	issues, err := s.trackingService.ListIssues(r.Context())
+	issues, err := s.trackingService.ListIssuesMatching(r.Context(), handlers.ParseIssueFilter(r.URL.Query()))
 	if err != nil {
 		http.Error(w, err.Error(), http.StatusInternalServerError)
 		return
@@ -61,6 +120,9 @@ func (s *Server) listIssues(w http.ResponseWriter, r *http.Request) {
 func (s *Server) createIssue(w http.ResponseWriter, r *http.Request) {
 	var req struct {
 		JiraURL string `json:"jira_url"`
+		// WithChildren also tracks every issue below the issue, and returns
+		// the list of tracked issues
+		WithChildren bool `json:"with_children"`
 	}
 
 	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
@@ -68,6 +130,19 @@ func (s *Server) createIssue(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
+	if req.WithChildren {
+		tracked, err := s.trackingService.TrackIssueWithChildren(r.Context(), req.JiraURL)
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusInternalServerError)
+			return
+		}
+
+		w.Header().Set("Content-Type", "application/json")
+		w.WriteHeader(http.StatusCreated)
+		json.NewEncoder(w).Encode(tracked)
+		return
+	}
+
 	issue, err := s.trackingService.TrackIssue(r.Context(), req.JiraURL)
 	if err != nil {
 		http.Error(w, err.Error(), http.StatusInternalServerError)
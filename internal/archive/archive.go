@@ -0,0 +1,201 @@
+// Package archive defines the portable format tracking data is exported to
+// and imported from. Archives reference issues by their qualified key and
+// pull requests by repository and number, never by storage IDs, so they can
+// be moved between instances.
+package archive
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"strings"
+	"time"
+
+	"github.com/jparrill/devtrackr/internal/models"
+)
+
+// Kind is the value of the format field of every archive
+const Kind = "devtrackr-archive"
+
+// Version is the version of the archive format written by this build.
+// Archives of a newer version are rejected.
+const Version = 1
+
+// Encoding identifies how an archive is serialized
+type Encoding string
+
+const (
+	// EncodingJSON writes a single JSON document holding every issue
+	EncodingJSON Encoding = "json"
+	// EncodingNDJSON writes the header on the first line and one issue per
+	// line after it, so large archives can be streamed
+	EncodingNDJSON Encoding = "ndjson"
+)
+
+// ParseEncoding converts a user supplied encoding name into an Encoding
+func ParseEncoding(name string) (Encoding, error) {
+	switch strings.ToLower(name) {
+	case "", "json":
+		return EncodingJSON, nil
+	case "ndjson", "jsonl":
+		return EncodingNDJSON, nil
+	default:
+		return "", fmt.Errorf("unsupported archive encoding: %s", name)
+	}
+}
+
+// Strategy sets what happens to the tracked issues missing from an archive
+// being imported
+type Strategy string
+
+const (
+	// StrategyMerge keeps them
+	StrategyMerge Strategy = "merge"
+	// StrategyReplace deletes them, leaving exactly the issues of the archive
+	StrategyReplace Strategy = "replace"
+)
+
+// ParseStrategy converts a user supplied strategy name into a Strategy
+func ParseStrategy(name string) (Strategy, error) {
+	switch s := Strategy(strings.ToLower(name)); s {
+	case StrategyMerge, StrategyReplace:
+		return s, nil
+	case "":
+		return StrategyMerge, nil
+	}
+	return "", fmt.Errorf("unknown import strategy %q, expected merge or replace", name)
+}
+
+// Conflict sets which side wins when an issue of an archive is already
+// tracked, matched by its qualified key
+type Conflict string
+
+const (
+	// ConflictNewer keeps the side whose issue was updated last
+	ConflictNewer Conflict = "newer"
+	// ConflictOurs keeps the tracked issue
+	ConflictOurs Conflict = "ours"
+	// ConflictTheirs overwrites the tracked issue with the archive
+	ConflictTheirs Conflict = "theirs"
+)
+
+// ParseConflict converts a user supplied conflict resolution into a Conflict
+func ParseConflict(name string) (Conflict, error) {
+	switch c := Conflict(strings.ToLower(name)); c {
+	case ConflictNewer, ConflictOurs, ConflictTheirs:
+		return c, nil
+	case "":
+		return ConflictNewer, nil
+	}
+	return "", fmt.Errorf("unknown conflict resolution %q, expected newer, ours or theirs", name)
+}
+
+// Header describes an archive
+type Header struct {
+	Format     string    `json:"format"`
+	Version    int       `json:"version"`
+	ExportedAt time.Time `json:"exported_at"`
+}
+
+// Archive is the tracking data of a set of issues
+type Archive struct {
+	Header
+	Issues []Issue `json:"issues"`
+}
+
+// New creates an empty archive of the current version
+func New() *Archive {
+	return &Archive{
+		Header: Header{Format: Kind, Version: Version, ExportedAt: time.Now()},
+		Issues: []Issue{},
+	}
+}
+
+// Issue is a tracked issue with everything recorded about it. The ID fields
+// of the embedded models are always zero.
+type Issue struct {
+	Issue         models.Issue        `json:"issue"` // Includes its children and links
+	PullRequests  []PullRequest       `json:"pull_requests"`
+	Subscriptions []Subscription      `json:"subscriptions"`
+	Events        []models.Event      `json:"events"`
+	Audit         []models.AuditEntry `json:"audit"`
+	Commits       []models.Commit     `json:"commits"`
+}
+
+// PullRequest is a tracked pull request with its backport link
+type PullRequest struct {
+	models.PullRequest
+	// Original references the original of a backport as org/repo#12. It
+	// replaces OriginalPRID, which is always nil.
+	Original string `json:"original,omitempty"`
+}
+
+// Subscription is the subscription of a user to an issue. User IDs come from
+// the X-User-ID header and are kept as they are.
+type Subscription struct {
+	UserID    int64     `json:"user_id"`
+	Active    bool      `json:"active"`
+	CreatedAt time.Time `json:"created_at"`
+	UpdatedAt time.Time `json:"updated_at"`
+}
+
+// Write serializes an archive
+func Write(w io.Writer, a *Archive, encoding Encoding) error {
+	switch encoding {
+	case EncodingJSON:
+		enc := json.NewEncoder(w)
+		enc.SetIndent("", "  ")
+		return enc.Encode(a)
+	case EncodingNDJSON:
+		enc := json.NewEncoder(w)
+		if err := enc.Encode(a.Header); err != nil {
+			return err
+		}
+		for _, issue := range a.Issues {
+			if err := enc.Encode(issue); err != nil {
+				return err
+			}
+		}
+		return nil
+	default:
+		return fmt.Errorf("unsupported archive encoding: %s", encoding)
+	}
+}
+
+// Read parses an archive in either encoding. Both start with the header
+// fields; a JSON document carries the issues in it, while NDJSON lists them
+// as the values following the header.
+func Read(r io.Reader) (*Archive, error) {
+	dec := json.NewDecoder(r)
+
+	var a Archive
+	if err := dec.Decode(&a); err != nil {
+		return nil, fmt.Errorf("failed to read archive header: %w", err)
+	}
+	if a.Format != Kind {
+		return nil, fmt.Errorf("not a DevTrackr archive: format is %q", a.Format)
+	}
+	if a.Version < 1 || a.Version > Version {
+		return nil, fmt.Errorf("unsupported archive version %d, this build reads up to %d", a.Version, Version)
+	}
+
+	for {
+		var issue Issue
+		err := dec.Decode(&issue)
+		if errors.Is(err, io.EOF) {
+			break
+		}
+		if err != nil {
+			return nil, fmt.Errorf("failed to read archive issue %d: %w", len(a.Issues)+1, err)
+		}
+		a.Issues = append(a.Issues, issue)
+	}
+
+	for i, issue := range a.Issues {
+		if issue.Issue.Key == "" {
+			return nil, fmt.Errorf("archive issue %d has no key", i+1)
+		}
+	}
+	return &a, nil
+}
@@ -0,0 +1,46 @@
+package archive
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+
+	"github.com/jparrill/devtrackr/internal/models"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestWriteRead(t *testing.T) {
+	a := New()
+	a.Issues = append(a.Issues,
+		Issue{Issue: models.Issue{Key: "OCPBUGS-1"}},
+		Issue{Issue: models.Issue{Key: "OCPBUGS-2"}, PullRequests: []PullRequest{
+			{PullRequest: models.PullRequest{Repository: "org/repo", Number: 2, IsBackport: true}, Original: "org/repo#1"},
+		}},
+	)
+
+	for _, encoding := range []Encoding{EncodingJSON, EncodingNDJSON} {
+		var buf bytes.Buffer
+		require.NoError(t, Write(&buf, a, encoding))
+		if encoding == EncodingNDJSON {
+			assert.Equal(t, 3, strings.Count(buf.String(), "\n"), "one line per issue after the header")
+		}
+
+		got, err := Read(&buf)
+		require.NoError(t, err, encoding)
+		require.Len(t, got.Issues, 2)
+		assert.Equal(t, "OCPBUGS-2", got.Issues[1].Issue.Key)
+		assert.Equal(t, "org/repo#1", got.Issues[1].PullRequests[0].Original)
+	}
+}
+
+func TestReadRejects(t *testing.T) {
+	for name, input := range map[string]string{
+		"format":  `{"format": "other", "version": 1}`,
+		"version": `{"format": "devtrackr-archive", "version": 2}`,
+		"key":     `{"format": "devtrackr-archive", "version": 1, "issues": [{"issue": {}}]}`,
+	} {
+		_, err := Read(strings.NewReader(input))
+		assert.Error(t, err, name)
+	}
+}
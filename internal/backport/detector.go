@@ -0,0 +1,222 @@
+package backport
+
+import (
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+
+	"github.com/jparrill/devtrackr/internal/models"
+)
+
+// Heuristic names accepted in Config.Heuristics
+const (
+	HeuristicTitlePrefix = "title_prefix"
+	HeuristicCherryPick  = "cherry_pick"
+	HeuristicLabels      = "labels"
+	HeuristicIssueKey    = "issue_key"
+)
+
+// Config controls how backports are detected from pull request metadata
+type Config struct {
+	// Heuristics lists the enabled heuristics. Empty enables all of them.
+	Heuristics []string `yaml:"heuristics"`
+	// TitlePattern matches backport title prefixes such as "[release-4.15]".
+	// Its first capture group, if any, is the release branch.
+	TitlePattern string `yaml:"title_pattern"`
+	// CherryPickPattern matches cherry-pick bot descriptions.
+	// Its first capture group is the number of the original pull request.
+	CherryPickPattern string `yaml:"cherry_pick_pattern"`
+	// Labels mark a pull request as a backport when present
+	Labels []string `yaml:"labels"`
+	// DefaultBranches are the development branches original fixes land on
+	DefaultBranches []string `yaml:"default_branches"`
+}
+
+// DefaultConfig returns the conventions used by the OpenShift cherry-pick tooling
+func DefaultConfig() Config {
+	return Config{
+		Heuristics:        []string{HeuristicTitlePrefix, HeuristicCherryPick, HeuristicLabels, HeuristicIssueKey},
+		TitlePattern:      `^\s*\[(release-[^\]]+)\]`,
+		CherryPickPattern: `(?i)this is an automated cherry-pick of #(\d+)`,
+		Labels:            []string{"cherry-pick-approved"},
+		DefaultBranches:   []string{"main", "master"},
+	}
+}
+
+// Detector infers backport relationships between pull requests
+type Detector struct {
+	heuristics      map[string]bool
+	titleRe         *regexp.Regexp
+	cherryPickRe    *regexp.Regexp
+	labels          map[string]bool
+	defaultBranches map[string]bool
+}
+
+// Result is the outcome of running the detector on a pull request
+type Result struct {
+	IsBackport     bool                `json:"is_backport"`
+	OriginalNumber int                 `json:"original_number,omitempty"` // Original PR number found in the metadata
+	Original       *models.PullRequest `json:"-"`                         // Tracked original pull request, if found
+	Reasons        []string            `json:"reasons"`
+}
+
+// NewDetector creates a detector. Empty config fields fall back to DefaultConfig.
+func NewDetector(cfg Config) (*Detector, error) {
+	defaults := DefaultConfig()
+	if len(cfg.Heuristics) == 0 {
+		cfg.Heuristics = defaults.Heuristics
+	}
+	if cfg.TitlePattern == "" {
+		cfg.TitlePattern = defaults.TitlePattern
+	}
+	if cfg.CherryPickPattern == "" {
+		cfg.CherryPickPattern = defaults.CherryPickPattern
+	}
+	if cfg.Labels == nil {
+		cfg.Labels = defaults.Labels
+	}
+	if len(cfg.DefaultBranches) == 0 {
+		cfg.DefaultBranches = defaults.DefaultBranches
+	}
+
+	d := &Detector{
+		heuristics:      map[string]bool{},
+		labels:          map[string]bool{},
+		defaultBranches: map[string]bool{},
+	}
+
+	for _, h := range cfg.Heuristics {
+		switch h {
+		case HeuristicTitlePrefix, HeuristicCherryPick, HeuristicLabels, HeuristicIssueKey:
+			d.heuristics[h] = true
+		default:
+			return nil, fmt.Errorf("unknown backport heuristic: %s", h)
+		}
+	}
+
+	var err error
+	if d.titleRe, err = regexp.Compile(cfg.TitlePattern); err != nil {
+		return nil, fmt.Errorf("invalid backport title pattern: %w", err)
+	}
+	if d.cherryPickRe, err = regexp.Compile(cfg.CherryPickPattern); err != nil {
+		return nil, fmt.Errorf("invalid cherry-pick pattern: %w", err)
+	}
+
+	for _, label := range cfg.Labels {
+		d.labels[strings.ToLower(label)] = true
+	}
+	for _, branch := range cfg.DefaultBranches {
+		d.defaultBranches[branch] = true
+	}
+
+	return d, nil
+}
+
+// IsDefaultBranch reports whether a branch is a development branch
+func (d *Detector) IsDefaultBranch(branch string) bool {
+	return d.defaultBranches[branch]
+}
+
+// Detect decides whether pr is a backport of another pull request of the
+// issue identified by issueKey. siblings are the other pull requests tracked
+// for the same issue and are used to find the original.
+func (d *Detector) Detect(pr *models.PullRequest, issueKey string, siblings []*models.PullRequest) Result {
+	var result Result
+
+	// Pull requests against a development branch are never backports
+	if d.IsDefaultBranch(pr.TargetBranch) {
+		return result
+	}
+
+	if d.heuristics[HeuristicTitlePrefix] {
+		if m := d.titleRe.FindStringSubmatch(pr.Title); m != nil {
+			result.IsBackport = true
+			result.Reasons = append(result.Reasons, fmt.Sprintf("title prefix %q", strings.TrimSpace(m[0])))
+		}
+	}
+
+	if d.heuristics[HeuristicCherryPick] {
+		if m := d.cherryPickRe.FindStringSubmatch(pr.Body); m != nil {
+			result.IsBackport = true
+			if len(m) > 1 {
+				if n, err := strconv.Atoi(m[1]); err == nil {
+					result.OriginalNumber = n
+				}
+			}
+			result.Reasons = append(result.Reasons, "cherry-pick description")
+		}
+	}
+
+	if d.heuristics[HeuristicLabels] {
+		for _, label := range pr.Labels {
+			if d.labels[strings.ToLower(label)] {
+				result.IsBackport = true
+				result.Reasons = append(result.Reasons, fmt.Sprintf("label %q", label))
+				break
+			}
+		}
+	}
+
+	if d.heuristics[HeuristicIssueKey] && issueKey != "" && pr.TargetBranch != "" {
+		if strings.Contains(pr.Title, issueKey) || strings.Contains(pr.Body, issueKey) {
+			result.IsBackport = true
+			result.Reasons = append(result.Reasons, fmt.Sprintf("mentions %s on branch %s", issueKey, pr.TargetBranch))
+		}
+	}
+
+	if result.IsBackport {
+		result.Original = d.findOriginal(pr, result.OriginalNumber, siblings)
+	}
+
+	return result
+}
+
+// findOriginal looks for the original pull request among the siblings. An
+// explicit original number wins; otherwise the non-backport pull request on a
+// development branch of the same repository is used, preferring merged ones.
+func (d *Detector) findOriginal(pr *models.PullRequest, number int, siblings []*models.PullRequest) *models.PullRequest {
+	var best *models.PullRequest
+	for _, s := range siblings {
+		if s == pr || (s.ID != 0 && s.ID == pr.ID) {
+			continue
+		}
+		if pr.Repository != "" && s.Repository != "" && s.Repository != pr.Repository {
+			continue
+		}
+
+		if number > 0 {
+			if s.Number == number {
+				return s
+			}
+			continue
+		}
+
+		if s.IsBackport || !d.IsDefaultBranch(s.TargetBranch) {
+			continue
+		}
+		if best == nil || (s.Status == models.PRStatusMerged && best.Status != models.PRStatusMerged) {
+			best = s
+		}
+	}
+	return best
+}
+
+// Apply records a detection result on the pull request. Existing manual
+// settings are never cleared, and an existing original link is kept.
+func (r Result) Apply(pr *models.PullRequest) bool {
+	if !r.IsBackport {
+		return false
+	}
+
+	changed := !pr.IsBackport
+	pr.IsBackport = true
+
+	if pr.OriginalPRID == nil && r.Original != nil && r.Original.ID != 0 {
+		id := r.Original.ID
+		pr.OriginalPRID = &id
+		changed = true
+	}
+
+	return changed
+}
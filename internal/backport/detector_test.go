@@ -0,0 +1,147 @@
+package backport
+
+import (
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/jparrill/devtrackr/internal/models"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// fixturePR is the subset of a GitHub pull request payload used by the fixtures
+type fixturePR struct {
+	Number  int    `json:"number"`
+	Title   string `json:"title"`
+	Body    string `json:"body"`
+	HTMLURL string `json:"html_url"`
+	Merged  bool   `json:"merged"`
+	Labels  []struct {
+		Name string `json:"name"`
+	} `json:"labels"`
+	Base struct {
+		Ref  string `json:"ref"`
+		Repo struct {
+			FullName string `json:"full_name"`
+		} `json:"repo"`
+	} `json:"base"`
+}
+
+// loadFixture reads a pull request payload from testdata
+func loadFixture(t *testing.T, name string) *models.PullRequest {
+	t.Helper()
+
+	data, err := os.ReadFile(filepath.Join("testdata", name))
+	require.NoError(t, err)
+
+	var payload fixturePR
+	require.NoError(t, json.Unmarshal(data, &payload))
+
+	pr := &models.PullRequest{
+		Number:       payload.Number,
+		Repository:   payload.Base.Repo.FullName,
+		Title:        payload.Title,
+		Body:         payload.Body,
+		URL:          payload.HTMLURL,
+		Status:       models.PRStatusOpen,
+		TargetBranch: payload.Base.Ref,
+	}
+	if payload.Merged {
+		pr.Status = models.PRStatusMerged
+	}
+	for _, label := range payload.Labels {
+		pr.Labels = append(pr.Labels, label.Name)
+	}
+	return pr
+}
+
+func TestDetect(t *testing.T) {
+	detector, err := NewDetector(Config{})
+	require.NoError(t, err)
+
+	original := loadFixture(t, "original.json")
+	original.ID = 1
+
+	tests := []struct {
+		fixture    string
+		isBackport bool
+		number     int
+		reasons    int
+	}{
+		{fixture: "original.json", isBackport: false},
+		{fixture: "cherry_pick_bot.json", isBackport: true, number: 123, reasons: 4},
+		{fixture: "title_prefix.json", isBackport: true, reasons: 1},
+		{fixture: "label_only.json", isBackport: true, reasons: 1},
+		{fixture: "issue_key.json", isBackport: true, reasons: 1},
+		{fixture: "unrelated.json", isBackport: false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.fixture, func(t *testing.T) {
+			pr := loadFixture(t, tt.fixture)
+			pr.ID = 2
+
+			result := detector.Detect(pr, "OCPBUGS-1234", []*models.PullRequest{original})
+			assert.Equal(t, tt.isBackport, result.IsBackport)
+			assert.Equal(t, tt.number, result.OriginalNumber)
+			assert.Len(t, result.Reasons, tt.reasons)
+
+			if tt.isBackport {
+				require.NotNil(t, result.Original)
+				assert.Equal(t, original.ID, result.Original.ID)
+			} else {
+				assert.Nil(t, result.Original)
+			}
+		})
+	}
+}
+
+func TestDetectConfiguredHeuristics(t *testing.T) {
+	detector, err := NewDetector(Config{Heuristics: []string{HeuristicCherryPick}})
+	require.NoError(t, err)
+
+	// Only the cherry-pick description is considered
+	assert.True(t, detector.Detect(loadFixture(t, "cherry_pick_bot.json"), "OCPBUGS-1234", nil).IsBackport)
+	assert.False(t, detector.Detect(loadFixture(t, "title_prefix.json"), "OCPBUGS-1234", nil).IsBackport)
+	assert.False(t, detector.Detect(loadFixture(t, "label_only.json"), "OCPBUGS-1234", nil).IsBackport)
+	assert.False(t, detector.Detect(loadFixture(t, "issue_key.json"), "OCPBUGS-1234", nil).IsBackport)
+
+	_, err = NewDetector(Config{Heuristics: []string{"magic"}})
+	assert.Error(t, err)
+
+	_, err = NewDetector(Config{TitlePattern: "("})
+	assert.Error(t, err)
+}
+
+func TestDetectOriginalByNumber(t *testing.T) {
+	detector, err := NewDetector(Config{})
+	require.NoError(t, err)
+
+	// The cherry-pick description points at #123 even if another PR targets main
+	other := &models.PullRequest{ID: 5, Number: 99, Repository: "openshift/hypershift", TargetBranch: "main", Status: models.PRStatusMerged}
+	original := &models.PullRequest{ID: 6, Number: 123, Repository: "openshift/hypershift", TargetBranch: "main"}
+
+	result := detector.Detect(loadFixture(t, "cherry_pick_bot.json"), "OCPBUGS-1234", []*models.PullRequest{other, original})
+	require.NotNil(t, result.Original)
+	assert.Equal(t, int64(6), result.Original.ID)
+}
+
+func TestApply(t *testing.T) {
+	manual := int64(42)
+	pr := &models.PullRequest{OriginalPRID: &manual}
+
+	result := Result{IsBackport: true, Original: &models.PullRequest{ID: 7}}
+	assert.True(t, result.Apply(pr))
+	assert.True(t, pr.IsBackport)
+	assert.Equal(t, int64(42), *pr.OriginalPRID, "manual links are kept")
+
+	pr = &models.PullRequest{}
+	assert.False(t, Result{}.Apply(pr))
+	assert.False(t, pr.IsBackport)
+
+	assert.True(t, result.Apply(pr))
+	assert.Equal(t, int64(7), *pr.OriginalPRID)
+	assert.False(t, result.Apply(pr), "applying twice is a no-op")
+}
@@ -0,0 +1,310 @@
+package config
+
+import (
+	"fmt"
+	"os"
+	"time"
+
+	"github.com/jparrill/devtrackr/internal/alerts"
+	"github.com/jparrill/devtrackr/internal/backport"
+	"github.com/jparrill/devtrackr/internal/forge"
+	"github.com/jparrill/devtrackr/internal/jira"
+	"github.com/jparrill/devtrackr/internal/polling"
+	"github.com/jparrill/devtrackr/internal/rules"
+	"gopkg.in/yaml.v3"
+)
+
+// DefaultPath is the configuration file used when none is given
+const DefaultPath = "devtrackr.yaml"
+
+// Config holds the DevTrackr configuration
+type Config struct {
+	// Branches lists the release branches expected for projects without their own list
+	Branches []string                 `yaml:"branches"`
+	Projects map[string]ProjectConfig `yaml:"projects"`
+	Backport backport.Config          `yaml:"backport"`
+	GitScan  GitScanConfig            `yaml:"git_scan"`
+	// Forges lists GitHub Enterprise and self-hosted GitLab instances;
+	// github.com and gitlab.com are always available
+	Forges []forge.Config `yaml:"forges"`
+	// Jira lists the Jira instances issues can be tracked from, picked by
+	// the host of each issue URL
+	Jira     []jira.InstanceConfig `yaml:"jira"`
+	Webhooks WebhookConfig         `yaml:"webhooks"`
+	// Polling sets the default polling interval and how it adapts to the
+	// lifecycle of each issue
+	Polling polling.Config `yaml:"polling"`
+	// Rules automate steps such as transitions and comments on changes of
+	// issues and pull requests, or on a schedule
+	Rules         []rules.Rule       `yaml:"rules"`
+	Notifications NotificationConfig `yaml:"notifications"`
+	// Clones describes how bugs are cloned for each z-stream release
+	Clones CloneConfig `yaml:"clones"`
+	// Alerts sets when pull requests and issues are flagged as stale
+	Alerts alerts.Config `yaml:"alerts"`
+	// Digest configures the summaries of what changed on subscribed issues
+	Digest DigestConfig `yaml:"digest"`
+	// Database configures backups and retention of the tracking database
+	Database DatabaseConfig `yaml:"database"`
+}
+
+// ProjectConfig holds the settings of a single Jira project, keyed by its key prefix (e.g. OCPBUGS)
+type ProjectConfig struct {
+	// Branches lists the branches a fix is expected to land on, e.g. main, release-4.16
+	Branches []string `yaml:"branches"`
+}
+
+// GitScanConfig configures the scheduled scan of local git repositories
+type GitScanConfig struct {
+	Interval     time.Duration   `yaml:"interval"`
+	Repositories []GitRepository `yaml:"repositories"`
+}
+
+// GitRepository is a local clone scanned for commits referencing tracked issues
+type GitRepository struct {
+	Path string `yaml:"path"`
+	// Branches are glob patterns such as "release-*". Empty scans every branch.
+	Branches []string `yaml:"branches"`
+}
+
+// WebhookConfig configures the inbound webhook receivers
+type WebhookConfig struct {
+	GitHub GitHubWebhookConfig `yaml:"github"`
+	Jira   JiraWebhookConfig   `yaml:"jira"`
+}
+
+// GitHubWebhookConfig configures the GitHub webhook receiver
+type GitHubWebhookConfig struct {
+	// SecretEnv names the environment variable holding the webhook secret
+	SecretEnv string `yaml:"secret_env"`
+}
+
+// Secret returns the webhook secret, empty when not set
+func (c GitHubWebhookConfig) Secret() string {
+	if c.SecretEnv == "" {
+		return ""
+	}
+	return os.Getenv(c.SecretEnv)
+}
+
+// JiraWebhookConfig configures the Jira webhook receiver
+type JiraWebhookConfig struct {
+	// SecretEnv names the environment variable holding the shared secret,
+	// used to check signatures, JWTs or the secret query parameter
+	SecretEnv string `yaml:"secret_env"`
+	// PollingInterval is the minimum polling interval of issues updated by
+	// webhooks, for which polling only reconciles missed deliveries
+	PollingInterval time.Duration `yaml:"polling_interval"`
+}
+
+// Secret returns the shared secret, empty when not set
+func (c JiraWebhookConfig) Secret() string {
+	if c.SecretEnv == "" {
+		return ""
+	}
+	return os.Getenv(c.SecretEnv)
+}
+
+// CloneConfig describes how clone chains of a bug across releases are found
+type CloneConfig struct {
+	// LinkType is the name of the Jira issue link type between a bug and
+	// its clones
+	LinkType string `yaml:"link_type"`
+	// TargetVersion is the issue attribute holding the release a clone
+	// targets, mapped under jira[].fields.custom. Issues without it fall
+	// back to their fix versions.
+	TargetVersion string `yaml:"target_version"`
+}
+
+// NotificationConfig configures where rule notifications are delivered
+type NotificationConfig struct {
+	// WebhookURLEnv names the environment variable holding the URL
+	// notifications are posted to, e.g. a Slack incoming webhook. Without
+	// it notifications are logged.
+	WebhookURLEnv string `yaml:"webhook_url_env"`
+}
+
+// WebhookURL returns the notification webhook URL, empty when not set
+func (c NotificationConfig) WebhookURL() string {
+	if c.WebhookURLEnv == "" {
+		return ""
+	}
+	return os.Getenv(c.WebhookURLEnv)
+}
+
+// DigestConfig configures the daily digest of subscribed issues
+type DigestConfig struct {
+	// Me is the user ID "--user me" refers to, as sent in the X-User-ID header
+	Me int64 `yaml:"me"`
+	// At is the local time of day, e.g. "08:30", scheduled digests are sent
+	// at. Empty disables them.
+	At string `yaml:"at"`
+	// Period is how far back scheduled digests look
+	Period time.Duration `yaml:"period"`
+	// Recipients lists the users receiving scheduled digests. Those without
+	// an email address get them through the notification webhook.
+	Recipients []DigestRecipient `yaml:"recipients"`
+	SMTP       SMTPConfig        `yaml:"smtp"`
+}
+
+// DigestRecipient is a user receiving the scheduled digest
+type DigestRecipient struct {
+	UserID int64  `yaml:"user_id"`
+	Email  string `yaml:"email"`
+}
+
+// SMTPConfig configures the mail server digests are emailed through
+type SMTPConfig struct {
+	// Addr is the host:port of the server; empty disables email
+	Addr     string `yaml:"addr"`
+	From     string `yaml:"from"`
+	Username string `yaml:"username"`
+	// PasswordEnv names the environment variable holding the password
+	PasswordEnv string `yaml:"password_env"`
+}
+
+// Password returns the SMTP password, empty when not set
+func (c SMTPConfig) Password() string {
+	if c.PasswordEnv == "" {
+		return ""
+	}
+	return os.Getenv(c.PasswordEnv)
+}
+
+// DatabaseConfig configures the tracking database and its maintenance
+type DatabaseConfig struct {
+	// DSN selects the storage backend: sqlite:///path/to/devtrackr.db, or
+	// postgres://user@host:5432/devtrackr for data shared between nodes
+	DSN string `yaml:"dsn"`
+	// DSNEnv names the environment variable overriding DSN when set, e.g. to
+	// keep a password out of the configuration
+	DSNEnv    string          `yaml:"dsn_env"`
+	Backup    BackupConfig    `yaml:"backup"`
+	Retention RetentionConfig `yaml:"retention"`
+}
+
+// DataSource returns the DSN of the database, taken from DSNEnv when set
+func (c DatabaseConfig) DataSource() string {
+	if c.DSNEnv != "" {
+		if dsn := os.Getenv(c.DSNEnv); dsn != "" {
+			return dsn
+		}
+	}
+	return c.DSN
+}
+
+// BackupConfig configures the scheduled backups taken by the server
+type BackupConfig struct {
+	// Dir is where backups are written; empty disables scheduled backups
+	Dir      string        `yaml:"dir"`
+	Interval time.Duration `yaml:"interval"`
+	// Keep is how many backups are kept, the oldest being deleted first
+	Keep int `yaml:"keep"`
+}
+
+// RetentionConfig sets how long history is kept. Zero keeps it forever.
+type RetentionConfig struct {
+	// Events is how long timeline events are kept. Analytics and digests
+	// only see the events kept.
+	Events time.Duration `yaml:"events"`
+	// Audit is how long the log of changes written to Jira is kept
+	Audit time.Duration `yaml:"audit"`
+}
+
+// Default returns the configuration used when no configuration file exists
+func Default() *Config {
+	return &Config{
+		Projects: map[string]ProjectConfig{},
+		Backport: backport.DefaultConfig(),
+		Jira:     jira.DefaultInstances(),
+		GitScan: GitScanConfig{
+			Interval: time.Hour,
+		},
+		Webhooks: WebhookConfig{
+			GitHub: GitHubWebhookConfig{SecretEnv: "GITHUB_WEBHOOK_SECRET"},
+			Jira: JiraWebhookConfig{
+				SecretEnv:       "JIRA_WEBHOOK_SECRET",
+				PollingInterval: time.Hour,
+			},
+		},
+		Polling: polling.DefaultConfig(),
+		Clones: CloneConfig{
+			LinkType:      "Cloners",
+			TargetVersion: "target_version",
+		},
+		Alerts: alerts.DefaultConfig(),
+		Digest: DigestConfig{
+			Period: 24 * time.Hour,
+		},
+		Database: DatabaseConfig{
+			DSN:    "sqlite://devtrackr.db",
+			DSNEnv: "DEVTRACKR_DSN",
+			Backup: BackupConfig{Interval: 24 * time.Hour, Keep: 7},
+		},
+	}
+}
+
+// Load reads the configuration from a YAML file. A missing file is not an
+// error and yields the default configuration.
+func Load(path string) (*Config, error) {
+	cfg := Default()
+
+	data, err := os.ReadFile(path)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return cfg, nil
+		}
+		return nil, fmt.Errorf("failed to read config file: %w", err)
+	}
+
+	if err := yaml.Unmarshal(data, cfg); err != nil {
+		return nil, fmt.Errorf("failed to parse config file %s: %w", path, err)
+	}
+
+	if cfg.Projects == nil {
+		cfg.Projects = map[string]ProjectConfig{}
+	}
+	if cfg.Polling.Projects == nil {
+		cfg.Polling.Projects = map[string]polling.Policy{}
+	}
+	if cfg.Alerts.Projects == nil {
+		cfg.Alerts.Projects = map[string]alerts.Thresholds{}
+	}
+
+	return cfg, nil
+}
+
+// PollingPolicy returns the engine computing the polling interval of issues
+func (c *Config) PollingPolicy() *polling.Engine {
+	return polling.NewEngine(c.Polling, c.Webhooks.Jira.PollingInterval)
+}
+
+// RuleEngine validates the automation rules and returns an engine matching them
+func (c *Config) RuleEngine() (*rules.Engine, error) {
+	return rules.NewEngine(c.Rules)
+}
+
+// CustomFields returns the custom field mapping of a Jira instance. Issues
+// with an unknown instance get the mappings of every instance.
+func (c *Config) CustomFields(instance string) []jira.CustomField {
+	var fields []jira.CustomField
+	for _, inst := range c.Jira {
+		if inst.Name == instance {
+			return inst.Fields.Custom
+		}
+		fields = append(fields, inst.Fields.Custom...)
+	}
+	if instance != "" {
+		return nil
+	}
+	return fields
+}
+
+// ExpectedBranches returns the branches a fix for the given project is
+// expected to land on, falling back to the global list
+func (c *Config) ExpectedBranches(project string) []string {
+	if p, ok := c.Projects[project]; ok && len(p.Branches) > 0 {
+		return p.Branches
+	}
+	return c.Branches
+}
@@ -0,0 +1,80 @@
+package config
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestLoadMissingFile(t *testing.T) {
+	cfg, err := Load(filepath.Join(t.TempDir(), "missing.yaml"))
+	require.NoError(t, err)
+	assert.NotNil(t, cfg.Projects)
+	assert.Empty(t, cfg.ExpectedBranches("OCPBUGS"))
+}
+
+func TestLoad(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "devtrackr.yaml")
+	err := os.WriteFile(path, []byte(`
+branches: [main]
+projects:
+  OCPBUGS:
+    branches: [main, release-4.16, release-4.15]
+git_scan:
+  interval: 30m
+  repositories:
+    - path: /src/hypershift
+      branches: [main, release-*]
+forges:
+  - type: gitlab
+    host: gitlab.example.com
+    token_env: GITLAB_EXAMPLE_TOKEN
+polling:
+  interval: 10m
+  done: 12h
+  projects:
+    OCPBUGS:
+      stop_after: 72h
+`), 0o644)
+	require.NoError(t, err)
+
+	cfg, err := Load(path)
+	require.NoError(t, err)
+	assert.Equal(t, []string{"main", "release-4.16", "release-4.15"}, cfg.ExpectedBranches("OCPBUGS"))
+	assert.Equal(t, []string{"main"}, cfg.ExpectedBranches("HOSTEDCP"))
+	assert.Equal(t, 30*time.Minute, cfg.GitScan.Interval)
+	require.Len(t, cfg.GitScan.Repositories, 1)
+	assert.Equal(t, []string{"main", "release-*"}, cfg.GitScan.Repositories[0].Branches)
+	require.Len(t, cfg.Forges, 1)
+	assert.Equal(t, "gitlab", cfg.Forges[0].Type)
+	assert.Equal(t, "gitlab.example.com", cfg.Forges[0].Host)
+	assert.Equal(t, "GITLAB_EXAMPLE_TOKEN", cfg.Forges[0].TokenEnv)
+	assert.Equal(t, 10*time.Minute, cfg.Polling.Interval)
+	assert.Equal(t, 12*time.Hour, cfg.Polling.Done)
+	assert.Equal(t, 30*time.Minute, cfg.Polling.ToDo, "unset policies keep their default")
+	assert.Equal(t, 72*time.Hour, cfg.PollingPolicy().PolicyFor("OCPBUGS").StopAfter)
+}
+
+func TestLoadInvalidFile(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "devtrackr.yaml")
+	require.NoError(t, os.WriteFile(path, []byte("projects: ["), 0o644))
+
+	_, err := Load(path)
+	assert.Error(t, err)
+}
+
+func TestDatabaseDataSource(t *testing.T) {
+	cfg := Default().Database
+	t.Setenv("DEVTRACKR_DSN", "")
+	assert.Equal(t, "sqlite://devtrackr.db", cfg.DataSource())
+
+	t.Setenv("DEVTRACKR_DSN", "postgres://devtrackr:secret@db/devtrackr")
+	assert.Equal(t, "postgres://devtrackr:secret@db/devtrackr", cfg.DataSource())
+
+	cfg.DSNEnv = ""
+	assert.Equal(t, "sqlite://devtrackr.db", cfg.DataSource())
+}
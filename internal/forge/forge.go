@@ -0,0 +1,146 @@
+package forge
+
+import (
+	"context"
+	"fmt"
+	"net/url"
+	"os"
+	"strings"
+
+	"github.com/jparrill/devtrackr/internal/gerrit"
+	"github.com/jparrill/devtrackr/internal/github"
+	"github.com/jparrill/devtrackr/internal/gitlab"
+	"github.com/jparrill/devtrackr/internal/models"
+)
+
+// Forge types supported in the configuration
+const (
+	TypeGitHub = "github"
+	TypeGitLab = "gitlab"
+	TypeGerrit = "gerrit"
+)
+
+// Provider fetches the state of pull requests (or their equivalent, such as
+// merge requests) from a code hosting service
+type Provider interface {
+	// Name returns the name of the forge, e.g. github
+	Name() string
+	// GetPullRequest retrieves the pull request behind a web URL
+	GetPullRequest(ctx context.Context, prURL string) (*models.PullRequest, error)
+}
+
+// Config configures the forge serving a host
+type Config struct {
+	// Type is the forge software: github, gitlab or gerrit
+	Type string `yaml:"type"`
+	// Host is the host name found in pull request URLs, e.g. gitlab.example.com
+	Host string `yaml:"host"`
+	// APIURL overrides the API endpoint derived from the host
+	APIURL string `yaml:"api_url"`
+	// TokenEnv names the environment variable holding the API token. For
+	// Gerrit it holds "username:http-password".
+	TokenEnv string `yaml:"token_env"`
+}
+
+// Registry picks the provider for a pull request URL by its host
+type Registry struct {
+	providers map[string]Provider
+}
+
+// NewRegistry creates an empty registry
+func NewRegistry() *Registry {
+	return &Registry{
+		providers: map[string]Provider{},
+	}
+}
+
+// NewRegistryFromConfig creates a registry serving github.com and gitlab.com
+// plus the configured forges, which take precedence for the same host
+func NewRegistryFromConfig(configs []Config) (*Registry, error) {
+	r := NewRegistry()
+	r.Register("github.com", github.NewClient(github.DefaultAPIURL, os.Getenv("GITHUB_TOKEN")))
+	r.Register("gitlab.com", gitlab.NewClient("https://gitlab.com", os.Getenv("GITLAB_TOKEN")))
+
+	for _, cfg := range configs {
+		provider, err := newProvider(cfg)
+		if err != nil {
+			return nil, err
+		}
+		r.Register(cfg.Host, provider)
+	}
+
+	return r, nil
+}
+
+// newProvider creates the client described by a forge configuration
+func newProvider(cfg Config) (Provider, error) {
+	if cfg.Host == "" {
+		return nil, fmt.Errorf("forge of type %q has no host", cfg.Type)
+	}
+
+	var token string
+	if cfg.TokenEnv != "" {
+		token = os.Getenv(cfg.TokenEnv)
+	}
+
+	switch cfg.Type {
+	case TypeGitHub:
+		apiURL := cfg.APIURL
+		if apiURL == "" {
+			apiURL = github.DefaultAPIURL
+			if cfg.Host != "github.com" {
+				apiURL = "https://" + cfg.Host + "/api/v3"
+			}
+		}
+		return github.NewClient(apiURL, token), nil
+	case TypeGitLab:
+		apiURL := cfg.APIURL
+		if apiURL == "" {
+			apiURL = "https://" + cfg.Host
+		}
+		return gitlab.NewClient(apiURL, token), nil
+	case TypeGerrit:
+		apiURL := cfg.APIURL
+		if apiURL == "" {
+			apiURL = "https://" + cfg.Host
+		}
+		username, password, _ := strings.Cut(token, ":")
+		return gerrit.NewClient(apiURL, username, password), nil
+	default:
+		return nil, fmt.Errorf("unsupported forge type %q for host %s", cfg.Type, cfg.Host)
+	}
+}
+
+// Register sets the provider serving a host
+func (r *Registry) Register(host string, provider Provider) {
+	r.providers[strings.ToLower(host)] = provider
+}
+
+// ProviderFor returns the provider serving the host of a pull request URL
+func (r *Registry) ProviderFor(prURL string) (Provider, error) {
+	parsedURL, err := url.Parse(prURL)
+	if err != nil {
+		return nil, fmt.Errorf("invalid pull request URL: %w", err)
+	}
+
+	provider, ok := r.providers[strings.ToLower(parsedURL.Hostname())]
+	if !ok {
+		return nil, fmt.Errorf("no forge configured for host %q", parsedURL.Hostname())
+	}
+	return provider, nil
+}
+
+// Supports reports whether a provider is registered for the host of a pull request URL
+func (r *Registry) Supports(prURL string) bool {
+	_, err := r.ProviderFor(prURL)
+	return err == nil
+}
+
+// GetPullRequest retrieves a pull request from the provider serving its host
+func (r *Registry) GetPullRequest(ctx context.Context, prURL string) (*models.PullRequest, error) {
+	provider, err := r.ProviderFor(prURL)
+	if err != nil {
+		return nil, err
+	}
+	return provider.GetPullRequest(ctx, prURL)
+}
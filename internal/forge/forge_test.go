@@ -0,0 +1,70 @@
+package forge
+
+import (
+	"context"
+	"testing"
+
+	"github.com/jparrill/devtrackr/internal/gerrit"
+	"github.com/jparrill/devtrackr/internal/github"
+	"github.com/jparrill/devtrackr/internal/gitlab"
+	"github.com/jparrill/devtrackr/internal/models"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type fakeProvider struct {
+	name string
+}
+
+func (p *fakeProvider) Name() string {
+	return p.name
+}
+
+func (p *fakeProvider) GetPullRequest(ctx context.Context, prURL string) (*models.PullRequest, error) {
+	return &models.PullRequest{URL: prURL, Repository: p.name}, nil
+}
+
+func TestRegistryPicksProviderByHost(t *testing.T) {
+	r := NewRegistry()
+	r.Register("github.com", &fakeProvider{name: "github"})
+	r.Register("GitLab.example.com", &fakeProvider{name: "gitlab"})
+
+	pr, err := r.GetPullRequest(context.Background(), "https://gitlab.example.com/team/tool/-/merge_requests/1")
+	require.NoError(t, err)
+	assert.Equal(t, "gitlab", pr.Repository)
+
+	provider, err := r.ProviderFor("https://github.com/openshift/hypershift/pull/1")
+	require.NoError(t, err)
+	assert.Equal(t, "github", provider.Name())
+
+	assert.False(t, r.Supports("https://bitbucket.org/team/repo/pull-requests/1"))
+	_, err = r.GetPullRequest(context.Background(), "https://bitbucket.org/team/repo/pull-requests/1")
+	assert.Error(t, err)
+}
+
+func TestNewRegistryFromConfig(t *testing.T) {
+	r, err := NewRegistryFromConfig([]Config{
+		{Type: TypeGitHub, Host: "github.example.com"},
+		{Type: TypeGitLab, Host: "gitlab.example.com", APIURL: "https://gitlab-api.example.com"},
+		{Type: TypeGerrit, Host: "review.opendev.org"},
+	})
+	require.NoError(t, err)
+
+	for url, want := range map[string]interface{}{
+		"https://github.com/o/r/pull/1":                       &github.Client{},
+		"https://gitlab.com/g/p/-/merge_requests/1":           &gitlab.Client{},
+		"https://github.example.com/o/r/pull/1":               &github.Client{},
+		"https://gitlab.example.com/g/p/-/merge_requests/1":   &gitlab.Client{},
+		"https://review.opendev.org/c/openstack/nova/+/12345": &gerrit.Client{},
+	} {
+		provider, err := r.ProviderFor(url)
+		require.NoError(t, err, url)
+		assert.IsType(t, want, provider, url)
+	}
+
+	_, err = NewRegistryFromConfig([]Config{{Type: "bitbucket", Host: "bitbucket.org"}})
+	assert.Error(t, err)
+
+	_, err = NewRegistryFromConfig([]Config{{Type: TypeGitLab}})
+	assert.Error(t, err)
+}
@@ -0,0 +1,295 @@
+package gerrit
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+
+	"github.com/jparrill/devtrackr/internal/models"
+)
+
+// magicPrefix is prepended by Gerrit to every JSON response to prevent XSSI
+const magicPrefix = ")]}'"
+
+// Client is a Gerrit REST API client for changes
+type Client struct {
+	baseURL    string
+	username   string
+	password   string
+	httpClient *http.Client
+}
+
+// NewClient creates a new Gerrit client. baseURL is the root of the Gerrit
+// instance, e.g. https://review.opendev.org. When username and password (an
+// HTTP password generated in Gerrit) are set, requests are authenticated.
+func NewClient(baseURL, username, password string) *Client {
+	return &Client{
+		baseURL:    strings.TrimSuffix(baseURL, "/"),
+		username:   username,
+		password:   password,
+		httpClient: &http.Client{},
+	}
+}
+
+// Name returns the name of the forge
+func (c *Client) Name() string {
+	return "gerrit"
+}
+
+// ParseChangeURL extracts the project and change number from a change URL.
+// Both https://review.example.com/c/project/+/12345 and the older
+// https://review.example.com/#/c/12345/ forms are accepted; the project is
+// empty for the latter.
+func ParseChangeURL(changeURL string) (string, int, error) {
+	parsedURL, err := url.Parse(changeURL)
+	if err != nil {
+		return "", 0, fmt.Errorf("invalid change URL: %w", err)
+	}
+
+	path := parsedURL.Path
+	if strings.HasPrefix(parsedURL.Fragment, "/c/") {
+		path = parsedURL.Fragment
+	}
+
+	var project, rest string
+	if _, after, found := strings.Cut(path, "/c/"); found {
+		if p, r, found := strings.Cut(after, "/+/"); found {
+			project, rest = p, r
+		} else {
+			rest = after
+		}
+	} else {
+		// Short links such as https://review.example.com/12345
+		rest = strings.TrimPrefix(path, "/")
+	}
+
+	number, err := strconv.Atoi(strings.SplitN(strings.Trim(rest, "/"), "/", 2)[0])
+	if err != nil {
+		return "", 0, fmt.Errorf("invalid Gerrit change URL: %s", changeURL)
+	}
+
+	return project, number, nil
+}
+
+// changeInfo represents the Gerrit ChangeInfo entity
+type changeInfo struct {
+	Project         string               `json:"project"`
+	Branch          string               `json:"branch"`
+	Subject         string               `json:"subject"`
+	Status          string               `json:"status"` // NEW, MERGED or ABANDONED
+	Number          int                  `json:"_number"`
+	WorkInProgress  bool                 `json:"work_in_progress"`
+	Hashtags        []string             `json:"hashtags"`
+	Labels          map[string]labelInfo `json:"labels"`
+	Mergeable       *bool                `json:"mergeable"`
+	Reviewers       map[string][]account `json:"reviewers"`
+	CurrentRevision string               `json:"current_revision"`
+	Revisions       map[string]struct {
+		Commit struct {
+			Message string `json:"message"`
+		} `json:"commit"`
+	} `json:"revisions"`
+}
+
+// account represents the Gerrit AccountInfo entity
+type account struct {
+	Username string `json:"username"`
+	Name     string `json:"name"`
+}
+
+// displayName returns the username of an account, falling back to its full name
+func (a account) displayName() string {
+	if a.Username != "" {
+		return a.Username
+	}
+	return a.Name
+}
+
+// vote represents a vote on a label, an ApprovalInfo in Gerrit terms
+type vote struct {
+	account
+	Value int `json:"value"`
+}
+
+// labelInfo represents the Gerrit LabelInfo entity with detailed votes
+type labelInfo struct {
+	Approved *struct{} `json:"approved"`
+	Rejected *struct{} `json:"rejected"`
+	All      []vote    `json:"all"`
+}
+
+// verifiedStatus maps the Verified label set by CI systems into a CIStatus
+// and returns the CI accounts voting against the change
+func verifiedStatus(change *changeInfo) (models.CIStatus, []string) {
+	verified, ok := change.Labels["Verified"]
+	if !ok {
+		return models.CIStatusUnknown, nil
+	}
+
+	var failing []string
+	for _, v := range verified.All {
+		if v.Value < 0 {
+			failing = append(failing, v.displayName())
+		}
+	}
+
+	switch {
+	case verified.Rejected != nil || len(failing) > 0:
+		if len(failing) == 0 {
+			failing = []string{"Verified"}
+		}
+		return models.CIStatusFailure, failing
+	case verified.Approved != nil:
+		return models.CIStatusSuccess, nil
+	default:
+		return models.CIStatusPending, nil
+	}
+}
+
+// reviewState returns the number of approving Code-Review votes and the
+// reviewers that have not voted on any label yet
+func reviewState(change *changeInfo) (int, []string) {
+	approvals := 0
+	for _, v := range change.Labels["Code-Review"].All {
+		if v.Value > 0 {
+			approvals++
+		}
+	}
+
+	voted := map[string]bool{}
+	for _, label := range change.Labels {
+		for _, v := range label.All {
+			if v.Value != 0 {
+				voted[v.displayName()] = true
+			}
+		}
+	}
+
+	var pending []string
+	for _, reviewer := range change.Reviewers["REVIEWER"] {
+		if name := reviewer.displayName(); name != "" && !voted[name] {
+			pending = append(pending, name)
+		}
+	}
+	return approvals, pending
+}
+
+// GetPullRequest retrieves a change and maps it into a PullRequest
+func (c *Client) GetPullRequest(ctx context.Context, changeURL string) (*models.PullRequest, error) {
+	project, number, err := ParseChangeURL(changeURL)
+	if err != nil {
+		return nil, err
+	}
+
+	changeID := strconv.Itoa(number)
+	if project != "" {
+		changeID = url.PathEscape(project) + "~" + changeID
+	}
+
+	var change changeInfo
+	path := "/changes/" + changeID + "?o=DETAILED_LABELS&o=CURRENT_REVISION&o=CURRENT_COMMIT"
+	if err := c.get(ctx, path, &change); err != nil {
+		return nil, fmt.Errorf("failed to fetch change: %w", err)
+	}
+
+	body := change.Subject
+	if revision, ok := change.Revisions[change.CurrentRevision]; ok && revision.Commit.Message != "" {
+		body = revision.Commit.Message
+	}
+
+	ciStatus, failingChecks := verifiedStatus(&change)
+	approvals, pendingReviewers := reviewState(&change)
+
+	return &models.PullRequest{
+		Number:            change.Number,
+		Repository:        change.Project,
+		Title:             change.Subject,
+		Body:              body,
+		Labels:            change.Hashtags,
+		URL:               changeURL,
+		Status:            changeStatus(&change),
+		TargetBranch:      change.Branch,
+		CIStatus:          ciStatus,
+		FailingChecks:     failingChecks,
+		RequiredReviewers: pendingReviewers,
+		Approvals:         approvals,
+		Mergeable:         change.Mergeable,
+	}, nil
+}
+
+// changeStatus maps the status of a change and its Code-Review votes into a PRStatus
+func changeStatus(change *changeInfo) models.PRStatus {
+	switch change.Status {
+	case "MERGED":
+		return models.PRStatusMerged
+	case "ABANDONED":
+		return models.PRStatusClosed
+	}
+
+	if change.WorkInProgress {
+		return models.PRStatusDraft
+	}
+
+	review, ok := change.Labels["Code-Review"]
+	if !ok {
+		return models.PRStatusOpen
+	}
+	if review.Approved != nil && review.Rejected == nil {
+		return models.PRStatusApproved
+	}
+	for _, vote := range review.All {
+		if vote.Value != 0 {
+			return models.PRStatusReview
+		}
+	}
+	if review.Rejected != nil {
+		return models.PRStatusReview
+	}
+	return models.PRStatusOpen
+}
+
+// get performs a GET request and decodes the JSON response into v, stripping
+// Gerrit's XSSI protection prefix
+func (c *Client) get(ctx context.Context, path string, v interface{}) error {
+	// Authenticated requests go through the /a/ endpoint
+	if c.username != "" {
+		path = "/a" + path
+	}
+
+	req, err := http.NewRequestWithContext(ctx, "GET", c.baseURL+path, nil)
+	if err != nil {
+		return fmt.Errorf("failed to create request: %w", err)
+	}
+
+	req.Header.Set("Accept", "application/json")
+	if c.username != "" {
+		req.SetBasicAuth(c.username, c.password)
+	}
+
+	resp, err := c.httpClient.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return fmt.Errorf("status code %d", resp.StatusCode)
+	}
+
+	data, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return fmt.Errorf("failed to read response: %w", err)
+	}
+	data = bytes.TrimPrefix(data, []byte(magicPrefix))
+
+	if err := json.Unmarshal(data, v); err != nil {
+		return fmt.Errorf("failed to parse response: %w", err)
+	}
+	return nil
+}
@@ -0,0 +1,170 @@
+package gerrit
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/jparrill/devtrackr/internal/models"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestParseChangeURL(t *testing.T) {
+	tests := []struct {
+		url     string
+		project string
+		number  int
+	}{
+		{url: "https://review.opendev.org/c/openstack/nova/+/12345", project: "openstack/nova", number: 12345},
+		{url: "https://review.opendev.org/c/openstack/nova/+/12345/3", project: "openstack/nova", number: 12345},
+		{url: "https://review.opendev.org/#/c/12345/", number: 12345},
+		{url: "https://review.opendev.org/c/12345", number: 12345},
+		{url: "https://review.opendev.org/12345", number: 12345},
+	}
+
+	for _, tt := range tests {
+		project, number, err := ParseChangeURL(tt.url)
+		require.NoError(t, err, tt.url)
+		assert.Equal(t, tt.project, project, tt.url)
+		assert.Equal(t, tt.number, number, tt.url)
+	}
+
+	_, _, err := ParseChangeURL("https://review.opendev.org/q/status:open")
+	assert.Error(t, err)
+}
+
+// fakeGerrit serves canned ChangeInfo responses, prefixed like a real Gerrit
+func fakeGerrit(t *testing.T, changes map[string]string) *httptest.Server {
+	return httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, "GET", r.Method)
+		assert.Contains(t, r.URL.Query()["o"], "DETAILED_LABELS")
+
+		body, ok := changes[r.URL.EscapedPath()]
+		if !ok {
+			http.NotFound(w, r)
+			return
+		}
+
+		w.Header().Set("Content-Type", "application/json")
+		w.Write([]byte(")]}'\n" + body))
+	}))
+}
+
+func TestClientGetPullRequest(t *testing.T) {
+	server := fakeGerrit(t, map[string]string{
+		"/changes/openstack%2Fnova~12345": `{
+			"project": "openstack/nova",
+			"branch": "stable/2024.1",
+			"subject": "Fix live migration",
+			"status": "NEW",
+			"_number": 12345,
+			"hashtags": ["backport"],
+			"current_revision": "abc123",
+			"revisions": {
+				"abc123": {"commit": {"message": "Fix live migration\n\nCloses-Bug: #1\n"}}
+			},
+			"mergeable": true,
+			"reviewers": {
+				"REVIEWER": [{"username": "alice"}, {"username": "bob"}, {"username": "carol"}, {"username": "zuul"}]
+			},
+			"labels": {
+				"Code-Review": {
+					"approved": {"_account_id": 1},
+					"all": [{"username": "alice", "value": 2}, {"username": "bob", "value": 1}, {"username": "carol", "value": 0}]
+				},
+				"Verified": {
+					"rejected": {"_account_id": 9},
+					"all": [{"username": "zuul", "value": -1}]
+				}
+			}
+		}`,
+		"/changes/678": `{
+			"project": "openstack/neutron",
+			"branch": "master",
+			"subject": "Drop old code",
+			"status": "ABANDONED",
+			"_number": 678
+		}`,
+	})
+	defer server.Close()
+
+	client := NewClient(server.URL, "", "")
+
+	pr, err := client.GetPullRequest(context.Background(), "https://review.opendev.org/c/openstack/nova/+/12345")
+	require.NoError(t, err)
+	assert.Equal(t, 12345, pr.Number)
+	assert.Equal(t, "openstack/nova", pr.Repository)
+	assert.Equal(t, "Fix live migration", pr.Title)
+	assert.Equal(t, "Fix live migration\n\nCloses-Bug: #1\n", pr.Body)
+	assert.Equal(t, []string{"backport"}, pr.Labels)
+	assert.Equal(t, "stable/2024.1", pr.TargetBranch)
+	assert.Equal(t, models.PRStatusApproved, pr.Status)
+	assert.Equal(t, "https://review.opendev.org/c/openstack/nova/+/12345", pr.URL)
+	assert.Equal(t, models.CIStatusFailure, pr.CIStatus)
+	assert.Equal(t, []string{"zuul"}, pr.FailingChecks)
+	assert.Equal(t, 2, pr.Approvals)
+	assert.Equal(t, []string{"carol"}, pr.RequiredReviewers)
+	require.NotNil(t, pr.Mergeable)
+	assert.True(t, *pr.Mergeable)
+
+	pr, err = client.GetPullRequest(context.Background(), "https://review.opendev.org/#/c/678/")
+	require.NoError(t, err)
+	assert.Equal(t, "openstack/neutron", pr.Repository)
+	assert.Equal(t, models.PRStatusClosed, pr.Status)
+
+	_, err = client.GetPullRequest(context.Background(), "https://review.opendev.org/c/openstack/nova/+/999")
+	assert.Error(t, err)
+}
+
+func TestClientAuthenticated(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		username, password, ok := r.BasicAuth()
+		assert.True(t, ok)
+		assert.Equal(t, "bot", username)
+		assert.Equal(t, "secret", password)
+		assert.Equal(t, "/a/changes/42", r.URL.Path)
+
+		w.Write([]byte(")]}'\n" + `{"project": "p", "branch": "main", "subject": "s", "status": "MERGED", "_number": 42}`))
+	}))
+	defer server.Close()
+
+	client := NewClient(server.URL, "bot", "secret")
+	pr, err := client.GetPullRequest(context.Background(), "https://review.example.com/42")
+	require.NoError(t, err)
+	assert.Equal(t, models.PRStatusMerged, pr.Status)
+}
+
+func TestChangeStatus(t *testing.T) {
+	votes := func(values ...int) labelInfo {
+		var label labelInfo
+		for _, v := range values {
+			label.All = append(label.All, vote{Value: v})
+		}
+		return label
+	}
+	rejected := votes(-2, 2)
+	rejected.Approved = &struct{}{}
+	rejected.Rejected = &struct{}{}
+
+	tests := []struct {
+		name   string
+		change changeInfo
+		want   models.PRStatus
+	}{
+		{name: "merged", change: changeInfo{Status: "MERGED"}, want: models.PRStatusMerged},
+		{name: "abandoned", change: changeInfo{Status: "ABANDONED"}, want: models.PRStatusClosed},
+		{name: "work in progress", change: changeInfo{Status: "NEW", WorkInProgress: true}, want: models.PRStatusDraft},
+		{name: "no votes", change: changeInfo{Status: "NEW", Labels: map[string]labelInfo{"Code-Review": votes(0)}}, want: models.PRStatusOpen},
+		{name: "reviewed", change: changeInfo{Status: "NEW", Labels: map[string]labelInfo{"Code-Review": votes(1, -1)}}, want: models.PRStatusReview},
+		{name: "vetoed", change: changeInfo{Status: "NEW", Labels: map[string]labelInfo{"Code-Review": rejected}}, want: models.PRStatusReview},
+		{name: "no code review label", change: changeInfo{Status: "NEW"}, want: models.PRStatusOpen},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.want, changeStatus(&tt.change))
+		})
+	}
+}
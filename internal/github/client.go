@@ -0,0 +1,300 @@
+package github
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+
+	"github.com/jparrill/devtrackr/internal/models"
+)
+
+// DefaultAPIURL is the REST API endpoint of github.com
+const DefaultAPIURL = "https://api.github.com"
+
+// Client is a GitHub REST API client for pull requests
+type Client struct {
+	apiURL     string
+	token      string
+	httpClient *http.Client
+}
+
+// NewClient creates a new GitHub client. apiURL is the REST API root, e.g.
+// https://api.github.com or https://github.example.com/api/v3. The token is
+// optional but unauthenticated requests are heavily rate limited.
+func NewClient(apiURL, token string) *Client {
+	if apiURL == "" {
+		apiURL = DefaultAPIURL
+	}
+	return &Client{
+		apiURL:     strings.TrimSuffix(apiURL, "/"),
+		token:      token,
+		httpClient: &http.Client{},
+	}
+}
+
+// Name returns the name of the forge
+func (c *Client) Name() string {
+	return "github"
+}
+
+// ParsePullRequestURL extracts the repository ("owner/repo") and number from
+// a pull request URL such as https://github.com/openshift/hypershift/pull/123
+func ParsePullRequestURL(prURL string) (string, int, error) {
+	parsedURL, err := url.Parse(prURL)
+	if err != nil {
+		return "", 0, fmt.Errorf("invalid pull request URL: %w", err)
+	}
+
+	parts := strings.Split(strings.Trim(parsedURL.Path, "/"), "/")
+	if len(parts) < 4 || parts[2] != "pull" {
+		return "", 0, fmt.Errorf("invalid GitHub pull request URL: %s", prURL)
+	}
+
+	number, err := strconv.Atoi(parts[3])
+	if err != nil {
+		return "", 0, fmt.Errorf("invalid pull request number in %s", prURL)
+	}
+
+	return parts[0] + "/" + parts[1], number, nil
+}
+
+// pullRequest represents the GitHub API pull request response
+type pullRequest struct {
+	Number int    `json:"number"`
+	Title  string `json:"title"`
+	Body   string `json:"body"`
+	State  string `json:"state"`
+	Draft  bool   `json:"draft"`
+	Merged bool   `json:"merged"`
+	URL    string `json:"html_url"`
+	Labels []struct {
+		Name string `json:"name"`
+	} `json:"labels"`
+	Base struct {
+		Ref string `json:"ref"`
+	} `json:"base"`
+	Head struct {
+		SHA string `json:"sha"`
+	} `json:"head"`
+	Mergeable          *bool `json:"mergeable"`
+	RequestedReviewers []struct {
+		Login string `json:"login"`
+	} `json:"requested_reviewers"`
+}
+
+// checkRuns represents the GitHub API check runs response
+type checkRuns struct {
+	CheckRuns []struct {
+		Name       string `json:"name"`
+		Status     string `json:"status"`     // queued, in_progress or completed
+		Conclusion string `json:"conclusion"` // set once completed
+	} `json:"check_runs"`
+}
+
+// combinedStatus represents the GitHub API combined commit status response,
+// used by CI systems such as Prow that report commit statuses
+type combinedStatus struct {
+	Statuses []struct {
+		Context string `json:"context"`
+		State   string `json:"state"` // error, failure, pending or success
+	} `json:"statuses"`
+}
+
+// review represents a GitHub pull request review
+type review struct {
+	State string `json:"state"`
+	User  struct {
+		Login string `json:"login"`
+	} `json:"user"`
+}
+
+// GetPullRequest retrieves a pull request and maps it into a PullRequest
+func (c *Client) GetPullRequest(ctx context.Context, prURL string) (*models.PullRequest, error) {
+	repository, number, err := ParsePullRequestURL(prURL)
+	if err != nil {
+		return nil, err
+	}
+
+	var pr pullRequest
+	if err := c.get(ctx, fmt.Sprintf("/repos/%s/pulls/%d", repository, number), &pr); err != nil {
+		return nil, fmt.Errorf("failed to fetch pull request: %w", err)
+	}
+
+	var reviews []review
+	if err := c.get(ctx, fmt.Sprintf("/repos/%s/pulls/%d/reviews?per_page=100", repository, number), &reviews); err != nil {
+		return nil, fmt.Errorf("failed to fetch pull request reviews: %w", err)
+	}
+
+	result := &models.PullRequest{
+		Number:       pr.Number,
+		Repository:   repository,
+		Title:        pr.Title,
+		Body:         pr.Body,
+		URL:          prURL,
+		Status:       pullRequestStatus(&pr, reviews),
+		TargetBranch: pr.Base.Ref,
+		Approvals:    approvals(reviews),
+		Mergeable:    pr.Mergeable,
+	}
+	for _, label := range pr.Labels {
+		result.Labels = append(result.Labels, label.Name)
+	}
+	for _, reviewer := range pr.RequestedReviewers {
+		result.RequiredReviewers = append(result.RequiredReviewers, reviewer.Login)
+	}
+
+	if pr.Head.SHA != "" {
+		if err := c.fillChecks(ctx, repository, pr.Head.SHA, result); err != nil {
+			return nil, fmt.Errorf("failed to fetch CI status: %w", err)
+		}
+	}
+
+	return result, nil
+}
+
+// fillChecks sets the CI rollup and failing checks of a pull request from
+// the check runs and commit statuses of its head commit
+func (c *Client) fillChecks(ctx context.Context, repository, sha string, pr *models.PullRequest) error {
+	var runs checkRuns
+	if err := c.get(ctx, fmt.Sprintf("/repos/%s/commits/%s/check-runs?per_page=100", repository, sha), &runs); err != nil {
+		return err
+	}
+
+	var combined combinedStatus
+	if err := c.get(ctx, fmt.Sprintf("/repos/%s/commits/%s/status?per_page=100", repository, sha), &combined); err != nil {
+		return err
+	}
+
+	var states []models.CIStatus
+	add := func(name string, state models.CIStatus) {
+		states = append(states, state)
+		if state == models.CIStatusFailure {
+			pr.FailingChecks = append(pr.FailingChecks, name)
+		}
+	}
+
+	for _, run := range runs.CheckRuns {
+		add(run.Name, checkRunStatus(run.Status, run.Conclusion))
+	}
+	for _, status := range combined.Statuses {
+		add(status.Context, commitStatus(status.State))
+	}
+
+	pr.CIStatus = models.RollupCIStatus(states)
+	return nil
+}
+
+// checkRunStatus maps the status and conclusion of a check run into a CIStatus
+func checkRunStatus(status, conclusion string) models.CIStatus {
+	if status != "completed" {
+		return models.CIStatusPending
+	}
+	switch conclusion {
+	case "success", "neutral", "skipped":
+		return models.CIStatusSuccess
+	default:
+		return models.CIStatusFailure
+	}
+}
+
+// commitStatus maps the state of a commit status into a CIStatus
+func commitStatus(state string) models.CIStatus {
+	switch state {
+	case "success":
+		return models.CIStatusSuccess
+	case "pending":
+		return models.CIStatusPending
+	default:
+		return models.CIStatusFailure
+	}
+}
+
+// latestReviews returns the state of the latest review of each reviewer.
+// Comments do not change the state of a previous review.
+func latestReviews(reviews []review) map[string]string {
+	latest := map[string]string{}
+	for _, r := range reviews {
+		if r.State == "COMMENTED" || r.State == "PENDING" {
+			continue
+		}
+		latest[r.User.Login] = r.State
+	}
+	return latest
+}
+
+// approvals counts the reviewers whose latest review approves the pull request
+func approvals(reviews []review) int {
+	count := 0
+	for _, state := range latestReviews(reviews) {
+		if state == "APPROVED" {
+			count++
+		}
+	}
+	return count
+}
+
+// pullRequestStatus maps the state of a pull request and its reviews into a PRStatus
+func pullRequestStatus(pr *pullRequest, reviews []review) models.PRStatus {
+	switch {
+	case pr.Merged:
+		return models.PRStatusMerged
+	case pr.State == "closed":
+		return models.PRStatusClosed
+	case pr.Draft:
+		return models.PRStatusDraft
+	}
+
+	latest := latestReviews(reviews)
+	if len(latest) == 0 {
+		if len(reviews) > 0 {
+			return models.PRStatusReview
+		}
+		return models.PRStatusOpen
+	}
+
+	approved := false
+	for _, state := range latest {
+		switch state {
+		case "CHANGES_REQUESTED":
+			return models.PRStatusReview
+		case "APPROVED":
+			approved = true
+		}
+	}
+	if approved {
+		return models.PRStatusApproved
+	}
+	return models.PRStatusReview
+}
+
+// get performs an authenticated GET request and decodes the JSON response into v
+func (c *Client) get(ctx context.Context, path string, v interface{}) error {
+	req, err := http.NewRequestWithContext(ctx, "GET", c.apiURL+path, nil)
+	if err != nil {
+		return fmt.Errorf("failed to create request: %w", err)
+	}
+
+	req.Header.Set("Accept", "application/vnd.github+json")
+	if c.token != "" {
+		req.Header.Set("Authorization", "Bearer "+c.token)
+	}
+
+	resp, err := c.httpClient.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return fmt.Errorf("status code %d", resp.StatusCode)
+	}
+
+	if err := json.NewDecoder(resp.Body).Decode(v); err != nil {
+		return fmt.Errorf("failed to parse response: %w", err)
+	}
+	return nil
+}
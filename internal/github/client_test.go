@@ -0,0 +1,124 @@
+package github
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/jparrill/devtrackr/internal/models"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestParsePullRequestURL(t *testing.T) {
+	repository, number, err := ParsePullRequestURL("https://github.com/openshift/hypershift/pull/123")
+	require.NoError(t, err)
+	assert.Equal(t, "openshift/hypershift", repository)
+	assert.Equal(t, 123, number)
+
+	repository, number, err = ParsePullRequestURL("https://github.com/openshift/hypershift/pull/45/files")
+	require.NoError(t, err)
+	assert.Equal(t, "openshift/hypershift", repository)
+	assert.Equal(t, 45, number)
+
+	_, _, err = ParsePullRequestURL("https://github.com/openshift/hypershift/issues/123")
+	assert.Error(t, err)
+
+	_, _, err = ParsePullRequestURL("https://github.com/openshift/hypershift/pull/abc")
+	assert.Error(t, err)
+}
+
+func TestClientGetPullRequest(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, "Bearer secret", r.Header.Get("Authorization"))
+
+		w.Header().Set("Content-Type", "application/json")
+		switch r.URL.Path {
+		case "/repos/openshift/hypershift/pulls/123":
+			w.Write([]byte(`{
+				"number": 123,
+				"title": "OCPBUGS-1234: Fix node pool scaling",
+				"body": "Fixes the scaling",
+				"state": "open",
+				"draft": false,
+				"merged": false,
+				"labels": [{"name": "lgtm"}, {"name": "approved"}],
+				"base": {"ref": "release-4.16"},
+				"head": {"sha": "abc123"},
+				"mergeable": false,
+				"requested_reviewers": [{"login": "carol"}]
+			}`))
+		case "/repos/openshift/hypershift/commits/abc123/check-runs":
+			w.Write([]byte(`{"check_runs": [
+				{"name": "verify", "status": "completed", "conclusion": "success"},
+				{"name": "unit", "status": "completed", "conclusion": "failure"}
+			]}`))
+		case "/repos/openshift/hypershift/commits/abc123/status":
+			w.Write([]byte(`{"statuses": [
+				{"context": "ci/prow/e2e-aws", "state": "pending"},
+				{"context": "ci/prow/images", "state": "error"}
+			]}`))
+		case "/repos/openshift/hypershift/pulls/123/reviews":
+			w.Write([]byte(`[
+				{"state": "CHANGES_REQUESTED", "user": {"login": "alice"}},
+				{"state": "COMMENTED", "user": {"login": "bob"}},
+				{"state": "APPROVED", "user": {"login": "alice"}}
+			]`))
+		default:
+			w.WriteHeader(http.StatusNotFound)
+		}
+	}))
+	defer server.Close()
+
+	client := NewClient(server.URL, "secret")
+	pr, err := client.GetPullRequest(context.Background(), "https://github.com/openshift/hypershift/pull/123")
+	require.NoError(t, err)
+
+	assert.Equal(t, 123, pr.Number)
+	assert.Equal(t, "openshift/hypershift", pr.Repository)
+	assert.Equal(t, "OCPBUGS-1234: Fix node pool scaling", pr.Title)
+	assert.Equal(t, "Fixes the scaling", pr.Body)
+	assert.Equal(t, []string{"lgtm", "approved"}, pr.Labels)
+	assert.Equal(t, "release-4.16", pr.TargetBranch)
+	assert.Equal(t, models.PRStatusApproved, pr.Status)
+	assert.Equal(t, "https://github.com/openshift/hypershift/pull/123", pr.URL)
+	assert.Equal(t, models.CIStatusFailure, pr.CIStatus)
+	assert.Equal(t, []string{"unit", "ci/prow/images"}, pr.FailingChecks)
+	assert.Equal(t, []string{"carol"}, pr.RequiredReviewers)
+	assert.Equal(t, 1, pr.Approvals)
+	require.NotNil(t, pr.Mergeable)
+	assert.False(t, *pr.Mergeable)
+
+	_, err = client.GetPullRequest(context.Background(), "https://github.com/openshift/hypershift/pull/404")
+	assert.Error(t, err)
+}
+
+func TestPullRequestStatus(t *testing.T) {
+	tests := []struct {
+		name    string
+		pr      pullRequest
+		reviews []string
+		want    models.PRStatus
+	}{
+		{name: "merged", pr: pullRequest{State: "closed", Merged: true}, want: models.PRStatusMerged},
+		{name: "closed", pr: pullRequest{State: "closed"}, want: models.PRStatusClosed},
+		{name: "draft", pr: pullRequest{State: "open", Draft: true}, want: models.PRStatusDraft},
+		{name: "no reviews", pr: pullRequest{State: "open"}, want: models.PRStatusOpen},
+		{name: "comments only", pr: pullRequest{State: "open"}, reviews: []string{"COMMENTED"}, want: models.PRStatusReview},
+		{name: "approved", pr: pullRequest{State: "open"}, reviews: []string{"APPROVED"}, want: models.PRStatusApproved},
+		{name: "changes requested", pr: pullRequest{State: "open"}, reviews: []string{"CHANGES_REQUESTED"}, want: models.PRStatusReview},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var reviews []review
+			for i, state := range tt.reviews {
+				r := review{State: state}
+				r.User.Login = string(rune('a' + i))
+				reviews = append(reviews, r)
+			}
+			assert.Equal(t, tt.want, pullRequestStatus(&tt.pr, reviews))
+		})
+	}
+}
@@ -0,0 +1,141 @@
+package github
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"strings"
+
+	"github.com/jparrill/devtrackr/internal/models"
+)
+
+// Webhook event types handled by DevTrackr
+const (
+	EventPing              = "ping"
+	EventPullRequest       = "pull_request"
+	EventPullRequestReview = "pull_request_review"
+	EventCheckSuite        = "check_suite"
+	EventIssueComment      = "issue_comment"
+)
+
+// ValidateSignature checks the X-Hub-Signature-256 header of a webhook
+// delivery against the HMAC-SHA256 of its body
+func ValidateSignature(secret string, body []byte, signature string) error {
+	if secret == "" {
+		return fmt.Errorf("webhook secret is not configured")
+	}
+
+	hexDigest, found := strings.CutPrefix(signature, "sha256=")
+	if !found {
+		return fmt.Errorf("missing or malformed signature")
+	}
+
+	expected, err := hex.DecodeString(hexDigest)
+	if err != nil {
+		return fmt.Errorf("malformed signature: %w", err)
+	}
+
+	mac := hmac.New(sha256.New, []byte(secret))
+	mac.Write(body)
+	if !hmac.Equal(mac.Sum(nil), expected) {
+		return fmt.Errorf("signature mismatch")
+	}
+	return nil
+}
+
+// WebhookEvent is the part of a webhook delivery relevant to tracking
+type WebhookEvent struct {
+	Type   string
+	Action string
+	// Repository is the full name of the repository, e.g. openshift/hypershift
+	Repository string
+	// PullRequests are the numbers of the pull requests affected by the event
+	PullRequests []int
+	// PullRequest holds the state carried by pull_request events. Its status
+	// is only set for merged, closed and draft pull requests, since the
+	// payload does not include reviews.
+	PullRequest *models.PullRequest
+}
+
+// webhookPayload holds the fields of the supported events
+type webhookPayload struct {
+	Action     string `json:"action"`
+	Repository struct {
+		FullName string `json:"full_name"`
+		HTMLURL  string `json:"html_url"`
+	} `json:"repository"`
+	PullRequest *pullRequest `json:"pull_request"`
+	CheckSuite  *struct {
+		PullRequests []struct {
+			Number int `json:"number"`
+		} `json:"pull_requests"`
+	} `json:"check_suite"`
+	Issue *struct {
+		Number      int       `json:"number"`
+		PullRequest *struct{} `json:"pull_request"`
+	} `json:"issue"`
+}
+
+// ParseWebhook extracts the affected pull requests from a webhook delivery.
+// Events of other types, and comments on plain issues, affect none.
+func ParseWebhook(eventType string, body []byte) (*WebhookEvent, error) {
+	event := &WebhookEvent{Type: eventType}
+	if eventType == EventPing {
+		return event, nil
+	}
+
+	var payload webhookPayload
+	if err := json.Unmarshal(body, &payload); err != nil {
+		return nil, fmt.Errorf("failed to parse %s payload: %w", eventType, err)
+	}
+	event.Action = payload.Action
+	event.Repository = payload.Repository.FullName
+
+	switch eventType {
+	case EventPullRequest, EventPullRequestReview:
+		if payload.PullRequest == nil {
+			return nil, fmt.Errorf("%s payload has no pull request", eventType)
+		}
+		event.PullRequests = []int{payload.PullRequest.Number}
+		if eventType == EventPullRequest {
+			event.PullRequest = webhookPullRequest(event.Repository, payload.PullRequest)
+		}
+	case EventCheckSuite:
+		if payload.CheckSuite != nil {
+			for _, pr := range payload.CheckSuite.PullRequests {
+				event.PullRequests = append(event.PullRequests, pr.Number)
+			}
+		}
+	case EventIssueComment:
+		if payload.Issue != nil && payload.Issue.PullRequest != nil {
+			event.PullRequests = []int{payload.Issue.Number}
+		}
+	}
+
+	return event, nil
+}
+
+// webhookPullRequest maps the pull request of a pull_request event
+func webhookPullRequest(repository string, pr *pullRequest) *models.PullRequest {
+	result := &models.PullRequest{
+		Number:       pr.Number,
+		Repository:   repository,
+		Title:        pr.Title,
+		Body:         pr.Body,
+		URL:          pr.URL,
+		TargetBranch: pr.Base.Ref,
+		Labels:       []string{},
+	}
+	for _, label := range pr.Labels {
+		result.Labels = append(result.Labels, label.Name)
+	}
+
+	// Without reviews an open pull request cannot be told apart from an approved one
+	if status := pullRequestStatus(pr, nil); status != models.PRStatusOpen {
+		result.Status = status
+	}
+
+	return result
+}
@@ -0,0 +1,110 @@
+package github
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"testing"
+
+	"github.com/jparrill/devtrackr/internal/models"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func sign(secret string, body []byte) string {
+	mac := hmac.New(sha256.New, []byte(secret))
+	mac.Write(body)
+	return "sha256=" + hex.EncodeToString(mac.Sum(nil))
+}
+
+func TestValidateSignature(t *testing.T) {
+	body := []byte(`{"action": "opened"}`)
+
+	assert.NoError(t, ValidateSignature("secret", body, sign("secret", body)))
+	assert.Error(t, ValidateSignature("secret", body, sign("other", body)))
+	assert.Error(t, ValidateSignature("secret", []byte(`{"action": "closed"}`), sign("secret", body)))
+	assert.Error(t, ValidateSignature("secret", body, ""))
+	assert.Error(t, ValidateSignature("secret", body, "sha256=zz"))
+	assert.Error(t, ValidateSignature("", body, sign("", body)), "an empty secret must not validate")
+}
+
+func TestParseWebhookPullRequest(t *testing.T) {
+	event, err := ParseWebhook(EventPullRequest, []byte(`{
+		"action": "closed",
+		"repository": {"full_name": "openshift/hypershift"},
+		"pull_request": {
+			"number": 123,
+			"title": "OCPBUGS-1234: Fix",
+			"body": "Body",
+			"state": "closed",
+			"merged": true,
+			"html_url": "https://github.com/openshift/hypershift/pull/123",
+			"labels": [{"name": "lgtm"}],
+			"base": {"ref": "main"}
+		}
+	}`))
+	require.NoError(t, err)
+	assert.Equal(t, "closed", event.Action)
+	assert.Equal(t, "openshift/hypershift", event.Repository)
+	assert.Equal(t, []int{123}, event.PullRequests)
+	require.NotNil(t, event.PullRequest)
+	assert.Equal(t, models.PRStatusMerged, event.PullRequest.Status)
+	assert.Equal(t, "https://github.com/openshift/hypershift/pull/123", event.PullRequest.URL)
+	assert.Equal(t, []string{"lgtm"}, event.PullRequest.Labels)
+	assert.Equal(t, "main", event.PullRequest.TargetBranch)
+
+	// Open pull requests carry no status, the payload has no reviews
+	event, err = ParseWebhook(EventPullRequest, []byte(`{
+		"action": "edited",
+		"repository": {"full_name": "openshift/hypershift"},
+		"pull_request": {"number": 124, "state": "open"}
+	}`))
+	require.NoError(t, err)
+	assert.Empty(t, event.PullRequest.Status)
+}
+
+func TestParseWebhookOtherEvents(t *testing.T) {
+	event, err := ParseWebhook(EventPullRequestReview, []byte(`{
+		"action": "submitted",
+		"repository": {"full_name": "o/r"},
+		"pull_request": {"number": 5}
+	}`))
+	require.NoError(t, err)
+	assert.Equal(t, []int{5}, event.PullRequests)
+	assert.Nil(t, event.PullRequest)
+
+	event, err = ParseWebhook(EventCheckSuite, []byte(`{
+		"action": "completed",
+		"repository": {"full_name": "o/r"},
+		"check_suite": {"pull_requests": [{"number": 5}, {"number": 6}]}
+	}`))
+	require.NoError(t, err)
+	assert.Equal(t, []int{5, 6}, event.PullRequests)
+
+	event, err = ParseWebhook(EventIssueComment, []byte(`{
+		"action": "created",
+		"repository": {"full_name": "o/r"},
+		"issue": {"number": 7, "pull_request": {"url": "https://api.github.com/repos/o/r/pulls/7"}}
+	}`))
+	require.NoError(t, err)
+	assert.Equal(t, []int{7}, event.PullRequests)
+
+	// Comments on plain issues affect no pull request
+	event, err = ParseWebhook(EventIssueComment, []byte(`{
+		"action": "created",
+		"repository": {"full_name": "o/r"},
+		"issue": {"number": 8}
+	}`))
+	require.NoError(t, err)
+	assert.Empty(t, event.PullRequests)
+
+	event, err = ParseWebhook(EventPing, []byte(`{"zen": "Keep it logically awesome."}`))
+	require.NoError(t, err)
+	assert.Empty(t, event.PullRequests)
+
+	_, err = ParseWebhook(EventPullRequest, []byte(`{"action": "opened"}`))
+	assert.Error(t, err)
+
+	_, err = ParseWebhook(EventPullRequest, []byte(`not json`))
+	assert.Error(t, err)
+}
@@ -0,0 +1,267 @@
+package gitlab
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/url"
+	"slices"
+	"strconv"
+	"strings"
+
+	"github.com/jparrill/devtrackr/internal/models"
+)
+
+// Client is a GitLab REST API client for merge requests
+type Client struct {
+	baseURL    string
+	token      string
+	httpClient *http.Client
+}
+
+// NewClient creates a new GitLab client. baseURL is the root of the GitLab
+// instance, e.g. https://gitlab.com. The token is sent as a private token.
+func NewClient(baseURL, token string) *Client {
+	return &Client{
+		baseURL:    strings.TrimSuffix(baseURL, "/"),
+		token:      token,
+		httpClient: &http.Client{},
+	}
+}
+
+// Name returns the name of the forge
+func (c *Client) Name() string {
+	return "gitlab"
+}
+
+// ParseMergeRequestURL extracts the project path and merge request IID from
+// a merge request URL such as
+// https://gitlab.com/group/subgroup/project/-/merge_requests/42
+func ParseMergeRequestURL(mrURL string) (string, int, error) {
+	parsedURL, err := url.Parse(mrURL)
+	if err != nil {
+		return "", 0, fmt.Errorf("invalid merge request URL: %w", err)
+	}
+
+	path := strings.Trim(parsedURL.Path, "/")
+	project, rest, found := strings.Cut(path, "/-/merge_requests/")
+	if !found {
+		// Older GitLab versions do not use the "/-/" separator
+		project, rest, found = strings.Cut(path, "/merge_requests/")
+	}
+	if !found || project == "" {
+		return "", 0, fmt.Errorf("invalid GitLab merge request URL: %s", mrURL)
+	}
+
+	iid, err := strconv.Atoi(strings.SplitN(rest, "/", 2)[0])
+	if err != nil {
+		return "", 0, fmt.Errorf("invalid merge request IID in %s", mrURL)
+	}
+
+	return project, iid, nil
+}
+
+// MergeRequest holds the state of a merge request relevant to tracking
+type MergeRequest struct {
+	IID            int
+	Project        string
+	Title          string
+	Description    string
+	State          string // opened, closed, locked or merged
+	Draft          bool
+	Labels         []string
+	TargetBranch   string
+	Approved       bool
+	ApprovedBy     []string
+	Reviewers      []string
+	HasConflicts   bool
+	PipelineStatus string   // status of the head pipeline, e.g. success, failed, running
+	FailedJobs     []string // names of the failed jobs of the head pipeline
+}
+
+// mergeRequest represents the GitLab API merge request response
+type mergeRequest struct {
+	IID          int      `json:"iid"`
+	Title        string   `json:"title"`
+	Description  string   `json:"description"`
+	State        string   `json:"state"`
+	Draft        bool     `json:"draft"`
+	WorkInProg   bool     `json:"work_in_progress"`
+	Labels       []string `json:"labels"`
+	TargetBranch string   `json:"target_branch"`
+	HasConflicts bool     `json:"has_conflicts"`
+	Reviewers    []struct {
+		Username string `json:"username"`
+	} `json:"reviewers"`
+	HeadPipeline *struct {
+		ID     int    `json:"id"`
+		Status string `json:"status"`
+	} `json:"head_pipeline"`
+}
+
+// job represents a GitLab pipeline job
+type job struct {
+	Name string `json:"name"`
+}
+
+// approvals represents the GitLab API merge request approvals response
+type approvals struct {
+	Approved   bool `json:"approved"`
+	ApprovedBy []struct {
+		User struct {
+			Username string `json:"username"`
+		} `json:"user"`
+	} `json:"approved_by"`
+}
+
+// GetMergeRequest retrieves a merge request with its approvals and pipeline status
+func (c *Client) GetMergeRequest(ctx context.Context, mrURL string) (*MergeRequest, error) {
+	project, iid, err := ParseMergeRequestURL(mrURL)
+	if err != nil {
+		return nil, err
+	}
+
+	path := fmt.Sprintf("/api/v4/projects/%s/merge_requests/%d", url.PathEscape(project), iid)
+
+	var mr mergeRequest
+	if err := c.get(ctx, path, &mr); err != nil {
+		return nil, fmt.Errorf("failed to fetch merge request: %w", err)
+	}
+
+	var approval approvals
+	if err := c.get(ctx, path+"/approvals", &approval); err != nil {
+		return nil, fmt.Errorf("failed to fetch merge request approvals: %w", err)
+	}
+
+	result := &MergeRequest{
+		IID:          mr.IID,
+		Project:      project,
+		Title:        mr.Title,
+		Description:  mr.Description,
+		State:        mr.State,
+		Draft:        mr.Draft || mr.WorkInProg,
+		Labels:       mr.Labels,
+		TargetBranch: mr.TargetBranch,
+		Approved:     approval.Approved && len(approval.ApprovedBy) > 0,
+	}
+	for _, a := range approval.ApprovedBy {
+		result.ApprovedBy = append(result.ApprovedBy, a.User.Username)
+	}
+	for _, r := range mr.Reviewers {
+		result.Reviewers = append(result.Reviewers, r.Username)
+	}
+	result.HasConflicts = mr.HasConflicts
+
+	if mr.HeadPipeline != nil {
+		result.PipelineStatus = mr.HeadPipeline.Status
+		if result.PipelineStatus == "failed" {
+			var jobs []job
+			jobsPath := fmt.Sprintf("/api/v4/projects/%s/pipelines/%d/jobs?scope[]=failed&per_page=100", url.PathEscape(project), mr.HeadPipeline.ID)
+			if err := c.get(ctx, jobsPath, &jobs); err != nil {
+				return nil, fmt.Errorf("failed to fetch pipeline jobs: %w", err)
+			}
+			for _, j := range jobs {
+				result.FailedJobs = append(result.FailedJobs, j.Name)
+			}
+		}
+	}
+
+	return result, nil
+}
+
+// GetPullRequest retrieves a merge request and maps it into a PullRequest
+func (c *Client) GetPullRequest(ctx context.Context, mrURL string) (*models.PullRequest, error) {
+	mr, err := c.GetMergeRequest(ctx, mrURL)
+	if err != nil {
+		return nil, err
+	}
+
+	mergeable := !mr.HasConflicts
+	return &models.PullRequest{
+		Number:            mr.IID,
+		Repository:        mr.Project,
+		Title:             mr.Title,
+		Body:              mr.Description,
+		Labels:            mr.Labels,
+		URL:               mrURL,
+		Status:            mr.Status(),
+		TargetBranch:      mr.TargetBranch,
+		CIStatus:          mr.CIStatus(),
+		FailingChecks:     mr.FailedJobs,
+		RequiredReviewers: mr.PendingReviewers(),
+		Approvals:         len(mr.ApprovedBy),
+		Mergeable:         &mergeable,
+	}, nil
+}
+
+// CIStatus maps the status of the head pipeline into a CIStatus
+func (mr *MergeRequest) CIStatus() models.CIStatus {
+	switch mr.PipelineStatus {
+	case "success":
+		return models.CIStatusSuccess
+	case "failed", "canceled":
+		return models.CIStatusFailure
+	case "created", "waiting_for_resource", "preparing", "pending", "running", "scheduled":
+		return models.CIStatusPending
+	default:
+		return models.CIStatusUnknown
+	}
+}
+
+// PendingReviewers returns the assigned reviewers that have not approved yet
+func (mr *MergeRequest) PendingReviewers() []string {
+	var pending []string
+	for _, reviewer := range mr.Reviewers {
+		if !slices.Contains(mr.ApprovedBy, reviewer) {
+			pending = append(pending, reviewer)
+		}
+	}
+	return pending
+}
+
+// Status maps the merge request state and approvals into a PRStatus
+func (mr *MergeRequest) Status() models.PRStatus {
+	switch {
+	case mr.State == "merged":
+		return models.PRStatusMerged
+	case mr.State == "closed" || mr.State == "locked":
+		return models.PRStatusClosed
+	case mr.Draft:
+		return models.PRStatusDraft
+	case mr.Approved:
+		return models.PRStatusApproved
+	case len(mr.ApprovedBy) > 0:
+		return models.PRStatusReview
+	default:
+		return models.PRStatusOpen
+	}
+}
+
+// get performs an authenticated GET request and decodes the JSON response into v
+func (c *Client) get(ctx context.Context, path string, v interface{}) error {
+	req, err := http.NewRequestWithContext(ctx, "GET", c.baseURL+path, nil)
+	if err != nil {
+		return fmt.Errorf("failed to create request: %w", err)
+	}
+
+	req.Header.Set("Accept", "application/json")
+	if c.token != "" {
+		req.Header.Set("PRIVATE-TOKEN", c.token)
+	}
+
+	resp, err := c.httpClient.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return fmt.Errorf("status code %d", resp.StatusCode)
+	}
+
+	if err := json.NewDecoder(resp.Body).Decode(v); err != nil {
+		return fmt.Errorf("failed to parse response: %w", err)
+	}
+	return nil
+}
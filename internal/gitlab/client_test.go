@@ -0,0 +1,115 @@
+package gitlab
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/jparrill/devtrackr/internal/models"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestParseMergeRequestURL(t *testing.T) {
+	project, iid, err := ParseMergeRequestURL("https://gitlab.com/group/subgroup/project/-/merge_requests/42")
+	require.NoError(t, err)
+	assert.Equal(t, "group/subgroup/project", project)
+	assert.Equal(t, 42, iid)
+
+	project, iid, err = ParseMergeRequestURL("https://gitlab.example.com/team/tool/merge_requests/7/diffs")
+	require.NoError(t, err)
+	assert.Equal(t, "team/tool", project)
+	assert.Equal(t, 7, iid)
+
+	_, _, err = ParseMergeRequestURL("https://gitlab.com/group/project/-/issues/42")
+	assert.Error(t, err)
+
+	_, _, err = ParseMergeRequestURL("https://gitlab.com/group/project/-/merge_requests/abc")
+	assert.Error(t, err)
+}
+
+func TestClientGetMergeRequest(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, "secret", r.Header.Get("PRIVATE-TOKEN"))
+
+		w.Header().Set("Content-Type", "application/json")
+		switch r.URL.EscapedPath() {
+		case "/api/v4/projects/group%2Fsubgroup%2Fproject/merge_requests/42":
+			w.Write([]byte(`{
+				"iid": 42,
+				"title": "OCPBUGS-1234: Fix node pool scaling",
+				"description": "Fixes the scaling",
+				"state": "opened",
+				"draft": false,
+				"labels": ["backport"],
+				"target_branch": "release-4.16",
+				"has_conflicts": true,
+				"reviewers": [{"username": "alice"}, {"username": "bob"}],
+				"head_pipeline": {"id": 99, "status": "failed"}
+			}`))
+		case "/api/v4/projects/group%2Fsubgroup%2Fproject/pipelines/99/jobs":
+			assert.Equal(t, "failed", r.URL.Query().Get("scope[]"))
+			w.Write([]byte(`[{"name": "unit"}, {"name": "lint"}]`))
+		case "/api/v4/projects/group%2Fsubgroup%2Fproject/merge_requests/42/approvals":
+			w.Write([]byte(`{
+				"approved": true,
+				"approved_by": [{"user": {"username": "alice"}}]
+			}`))
+		default:
+			w.WriteHeader(http.StatusNotFound)
+		}
+	}))
+	defer server.Close()
+
+	client := NewClient(server.URL, "secret")
+	mrURL := "https://gitlab.com/group/subgroup/project/-/merge_requests/42"
+
+	mr, err := client.GetMergeRequest(context.Background(), mrURL)
+	require.NoError(t, err)
+	assert.Equal(t, "failed", mr.PipelineStatus)
+	assert.Equal(t, []string{"alice"}, mr.ApprovedBy)
+	assert.True(t, mr.Approved)
+
+	pr, err := client.GetPullRequest(context.Background(), mrURL)
+	require.NoError(t, err)
+	assert.Equal(t, 42, pr.Number)
+	assert.Equal(t, "group/subgroup/project", pr.Repository)
+	assert.Equal(t, "OCPBUGS-1234: Fix node pool scaling", pr.Title)
+	assert.Equal(t, "Fixes the scaling", pr.Body)
+	assert.Equal(t, []string{"backport"}, pr.Labels)
+	assert.Equal(t, "release-4.16", pr.TargetBranch)
+	assert.Equal(t, models.PRStatusApproved, pr.Status)
+	assert.Equal(t, mrURL, pr.URL)
+	assert.Equal(t, models.CIStatusFailure, pr.CIStatus)
+	assert.Equal(t, []string{"unit", "lint"}, pr.FailingChecks)
+	assert.Equal(t, []string{"bob"}, pr.RequiredReviewers)
+	assert.Equal(t, 1, pr.Approvals)
+	require.NotNil(t, pr.Mergeable)
+	assert.False(t, *pr.Mergeable)
+
+	_, err = client.GetPullRequest(context.Background(), "https://gitlab.com/group/project/-/merge_requests/1")
+	assert.Error(t, err)
+}
+
+func TestMergeRequestStatus(t *testing.T) {
+	tests := []struct {
+		name string
+		mr   MergeRequest
+		want models.PRStatus
+	}{
+		{name: "merged", mr: MergeRequest{State: "merged"}, want: models.PRStatusMerged},
+		{name: "closed", mr: MergeRequest{State: "closed"}, want: models.PRStatusClosed},
+		{name: "locked", mr: MergeRequest{State: "locked"}, want: models.PRStatusClosed},
+		{name: "draft", mr: MergeRequest{State: "opened", Draft: true}, want: models.PRStatusDraft},
+		{name: "approved", mr: MergeRequest{State: "opened", Approved: true, ApprovedBy: []string{"alice"}}, want: models.PRStatusApproved},
+		{name: "partially approved", mr: MergeRequest{State: "opened", ApprovedBy: []string{"alice"}}, want: models.PRStatusReview},
+		{name: "open", mr: MergeRequest{State: "opened"}, want: models.PRStatusOpen},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.want, tt.mr.Status())
+		})
+	}
+}
@@ -0,0 +1,266 @@
+package gitscan
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"os/exec"
+	"path"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Finding is a commit whose message references a tracked issue
+type Finding struct {
+	IssueKey    string    `json:"issue_key"`
+	Commit      string    `json:"commit"`
+	Subject     string    `json:"subject"`
+	CommittedAt time.Time `json:"committed_at"`
+	Branches    []string  `json:"branches"` // Scanned branches containing the commit
+	Tags        []string  `json:"tags"`     // Tags containing the commit
+}
+
+// Options restricts what a scan looks at
+type Options struct {
+	// Branches are glob patterns such as "main" or "release-*" matched against
+	// local and remote branch names. Empty scans every branch.
+	Branches []string
+	// Since skips commits older than the given time when set
+	Since time.Time
+}
+
+// Scanner walks the history of local git repositories by shelling out to git
+type Scanner struct {
+	gitPath string
+}
+
+// NewScanner creates a scanner using the git binary found in PATH
+func NewScanner() *Scanner {
+	return &Scanner{gitPath: "git"}
+}
+
+// Scan finds the commits of repoPath whose messages mention any of the given
+// issue keys and records which scanned branches and which tags contain them
+func (s *Scanner) Scan(ctx context.Context, repoPath string, keys []string, opts Options) ([]Finding, error) {
+	if len(keys) == 0 {
+		return nil, nil
+	}
+
+	if _, err := s.git(ctx, repoPath, "rev-parse", "--git-dir"); err != nil {
+		return nil, fmt.Errorf("not a git repository: %s: %w", repoPath, err)
+	}
+
+	keyRe := keyPattern(keys)
+
+	refs, err := s.branches(ctx, repoPath, opts.Branches)
+	if err != nil {
+		return nil, err
+	}
+
+	findings := map[string]*Finding{} // keyed by commit and issue key
+	branchSets := map[string]map[string]bool{}
+	for _, ref := range refs {
+		commits, err := s.log(ctx, repoPath, ref.ref, opts.Since)
+		if err != nil {
+			return nil, err
+		}
+
+		for _, c := range commits {
+			for _, key := range uniqueMatches(keyRe, c.subject+"\n"+c.body) {
+				id := c.sha + " " + key
+				f, ok := findings[id]
+				if !ok {
+					f = &Finding{
+						IssueKey:    key,
+						Commit:      c.sha,
+						Subject:     c.subject,
+						CommittedAt: c.committedAt,
+					}
+					findings[id] = f
+					branchSets[id] = map[string]bool{}
+				}
+				if !branchSets[id][ref.name] {
+					branchSets[id][ref.name] = true
+					f.Branches = append(f.Branches, ref.name)
+				}
+			}
+		}
+	}
+
+	// Tags are resolved once per commit
+	tagsByCommit := map[string][]string{}
+	result := make([]Finding, 0, len(findings))
+	for _, f := range findings {
+		tags, ok := tagsByCommit[f.Commit]
+		if !ok {
+			if tags, err = s.tags(ctx, repoPath, f.Commit); err != nil {
+				return nil, err
+			}
+			tagsByCommit[f.Commit] = tags
+		}
+		f.Tags = tags
+		sort.Strings(f.Branches)
+		result = append(result, *f)
+	}
+
+	sort.Slice(result, func(i, j int) bool {
+		if result[i].IssueKey != result[j].IssueKey {
+			return result[i].IssueKey < result[j].IssueKey
+		}
+		return result[i].CommittedAt.Before(result[j].CommittedAt)
+	})
+
+	return result, nil
+}
+
+// branchRef is a git ref to scan and the branch name reported for it
+type branchRef struct {
+	ref  string
+	name string
+}
+
+// branches lists the local and remote branches matching the patterns
+func (s *Scanner) branches(ctx context.Context, repoPath string, patterns []string) ([]branchRef, error) {
+	out, err := s.git(ctx, repoPath, "for-each-ref", "--format=%(refname)", "refs/heads", "refs/remotes")
+	if err != nil {
+		return nil, fmt.Errorf("failed to list branches: %w", err)
+	}
+
+	var refs []branchRef
+	for _, ref := range strings.Split(strings.TrimSpace(out), "\n") {
+		if ref == "" {
+			continue
+		}
+
+		var name string
+		switch {
+		case strings.HasPrefix(ref, "refs/heads/"):
+			name = strings.TrimPrefix(ref, "refs/heads/")
+		case strings.HasPrefix(ref, "refs/remotes/"):
+			// Drop the remote name so origin/release-4.15 reports as release-4.15
+			parts := strings.SplitN(strings.TrimPrefix(ref, "refs/remotes/"), "/", 2)
+			if len(parts) != 2 || parts[1] == "HEAD" {
+				continue
+			}
+			name = parts[1]
+		default:
+			continue
+		}
+
+		if matchesAny(name, patterns) {
+			refs = append(refs, branchRef{ref: ref, name: name})
+		}
+	}
+
+	return refs, nil
+}
+
+// commit is a single entry of the git log
+type commit struct {
+	sha         string
+	committedAt time.Time
+	subject     string
+	body        string
+}
+
+// log returns the history reachable from ref
+func (s *Scanner) log(ctx context.Context, repoPath, ref string, since time.Time) ([]commit, error) {
+	args := []string{"log", "--format=%H%x1f%ct%x1f%s%x1f%b%x1e"}
+	if !since.IsZero() {
+		args = append(args, "--since="+since.Format(time.RFC3339))
+	}
+	args = append(args, ref, "--")
+
+	out, err := s.git(ctx, repoPath, args...)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read history of %s: %w", ref, err)
+	}
+
+	var commits []commit
+	for _, record := range strings.Split(out, "\x1e") {
+		fields := strings.Split(strings.TrimLeft(record, "\n"), "\x1f")
+		if len(fields) != 4 {
+			continue
+		}
+
+		ts, err := strconv.ParseInt(fields[1], 10, 64)
+		if err != nil {
+			return nil, fmt.Errorf("failed to parse commit time %q: %w", fields[1], err)
+		}
+
+		commits = append(commits, commit{
+			sha:         fields[0],
+			committedAt: time.Unix(ts, 0).UTC(),
+			subject:     fields[2],
+			body:        fields[3],
+		})
+	}
+
+	return commits, nil
+}
+
+// tags lists the tags containing a commit
+func (s *Scanner) tags(ctx context.Context, repoPath, sha string) ([]string, error) {
+	out, err := s.git(ctx, repoPath, "tag", "--contains", sha)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list tags containing %s: %w", sha, err)
+	}
+	return strings.Fields(out), nil
+}
+
+// git runs a git command in the repository and returns its standard output
+func (s *Scanner) git(ctx context.Context, repoPath string, args ...string) (string, error) {
+	cmd := exec.CommandContext(ctx, s.gitPath, append([]string{"-C", repoPath}, args...)...)
+
+	var stdout, stderr bytes.Buffer
+	cmd.Stdout = &stdout
+	cmd.Stderr = &stderr
+
+	if err := cmd.Run(); err != nil {
+		if msg := strings.TrimSpace(stderr.String()); msg != "" {
+			return "", fmt.Errorf("%w: %s", err, msg)
+		}
+		return "", err
+	}
+
+	return stdout.String(), nil
+}
+
+// keyPattern builds a regular expression matching any of the issue keys as whole words
+func keyPattern(keys []string) *regexp.Regexp {
+	quoted := make([]string, 0, len(keys))
+	for _, key := range keys {
+		quoted = append(quoted, regexp.QuoteMeta(key))
+	}
+	return regexp.MustCompile(`\b(` + strings.Join(quoted, "|") + `)\b`)
+}
+
+// uniqueMatches returns the distinct issue keys mentioned in a message
+func uniqueMatches(re *regexp.Regexp, message string) []string {
+	var keys []string
+	seen := map[string]bool{}
+	for _, key := range re.FindAllString(message, -1) {
+		if !seen[key] {
+			seen[key] = true
+			keys = append(keys, key)
+		}
+	}
+	return keys
+}
+
+// matchesAny reports whether a branch name matches one of the glob patterns.
+// No patterns match every branch.
+func matchesAny(name string, patterns []string) bool {
+	if len(patterns) == 0 {
+		return true
+	}
+	for _, pattern := range patterns {
+		if ok, _ := path.Match(pattern, name); ok {
+			return true
+		}
+	}
+	return false
+}
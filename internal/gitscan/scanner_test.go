@@ -0,0 +1,87 @@
+package gitscan
+
+import (
+	"context"
+	"os/exec"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// run executes a git command in dir and fails the test on error
+func run(t *testing.T, dir string, args ...string) {
+	t.Helper()
+	cmd := exec.Command("git", append([]string{"-C", dir}, args...)...)
+	cmd.Env = append(cmd.Environ(),
+		"GIT_AUTHOR_NAME=test", "GIT_AUTHOR_EMAIL=test@example.com",
+		"GIT_COMMITTER_NAME=test", "GIT_COMMITTER_EMAIL=test@example.com",
+	)
+	out, err := cmd.CombinedOutput()
+	require.NoError(t, err, string(out))
+}
+
+// setupRepo creates a repository where OCPBUGS-1 is fixed on main and
+// backported to release-4.15 and OCPBUGS-2 only exists on main
+func setupRepo(t *testing.T) string {
+	t.Helper()
+	if _, err := exec.LookPath("git"); err != nil {
+		t.Skip("git is not installed")
+	}
+
+	dir := t.TempDir()
+	run(t, dir, "init", "-q", "-b", "main")
+	run(t, dir, "commit", "-q", "--allow-empty", "-m", "Initial commit")
+	run(t, dir, "branch", "release-4.15")
+	run(t, dir, "commit", "-q", "--allow-empty", "-m", "OCPBUGS-1: Fix reconciliation")
+	run(t, dir, "commit", "-q", "--allow-empty", "-m", "Refactor", "-m", "Needed for OCPBUGS-2 and OCPBUGS-20")
+	run(t, dir, "tag", "v4.16.0")
+	run(t, dir, "checkout", "-q", "release-4.15")
+	run(t, dir, "commit", "-q", "--allow-empty", "-m", "[release-4.15] OCPBUGS-1: Fix reconciliation")
+	run(t, dir, "branch", "feature-x")
+	return dir
+}
+
+func TestScan(t *testing.T) {
+	dir := setupRepo(t)
+
+	findings, err := NewScanner().Scan(context.Background(), dir, []string{"OCPBUGS-1", "OCPBUGS-2"}, Options{
+		Branches: []string{"main", "release-*"},
+	})
+	require.NoError(t, err)
+	require.Len(t, findings, 3)
+
+	assert.Equal(t, "OCPBUGS-1", findings[0].IssueKey)
+	assert.Equal(t, "OCPBUGS-1: Fix reconciliation", findings[0].Subject)
+	assert.Equal(t, []string{"main"}, findings[0].Branches)
+	assert.Equal(t, []string{"v4.16.0"}, findings[0].Tags)
+
+	assert.Equal(t, "OCPBUGS-1", findings[1].IssueKey)
+	assert.Equal(t, []string{"release-4.15"}, findings[1].Branches, "feature-x is not scanned")
+	assert.Empty(t, findings[1].Tags)
+
+	// OCPBUGS-20 must not match OCPBUGS-2
+	assert.Equal(t, "OCPBUGS-2", findings[2].IssueKey)
+	assert.Equal(t, "Refactor", findings[2].Subject)
+}
+
+func TestScanNoKeys(t *testing.T) {
+	findings, err := NewScanner().Scan(context.Background(), t.TempDir(), nil, Options{})
+	assert.NoError(t, err)
+	assert.Empty(t, findings)
+}
+
+func TestScanNotARepository(t *testing.T) {
+	if _, err := exec.LookPath("git"); err != nil {
+		t.Skip("git is not installed")
+	}
+	_, err := NewScanner().Scan(context.Background(), t.TempDir(), []string{"OCPBUGS-1"}, Options{})
+	assert.Error(t, err)
+	assert.Contains(t, err.Error(), "not a git repository")
+}
+
+func TestMatchesAny(t *testing.T) {
+	assert.True(t, matchesAny("release-4.15", []string{"main", "release-*"}))
+	assert.False(t, matchesAny("feature-x", []string{"main", "release-*"}))
+	assert.True(t, matchesAny("feature-x", nil))
+}
@@ -4,6 +4,7 @@ import (
 	"context"
 	"encoding/json"
 	"fmt"
+	"io"
 	"net/http"
 	"net/url"
 	"strings"
@@ -20,12 +21,50 @@ type JiraClient interface {
 type Client struct {
 	baseURL    string
 	httpClient *http.Client
+	token      string
+	username   string
+	password   string
+	limiter    *rateLimiter
+	fields     FieldConfig
+}
+
+// ClientOption configures optional settings of a Jira client
+type ClientOption func(*Client)
+
+// WithToken authenticates requests with a personal access token
+func WithToken(token string) ClientOption {
+	return func(c *Client) {
+		c.token = token
+	}
+}
+
+// WithBasicAuth authenticates requests with a username and password, or an
+// email and API token on Jira Cloud
+func WithBasicAuth(username, password string) ClientOption {
+	return func(c *Client) {
+		c.username = username
+		c.password = password
+	}
+}
+
+// WithRateLimit limits the client to the given number of requests per second
+func WithRateLimit(perSecond float64) ClientOption {
+	return func(c *Client) {
+		c.limiter = newRateLimiter(perSecond)
+	}
 }
 
 // MockClient represents a mock Jira client for testing
 type MockClient struct {
 	initialStatus string
 	currentStatus string
+
+	// Comments and Labels record the writes made through the mock
+	Comments []string
+	Labels   []string
+
+	// Children lists the sub-tasks returned with each issue, by issue key
+	Children map[string][]models.IssueChild
 }
 
 // NewMockClient creates a new mock Jira client
@@ -53,10 +92,11 @@ func (m *MockClient) GetIssue(ctx context.Context, issueURL string) (*models.Iss
 
 	// Return a mock issue with the current status
 	return &models.Issue{
-		Key:     key,
-		Title:   "Mock Issue",
-		Status:  m.currentStatus,
-		JiraURL: issueURL,
+		Key:      key,
+		Title:    "Mock Issue",
+		Status:   m.currentStatus,
+		JiraURL:  issueURL,
+		Children: m.Children[key],
 	}, nil
 }
 
@@ -65,52 +105,167 @@ func (m *MockClient) UpdateStatus(status string) {
 	m.currentStatus = status
 }
 
+// mockTransitions are the transitions offered by the mock on every issue
+var mockTransitions = []Transition{
+	{ID: "11", Name: "Code Review", To: "Code Review"},
+	{ID: "21", Name: "Close", To: "Closed"},
+}
+
+// GetTransitions returns the fixed mock transitions
+func (m *MockClient) GetTransitions(ctx context.Context, issueURL string) ([]Transition, error) {
+	return mockTransitions, nil
+}
+
+// TransitionIssue moves the mock status to the target of the transition
+func (m *MockClient) TransitionIssue(ctx context.Context, issueURL, transitionID string) error {
+	for _, t := range mockTransitions {
+		if t.ID == transitionID {
+			m.currentStatus = t.To
+			return nil
+		}
+	}
+	return fmt.Errorf("unknown transition %q", transitionID)
+}
+
+// AddComment records the comment
+func (m *MockClient) AddComment(ctx context.Context, issueURL, body string) error {
+	m.Comments = append(m.Comments, body)
+	return nil
+}
+
+// UpdateLabels applies the label changes to the recorded labels
+func (m *MockClient) UpdateLabels(ctx context.Context, issueURL string, add, remove []string) error {
+	m.Labels = models.ApplyLabels(m.Labels, add, remove)
+	return nil
+}
+
 // JiraIssue represents the Jira API response structure
 type JiraIssue struct {
+	Key    string `json:"key"`
+	Self   string `json:"self"` // REST API URL of the issue
 	Fields struct {
 		Summary string `json:"summary"`
 		Status  struct {
-			Name string `json:"name"`
+			Name           string `json:"name"`
+			StatusCategory struct {
+				Key string `json:"key"`
+			} `json:"statusCategory"`
 		} `json:"status"`
-		Description string `json:"description"`
+		Description     string        `json:"description"`
+		FixVersions     []namedItem   `json:"fixVersions"`
+		AffectsVersions []namedItem   `json:"versions"`
+		Components      []namedItem   `json:"components"`
+		Assignee        *user         `json:"assignee"`
+		Reporter        *user         `json:"reporter"`
+		Priority        *namedItem    `json:"priority"`
+		IssueType       *namedItem    `json:"issuetype"`
+		Resolution      *namedItem    `json:"resolution"`
+		Labels          []string      `json:"labels"`
+		DueDate         string        `json:"duedate"`
+		Updated         string        `json:"updated"`
+		Subtasks        []linkedIssue `json:"subtasks"`
+		IssueLinks      []issueLink   `json:"issuelinks"`
 	} `json:"fields"`
+	// Custom holds every field by ID, to read custom fields such as the sprint
+	Custom map[string]json.RawMessage `json:"-"`
+}
+
+// UnmarshalJSON decodes an issue, keeping its raw fields in Custom
+func (j *JiraIssue) UnmarshalJSON(data []byte) error {
+	type plain JiraIssue
+	var raw struct {
+		plain
+		RawFields map[string]json.RawMessage `json:"fields"`
+	}
+	if err := json.Unmarshal(data, &raw.plain); err != nil {
+		return err
+	}
+	if err := json.Unmarshal(data, &raw); err != nil {
+		return err
+	}
+	*j = JiraIssue(raw.plain)
+	j.Custom = raw.RawFields
+	return nil
+}
+
+// user represents a Jira user
+type user struct {
+	DisplayName string `json:"displayName"`
+	Name        string `json:"name"`
+}
+
+// String returns the display name of a user, or its login name
+func (u *user) String() string {
+	if u == nil {
+		return ""
+	}
+	if u.DisplayName != "" {
+		return u.DisplayName
+	}
+	return u.Name
+}
+
+// namedItem represents Jira objects such as versions and components
+// that are identified by their name
+type namedItem struct {
+	Name string `json:"name"`
+}
+
+// name returns the name of an optional item
+func (n *namedItem) name() string {
+	if n == nil {
+		return ""
+	}
+	return n.Name
+}
+
+// names returns the names of the given items
+func names(items []namedItem) []string {
+	if len(items) == 0 {
+		return nil
+	}
+	result := make([]string, 0, len(items))
+	for _, item := range items {
+		result = append(result, item.Name)
+	}
+	return result
 }
 
 // NewClient creates a new Jira client
-func NewClient(baseURL string) JiraClient {
-	return &Client{
-		baseURL:    baseURL,
+func NewClient(baseURL string, opts ...ClientOption) JiraClient {
+	c := &Client{
+		baseURL:    strings.TrimSuffix(baseURL, "/"),
 		httpClient: &http.Client{},
 	}
+	for _, opt := range opts {
+		opt(c)
+	}
+	return c
+}
+
+// WithFields sets the IDs of the custom fields read from issues
+func WithFields(fields FieldConfig) ClientOption {
+	return func(c *Client) {
+		c.fields = fields
+	}
 }
 
 // GetIssue retrieves issue information from Jira
 func (c *Client) GetIssue(ctx context.Context, issueURL string) (*models.Issue, error) {
-	// Parse the URL to extract the issue key
-	parsedURL, err := url.Parse(issueURL)
+	key, err := issueKeyFromURL(issueURL)
 	if err != nil {
-		return nil, fmt.Errorf("invalid Jira URL: %w", err)
+		return nil, err
 	}
 
-	// Extract the issue key from the URL path
-	pathParts := strings.Split(parsedURL.Path, "/")
-	if len(pathParts) < 2 {
-		return nil, fmt.Errorf("invalid Jira URL format")
-	}
-	key := pathParts[len(pathParts)-1]
-
 	// Construct API URL
 	apiURL := fmt.Sprintf("%s/rest/api/2/issue/%s", c.baseURL, key)
 
 	// Create request
-	req, err := http.NewRequestWithContext(ctx, "GET", apiURL, nil)
+	req, err := c.newRequest(ctx, "GET", apiURL, nil)
 	if err != nil {
-		return nil, fmt.Errorf("failed to create request: %w", err)
+		return nil, fmt.Errorf("failed to fetch issue: %w", err)
 	}
 
-	// Set headers for JSON response
-	req.Header.Set("Accept", "application/json")
-
 	// Make the request
 	resp, err := c.httpClient.Do(req)
 	if err != nil {
@@ -128,13 +283,77 @@ func (c *Client) GetIssue(ctx context.Context, issueURL string) (*models.Issue,
 		return nil, fmt.Errorf("failed to parse response: %w", err)
 	}
 
-	// Create issue object
-	issue := models.Issue{
-		Key:     key,
-		Title:   jiraIssue.Fields.Summary,
-		Status:  jiraIssue.Fields.Status.Name,
-		JiraURL: issueURL,
+	if jiraIssue.Key == "" {
+		jiraIssue.Key = key
 	}
+	return jiraIssue.toIssue(issueURL, c.fields), nil
+}
 
-	return &issue, nil
+// issueKeyFromURL extracts the issue key from the last segment of an issue URL
+func issueKeyFromURL(issueURL string) (string, error) {
+	parsedURL, err := url.Parse(issueURL)
+	if err != nil {
+		return "", fmt.Errorf("invalid Jira URL: %w", err)
+	}
+
+	pathParts := strings.Split(parsedURL.Path, "/")
+	if len(pathParts) < 2 {
+		return "", fmt.Errorf("invalid Jira URL format")
+	}
+	return pathParts[len(pathParts)-1], nil
+}
+
+// newRequest creates an authenticated JSON request, waiting for the rate
+// limit first
+func (c *Client) newRequest(ctx context.Context, method, apiURL string, body io.Reader) (*http.Request, error) {
+	req, err := http.NewRequestWithContext(ctx, method, apiURL, body)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create request: %w", err)
+	}
+
+	req.Header.Set("Accept", "application/json")
+	if body != nil {
+		req.Header.Set("Content-Type", "application/json")
+	}
+	switch {
+	case c.token != "":
+		req.Header.Set("Authorization", "Bearer "+c.token)
+	case c.username != "":
+		req.SetBasicAuth(c.username, c.password)
+	}
+
+	if c.limiter != nil {
+		if err := c.limiter.Wait(ctx); err != nil {
+			return nil, err
+		}
+	}
+	return req, nil
+}
+
+// toIssue maps the Jira API representation of an issue into an Issue, reading
+// custom fields with the given IDs
+func (j *JiraIssue) toIssue(issueURL string, fields FieldConfig) *models.Issue {
+	issue := &models.Issue{
+		Key:             j.Key,
+		Title:           j.Fields.Summary,
+		Status:          j.Fields.Status.Name,
+		StatusCategory:  j.Fields.Status.StatusCategory.Key,
+		JiraURL:         issueURL,
+		FixVersions:     names(j.Fields.FixVersions),
+		AffectsVersions: names(j.Fields.AffectsVersions),
+		Components:      names(j.Fields.Components),
+		Description:     j.Fields.Description,
+		Assignee:        j.Fields.Assignee.String(),
+		Reporter:        j.Fields.Reporter.String(),
+		Priority:        j.Fields.Priority.name(),
+		IssueType:       j.Fields.IssueType.name(),
+		Resolution:      j.Fields.Resolution.name(),
+		Labels:          j.Fields.Labels,
+		DueDate:         parseDate(j.Fields.DueDate),
+		JiraUpdatedAt:   parseTimestamp(j.Fields.Updated),
+		Children:        subtasks(j.Fields.Subtasks),
+		Links:           links(j.Fields.IssueLinks),
+	}
+	fields.apply(issue, j.Custom)
+	return issue
 }
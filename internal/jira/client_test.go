@@ -5,7 +5,9 @@ import (
 	"net/http"
 	"net/http/httptest"
 	"testing"
+	"time"
 
+	"github.com/jparrill/devtrackr/internal/models"
 	"github.com/stretchr/testify/assert"
 )
 
@@ -53,9 +55,21 @@ func TestClient(t *testing.T) {
 			"fields": {
 				"summary": "Test Issue",
 				"status": {
-					"name": "In Progress"
+					"name": "In Progress",
+					"statusCategory": {"key": "indeterminate", "name": "In Progress"}
 				},
-				"description": "Test Description"
+				"description": "Test Description",
+				"fixVersions": [{"name": "4.16.0"}, {"name": "4.15.z"}],
+				"versions": [{"name": "4.14"}],
+				"components": [{"name": "HyperShift"}],
+				"assignee": {"name": "alice", "displayName": "Alice Example"},
+				"reporter": {"name": "bob"},
+				"priority": {"name": "Critical"},
+				"issuetype": {"name": "Bug"},
+				"resolution": null,
+				"labels": ["triaged"],
+				"duedate": "2024-06-30",
+				"updated": "2024-06-10T12:30:00.000+0200"
 			}
 		}`))
 	}))
@@ -73,7 +87,20 @@ func TestClient(t *testing.T) {
 	assert.Equal(t, "TEST-123", issue.Key)
 	assert.Equal(t, "Test Issue", issue.Title)
 	assert.Equal(t, "In Progress", issue.Status)
+	assert.Equal(t, models.StatusCategoryInProgress, issue.StatusCategory)
 	assert.Equal(t, "https://issues.redhat.com/browse/TEST-123", issue.JiraURL)
+	assert.Equal(t, []string{"4.16.0", "4.15.z"}, issue.FixVersions)
+	assert.Equal(t, []string{"4.14"}, issue.AffectsVersions)
+	assert.Equal(t, []string{"HyperShift"}, issue.Components)
+	assert.Equal(t, "Test Description", issue.Description)
+	assert.Equal(t, "Alice Example", issue.Assignee)
+	assert.Equal(t, "bob", issue.Reporter)
+	assert.Equal(t, "Critical", issue.Priority)
+	assert.Equal(t, "Bug", issue.IssueType)
+	assert.Empty(t, issue.Resolution)
+	assert.Equal(t, []string{"triaged"}, issue.Labels)
+	assert.Equal(t, time.Date(2024, 6, 30, 0, 0, 0, 0, time.UTC), issue.DueDate)
+	assert.Equal(t, time.Date(2024, 6, 10, 10, 30, 0, 0, time.UTC), issue.JiraUpdatedAt)
 
 	// Test GetIssue with invalid URL
 	_, err = client.GetIssue(ctx, "invalid-url")
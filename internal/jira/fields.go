@@ -0,0 +1,239 @@
+package jira
+
+import (
+	"encoding/json"
+	"fmt"
+	"regexp"
+	"strings"
+	"time"
+
+	"github.com/jparrill/devtrackr/internal/models"
+)
+
+// FieldConfig holds the IDs of custom fields, which differ between Jira
+// instances. Empty IDs leave the matching issue fields unset.
+type FieldConfig struct {
+	// Sprint is the Jira Software sprint field, e.g. customfield_10020
+	Sprint string `yaml:"sprint"`
+	// EpicLink is the field holding the key of the epic of an issue
+	EpicLink string `yaml:"epic_link"`
+	// Custom maps further fields into the attributes of issues
+	Custom []CustomField `yaml:"custom"`
+}
+
+// Custom field types accepted in CustomField.Type
+const (
+	FieldTypeString = "string"
+	FieldTypeNumber = "number"
+	FieldTypeUser   = "user"
+	FieldTypeOption = "option"
+	FieldTypeArray  = "array"
+)
+
+// CustomField maps a Jira field into a named attribute of issues
+type CustomField struct {
+	// ID is the Jira field ID, e.g. customfield_12345
+	ID string `yaml:"id"`
+	// Name is the attribute name, used in API filters as attr.<name>
+	Name string `yaml:"name"`
+	// Type tells how the field value is decoded: string, number, user
+	// (display name), option (selected value) or array (of any of those)
+	Type string `yaml:"type"`
+	// Label is the heading shown with the rendered value, defaults to Name
+	Label string `yaml:"label"`
+	// Format is a fmt format applied to each rendered value, e.g. "%.0f points"
+	Format string `yaml:"format"`
+}
+
+// validate checks the custom field definitions of an instance
+func (f FieldConfig) validate() error {
+	names := map[string]bool{}
+	for _, c := range f.Custom {
+		if c.ID == "" || c.Name == "" {
+			return fmt.Errorf("custom field %q needs both an id and a name", c.ID+c.Name)
+		}
+		if names[c.Name] {
+			return fmt.Errorf("duplicate custom field name %q", c.Name)
+		}
+		names[c.Name] = true
+
+		switch c.Type {
+		case FieldTypeString, FieldTypeNumber, FieldTypeUser, FieldTypeOption, FieldTypeArray:
+		default:
+			return fmt.Errorf("custom field %s has unknown type %q", c.Name, c.Type)
+		}
+	}
+	return nil
+}
+
+// Render formats an attribute value for display
+func (c CustomField) Render(value any) string {
+	format := c.Format
+	if format == "" {
+		format = "%v"
+	}
+
+	if values, ok := value.([]any); ok {
+		parts := make([]string, 0, len(values))
+		for _, v := range values {
+			parts = append(parts, fmt.Sprintf(format, v))
+		}
+		return strings.Join(parts, ", ")
+	}
+	return fmt.Sprintf(format, value)
+}
+
+// Heading returns the label shown with the rendered value
+func (c CustomField) Heading() string {
+	if c.Label != "" {
+		return c.Label
+	}
+	return c.Name
+}
+
+// decode converts the raw value of the field, returning false when it is
+// empty or does not have the declared type
+func (c CustomField) decode(raw json.RawMessage) (any, bool) {
+	if len(raw) == 0 || string(raw) == "null" {
+		return nil, false
+	}
+
+	switch c.Type {
+	case FieldTypeNumber:
+		var n float64
+		if json.Unmarshal(raw, &n) != nil {
+			return nil, false
+		}
+		return n, true
+	case FieldTypeArray:
+		var items []json.RawMessage
+		if json.Unmarshal(raw, &items) != nil {
+			return nil, false
+		}
+		values := make([]any, 0, len(items))
+		for _, item := range items {
+			if v := scalarValue(item); v != "" {
+				values = append(values, v)
+			}
+		}
+		if len(values) == 0 {
+			return nil, false
+		}
+		return values, true
+	default:
+		v := scalarValue(raw)
+		return v, v != ""
+	}
+}
+
+// scalarValue reads a string, or the displayed value of an option, user or
+// other named Jira object
+func scalarValue(raw json.RawMessage) string {
+	var s string
+	if json.Unmarshal(raw, &s) == nil {
+		return s
+	}
+
+	var object struct {
+		Value       string `json:"value"`
+		DisplayName string `json:"displayName"`
+		Name        string `json:"name"`
+	}
+	if json.Unmarshal(raw, &object) != nil {
+		return ""
+	}
+	for _, v := range []string{object.Value, object.DisplayName, object.Name} {
+		if v != "" {
+			return v
+		}
+	}
+	return ""
+}
+
+// apply reads the configured custom fields from the raw fields of an issue
+func (f FieldConfig) apply(issue *models.Issue, raw map[string]json.RawMessage) {
+	if f.Sprint != "" {
+		issue.Sprint = parseSprint(raw[f.Sprint])
+	}
+	if f.EpicLink != "" {
+		var epic string
+		if json.Unmarshal(raw[f.EpicLink], &epic) == nil {
+			issue.EpicLink = epic
+		}
+	}
+
+	if len(f.Custom) > 0 {
+		issue.Attributes = map[string]any{}
+		for _, c := range f.Custom {
+			if v, ok := c.decode(raw[c.ID]); ok {
+				issue.Attributes[c.Name] = v
+			}
+		}
+	}
+}
+
+// sprintNamePattern extracts the name and state from the legacy string
+// representation of sprints used by Jira Server,
+// "com.atlassian.greenhopper.service.sprint.Sprint@1a2b[id=1,state=ACTIVE,name=Sprint 1,...]"
+var (
+	sprintNamePattern  = regexp.MustCompile(`[\[,]name=([^,\]]*)`)
+	sprintStatePattern = regexp.MustCompile(`[\[,]state=([^,\]]*)`)
+)
+
+// parseSprint returns the name of the active sprint of an issue, or of the
+// last one it was part of
+func parseSprint(raw json.RawMessage) string {
+	type sprint struct {
+		Name  string `json:"name"`
+		State string `json:"state"`
+	}
+
+	var sprints []sprint
+	var objects []sprint
+	var legacy []string
+	switch {
+	case json.Unmarshal(raw, &objects) == nil:
+		sprints = objects
+	case json.Unmarshal(raw, &legacy) == nil:
+		for _, s := range legacy {
+			sp := sprint{}
+			if m := sprintNamePattern.FindStringSubmatch(s); m != nil {
+				sp.Name = m[1]
+			}
+			if m := sprintStatePattern.FindStringSubmatch(s); m != nil {
+				sp.State = m[1]
+			}
+			sprints = append(sprints, sp)
+		}
+	}
+
+	name := ""
+	for _, s := range sprints {
+		if s.Name == "" {
+			continue
+		}
+		if s.State == "active" || s.State == "ACTIVE" {
+			return s.Name
+		}
+		name = s.Name
+	}
+	return name
+}
+
+// parseDate parses a Jira date such as a due date, zero when empty or invalid
+func parseDate(value string) time.Time {
+	t, err := time.Parse("2006-01-02", value)
+	if err != nil {
+		return time.Time{}
+	}
+	return t
+}
+
+// parseTimestamp parses a Jira timestamp, zero when empty or invalid
+func parseTimestamp(value string) time.Time {
+	t, err := time.Parse("2006-01-02T15:04:05.000-0700", value)
+	if err != nil {
+		return time.Time{}
+	}
+	return t.UTC()
+}
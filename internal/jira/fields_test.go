@@ -0,0 +1,92 @@
+package jira
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/jparrill/devtrackr/internal/models"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestParseSprint(t *testing.T) {
+	assert.Equal(t, "Sprint 2", parseSprint(json.RawMessage(`[
+		{"id": 1, "name": "Sprint 1", "state": "closed"},
+		{"id": 2, "name": "Sprint 2", "state": "active"},
+		{"id": 3, "name": "Sprint 3", "state": "future"}
+	]`)))
+	assert.Equal(t, "HOSTEDCP Sprint 250", parseSprint(json.RawMessage(`[
+		"com.atlassian.greenhopper.service.sprint.Sprint@1a2b[id=1,rapidViewId=2,state=CLOSED,name=HOSTEDCP Sprint 249,startDate=2024-01-01]",
+		"com.atlassian.greenhopper.service.sprint.Sprint@3c4d[id=2,rapidViewId=2,state=ACTIVE,name=HOSTEDCP Sprint 250,startDate=2024-01-15]"
+	]`)))
+	assert.Equal(t, "Sprint 1", parseSprint(json.RawMessage(`[{"name": "Sprint 1", "state": "closed"}]`)))
+	assert.Empty(t, parseSprint(json.RawMessage(`null`)))
+	assert.Empty(t, parseSprint(nil))
+}
+
+func TestFieldConfigApply(t *testing.T) {
+	var j JiraIssue
+	require.NoError(t, json.Unmarshal([]byte(`{
+		"key": "PROJ-1",
+		"fields": {
+			"summary": "Test",
+			"customfield_10020": [{"name": "Sprint 7", "state": "active"}],
+			"customfield_10014": "PROJ-100"
+		}
+	}`), &j))
+	assert.Equal(t, "Test", j.Fields.Summary)
+
+	issue := j.toIssue("", FieldConfig{Sprint: "customfield_10020", EpicLink: "customfield_10014"})
+	assert.Equal(t, "Sprint 7", issue.Sprint)
+	assert.Equal(t, "PROJ-100", issue.EpicLink)
+
+	issue = j.toIssue("", FieldConfig{})
+	assert.Equal(t, &models.Issue{Key: "PROJ-1", Title: "Test"}, issue)
+}
+
+func TestCustomFieldAttributes(t *testing.T) {
+	fields := FieldConfig{Custom: []CustomField{
+		{ID: "customfield_1", Name: "release_note", Type: FieldTypeString},
+		{ID: "customfield_2", Name: "story_points", Type: FieldTypeNumber, Label: "Story points", Format: "%g pts"},
+		{ID: "customfield_3", Name: "qa_contact", Type: FieldTypeUser},
+		{ID: "customfield_4", Name: "target_version", Type: FieldTypeArray},
+		{ID: "customfield_5", Name: "severity", Type: FieldTypeOption},
+		{ID: "customfield_6", Name: "unset", Type: FieldTypeString},
+	}}
+	require.NoError(t, fields.validate())
+
+	var j JiraIssue
+	require.NoError(t, json.Unmarshal([]byte(`{
+		"key": "PROJ-1",
+		"fields": {
+			"customfield_1": "Fixed a crash",
+			"customfield_2": 3,
+			"customfield_3": {"name": "carol", "displayName": "Carol Example"},
+			"customfield_4": [{"name": "4.16.0"}, {"name": "4.15.z"}],
+			"customfield_5": {"value": "Important", "id": "10"},
+			"customfield_6": null
+		}
+	}`), &j))
+
+	issue := j.toIssue("", fields)
+	assert.Equal(t, map[string]any{
+		"release_note":   "Fixed a crash",
+		"story_points":   3.0,
+		"qa_contact":     "Carol Example",
+		"target_version": []any{"4.16.0", "4.15.z"},
+		"severity":       "Important",
+	}, issue.Attributes)
+
+	assert.Equal(t, "3 pts", fields.Custom[1].Render(issue.Attributes["story_points"]))
+	assert.Equal(t, "Story points", fields.Custom[1].Heading())
+	assert.Equal(t, "4.16.0, 4.15.z", fields.Custom[3].Render(issue.Attributes["target_version"]))
+}
+
+func TestFieldConfigValidate(t *testing.T) {
+	assert.Error(t, FieldConfig{Custom: []CustomField{{ID: "customfield_1", Type: FieldTypeString}}}.validate())
+	assert.Error(t, FieldConfig{Custom: []CustomField{{ID: "customfield_1", Name: "points", Type: "float"}}}.validate())
+	assert.Error(t, FieldConfig{Custom: []CustomField{
+		{ID: "customfield_1", Name: "points", Type: FieldTypeNumber},
+		{ID: "customfield_2", Name: "points", Type: FieldTypeNumber},
+	}}.validate())
+}
@@ -0,0 +1,179 @@
+package jira
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strings"
+
+	"github.com/jparrill/devtrackr/internal/models"
+)
+
+// ChildLister is implemented by clients that can find the issues in an epic.
+// Jira lists the sub-tasks of an issue on the issue itself, but the issues of
+// an epic have to be searched for.
+type ChildLister interface {
+	GetEpicChildren(ctx context.Context, issueURL string) ([]models.IssueChild, error)
+}
+
+// searchPageSize is the number of issues requested per search page
+const searchPageSize = 100
+
+// linkedIssue is the short form of an issue found in sub-tasks, issue links
+// and search results
+type linkedIssue struct {
+	Key    string `json:"key"`
+	Fields struct {
+		Summary string `json:"summary"`
+		Status  struct {
+			Name           string `json:"name"`
+			StatusCategory struct {
+				Key string `json:"key"`
+			} `json:"statusCategory"`
+		} `json:"status"`
+		IssueType *struct {
+			Subtask bool `json:"subtask"`
+		} `json:"issuetype"`
+	} `json:"fields"`
+}
+
+// issueLink is a link between two issues. Only one of InwardIssue and
+// OutwardIssue is set, the other end being the issue the link was read from.
+type issueLink struct {
+	Type struct {
+		Name    string `json:"name"`
+		Inward  string `json:"inward"`
+		Outward string `json:"outward"`
+	} `json:"type"`
+	InwardIssue  *linkedIssue `json:"inwardIssue"`
+	OutwardIssue *linkedIssue `json:"outwardIssue"`
+}
+
+// child maps a linked issue into a child of the given kind
+func (l *linkedIssue) child(kind models.ChildKind) models.IssueChild {
+	return models.IssueChild{
+		Key:            l.Key,
+		Kind:           kind,
+		Title:          l.Fields.Summary,
+		Status:         l.Fields.Status.Name,
+		StatusCategory: l.Fields.Status.StatusCategory.Key,
+	}
+}
+
+// subtasks maps the sub-tasks of an issue
+func subtasks(items []linkedIssue) []models.IssueChild {
+	var children []models.IssueChild
+	for _, item := range items {
+		children = append(children, item.child(models.ChildSubtask))
+	}
+	return children
+}
+
+// links maps the issue links of an issue, naming each relation from the
+// side of that issue
+func links(items []issueLink) []models.IssueLink {
+	var result []models.IssueLink
+	for _, item := range items {
+		other, relation, outward := item.OutwardIssue, item.Type.Outward, true
+		if other == nil {
+			other, relation, outward = item.InwardIssue, item.Type.Inward, false
+		}
+		if other == nil {
+			continue
+		}
+		result = append(result, models.IssueLink{
+			Type:           item.Type.Name,
+			Relation:       relation,
+			Outward:        outward,
+			Key:            other.Key,
+			Title:          other.Fields.Summary,
+			Status:         other.Fields.Status.Name,
+			StatusCategory: other.Fields.Status.StatusCategory.Key,
+		})
+	}
+	return result
+}
+
+// GetEpicChildren searches for the issues in an epic. Issues are found through
+// their parent, and through the epic link field when its ID is configured.
+func (c *Client) GetEpicChildren(ctx context.Context, issueURL string) ([]models.IssueChild, error) {
+	key, err := issueKeyFromURL(issueURL)
+	if err != nil {
+		return nil, err
+	}
+
+	jql := fmt.Sprintf("parent = %s", key)
+	if id, ok := strings.CutPrefix(c.fields.EpicLink, "customfield_"); ok {
+		jql += fmt.Sprintf(" OR cf[%s] = %s", id, key)
+	}
+
+	var children []models.IssueChild
+	for startAt := 0; ; {
+		var page struct {
+			Total  int           `json:"total"`
+			Issues []linkedIssue `json:"issues"`
+		}
+		if err := c.search(ctx, jql, startAt, &page); err != nil {
+			return nil, fmt.Errorf("failed to get children of %s: %w", key, err)
+		}
+
+		for _, item := range page.Issues {
+			kind := models.ChildEpic
+			if item.Fields.IssueType != nil && item.Fields.IssueType.Subtask {
+				kind = models.ChildSubtask
+			}
+			children = append(children, item.child(kind))
+		}
+
+		startAt += len(page.Issues)
+		if len(page.Issues) == 0 || startAt >= page.Total {
+			return children, nil
+		}
+	}
+}
+
+// search runs a JQL search for the short form of issues, decoding one page
+// of results into out
+func (c *Client) search(ctx context.Context, jql string, startAt int, out any) error {
+	query := url.Values{
+		"jql":        {jql},
+		"fields":     {"summary,status,issuetype"},
+		"startAt":    {fmt.Sprint(startAt)},
+		"maxResults": {fmt.Sprint(searchPageSize)},
+	}
+	req, err := c.newRequest(ctx, http.MethodGet, c.baseURL+"/rest/api/2/search?"+query.Encode(), nil)
+	if err != nil {
+		return err
+	}
+
+	resp, err := c.httpClient.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		msg, _ := io.ReadAll(io.LimitReader(resp.Body, 1024))
+		return fmt.Errorf("status code %d: %s", resp.StatusCode, strings.TrimSpace(string(msg)))
+	}
+	if err := json.NewDecoder(resp.Body).Decode(out); err != nil {
+		return fmt.Errorf("failed to parse response: %w", err)
+	}
+	return nil
+}
+
+// GetEpicChildren searches for the issues in an epic on the instance serving it
+func (r *Router) GetEpicChildren(ctx context.Context, issueURL string) ([]models.IssueChild, error) {
+	inst, err := r.instanceFor(issueURL)
+	if err != nil {
+		return nil, err
+	}
+	lister, ok := inst.client.(ChildLister)
+	if !ok {
+		return nil, nil
+	}
+	return lister.GetEpicChildren(ctx, issueURL)
+}
@@ -0,0 +1,75 @@
+package jira
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/jparrill/devtrackr/internal/models"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestToIssueHierarchy(t *testing.T) {
+	var j JiraIssue
+	require.NoError(t, json.Unmarshal([]byte(`{
+		"key": "PROJ-1",
+		"fields": {
+			"summary": "Parent",
+			"subtasks": [
+				{"key": "PROJ-2", "fields": {"summary": "Write docs", "status": {"name": "Closed", "statusCategory": {"key": "done"}}}}
+			],
+			"issuelinks": [
+				{"type": {"name": "Blocks", "inward": "is blocked by", "outward": "blocks"},
+				 "inwardIssue": {"key": "PROJ-3", "fields": {"summary": "Fix API", "status": {"name": "New", "statusCategory": {"key": "new"}}}}},
+				{"type": {"name": "Cloners", "inward": "is cloned by", "outward": "clones"},
+				 "outwardIssue": {"key": "PROJ-4", "fields": {"summary": "Original", "status": {"name": "Verified", "statusCategory": {"key": "done"}}}}}
+			]
+		}
+	}`), &j))
+
+	issue := j.toIssue("https://jira.example.com/browse/PROJ-1", FieldConfig{})
+	assert.Equal(t, []models.IssueChild{
+		{Key: "PROJ-2", Kind: models.ChildSubtask, Title: "Write docs", Status: "Closed", StatusCategory: "done"},
+	}, issue.Children)
+	assert.Equal(t, []models.IssueLink{
+		{Type: "Blocks", Relation: "is blocked by", Key: "PROJ-3", Title: "Fix API", Status: "New", StatusCategory: "new"},
+		{Type: "Cloners", Relation: "clones", Outward: true, Key: "PROJ-4", Title: "Original", Status: "Verified", StatusCategory: "done"},
+	}, issue.Links)
+}
+
+func TestGetEpicChildren(t *testing.T) {
+	var queries []string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		require.Equal(t, "/rest/api/2/search", r.URL.Path)
+		queries = append(queries, r.URL.Query().Get("jql"))
+
+		// Serve one issue per page to walk through the pages
+		if r.URL.Query().Get("startAt") == "0" {
+			fmt.Fprint(w, `{"total": 2, "issues": [
+				{"key": "PROJ-5", "fields": {"summary": "Story", "status": {"name": "New", "statusCategory": {"key": "new"}}, "issuetype": {"subtask": false}}}
+			]}`)
+			return
+		}
+		fmt.Fprint(w, `{"total": 2, "issues": [
+			{"key": "PROJ-6", "fields": {"summary": "Task", "status": {"name": "Done", "statusCategory": {"key": "done"}}, "issuetype": {"subtask": true}}}
+		]}`)
+	}))
+	defer server.Close()
+
+	c := NewClient(server.URL, WithFields(FieldConfig{EpicLink: "customfield_12311140"})).(ChildLister)
+	children, err := c.GetEpicChildren(context.Background(), server.URL+"/browse/PROJ-1")
+	require.NoError(t, err)
+
+	assert.Equal(t, []models.IssueChild{
+		{Key: "PROJ-5", Kind: models.ChildEpic, Title: "Story", Status: "New", StatusCategory: "new"},
+		{Key: "PROJ-6", Kind: models.ChildSubtask, Title: "Task", Status: "Done", StatusCategory: "done"},
+	}, children)
+	assert.Equal(t, []string{
+		"parent = PROJ-1 OR cf[12311140] = PROJ-1",
+		"parent = PROJ-1 OR cf[12311140] = PROJ-1",
+	}, queries)
+}
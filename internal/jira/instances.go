@@ -0,0 +1,188 @@
+package jira
+
+import (
+	"context"
+	"fmt"
+	"net/url"
+	"os"
+	"strings"
+
+	"github.com/jparrill/devtrackr/internal/models"
+)
+
+// InstanceConfig configures a Jira instance issues can be tracked from
+type InstanceConfig struct {
+	// Name identifies the instance. It is stored with every issue so keys
+	// from different instances are kept apart.
+	Name string `yaml:"name"`
+	// URL is the base URL of the instance, e.g. https://issues.redhat.com
+	URL string `yaml:"url"`
+	// TokenEnv names the environment variable holding a personal access
+	// token, or "email:api-token" for Jira Cloud
+	TokenEnv string `yaml:"token_env"`
+	// RateLimit caps the requests per second sent to the instance, 0 means
+	// no limit
+	RateLimit float64 `yaml:"rate_limit"`
+	// Fields holds the IDs of the custom fields of the instance
+	Fields FieldConfig `yaml:"fields"`
+}
+
+// DefaultInstances returns the instances used when none are configured
+func DefaultInstances() []InstanceConfig {
+	return []InstanceConfig{
+		{
+			Name:     "redhat",
+			URL:      "https://issues.redhat.com",
+			TokenEnv: "JIRA_TOKEN",
+			Fields: FieldConfig{
+				Sprint:   "customfield_12310940",
+				EpicLink: "customfield_12311140",
+			},
+		},
+	}
+}
+
+// InstanceResolver is implemented by clients serving several Jira instances
+type InstanceResolver interface {
+	// InstanceFor returns the name of the instance serving an issue URL
+	InstanceFor(issueURL string) (string, error)
+	// ResolveWebhook completes the issue of a webhook delivery with the
+	// instance that sent it and the custom fields of that instance
+	ResolveWebhook(event *WebhookEvent) error
+}
+
+// instance is a Jira instance registered in a router
+type instance struct {
+	name   string
+	client JiraClient
+}
+
+// Router sends requests to the Jira instance serving the host of each
+// issue URL, and tags the issues it returns with the instance name
+type Router struct {
+	instances map[string]*instance
+}
+
+// NewRouter creates an empty router
+func NewRouter() *Router {
+	return &Router{
+		instances: map[string]*instance{},
+	}
+}
+
+// NewRouterFromConfig creates a router serving the configured instances
+func NewRouterFromConfig(configs []InstanceConfig) (*Router, error) {
+	r := NewRouter()
+	names := map[string]bool{}
+
+	for _, cfg := range configs {
+		if cfg.Name == "" {
+			return nil, fmt.Errorf("jira instance %s has no name", cfg.URL)
+		}
+		if names[cfg.Name] {
+			return nil, fmt.Errorf("duplicate jira instance name %q", cfg.Name)
+		}
+		names[cfg.Name] = true
+
+		if err := cfg.Fields.validate(); err != nil {
+			return nil, fmt.Errorf("jira instance %q: %w", cfg.Name, err)
+		}
+
+		opts := []ClientOption{WithFields(cfg.Fields)}
+		if cfg.TokenEnv != "" {
+			if token := os.Getenv(cfg.TokenEnv); token != "" {
+				if username, password, ok := strings.Cut(token, ":"); ok {
+					opts = append(opts, WithBasicAuth(username, password))
+				} else {
+					opts = append(opts, WithToken(token))
+				}
+			}
+		}
+		if cfg.RateLimit > 0 {
+			opts = append(opts, WithRateLimit(cfg.RateLimit))
+		}
+
+		if err := r.Register(cfg.Name, cfg.URL, NewClient(cfg.URL, opts...)); err != nil {
+			return nil, err
+		}
+	}
+
+	return r, nil
+}
+
+// Register adds the instance with the given base URL
+func (r *Router) Register(name, baseURL string, client JiraClient) error {
+	host, err := hostOf(baseURL)
+	if err != nil {
+		return fmt.Errorf("invalid URL of jira instance %q: %w", name, err)
+	}
+	if existing, ok := r.instances[host]; ok {
+		return fmt.Errorf("jira instances %q and %q share host %s", existing.name, name, host)
+	}
+	r.instances[host] = &instance{name: name, client: client}
+	return nil
+}
+
+// InstanceFor returns the name of the instance serving an issue URL
+func (r *Router) InstanceFor(issueURL string) (string, error) {
+	inst, err := r.instanceFor(issueURL)
+	if err != nil {
+		return "", err
+	}
+	return inst.name, nil
+}
+
+// GetIssue retrieves an issue from the instance serving its URL
+func (r *Router) GetIssue(ctx context.Context, issueURL string) (*models.Issue, error) {
+	inst, err := r.instanceFor(issueURL)
+	if err != nil {
+		return nil, err
+	}
+
+	issue, err := inst.client.GetIssue(ctx, issueURL)
+	if err != nil {
+		return nil, err
+	}
+	issue.Instance = inst.name
+	return issue, nil
+}
+
+// ResolveWebhook records the instance that sent a webhook delivery on its
+// issue, and reads the custom fields of that instance from the delivery
+func (r *Router) ResolveWebhook(event *WebhookEvent) error {
+	inst, err := r.instanceFor(event.Issue.JiraURL)
+	if err != nil {
+		return err
+	}
+
+	event.Issue.Instance = inst.name
+	if c, ok := inst.client.(*Client); ok {
+		c.fields.apply(event.Issue, event.fields)
+	}
+	return nil
+}
+
+// instanceFor looks up the instance serving the host of an issue URL
+func (r *Router) instanceFor(issueURL string) (*instance, error) {
+	host, err := hostOf(issueURL)
+	if err != nil {
+		return nil, fmt.Errorf("invalid Jira URL: %w", err)
+	}
+	inst, ok := r.instances[host]
+	if !ok {
+		return nil, fmt.Errorf("no jira instance configured for host %s", host)
+	}
+	return inst, nil
+}
+
+// hostOf returns the lower-cased host of a URL
+func hostOf(rawURL string) (string, error) {
+	u, err := url.Parse(rawURL)
+	if err != nil {
+		return "", err
+	}
+	if u.Host == "" {
+		return "", fmt.Errorf("no host in %q", rawURL)
+	}
+	return strings.ToLower(u.Host), nil
+}
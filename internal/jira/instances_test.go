@@ -0,0 +1,97 @@
+package jira
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestRouterRoutesByHost(t *testing.T) {
+	r := NewRouter()
+	require.NoError(t, r.Register("redhat", "https://issues.redhat.com", NewMockClient("New")))
+	require.NoError(t, r.Register("apache", "https://issues.apache.org/jira", NewMockClient("Open")))
+
+	issue, err := r.GetIssue(context.Background(), "https://issues.apache.org/jira/browse/PROJ-1")
+	require.NoError(t, err)
+	assert.Equal(t, "apache", issue.Instance)
+	assert.Equal(t, "Open", issue.Status)
+	assert.Equal(t, "apache:PROJ-1", issue.QualifiedKey())
+
+	instance, err := r.InstanceFor("https://ISSUES.redhat.com/browse/PROJ-1")
+	require.NoError(t, err)
+	assert.Equal(t, "redhat", instance)
+
+	_, err = r.GetIssue(context.Background(), "https://jira.example.com/browse/PROJ-1")
+	assert.Error(t, err)
+
+	assert.Error(t, r.Register("other", "https://issues.redhat.com", NewMockClient("New")))
+}
+
+func TestNewRouterFromConfig(t *testing.T) {
+	var auth string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		auth = r.Header.Get("Authorization")
+		w.Write([]byte(`{"key": "PROJ-1", "fields": {"summary": "Test", "status": {"name": "New"}}}`))
+	}))
+	defer server.Close()
+
+	t.Setenv("TEST_JIRA_TOKEN", "secret")
+	r, err := NewRouterFromConfig([]InstanceConfig{
+		{Name: "test", URL: server.URL, TokenEnv: "TEST_JIRA_TOKEN", RateLimit: 100},
+	})
+	require.NoError(t, err)
+
+	issue, err := r.GetIssue(context.Background(), server.URL+"/browse/PROJ-1")
+	require.NoError(t, err)
+	assert.Equal(t, "test", issue.Instance)
+	assert.Equal(t, "Bearer secret", auth)
+
+	_, err = NewRouterFromConfig([]InstanceConfig{{URL: server.URL}})
+	assert.Error(t, err, "instances need a name")
+
+	_, err = NewRouterFromConfig([]InstanceConfig{
+		{Name: "test", URL: "https://a.example.com"},
+		{Name: "test", URL: "https://b.example.com"},
+	})
+	assert.Error(t, err, "instance names must be unique")
+}
+
+func TestRateLimiter(t *testing.T) {
+	l := newRateLimiter(20)
+	start := time.Now()
+	for i := 0; i < 3; i++ {
+		require.NoError(t, l.Wait(context.Background()))
+	}
+	assert.GreaterOrEqual(t, time.Since(start), 100*time.Millisecond)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	assert.Error(t, l.Wait(ctx))
+}
+
+func TestRouterResolveWebhook(t *testing.T) {
+	r, err := NewRouterFromConfig([]InstanceConfig{
+		{Name: "cloud", URL: "https://example.atlassian.net", Fields: FieldConfig{Sprint: "customfield_10020"}},
+	})
+	require.NoError(t, err)
+
+	event, err := ParseWebhook([]byte(`{
+		"webhookEvent": "jira:issue_updated",
+		"issue": {
+			"key": "PROJ-1",
+			"self": "https://example.atlassian.net/rest/api/2/issue/10001",
+			"fields": {"customfield_10020": [{"name": "Sprint 7", "state": "active"}]}
+		}
+	}`))
+	require.NoError(t, err)
+	assert.Empty(t, event.Issue.Sprint)
+
+	require.NoError(t, r.ResolveWebhook(event))
+	assert.Equal(t, "cloud", event.Issue.Instance)
+	assert.Equal(t, "Sprint 7", event.Issue.Sprint)
+}
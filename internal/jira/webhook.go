@@ -0,0 +1,208 @@
+package jira
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"crypto/subtle"
+	"encoding/base64"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+
+	"github.com/jparrill/devtrackr/internal/models"
+)
+
+// Webhook event types handled by DevTrackr
+const (
+	EventIssueUpdated = "jira:issue_updated"
+	EventIssueDeleted = "jira:issue_deleted"
+)
+
+// VerifyWebhook authenticates a webhook delivery with the shared secret. Three
+// schemes are accepted, matching what the different Jira flavors can send:
+//   - an X-Hub-Signature header with the HMAC-SHA256 of the body (Jira Cloud
+//     webhooks with a secret),
+//   - an "Authorization: JWT <token>" header signed with HS256 (Connect apps),
+//   - a "secret" query parameter in the webhook URL (Jira Server/Data Center).
+func VerifyWebhook(secret string, r *http.Request, body []byte) error {
+	if secret == "" {
+		return fmt.Errorf("webhook secret is not configured")
+	}
+
+	if signature := r.Header.Get("X-Hub-Signature"); signature != "" {
+		return verifySignature(secret, body, signature)
+	}
+
+	if token, found := strings.CutPrefix(r.Header.Get("Authorization"), "JWT "); found {
+		return verifyJWT(secret, token, time.Now())
+	}
+
+	if value := r.URL.Query().Get("secret"); value != "" {
+		if subtle.ConstantTimeCompare([]byte(value), []byte(secret)) != 1 {
+			return fmt.Errorf("secret mismatch")
+		}
+		return nil
+	}
+
+	return fmt.Errorf("webhook delivery is not authenticated")
+}
+
+// verifySignature checks a "sha256=<hex>" HMAC signature of the body
+func verifySignature(secret string, body []byte, signature string) error {
+	hexDigest, found := strings.CutPrefix(signature, "sha256=")
+	if !found {
+		return fmt.Errorf("malformed signature")
+	}
+
+	expected, err := hex.DecodeString(hexDigest)
+	if err != nil {
+		return fmt.Errorf("malformed signature: %w", err)
+	}
+
+	mac := hmac.New(sha256.New, []byte(secret))
+	mac.Write(body)
+	if !hmac.Equal(mac.Sum(nil), expected) {
+		return fmt.Errorf("signature mismatch")
+	}
+	return nil
+}
+
+// verifyJWT checks the HS256 signature and expiry of a JWT
+func verifyJWT(secret, token string, now time.Time) error {
+	parts := strings.Split(token, ".")
+	if len(parts) != 3 {
+		return fmt.Errorf("malformed JWT")
+	}
+
+	headerJSON, err := base64.RawURLEncoding.DecodeString(parts[0])
+	if err != nil {
+		return fmt.Errorf("malformed JWT header: %w", err)
+	}
+	var header struct {
+		Alg string `json:"alg"`
+	}
+	if err := json.Unmarshal(headerJSON, &header); err != nil {
+		return fmt.Errorf("malformed JWT header: %w", err)
+	}
+	if header.Alg != "HS256" {
+		return fmt.Errorf("unsupported JWT algorithm %q", header.Alg)
+	}
+
+	signature, err := base64.RawURLEncoding.DecodeString(parts[2])
+	if err != nil {
+		return fmt.Errorf("malformed JWT signature: %w", err)
+	}
+	mac := hmac.New(sha256.New, []byte(secret))
+	mac.Write([]byte(parts[0] + "." + parts[1]))
+	if !hmac.Equal(mac.Sum(nil), signature) {
+		return fmt.Errorf("JWT signature mismatch")
+	}
+
+	claimsJSON, err := base64.RawURLEncoding.DecodeString(parts[1])
+	if err != nil {
+		return fmt.Errorf("malformed JWT claims: %w", err)
+	}
+	var claims struct {
+		Exp int64 `json:"exp"`
+	}
+	if err := json.Unmarshal(claimsJSON, &claims); err != nil {
+		return fmt.Errorf("malformed JWT claims: %w", err)
+	}
+	if claims.Exp != 0 && now.Unix() >= claims.Exp {
+		return fmt.Errorf("JWT expired")
+	}
+
+	return nil
+}
+
+// Change is a single field change from the changelog of a webhook delivery
+type Change struct {
+	Field string
+	From  string
+	To    string
+}
+
+// WebhookEvent is the part of a Jira webhook delivery relevant to tracking
+type WebhookEvent struct {
+	Type      string
+	Key       string
+	Author    string
+	Timestamp time.Time
+	// Issue holds the fields of the issue after the change. Its JiraURL is
+	// derived from the REST API URL of the delivery, empty when it has none.
+	// Custom fields such as the sprint are only read by Router.ResolveWebhook,
+	// which knows their IDs on the instance that sent the delivery.
+	Issue   *models.Issue
+	Changes []Change
+
+	// fields holds the raw issue fields, to read custom fields once the
+	// instance that sent the delivery is known
+	fields map[string]json.RawMessage
+}
+
+// webhookPayload represents a Jira issue webhook delivery
+type webhookPayload struct {
+	Timestamp    int64     `json:"timestamp"`
+	WebhookEvent string    `json:"webhookEvent"`
+	Issue        JiraIssue `json:"issue"`
+	User         struct {
+		DisplayName string `json:"displayName"`
+		Name        string `json:"name"`
+	} `json:"user"`
+	Changelog struct {
+		Items []struct {
+			Field      string `json:"field"`
+			FromString string `json:"fromString"`
+			ToString   string `json:"toString"`
+		} `json:"items"`
+	} `json:"changelog"`
+}
+
+// ParseWebhook parses a Jira issue webhook delivery
+func ParseWebhook(body []byte) (*WebhookEvent, error) {
+	var payload webhookPayload
+	if err := json.Unmarshal(body, &payload); err != nil {
+		return nil, fmt.Errorf("failed to parse webhook payload: %w", err)
+	}
+	if payload.Issue.Key == "" {
+		return nil, fmt.Errorf("webhook payload has no issue key")
+	}
+
+	event := &WebhookEvent{
+		Type:      payload.WebhookEvent,
+		Key:       payload.Issue.Key,
+		Author:    payload.User.DisplayName,
+		Timestamp: time.Now(),
+		Issue:     payload.Issue.toIssue(browseURL(payload.Issue.Self, payload.Issue.Key), FieldConfig{}),
+		fields:    payload.Issue.Custom,
+	}
+	if event.Author == "" {
+		event.Author = payload.User.Name
+	}
+	if payload.Timestamp > 0 {
+		event.Timestamp = time.UnixMilli(payload.Timestamp)
+	}
+
+	for _, item := range payload.Changelog.Items {
+		event.Changes = append(event.Changes, Change{
+			Field: item.Field,
+			From:  item.FromString,
+			To:    item.ToString,
+		})
+	}
+
+	return event, nil
+}
+
+// browseURL turns the REST API URL of an issue into its web URL
+func browseURL(self, key string) string {
+	u, err := url.Parse(self)
+	if err != nil || u.Host == "" {
+		return ""
+	}
+	return fmt.Sprintf("%s://%s/browse/%s", u.Scheme, u.Host, key)
+}
@@ -0,0 +1,106 @@
+package jira
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const updatedPayload = `{
+	"timestamp": 1718000000000,
+	"webhookEvent": "jira:issue_updated",
+	"user": {"displayName": "Alice Example", "name": "alice"},
+	"issue": {
+		"key": "OCPBUGS-1234",
+		"self": "https://issues.redhat.com/rest/api/2/issue/1",
+		"fields": {
+			"summary": "Node pool does not scale",
+			"status": {"name": "ON_QA"},
+			"fixVersions": [{"name": "4.16.0"}]
+		}
+	},
+	"changelog": {
+		"items": [
+			{"field": "status", "fromString": "POST", "toString": "ON_QA"},
+			{"field": "assignee", "fromString": "", "toString": "Bob"}
+		]
+	}
+}`
+
+func signJWT(secret, claims string) string {
+	enc := base64.RawURLEncoding
+	unsigned := enc.EncodeToString([]byte(`{"alg":"HS256","typ":"JWT"}`)) + "." + enc.EncodeToString([]byte(claims))
+	mac := hmac.New(sha256.New, []byte(secret))
+	mac.Write([]byte(unsigned))
+	return unsigned + "." + enc.EncodeToString(mac.Sum(nil))
+}
+
+func TestVerifyWebhook(t *testing.T) {
+	body := []byte(updatedPayload)
+	mac := hmac.New(sha256.New, []byte("secret"))
+	mac.Write(body)
+	signature := "sha256=" + hex.EncodeToString(mac.Sum(nil))
+
+	request := func(target string, headers map[string]string) *http.Request {
+		r := httptest.NewRequest("POST", target, strings.NewReader(updatedPayload))
+		for k, v := range headers {
+			r.Header.Set(k, v)
+		}
+		return r
+	}
+
+	// Signature
+	assert.NoError(t, VerifyWebhook("secret", request("/hook", map[string]string{"X-Hub-Signature": signature}), body))
+	assert.Error(t, VerifyWebhook("other", request("/hook", map[string]string{"X-Hub-Signature": signature}), body))
+
+	// JWT
+	valid := signJWT("secret", `{"iss":"jira","exp":`+strconv.FormatInt(time.Now().Add(time.Minute).Unix(), 10)+`}`)
+	expired := signJWT("secret", `{"iss":"jira","exp":`+strconv.FormatInt(time.Now().Add(-time.Minute).Unix(), 10)+`}`)
+	assert.NoError(t, VerifyWebhook("secret", request("/hook", map[string]string{"Authorization": "JWT " + valid}), body))
+	assert.Error(t, VerifyWebhook("other", request("/hook", map[string]string{"Authorization": "JWT " + valid}), body))
+	assert.Error(t, VerifyWebhook("secret", request("/hook", map[string]string{"Authorization": "JWT " + expired}), body))
+	assert.Error(t, VerifyWebhook("secret", request("/hook", map[string]string{"Authorization": "JWT not.a.jwt"}), body))
+
+	// Query parameter
+	assert.NoError(t, VerifyWebhook("secret", request("/hook?secret=secret", nil), body))
+	assert.Error(t, VerifyWebhook("secret", request("/hook?secret=wrong", nil), body))
+
+	// Unauthenticated deliveries and missing secrets are rejected
+	assert.Error(t, VerifyWebhook("secret", request("/hook", nil), body))
+	assert.Error(t, VerifyWebhook("", request("/hook?secret=", nil), body))
+}
+
+func TestParseWebhook(t *testing.T) {
+	event, err := ParseWebhook([]byte(updatedPayload))
+	require.NoError(t, err)
+
+	assert.Equal(t, EventIssueUpdated, event.Type)
+	assert.Equal(t, "OCPBUGS-1234", event.Key)
+	assert.Equal(t, "Alice Example", event.Author)
+	assert.Equal(t, time.UnixMilli(1718000000000), event.Timestamp)
+	require.NotNil(t, event.Issue)
+	assert.Equal(t, "ON_QA", event.Issue.Status)
+	assert.Equal(t, "Node pool does not scale", event.Issue.Title)
+	assert.Equal(t, []string{"4.16.0"}, event.Issue.FixVersions)
+	assert.Equal(t, "https://issues.redhat.com/browse/OCPBUGS-1234", event.Issue.JiraURL)
+	assert.Equal(t, []Change{
+		{Field: "status", From: "POST", To: "ON_QA"},
+		{Field: "assignee", From: "", To: "Bob"},
+	}, event.Changes)
+
+	_, err = ParseWebhook([]byte(`{"webhookEvent": "jira:issue_updated"}`))
+	assert.Error(t, err)
+
+	_, err = ParseWebhook([]byte(`not json`))
+	assert.Error(t, err)
+}
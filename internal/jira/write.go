@@ -0,0 +1,175 @@
+package jira
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+)
+
+// Transition is a workflow transition available on an issue
+type Transition struct {
+	ID   string `json:"id"`
+	Name string `json:"name"`
+	To   string `json:"to"` // Status the issue moves to
+}
+
+// IssueWriter is implemented by clients that can change issues in Jira
+type IssueWriter interface {
+	// GetTransitions lists the transitions currently available on an issue
+	GetTransitions(ctx context.Context, issueURL string) ([]Transition, error)
+	// TransitionIssue moves an issue through the transition with the given ID
+	TransitionIssue(ctx context.Context, issueURL, transitionID string) error
+	// AddComment posts a comment on an issue
+	AddComment(ctx context.Context, issueURL, body string) error
+	// UpdateLabels adds and removes labels of an issue
+	UpdateLabels(ctx context.Context, issueURL string, add, remove []string) error
+}
+
+// GetTransitions lists the transitions currently available on an issue
+func (c *Client) GetTransitions(ctx context.Context, issueURL string) ([]Transition, error) {
+	var resp struct {
+		Transitions []struct {
+			ID   string    `json:"id"`
+			Name string    `json:"name"`
+			To   namedItem `json:"to"`
+		} `json:"transitions"`
+	}
+	if err := c.call(ctx, http.MethodGet, issueURL, "/transitions", nil, &resp); err != nil {
+		return nil, fmt.Errorf("failed to get transitions: %w", err)
+	}
+
+	transitions := make([]Transition, 0, len(resp.Transitions))
+	for _, t := range resp.Transitions {
+		transitions = append(transitions, Transition{ID: t.ID, Name: t.Name, To: t.To.Name})
+	}
+	return transitions, nil
+}
+
+// TransitionIssue moves an issue through the transition with the given ID
+func (c *Client) TransitionIssue(ctx context.Context, issueURL, transitionID string) error {
+	body := map[string]any{"transition": map[string]string{"id": transitionID}}
+	if err := c.call(ctx, http.MethodPost, issueURL, "/transitions", body, nil); err != nil {
+		return fmt.Errorf("failed to transition issue: %w", err)
+	}
+	return nil
+}
+
+// AddComment posts a comment on an issue
+func (c *Client) AddComment(ctx context.Context, issueURL, body string) error {
+	if err := c.call(ctx, http.MethodPost, issueURL, "/comment", map[string]string{"body": body}, nil); err != nil {
+		return fmt.Errorf("failed to add comment: %w", err)
+	}
+	return nil
+}
+
+// UpdateLabels adds and removes labels of an issue
+func (c *Client) UpdateLabels(ctx context.Context, issueURL string, add, remove []string) error {
+	var ops []map[string]string
+	for _, l := range add {
+		ops = append(ops, map[string]string{"add": l})
+	}
+	for _, l := range remove {
+		ops = append(ops, map[string]string{"remove": l})
+	}
+
+	body := map[string]any{"update": map[string]any{"labels": ops}}
+	if err := c.call(ctx, http.MethodPut, issueURL, "", body, nil); err != nil {
+		return fmt.Errorf("failed to update labels: %w", err)
+	}
+	return nil
+}
+
+// call sends a request to a sub-resource of the issue behind issueURL,
+// encoding body and decoding the response into out when they are not nil
+func (c *Client) call(ctx context.Context, method, issueURL, path string, body, out any) error {
+	key, err := issueKeyFromURL(issueURL)
+	if err != nil {
+		return err
+	}
+
+	var reader io.Reader
+	if body != nil {
+		data, err := json.Marshal(body)
+		if err != nil {
+			return err
+		}
+		reader = bytes.NewReader(data)
+	}
+
+	req, err := c.newRequest(ctx, method, fmt.Sprintf("%s/rest/api/2/issue/%s%s", c.baseURL, key, path), reader)
+	if err != nil {
+		return err
+	}
+
+	resp, err := c.httpClient.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		msg, _ := io.ReadAll(io.LimitReader(resp.Body, 1024))
+		return fmt.Errorf("status code %d: %s", resp.StatusCode, strings.TrimSpace(string(msg)))
+	}
+
+	if out != nil {
+		if err := json.NewDecoder(resp.Body).Decode(out); err != nil {
+			return fmt.Errorf("failed to parse response: %w", err)
+		}
+	}
+	return nil
+}
+
+// GetTransitions lists the transitions of an issue on the instance serving it
+func (r *Router) GetTransitions(ctx context.Context, issueURL string) ([]Transition, error) {
+	w, err := r.writerFor(issueURL)
+	if err != nil {
+		return nil, err
+	}
+	return w.GetTransitions(ctx, issueURL)
+}
+
+// TransitionIssue transitions an issue on the instance serving it
+func (r *Router) TransitionIssue(ctx context.Context, issueURL, transitionID string) error {
+	w, err := r.writerFor(issueURL)
+	if err != nil {
+		return err
+	}
+	return w.TransitionIssue(ctx, issueURL, transitionID)
+}
+
+// AddComment comments on an issue on the instance serving it
+func (r *Router) AddComment(ctx context.Context, issueURL, body string) error {
+	w, err := r.writerFor(issueURL)
+	if err != nil {
+		return err
+	}
+	return w.AddComment(ctx, issueURL, body)
+}
+
+// UpdateLabels changes the labels of an issue on the instance serving it
+func (r *Router) UpdateLabels(ctx context.Context, issueURL string, add, remove []string) error {
+	w, err := r.writerFor(issueURL)
+	if err != nil {
+		return err
+	}
+	return w.UpdateLabels(ctx, issueURL, add, remove)
+}
+
+// writerFor returns the client of the instance serving an issue URL, if it
+// supports writes
+func (r *Router) writerFor(issueURL string) (IssueWriter, error) {
+	inst, err := r.instanceFor(issueURL)
+	if err != nil {
+		return nil, err
+	}
+	w, ok := inst.client.(IssueWriter)
+	if !ok {
+		return nil, fmt.Errorf("jira instance %q does not support changing issues", inst.name)
+	}
+	return w, nil
+}
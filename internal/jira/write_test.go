@@ -0,0 +1,87 @@
+package jira
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestClientWrites(t *testing.T) {
+	var requests []string
+	var bodies []map[string]any
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requests = append(requests, r.Method+" "+r.URL.Path)
+		if r.Method == http.MethodGet {
+			w.Write([]byte(`{"transitions": [{"id": "11", "name": "Start Review", "to": {"name": "Code Review"}}]}`))
+			return
+		}
+
+		var body map[string]any
+		require.NoError(t, json.NewDecoder(r.Body).Decode(&body))
+		bodies = append(bodies, body)
+		w.WriteHeader(http.StatusNoContent)
+	}))
+	defer server.Close()
+
+	c := NewClient(server.URL, WithToken("secret")).(IssueWriter)
+	issueURL := server.URL + "/browse/PROJ-1"
+	ctx := context.Background()
+
+	transitions, err := c.GetTransitions(ctx, issueURL)
+	require.NoError(t, err)
+	assert.Equal(t, []Transition{{ID: "11", Name: "Start Review", To: "Code Review"}}, transitions)
+
+	require.NoError(t, c.TransitionIssue(ctx, issueURL, "11"))
+	require.NoError(t, c.AddComment(ctx, issueURL, "All merged"))
+	require.NoError(t, c.UpdateLabels(ctx, issueURL, []string{"ready"}, []string{"blocked"}))
+
+	assert.Equal(t, []string{
+		"GET /rest/api/2/issue/PROJ-1/transitions",
+		"POST /rest/api/2/issue/PROJ-1/transitions",
+		"POST /rest/api/2/issue/PROJ-1/comment",
+		"PUT /rest/api/2/issue/PROJ-1",
+	}, requests)
+	assert.Equal(t, map[string]any{"id": "11"}, bodies[0]["transition"])
+	assert.Equal(t, "All merged", bodies[1]["body"])
+	assert.Equal(t, map[string]any{"labels": []any{
+		map[string]any{"add": "ready"},
+		map[string]any{"remove": "blocked"},
+	}}, bodies[2]["update"])
+}
+
+func TestClientWriteRejected(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		http.Error(w, `{"errorMessages": ["You do not have permission"]}`, http.StatusForbidden)
+	}))
+	defer server.Close()
+
+	c := NewClient(server.URL).(IssueWriter)
+	err := c.AddComment(context.Background(), server.URL+"/browse/PROJ-1", "hello")
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "403")
+	assert.Contains(t, err.Error(), "You do not have permission")
+}
+
+func TestRouterWrites(t *testing.T) {
+	mock := NewMockClient("New")
+	r := NewRouter()
+	require.NoError(t, r.Register("redhat", "https://issues.redhat.com", mock))
+
+	issueURL := "https://issues.redhat.com/browse/PROJ-1"
+	require.NoError(t, r.TransitionIssue(context.Background(), issueURL, "11"))
+	issue, err := r.GetIssue(context.Background(), issueURL)
+	require.NoError(t, err)
+	assert.Equal(t, "Code Review", issue.Status)
+
+	require.NoError(t, r.UpdateLabels(context.Background(), issueURL, []string{"a", "b"}, nil))
+	require.NoError(t, r.UpdateLabels(context.Background(), issueURL, []string{"c"}, []string{"a"}))
+	assert.Equal(t, []string{"b", "c"}, mock.Labels)
+
+	err = r.AddComment(context.Background(), "https://jira.example.com/browse/PROJ-1", "hello")
+	assert.Error(t, err)
+}
@@ -0,0 +1,30 @@
+package models
+
+import "time"
+
+// AuditEntry records a change DevTrackr made, or would have made in dry-run
+// mode, to an issue in Jira
+type AuditEntry struct {
+	ID        int64       `json:"id"`
+	IssueID   int64       `json:"issue_id"`
+	Action    AuditAction `json:"action"`
+	Details   string      `json:"details"` // What was sent, e.g. the transition or comment text
+	Actor     string      `json:"actor"`   // Who asked for the change, e.g. cli:alice
+	DryRun    bool        `json:"dry_run"`
+	Error     string      `json:"error,omitempty"` // Why Jira rejected the change
+	CreatedAt time.Time   `json:"created_at"`
+}
+
+// AuditAction represents the kinds of changes made to Jira issues
+type AuditAction string
+
+const (
+	AuditTransition AuditAction = "transition"
+	AuditComment    AuditAction = "comment"
+	AuditLabels     AuditAction = "labels"
+)
+
+// TableName returns the table name for the AuditEntry model
+func (AuditEntry) TableName() string {
+	return "jira_audit"
+}
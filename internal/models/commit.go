@@ -0,0 +1,31 @@
+package models
+
+import "time"
+
+// Commit represents a commit found in a local git repository that references an issue
+type Commit struct {
+	ID          int64     `json:"id"`
+	IssueID     int64     `json:"issue_id"`
+	Repository  string    `json:"repository"` // Path of the scanned repository
+	SHA         string    `json:"sha"`
+	Subject     string    `json:"subject"`
+	CommittedAt time.Time `json:"committed_at"`
+	Branches    []string  `json:"branches"` // Branches containing the commit
+	Tags        []string  `json:"tags"`     // Tags containing the commit
+	ScannedAt   time.Time `json:"scanned_at"`
+}
+
+// TableName returns the table name for the Commit model
+func (Commit) TableName() string {
+	return "issue_commits"
+}
+
+// OnBranch reports whether the commit is contained in the given branch
+func (c Commit) OnBranch(branch string) bool {
+	for _, b := range c.Branches {
+		if b == branch {
+			return true
+		}
+	}
+	return false
+}
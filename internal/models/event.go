@@ -0,0 +1,42 @@
+package models
+
+import "time"
+
+// Event is an entry of the timeline of a tracked issue
+type Event struct {
+	ID         int64     `json:"id"`
+	IssueID    int64     `json:"issue_id"`
+	Type       EventType `json:"type"`
+	Field      string    `json:"field"`  // Jira field that changed, e.g. status, or the pull request
+	From       string    `json:"from"`   // Value before the change
+	To         string    `json:"to"`     // Value after the change
+	Author     string    `json:"author"` // Who made the change, when known
+	Source     string    `json:"source"` // Where the change was observed, e.g. jira_webhook
+	OccurredAt time.Time `json:"occurred_at"`
+	CreatedAt  time.Time `json:"created_at"`
+}
+
+// EventType represents the kinds of timeline events
+type EventType string
+
+const (
+	EventStatusChanged EventType = "status_changed"
+	EventFieldChanged  EventType = "field_changed"
+	// EventPullRequestChanged records a pull request changing state. Field
+	// holds the pull request, e.g. org/repo#12.
+	EventPullRequestChanged EventType = "pull_request_changed"
+)
+
+// Sources of timeline events
+const (
+	EventSourcePolling       = "polling"
+	EventSourceJiraWebhook   = "jira_webhook"
+	EventSourceWriteBack     = "jira_writeback"
+	EventSourceForge         = "forge"
+	EventSourceGitHubWebhook = "github_webhook"
+)
+
+// TableName returns the table name for the Event model
+func (Event) TableName() string {
+	return "issue_events"
+}
@@ -0,0 +1,120 @@
+package models
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// FilterNone matches issues where a single-valued field is empty, e.g.
+// unassigned issues for the assignee
+const FilterNone = "none"
+
+// IssueFilter selects tracked issues by their Jira fields. Every set field
+// must match; a field lists the accepted values, compared case-insensitively.
+type IssueFilter struct {
+	Instance       []string
+	Project        []string
+	Status         []string
+	StatusCategory []string
+	Assignee       []string
+	Reporter       []string
+	Priority       []string
+	IssueType      []string
+	Resolution     []string
+	Sprint         []string
+	EpicLink       []string
+	Label          []string // Issues with any of the labels
+	Component      []string // Issues in any of the components
+	FixVersion     []string // Issues targeting any of the releases
+	// Attributes filters on custom field attributes by name. Array
+	// attributes match when any of their values is accepted.
+	Attributes map[string][]string
+}
+
+// Matches reports whether an issue passes the filter
+func (f IssueFilter) Matches(issue *Issue) bool {
+	return matchesOne(f.Instance, issue.Instance) &&
+		matchesOne(f.Project, issue.Project()) &&
+		matchesOne(f.Status, issue.Status) &&
+		matchesOne(f.StatusCategory, issue.StatusCategory) &&
+		matchesOne(f.Assignee, issue.Assignee) &&
+		matchesOne(f.Reporter, issue.Reporter) &&
+		matchesOne(f.Priority, issue.Priority) &&
+		matchesOne(f.IssueType, issue.IssueType) &&
+		matchesOne(f.Resolution, issue.Resolution) &&
+		matchesOne(f.Sprint, issue.Sprint) &&
+		matchesOne(f.EpicLink, issue.EpicLink) &&
+		matchesAny(f.Label, issue.Labels) &&
+		matchesAny(f.Component, issue.Components) &&
+		matchesRelease(f.FixVersion, issue) &&
+		matchesAttributes(f.Attributes, issue.Attributes)
+}
+
+// matchesAttributes reports whether every filtered attribute has an accepted value
+func matchesAttributes(accepted map[string][]string, attrs map[string]any) bool {
+	for name, values := range accepted {
+		var found []string
+		switch v := attrs[name].(type) {
+		case nil:
+		case []any:
+			for _, item := range v {
+				found = append(found, fmt.Sprint(item))
+			}
+		case float64:
+			found = append(found, strconv.FormatFloat(v, 'f', -1, 64))
+		default:
+			found = append(found, fmt.Sprint(v))
+		}
+
+		if len(found) == 0 {
+			if !matchesOne(values, "") {
+				return false
+			}
+			continue
+		}
+		if !matchesAny(values, found) {
+			return false
+		}
+	}
+	return true
+}
+
+// matchesOne reports whether a single-valued field has one of the accepted values
+func matchesOne(accepted []string, value string) bool {
+	if len(accepted) == 0 {
+		return true
+	}
+	for _, a := range accepted {
+		if strings.EqualFold(a, value) || (a == FilterNone && value == "") {
+			return true
+		}
+	}
+	return false
+}
+
+// matchesAny reports whether a list field holds any of the accepted values
+func matchesAny(accepted, values []string) bool {
+	if len(accepted) == 0 {
+		return true
+	}
+	for _, v := range values {
+		if matchesOne(accepted, v) {
+			return true
+		}
+	}
+	return false
+}
+
+// matchesRelease reports whether an issue targets any of the accepted releases
+func matchesRelease(accepted []string, issue *Issue) bool {
+	if len(accepted) == 0 {
+		return true
+	}
+	for _, version := range accepted {
+		if issue.TargetsRelease(version) {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,48 @@
+package models
+
+// ChildKind represents how an issue sits below its parent in Jira
+type ChildKind string
+
+const (
+	ChildSubtask ChildKind = "subtask"    // Sub-task of the parent
+	ChildEpic    ChildKind = "epic_child" // Issue in the parent epic
+)
+
+// IssueChild is an issue below a tracked issue in Jira. The child does not
+// need to be tracked itself; its title and status are the ones seen when the
+// parent was last read from Jira.
+type IssueChild struct {
+	ID             int64     `json:"id"`
+	IssueID        int64     `json:"issue_id"` // Tracked parent issue
+	Key            string    `json:"key"`
+	Kind           ChildKind `json:"kind"`
+	Title          string    `json:"title"`
+	Status         string    `json:"status"`
+	StatusCategory string    `json:"status_category"`
+}
+
+// TableName returns the table name for the IssueChild model
+func (IssueChild) TableName() string {
+	return "issue_children"
+}
+
+// IssueLink is a Jira issue link from a tracked issue to another issue, as
+// seen when the tracked issue was last read from Jira
+type IssueLink struct {
+	ID       int64  `json:"id"`
+	IssueID  int64  `json:"issue_id"` // Tracked issue the link was read from
+	Type     string `json:"type"`     // Name of the link type, e.g. Blocks
+	Relation string `json:"relation"` // Link as read from the tracked issue, e.g. "is blocked by"
+	// Outward reports whether the tracked issue is on the outward side of
+	// the link, e.g. the issue that blocks, or the clone of the other issue
+	Outward        bool   `json:"outward"`
+	Key            string `json:"key"`
+	Title          string `json:"title"`
+	Status         string `json:"status"`
+	StatusCategory string `json:"status_category"`
+}
+
+// TableName returns the table name for the IssueLink model
+func (IssueLink) TableName() string {
+	return "issue_links"
+}
@@ -1,20 +1,128 @@
 package models
 
 import (
+	"slices"
+	"strings"
 	"time"
 )
 
 // Issue represents a Jira issue being tracked
 type Issue struct {
 	ID              int64     `json:"id"`
+	Instance        string    `json:"instance"` // Name of the Jira instance the issue belongs to
 	Key             string    `json:"key"`
 	Title           string    `json:"title"`
 	Status          string    `json:"status"`
+	StatusCategory  string    `json:"status_category"` // Jira status category of Status, one of the StatusCategory constants
 	JiraURL         string    `json:"jira_url"`
 	CreatedAt       time.Time `json:"created_at"`
 	UpdatedAt       time.Time `json:"updated_at"`
-	PollingInterval int       `json:"polling_interval"` // Interval in minutes, 0 means no polling
+	PollingInterval int       `json:"polling_interval"` // Interval in seconds, 0 means the default interval
 	LastPolledAt    time.Time `json:"last_polled_at"`
+	FixVersions     []string  `json:"fix_versions"`     // Releases the issue is targeted to be fixed in
+	AffectsVersions []string  `json:"affects_versions"` // Releases the issue was found in
+	Components      []string  `json:"components"`       // Jira components the issue belongs to
+	LastWebhookAt   time.Time `json:"last_webhook_at"`  // Last update received through a Jira webhook
+	Description     string    `json:"description"`
+	Assignee        string    `json:"assignee"` // Display name of the assignee, empty when unassigned
+	Reporter        string    `json:"reporter"`
+	Priority        string    `json:"priority"`
+	IssueType       string    `json:"issue_type"`
+	Labels          []string  `json:"labels"`
+	Sprint          string    `json:"sprint"`    // Active sprint, or the last one the issue was part of
+	EpicLink        string    `json:"epic_link"` // Key of the epic the issue belongs to
+	Resolution      string    `json:"resolution"`
+	DueDate         time.Time `json:"due_date"`
+	JiraUpdatedAt   time.Time `json:"jira_updated_at"` // Last update of the issue in Jira
+	// Attributes holds the custom fields mapped in the configuration of the
+	// Jira instance, keyed by attribute name. Values are strings, float64
+	// numbers or []any of strings.
+	Attributes map[string]any `json:"attributes,omitempty"`
+	// RenderedAttributes holds the attributes formatted for display, keyed by
+	// their label. It is computed when the issue is read and never stored.
+	RenderedAttributes map[string]string `json:"rendered_attributes,omitempty"`
+
+	// Polling is the effective polling schedule of the issue. It is computed
+	// when the issue is read and never stored.
+	Polling *PollingDecision `json:"polling,omitempty"`
+
+	// Children and Links are the issues below and related to the issue in
+	// Jira. They are stored in their own tables.
+	Children []IssueChild `json:"children,omitempty"`
+	Links    []IssueLink  `json:"links,omitempty"`
+}
+
+// Jira status categories, as reported in the statusCategory key of a status
+const (
+	StatusCategoryToDo       = "new"
+	StatusCategoryInProgress = "indeterminate"
+	StatusCategoryDone       = "done"
+)
+
+// PollingDecision is the polling interval chosen for an issue and why
+type PollingDecision struct {
+	Interval int    `json:"interval"` // Interval in seconds, 0 when polling stopped
+	Reason   string `json:"reason"`
+	Stopped  bool   `json:"stopped"` // The issue is no longer polled
+}
+
+// Duration returns the polling interval as a duration
+func (d PollingDecision) Duration() time.Duration {
+	return time.Duration(d.Interval) * time.Second
+}
+
+// QualifiedKey returns the key of the issue prefixed with its Jira instance
+// (e.g. "redhat:OCPBUGS-1234"), or the bare key when the instance is unknown
+func (i Issue) QualifiedKey() string {
+	return QualifyKey(i.Instance, i.Key)
+}
+
+// QualifyKey prefixes an issue key with the name of its Jira instance
+func QualifyKey(instance, key string) string {
+	if instance == "" {
+		return key
+	}
+	return instance + ":" + key
+}
+
+// SplitQualifiedKey splits a key returned by QualifiedKey into the instance
+// name, empty for bare keys, and the issue key
+func SplitQualifiedKey(qualified string) (instance, key string) {
+	if instance, key, ok := strings.Cut(qualified, ":"); ok {
+		return instance, key
+	}
+	return "", qualified
+}
+
+// Project returns the Jira project key of the issue (e.g. "OCPBUGS" for "OCPBUGS-1234")
+func (i Issue) Project() string {
+	if idx := strings.LastIndex(i.Key, "-"); idx > 0 {
+		return i.Key[:idx]
+	}
+	return i.Key
+}
+
+// TargetsRelease reports whether any of the issue fix versions is the given
+// release or one of its patch releases (e.g. "4.16.0" and "4.16.z" for "4.16")
+func (i Issue) TargetsRelease(version string) bool {
+	for _, v := range i.FixVersions {
+		if v == version || strings.HasPrefix(v, version+".") {
+			return true
+		}
+	}
+	return false
+}
+
+// ApplyLabels returns labels with the labels in add appended, unless already
+// present, and the labels in remove dropped
+func ApplyLabels(labels, add, remove []string) []string {
+	result := make([]string, 0, len(labels)+len(add))
+	for _, l := range append(append([]string{}, labels...), add...) {
+		if !slices.Contains(remove, l) && !slices.Contains(result, l) {
+			result = append(result, l)
+		}
+	}
+	return result
 }
 
 // TableName returns the table name for the Issue model
@@ -91,3 +91,88 @@ func TestSubscriptionModel(t *testing.T) {
 	assert.Equal(t, now, sub.CreatedAt)
 	assert.Equal(t, now, sub.UpdatedAt)
 }
+
+func TestIssueTargetsRelease(t *testing.T) {
+	issue := Issue{FixVersions: []string{"4.16.0", "4.15.z"}}
+
+	assert.True(t, issue.TargetsRelease("4.16"))
+	assert.True(t, issue.TargetsRelease("4.15"))
+	assert.True(t, issue.TargetsRelease("4.16.0"))
+	assert.False(t, issue.TargetsRelease("4.1"))
+	assert.False(t, issue.TargetsRelease("4.14"))
+}
+
+func TestIssueProject(t *testing.T) {
+	assert.Equal(t, "OCPBUGS", Issue{Key: "OCPBUGS-1234"}.Project())
+	assert.Equal(t, "MY-PROJ", Issue{Key: "MY-PROJ-1"}.Project())
+	assert.Equal(t, "NOKEY", Issue{Key: "NOKEY"}.Project())
+}
+
+func TestRollupCIStatus(t *testing.T) {
+	assert.Equal(t, CIStatusUnknown, RollupCIStatus(nil))
+	assert.Equal(t, CIStatusSuccess, RollupCIStatus([]CIStatus{CIStatusSuccess, CIStatusSuccess}))
+	assert.Equal(t, CIStatusPending, RollupCIStatus([]CIStatus{CIStatusSuccess, CIStatusPending}))
+	assert.Equal(t, CIStatusFailure, RollupCIStatus([]CIStatus{CIStatusPending, CIStatusFailure, CIStatusSuccess}))
+}
+
+func TestPullRequestMergeBlockers(t *testing.T) {
+	conflicting := false
+
+	merged := PullRequest{Status: PRStatusMerged, CIStatus: CIStatusFailure}
+	assert.Empty(t, merged.MergeBlockers())
+
+	closed := PullRequest{Status: PRStatusClosed}
+	assert.Equal(t, []string{"pull request was closed without merging"}, closed.MergeBlockers())
+
+	ready := PullRequest{Status: PRStatusApproved, CIStatus: CIStatusSuccess, Approvals: 2, Labels: []string{"lgtm"}}
+	assert.Empty(t, ready.MergeBlockers())
+
+	blocked := PullRequest{
+		Status:            PRStatusReview,
+		CIStatus:          CIStatusFailure,
+		FailingChecks:     []string{"unit", "e2e"},
+		RequiredReviewers: []string{"alice"},
+		Mergeable:         &conflicting,
+		Labels:            []string{"lgtm", "do-not-merge/hold"},
+	}
+	assert.Equal(t, []string{
+		"CI is failing: unit, e2e",
+		"waiting for review from alice",
+		"no approvals yet",
+		"has merge conflicts",
+		"label do-not-merge/hold blocks merging",
+	}, blocked.MergeBlockers())
+}
+
+func TestIssueFilterMatches(t *testing.T) {
+	issue := &Issue{
+		Key:         "OCPBUGS-1234",
+		Assignee:    "Alice Example",
+		Priority:    "Critical",
+		Labels:      []string{"triaged", "ux"},
+		Components:  []string{"HyperShift"},
+		FixVersions: []string{"4.16.0"},
+	}
+
+	assert.True(t, IssueFilter{}.Matches(issue))
+	assert.True(t, IssueFilter{Assignee: []string{"bob", "alice example"}, Priority: []string{"critical"}}.Matches(issue))
+	assert.True(t, IssueFilter{Label: []string{"ux"}, Project: []string{"OCPBUGS"}, FixVersion: []string{"4.16"}}.Matches(issue))
+	assert.True(t, IssueFilter{Sprint: []string{FilterNone}}.Matches(issue))
+	assert.False(t, IssueFilter{Assignee: []string{FilterNone}}.Matches(issue))
+	assert.False(t, IssueFilter{Assignee: []string{"Alice Example"}, Priority: []string{"Minor"}}.Matches(issue))
+	assert.False(t, IssueFilter{Component: []string{"Networking"}}.Matches(issue))
+}
+
+func TestIssueFilterMatchesAttributes(t *testing.T) {
+	issue := &Issue{Attributes: map[string]any{
+		"story_points":   3.0,
+		"qa_contact":     "Carol Example",
+		"target_version": []any{"4.16.0", "4.15.z"},
+	}}
+
+	assert.True(t, IssueFilter{Attributes: map[string][]string{"story_points": {"3"}}}.Matches(issue))
+	assert.True(t, IssueFilter{Attributes: map[string][]string{"target_version": {"4.15.z"}, "qa_contact": {"carol example"}}}.Matches(issue))
+	assert.True(t, IssueFilter{Attributes: map[string][]string{"release_note": {FilterNone}}}.Matches(issue))
+	assert.False(t, IssueFilter{Attributes: map[string][]string{"release_note": {"anything"}}}.Matches(issue))
+	assert.False(t, IssueFilter{Attributes: map[string][]string{"story_points": {"5"}}}.Matches(issue))
+}
@@ -1,21 +1,34 @@
 package models
 
-import "time"
+import (
+	"fmt"
+	"strings"
+	"time"
+)
 
 // PullRequest represents a pull request associated with an issue
 type PullRequest struct {
-	ID           int64     `json:"id"`
-	IssueID      int64     `json:"issue_id"`
-	Number       int       `json:"number"`         // PR number in the repository
-	Repository   string    `json:"repository"`     // Repository name
-	Title        string    `json:"title"`          // PR title
-	URL          string    `json:"url"`            // PR URL
-	Status       PRStatus  `json:"status"`         // Current status
-	TargetBranch string    `json:"target_branch"`  // Branch where the PR is targeting
-	IsBackport   bool      `json:"is_backport"`    // Whether this is a backport PR
-	OriginalPRID *int64    `json:"original_pr_id"` // Reference to the original PR if this is a backport
-	CreatedAt    time.Time `json:"created_at"`
-	UpdatedAt    time.Time `json:"updated_at"`
+	ID           int64    `json:"id"`
+	IssueID      int64    `json:"issue_id"`
+	Number       int      `json:"number"`         // PR number in the repository
+	Repository   string   `json:"repository"`     // Repository name
+	Title        string   `json:"title"`          // PR title
+	Body         string   `json:"body"`           // PR description
+	Labels       []string `json:"labels"`         // Labels set on the PR
+	URL          string   `json:"url"`            // PR URL
+	Status       PRStatus `json:"status"`         // Current status
+	TargetBranch string   `json:"target_branch"`  // Branch where the PR is targeting
+	IsBackport   bool     `json:"is_backport"`    // Whether this is a backport PR
+	OriginalPRID *int64   `json:"original_pr_id"` // Reference to the original PR if this is a backport
+
+	CIStatus          CIStatus `json:"ci_status"`          // Rollup of the CI checks on the head commit
+	FailingChecks     []string `json:"failing_checks"`     // Names of the failing CI checks
+	RequiredReviewers []string `json:"required_reviewers"` // Reviewers whose review is still requested
+	Approvals         int      `json:"approvals"`          // Number of approving reviews
+	Mergeable         *bool    `json:"mergeable"`          // Whether the PR merges cleanly, nil if unknown
+
+	CreatedAt time.Time `json:"created_at"`
+	UpdatedAt time.Time `json:"updated_at"`
 }
 
 // PRStatus represents the possible states of a pull request
@@ -30,6 +43,87 @@ const (
 	PRStatusApproved PRStatus = "approved"
 )
 
+// CIStatus represents the rollup of the CI checks of a pull request
+type CIStatus string
+
+const (
+	CIStatusUnknown CIStatus = ""
+	CIStatusPending CIStatus = "pending"
+	CIStatusSuccess CIStatus = "success"
+	CIStatusFailure CIStatus = "failure"
+)
+
+// RollupCIStatus combines the states of individual checks: any failure fails
+// the rollup, otherwise any pending check keeps it pending
+func RollupCIStatus(states []CIStatus) CIStatus {
+	result := CIStatusUnknown
+	for _, state := range states {
+		switch state {
+		case CIStatusFailure:
+			return CIStatusFailure
+		case CIStatusPending:
+			result = CIStatusPending
+		case CIStatusSuccess:
+			if result == CIStatusUnknown {
+				result = CIStatusSuccess
+			}
+		}
+	}
+	return result
+}
+
+// blockingLabelPrefixes lists label prefixes that prevent merging by convention
+var blockingLabelPrefixes = []string{"do-not-merge", "needs-rebase"}
+
+// MergeBlockers explains why a pull request is not merged yet. It returns
+// nothing for merged pull requests and for pull requests with nothing known
+// to block them.
+func (pr *PullRequest) MergeBlockers() []string {
+	var blockers []string
+
+	switch pr.Status {
+	case PRStatusMerged:
+		return nil
+	case PRStatusClosed:
+		return []string{"pull request was closed without merging"}
+	case PRStatusDraft:
+		blockers = append(blockers, "pull request is a draft")
+	}
+
+	switch pr.CIStatus {
+	case CIStatusFailure:
+		if len(pr.FailingChecks) > 0 {
+			blockers = append(blockers, fmt.Sprintf("CI is failing: %s", strings.Join(pr.FailingChecks, ", ")))
+		} else {
+			blockers = append(blockers, "CI is failing")
+		}
+	case CIStatusPending:
+		blockers = append(blockers, "CI is still running")
+	}
+
+	if len(pr.RequiredReviewers) > 0 {
+		blockers = append(blockers, fmt.Sprintf("waiting for review from %s", strings.Join(pr.RequiredReviewers, ", ")))
+	}
+	if pr.Status != PRStatusApproved && pr.Approvals == 0 {
+		blockers = append(blockers, "no approvals yet")
+	}
+
+	if pr.Mergeable != nil && !*pr.Mergeable {
+		blockers = append(blockers, "has merge conflicts")
+	}
+
+	for _, label := range pr.Labels {
+		for _, prefix := range blockingLabelPrefixes {
+			if strings.HasPrefix(label, prefix) {
+				blockers = append(blockers, fmt.Sprintf("label %s blocks merging", label))
+				break
+			}
+		}
+	}
+
+	return blockers
+}
+
 // TableName returns the table name for the PullRequest model
 func (PullRequest) TableName() string {
 	return "pull_requests"
@@ -0,0 +1,165 @@
+package polling
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/jparrill/devtrackr/internal/models"
+)
+
+// Policy sets how often issues are polled depending on their lifecycle.
+// Zero durations fall back to the default interval, except for Active and
+// StopAfter where zero disables the rule.
+type Policy struct {
+	// ToDo is the interval of issues whose status is in the To Do category
+	ToDo time.Duration `yaml:"todo"`
+	// InProgress is the interval of issues in the In Progress category, or
+	// whose category is unknown
+	InProgress time.Duration `yaml:"in_progress"`
+	// Done is the interval of issues in the Done category
+	Done time.Duration `yaml:"done"`
+	// Active is the interval of issues updated within ActiveWindow
+	Active       time.Duration `yaml:"active"`
+	ActiveWindow time.Duration `yaml:"active_window"`
+	// StopAfter stops polling Done issues with every pull request merged
+	// once they have not changed for this long
+	StopAfter time.Duration `yaml:"stop_after"`
+}
+
+// Config holds the default polling interval and the policies applied on top of it
+type Config struct {
+	// Interval is the default polling interval
+	Interval time.Duration `yaml:"interval"`
+	Policy   `yaml:",inline"`
+	// Projects overrides the policy of single Jira projects, keyed by their
+	// key prefix (e.g. OCPBUGS). Unset fields keep the global value.
+	Projects map[string]Policy `yaml:"projects"`
+}
+
+// DefaultConfig returns the policies used when none are configured
+func DefaultConfig() Config {
+	return Config{
+		Interval: 5 * time.Minute,
+		Policy: Policy{
+			ToDo:         30 * time.Minute,
+			Done:         6 * time.Hour,
+			Active:       2 * time.Minute,
+			ActiveWindow: time.Hour,
+			StopAfter:    14 * 24 * time.Hour,
+		},
+		Projects: map[string]Policy{},
+	}
+}
+
+// Engine computes the effective polling interval of issues
+type Engine struct {
+	cfg             Config
+	webhookInterval time.Duration
+}
+
+// NewEngine creates a policy engine. Issues receiving Jira webhook updates
+// are polled at most every webhookInterval; zero disables that rule.
+func NewEngine(cfg Config, webhookInterval time.Duration) *Engine {
+	if cfg.Interval <= 0 {
+		cfg.Interval = DefaultConfig().Interval
+	}
+	return &Engine{cfg: cfg, webhookInterval: webhookInterval}
+}
+
+// Interval returns the default polling interval
+func (e *Engine) Interval() time.Duration {
+	return e.cfg.Interval
+}
+
+// PolicyFor returns the policy of a project, merged with the global one
+func (e *Engine) PolicyFor(project string) Policy {
+	p := e.cfg.Policy
+	override, ok := e.cfg.Projects[project]
+	if !ok {
+		return p
+	}
+
+	for _, f := range []struct {
+		dst *time.Duration
+		src time.Duration
+	}{
+		{&p.ToDo, override.ToDo},
+		{&p.InProgress, override.InProgress},
+		{&p.Done, override.Done},
+		{&p.Active, override.Active},
+		{&p.ActiveWindow, override.ActiveWindow},
+		{&p.StopAfter, override.StopAfter},
+	} {
+		if f.src != 0 {
+			*f.dst = f.src
+		}
+	}
+	return p
+}
+
+// NeedsPullRequests reports whether Evaluate depends on the issue having
+// unmerged pull requests, so callers can skip looking them up otherwise
+func (e *Engine) NeedsPullRequests(issue *models.Issue) bool {
+	return issue.PollingInterval == 0 && issue.StatusCategory == models.StatusCategoryDone
+}
+
+// Evaluate picks the polling interval of an issue. A manual interval set on
+// the issue always wins, even over the webhook interval; otherwise the
+// interval follows its status category and recent activity, and polling stops
+// for Done issues whose pull requests are all merged once they have been
+// quiet for the policy StopAfter.
+func (e *Engine) Evaluate(issue *models.Issue, hasUnmerged bool, now time.Time) models.PollingDecision {
+	if issue.PollingInterval > 0 {
+		return models.PollingDecision{
+			Interval: issue.PollingInterval,
+			Reason:   "interval set manually",
+		}
+	}
+
+	p := e.PolicyFor(issue.Project())
+	quiet := now.Sub(issue.UpdatedAt)
+
+	if issue.StatusCategory == models.StatusCategoryDone && !hasUnmerged {
+		if p.StopAfter > 0 && quiet >= p.StopAfter {
+			return models.PollingDecision{
+				Reason:  fmt.Sprintf("done with all pull requests merged and unchanged for %s", p.StopAfter),
+				Stopped: true,
+			}
+		}
+		return e.relax(issue, e.orDefault(p.Done), "status category is done")
+	}
+
+	if p.Active > 0 && quiet < p.ActiveWindow {
+		return e.relax(issue, p.Active, fmt.Sprintf("updated within the last %s", p.ActiveWindow))
+	}
+
+	switch issue.StatusCategory {
+	case models.StatusCategoryToDo:
+		return e.relax(issue, e.orDefault(p.ToDo), "status category is to do")
+	case models.StatusCategoryDone:
+		return e.relax(issue, e.orDefault(p.InProgress), "done with unmerged pull requests")
+	default:
+		return e.relax(issue, e.orDefault(p.InProgress), "status category is in progress")
+	}
+}
+
+// orDefault returns d, or the default interval when d is not set
+func (e *Engine) orDefault(d time.Duration) time.Duration {
+	if d <= 0 {
+		return e.cfg.Interval
+	}
+	return d
+}
+
+// relax builds a decision, lengthening the interval of issues updated through
+// webhooks since polling only reconciles missed deliveries for them
+func (e *Engine) relax(issue *models.Issue, interval time.Duration, reason string) models.PollingDecision {
+	if !issue.LastWebhookAt.IsZero() && interval < e.webhookInterval {
+		interval = e.webhookInterval
+		reason += ", relaxed because updates arrive through webhooks"
+	}
+	return models.PollingDecision{
+		Interval: int(interval / time.Second),
+		Reason:   reason,
+	}
+}
@@ -0,0 +1,95 @@
+package polling
+
+import (
+	"testing"
+	"time"
+
+	"github.com/jparrill/devtrackr/internal/models"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestEvaluate(t *testing.T) {
+	now := time.Now()
+	cfg := DefaultConfig()
+	cfg.Projects["OCPBUGS"] = Policy{Done: time.Hour}
+	engine := NewEngine(cfg, 2*time.Hour)
+
+	quiet := now.Add(-48 * time.Hour)
+	longDone := now.Add(-30 * 24 * time.Hour)
+
+	tests := []struct {
+		name        string
+		issue       models.Issue
+		hasUnmerged bool
+		interval    time.Duration
+		stopped     bool
+	}{
+		{
+			name:     "manual interval wins",
+			issue:    models.Issue{Key: "HOSTEDCP-1", StatusCategory: models.StatusCategoryDone, PollingInterval: 60, UpdatedAt: longDone},
+			interval: time.Minute,
+		},
+		{
+			name:     "manual interval wins over webhook updates",
+			issue:    models.Issue{Key: "HOSTEDCP-1", StatusCategory: models.StatusCategoryToDo, PollingInterval: 120, UpdatedAt: quiet, LastWebhookAt: now},
+			interval: 2 * time.Minute,
+		},
+		{
+			name:     "to do",
+			issue:    models.Issue{Key: "HOSTEDCP-1", StatusCategory: models.StatusCategoryToDo, UpdatedAt: quiet},
+			interval: 30 * time.Minute,
+		},
+		{
+			name:     "in progress falls back to the default interval",
+			issue:    models.Issue{Key: "HOSTEDCP-1", StatusCategory: models.StatusCategoryInProgress, UpdatedAt: quiet},
+			interval: 5 * time.Minute,
+		},
+		{
+			name:     "recent activity",
+			issue:    models.Issue{Key: "HOSTEDCP-1", StatusCategory: models.StatusCategoryToDo, UpdatedAt: now.Add(-10 * time.Minute)},
+			interval: 2 * time.Minute,
+		},
+		{
+			name:     "done",
+			issue:    models.Issue{Key: "HOSTEDCP-1", StatusCategory: models.StatusCategoryDone, UpdatedAt: quiet},
+			interval: 6 * time.Hour,
+		},
+		{
+			name:     "done with a project policy",
+			issue:    models.Issue{Key: "OCPBUGS-1", StatusCategory: models.StatusCategoryDone, UpdatedAt: quiet},
+			interval: time.Hour,
+		},
+		{
+			name:        "done with unmerged pull requests",
+			issue:       models.Issue{Key: "HOSTEDCP-1", StatusCategory: models.StatusCategoryDone, UpdatedAt: longDone},
+			hasUnmerged: true,
+			interval:    5 * time.Minute,
+		},
+		{
+			name:    "done and quiet stops polling",
+			issue:   models.Issue{Key: "HOSTEDCP-1", StatusCategory: models.StatusCategoryDone, UpdatedAt: longDone},
+			stopped: true,
+		},
+		{
+			name:     "webhook updates relax the interval",
+			issue:    models.Issue{Key: "HOSTEDCP-1", StatusCategory: models.StatusCategoryToDo, UpdatedAt: quiet, LastWebhookAt: now},
+			interval: 2 * time.Hour,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			decision := engine.Evaluate(&tt.issue, tt.hasUnmerged, now)
+			assert.Equal(t, tt.stopped, decision.Stopped)
+			assert.Equal(t, tt.interval, decision.Duration())
+			assert.NotEmpty(t, decision.Reason)
+		})
+	}
+}
+
+func TestNeedsPullRequests(t *testing.T) {
+	engine := NewEngine(DefaultConfig(), 0)
+	assert.True(t, engine.NeedsPullRequests(&models.Issue{StatusCategory: models.StatusCategoryDone}))
+	assert.False(t, engine.NeedsPullRequests(&models.Issue{StatusCategory: models.StatusCategoryDone, PollingInterval: 60}))
+	assert.False(t, engine.NeedsPullRequests(&models.Issue{StatusCategory: models.StatusCategoryInProgress}))
+}
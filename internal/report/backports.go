@@ -0,0 +1,149 @@
+package report
+
+import (
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"io"
+	"strings"
+	"text/tabwriter"
+	"time"
+)
+
+// MatrixCell is the backport state of an issue on a single branch
+type MatrixCell struct {
+	Expected    bool            `json:"expected"`               // Whether the project expects a fix on this branch
+	PullRequest *PullRequestRef `json:"pull_request,omitempty"` // Most relevant pull request on the branch
+	Count       int             `json:"count"`                  // Number of pull requests targeting the branch
+}
+
+// Missing reports whether an expected branch has no pull request
+func (c MatrixCell) Missing() bool {
+	return c.Expected && c.PullRequest == nil
+}
+
+// String returns the cell text, e.g. "#123 merged", "missing" or "-"
+func (c MatrixCell) String() string {
+	switch {
+	case c.PullRequest != nil:
+		text := fmt.Sprintf("#%d %s", c.PullRequest.Number, c.PullRequest.Status)
+		if c.Count > 1 {
+			text += fmt.Sprintf(" (+%d)", c.Count-1)
+		}
+		return text
+	case c.Expected:
+		return "missing"
+	default:
+		return "-"
+	}
+}
+
+// MatrixRow holds the backport state of a tracked issue across branches
+type MatrixRow struct {
+	Key     string                `json:"key"`
+	Title   string                `json:"title"`
+	Status  string                `json:"status"`
+	JiraURL string                `json:"jira_url"`
+	Cells   map[string]MatrixCell `json:"cells"` // Keyed by branch
+}
+
+// BackportMatrix shows the pull requests of every tracked issue per release branch
+type BackportMatrix struct {
+	Branches    []string    `json:"branches"`
+	GeneratedAt time.Time   `json:"generated_at"`
+	Rows        []MatrixRow `json:"rows"`
+}
+
+// RenderBackports writes the backport matrix in the requested format
+func RenderBackports(w io.Writer, m *BackportMatrix, format Format) error {
+	switch format {
+	case FormatJSON:
+		enc := json.NewEncoder(w)
+		enc.SetIndent("", "  ")
+		return enc.Encode(m)
+	case FormatTable:
+		return renderBackportsTable(w, m)
+	case FormatCSV:
+		return renderBackportsCSV(w, m)
+	case FormatMarkdown:
+		return renderBackportsMarkdown(w, m)
+	default:
+		return fmt.Errorf("unsupported backport matrix format: %s", format)
+	}
+}
+
+// renderBackportsTable writes the matrix as an aligned plain text table
+func renderBackportsTable(w io.Writer, m *BackportMatrix) error {
+	tw := tabwriter.NewWriter(w, 0, 0, 2, ' ', 0)
+
+	header := append([]string{"ISSUE", "STATUS"}, m.Branches...)
+	fmt.Fprintln(tw, strings.Join(header, "\t"))
+
+	for _, row := range m.Rows {
+		fmt.Fprintln(tw, strings.Join(row.values(m.Branches), "\t"))
+	}
+
+	return tw.Flush()
+}
+
+// renderBackportsCSV writes the matrix as CSV with one column per branch
+func renderBackportsCSV(w io.Writer, m *BackportMatrix) error {
+	cw := csv.NewWriter(w)
+
+	header := append([]string{"issue", "status"}, m.Branches...)
+	if err := cw.Write(header); err != nil {
+		return err
+	}
+
+	for _, row := range m.Rows {
+		if err := cw.Write(row.values(m.Branches)); err != nil {
+			return err
+		}
+	}
+
+	cw.Flush()
+	return cw.Error()
+}
+
+// renderBackportsMarkdown writes the matrix as a Markdown table
+func renderBackportsMarkdown(w io.Writer, m *BackportMatrix) error {
+	var b strings.Builder
+
+	b.WriteString("| Issue | Status |")
+	for _, branch := range m.Branches {
+		fmt.Fprintf(&b, " %s |", branch)
+	}
+	b.WriteString("\n|-------|--------|")
+	for range m.Branches {
+		b.WriteString("---|")
+	}
+	b.WriteString("\n")
+
+	for _, row := range m.Rows {
+		fmt.Fprintf(&b, "| [%s](%s) | %s |", row.Key, row.JiraURL, row.Status)
+		for _, branch := range m.Branches {
+			cell := row.Cells[branch]
+			switch {
+			case cell.PullRequest != nil:
+				fmt.Fprintf(&b, " [%s](%s) |", cell, cell.PullRequest.URL)
+			case cell.Missing():
+				fmt.Fprintf(&b, " **%s** |", cell)
+			default:
+				fmt.Fprintf(&b, " %s |", cell)
+			}
+		}
+		b.WriteString("\n")
+	}
+
+	_, err := io.WriteString(w, b.String())
+	return err
+}
+
+// values returns the row cells in branch order, prefixed by the issue key and status
+func (r MatrixRow) values(branches []string) []string {
+	values := []string{r.Key, r.Status}
+	for _, branch := range branches {
+		values = append(values, r.Cells[branch].String())
+	}
+	return values
+}
@@ -0,0 +1,56 @@
+package report
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/jparrill/devtrackr/internal/models"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func testBackportMatrix() *BackportMatrix {
+	return &BackportMatrix{
+		Branches: []string{"main", "release-4.16"},
+		Rows: []MatrixRow{
+			{
+				Key:     "TEST-1",
+				Status:  "POST",
+				JiraURL: "https://issues.redhat.com/browse/TEST-1",
+				Cells: map[string]MatrixCell{
+					"main": {
+						Expected:    true,
+						Count:       1,
+						PullRequest: &PullRequestRef{Number: 10, URL: "https://github.com/org/repo/pull/10", Status: models.PRStatusMerged},
+					},
+					"release-4.16": {Expected: true},
+				},
+			},
+		},
+	}
+}
+
+func TestRenderBackportsCSV(t *testing.T) {
+	var buf bytes.Buffer
+	require.NoError(t, RenderBackports(&buf, testBackportMatrix(), FormatCSV))
+	assert.Equal(t, "issue,status,main,release-4.16\nTEST-1,POST,#10 merged,missing\n", buf.String())
+}
+
+func TestRenderBackportsTable(t *testing.T) {
+	var buf bytes.Buffer
+	require.NoError(t, RenderBackports(&buf, testBackportMatrix(), FormatTable))
+	assert.Contains(t, buf.String(), "ISSUE   STATUS  main        release-4.16")
+	assert.Contains(t, buf.String(), "TEST-1  POST    #10 merged  missing")
+}
+
+func TestRenderBackportsMarkdown(t *testing.T) {
+	var buf bytes.Buffer
+	require.NoError(t, RenderBackports(&buf, testBackportMatrix(), FormatMarkdown))
+	assert.Contains(t, buf.String(), "| Issue | Status | main | release-4.16 |")
+	assert.Contains(t, buf.String(), "[#10 merged](https://github.com/org/repo/pull/10) | **missing** |")
+}
+
+func TestRenderBackportsUnsupported(t *testing.T) {
+	var buf bytes.Buffer
+	assert.Error(t, RenderBackports(&buf, testBackportMatrix(), FormatHTML))
+}
@@ -0,0 +1,153 @@
+package report
+
+import (
+	"cmp"
+	"encoding/json"
+	"fmt"
+	"io"
+	"strings"
+	"text/tabwriter"
+	"time"
+)
+
+// CloneProblemKind identifies a gap or mismatch in a clone chain
+type CloneProblemKind string
+
+const (
+	CloneNotTracked       CloneProblemKind = "not_tracked"
+	CloneNoTargetVersion  CloneProblemKind = "no_target_version"
+	CloneDuplicateRelease CloneProblemKind = "duplicate_release"
+	CloneMissingPR        CloneProblemKind = "missing_pr"
+	ClonePRMergedIssueNew CloneProblemKind = "pr_merged_issue_new"
+	CloneIssueDonePROpen  CloneProblemKind = "issue_done_pr_open"
+)
+
+// CloneProblem describes a single gap or mismatch of a clone
+type CloneProblem struct {
+	Kind    CloneProblemKind `json:"kind"`
+	Message string           `json:"message"`
+}
+
+// CloneEntry is a bug or one of its clones, paired with the pull requests
+// targeting the release branch of its target version
+type CloneEntry struct {
+	Key            string           `json:"key"`
+	Title          string           `json:"title"`
+	Status         string           `json:"status"`
+	StatusCategory string           `json:"status_category"`
+	JiraURL        string           `json:"jira_url,omitempty"`
+	Original       bool             `json:"original"` // The bug the others were cloned from
+	Tracked        bool             `json:"tracked"`
+	TargetVersion  string           `json:"target_version"`
+	Branch         string           `json:"branch"` // Release branch matching the target version
+	PullRequests   []PullRequestRef `json:"pull_requests"`
+	Problems       []CloneProblem   `json:"problems"`
+}
+
+// CloneChain is the single view of a bug across the releases it was cloned for
+type CloneChain struct {
+	Key         string       `json:"key"` // Key of the original bug
+	Title       string       `json:"title"`
+	GeneratedAt time.Time    `json:"generated_at"`
+	Clones      []CloneEntry `json:"clones"` // The original first, then by release, newest first
+	// Unpaired lists the pull requests of the chain targeting a branch no
+	// clone is expected on
+	Unpaired []PullRequestRef `json:"unpaired"`
+	Problems int              `json:"problems"` // Number of problems of every clone plus unpaired pull requests
+}
+
+// RenderClones writes the clone chain in the requested format
+func RenderClones(w io.Writer, c *CloneChain, format Format) error {
+	switch format {
+	case FormatJSON:
+		enc := json.NewEncoder(w)
+		enc.SetIndent("", "  ")
+		return enc.Encode(c)
+	case FormatTable:
+		return renderClonesTable(w, c)
+	case FormatMarkdown:
+		return renderClonesMarkdown(w, c)
+	default:
+		return fmt.Errorf("unsupported clone chain format: %s", format)
+	}
+}
+
+// renderClonesTable writes one line per clone as an aligned plain text table
+func renderClonesTable(w io.Writer, c *CloneChain) error {
+	tw := tabwriter.NewWriter(w, 0, 0, 2, ' ', 0)
+
+	fmt.Fprintln(tw, "ISSUE\tSTATUS\tTARGET\tBRANCH\tPULL REQUESTS\tPROBLEMS")
+	for _, e := range c.Clones {
+		fmt.Fprintf(tw, "%s\t%s\t%s\t%s\t%s\t%s\n", e.label(), e.Status, cmp.Or(e.TargetVersion, "-"),
+			cmp.Or(e.Branch, "-"), cmp.Or(pullRequestList(e.PullRequests), "-"), cmp.Or(problemList(e.Problems), "-"))
+	}
+	for _, pr := range c.Unpaired {
+		fmt.Fprintf(tw, "-\t-\t-\t%s\t%s %s\tno clone targets %s\n", pr.TargetBranch, pr, pr.Status, pr.TargetBranch)
+	}
+
+	return tw.Flush()
+}
+
+// renderClonesMarkdown writes the clone chain as a Markdown document
+func renderClonesMarkdown(w io.Writer, c *CloneChain) error {
+	var b strings.Builder
+
+	fmt.Fprintf(&b, "# %s: %s\n\n", c.Key, markdownEscape(c.Title))
+	fmt.Fprintf(&b, "Generated at: %s  \n", c.GeneratedAt.Format(time.RFC3339))
+	fmt.Fprintf(&b, "**%d** issues, **%d** problems\n\n", len(c.Clones), c.Problems)
+
+	b.WriteString("| Issue | Status | Target | Branch | Pull requests | Problems |\n")
+	b.WriteString("|-------|--------|--------|--------|---------------|----------|\n")
+	for _, e := range c.Clones {
+		issue := e.label()
+		if e.JiraURL != "" {
+			issue = fmt.Sprintf("[%s](%s)", issue, e.JiraURL)
+		}
+		var prs []string
+		for _, pr := range e.PullRequests {
+			prs = append(prs, fmt.Sprintf("[%s](%s) %s", pr, pr.URL, pr.Status))
+		}
+		fmt.Fprintf(&b, "| %s | %s | %s | %s | %s | %s |\n", issue, e.Status, cmp.Or(e.TargetVersion, "-"),
+			cmp.Or(e.Branch, "-"), cmp.Or(strings.Join(prs, ", "), "-"), cmp.Or(markdownEscape(problemList(e.Problems)), "-"))
+	}
+
+	if len(c.Unpaired) > 0 {
+		b.WriteString("\n## Pull requests without a clone\n\n")
+		for _, pr := range c.Unpaired {
+			fmt.Fprintf(&b, "- [%s](%s) %s, targeting `%s`\n", pr, pr.URL, pr.Status, pr.TargetBranch)
+		}
+	}
+
+	_, err := io.WriteString(w, b.String())
+	return err
+}
+
+// label returns the issue key, marked when it is the original or untracked
+func (e CloneEntry) label() string {
+	label := e.Key
+	if e.Original {
+		label += " (original)"
+	}
+	if !e.Tracked {
+		label += " *"
+	}
+	return label
+}
+
+// pullRequestList joins pull requests as "org/repo#1 merged, ..."
+func pullRequestList(prs []PullRequestRef) string {
+	var items []string
+	for _, pr := range prs {
+		items = append(items, fmt.Sprintf("%s %s", pr, pr.Status))
+	}
+	return strings.Join(items, ", ")
+}
+
+// problemList joins the messages of problems
+func problemList(problems []CloneProblem) string {
+	var items []string
+	for _, p := range problems {
+		items = append(items, p.Message)
+	}
+	return strings.Join(items, "; ")
+}
@@ -0,0 +1,47 @@
+package report
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/jparrill/devtrackr/internal/models"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func testCloneChain() *CloneChain {
+	return &CloneChain{
+		Key:   "OCPBUGS-100",
+		Title: "Crash",
+		Clones: []CloneEntry{
+			{
+				Key: "OCPBUGS-100", Status: "ON_QA", Original: true, Tracked: true, TargetVersion: "4.16.0", Branch: "release-4.16",
+				PullRequests: []PullRequestRef{{Number: 1, Repository: "org/repo", URL: "https://github.com/org/repo/pull/1", Status: models.PRStatusMerged, TargetBranch: "main"}},
+			},
+			{
+				Key: "OCPBUGS-200", Status: "New", Tracked: true, TargetVersion: "4.15.z", Branch: "release-4.15",
+				Problems: []CloneProblem{{Kind: CloneMissingPR, Message: "no pull request targets release-4.15"}},
+			},
+			{Key: "OCPBUGS-201", Status: "New"},
+		},
+		Unpaired: []PullRequestRef{{Number: 3, Repository: "org/repo", Status: models.PRStatusOpen, TargetBranch: "release-4.13"}},
+		Problems: 2,
+	}
+}
+
+func TestRenderClonesTable(t *testing.T) {
+	var buf bytes.Buffer
+	require.NoError(t, RenderClones(&buf, testCloneChain(), FormatTable))
+	assert.Contains(t, buf.String(), "OCPBUGS-100 (original)  ON_QA   4.16.0  release-4.16  org/repo#1 merged")
+	assert.Contains(t, buf.String(), "no pull request targets release-4.15")
+	assert.Contains(t, buf.String(), "OCPBUGS-201 *")
+	assert.Contains(t, buf.String(), "no clone targets release-4.13")
+}
+
+func TestRenderClonesMarkdown(t *testing.T) {
+	var buf bytes.Buffer
+	require.NoError(t, RenderClones(&buf, testCloneChain(), FormatMarkdown))
+	assert.Contains(t, buf.String(), "**3** issues, **2** problems")
+	assert.Contains(t, buf.String(), "[org/repo#1](https://github.com/org/repo/pull/1) merged")
+	assert.Contains(t, buf.String(), "## Pull requests without a clone")
+}
@@ -0,0 +1,146 @@
+package report
+
+import (
+	"encoding/json"
+	"fmt"
+	"html/template"
+	"io"
+	"strings"
+	"time"
+)
+
+// DigestChangeKind identifies what changed on an issue during a digest period
+type DigestChangeKind string
+
+const (
+	DigestStatusChanged DigestChangeKind = "status_changed"
+	DigestPROpened      DigestChangeKind = "pr_opened"
+	DigestPRMerged      DigestChangeKind = "pr_merged"
+	DigestPRClosed      DigestChangeKind = "pr_closed"
+	DigestBackport      DigestChangeKind = "backport"
+)
+
+// DigestChange is a single change of an issue or one of its pull requests
+type DigestChange struct {
+	Kind    DigestChangeKind `json:"kind"`
+	At      time.Time        `json:"at"`
+	Message string           `json:"message"`
+	URL     string           `json:"url,omitempty"` // The pull request the change is about, if any
+}
+
+// DigestIssue is what happened to a subscribed issue, and what still blocks it
+type DigestIssue struct {
+	Key      string         `json:"key"`
+	Title    string         `json:"title"`
+	Status   string         `json:"status"`
+	JiraURL  string         `json:"jira_url"`
+	Changes  []DigestChange `json:"changes"` // Oldest first
+	Blockers []Blocker      `json:"blockers"`
+}
+
+// Digest summarizes what changed on the issues a user is subscribed to
+type Digest struct {
+	UserID      int64         `json:"user_id"`
+	Since       time.Time     `json:"since"`
+	GeneratedAt time.Time     `json:"generated_at"`
+	Issues      []DigestIssue `json:"issues"` // Issues with changes or blockers
+	Quiet       int           `json:"quiet"`  // Subscribed issues with neither
+	Changes     int           `json:"changes"`
+	Blocked     int           `json:"blocked"`
+}
+
+// Subject returns a one line summary of the digest, e.g. for an email
+func (d *Digest) Subject() string {
+	return fmt.Sprintf("DevTrackr digest: %d changes, %d blocked issues since %s",
+		d.Changes, d.Blocked, d.Since.Format("Mon 15:04"))
+}
+
+// RenderDigest writes the digest in the requested format. HTML is a
+// standalone document suitable as the body of an email.
+func RenderDigest(w io.Writer, d *Digest, format Format) error {
+	switch format {
+	case FormatJSON:
+		enc := json.NewEncoder(w)
+		enc.SetIndent("", "  ")
+		return enc.Encode(d)
+	case FormatMarkdown:
+		return renderDigestMarkdown(w, d)
+	case FormatText:
+		return renderDigestText(w, d)
+	case FormatHTML:
+		return digestHTMLTemplate.Execute(w, d)
+	default:
+		return fmt.Errorf("unsupported digest format: %s", format)
+	}
+}
+
+// renderDigestMarkdown writes the digest as a Markdown document
+func renderDigestMarkdown(w io.Writer, d *Digest) error {
+	var b strings.Builder
+
+	fmt.Fprintf(&b, "# Digest since %s\n\n", d.Since.Format(time.RFC3339))
+	fmt.Fprintf(&b, "**%d** changes, **%d** blocked issues, **%d** quiet issues\n", d.Changes, d.Blocked, d.Quiet)
+
+	for _, issue := range d.Issues {
+		fmt.Fprintf(&b, "\n## [%s](%s) %s\n\n", issue.Key, issue.JiraURL, markdownEscape(issue.Title))
+		fmt.Fprintf(&b, "Status: %s\n\n", issue.Status)
+		for _, c := range issue.Changes {
+			message := c.Message
+			if c.URL != "" {
+				message = fmt.Sprintf("[%s](%s)", message, c.URL)
+			}
+			fmt.Fprintf(&b, "- %s %s\n", c.At.Format("Mon 15:04"), message)
+		}
+		for _, blocker := range issue.Blockers {
+			fmt.Fprintf(&b, "- **blocked**: %s\n", blocker.Message)
+		}
+	}
+
+	_, err := io.WriteString(w, b.String())
+	return err
+}
+
+// renderDigestText writes the digest as plain text, e.g. for chat messages
+func renderDigestText(w io.Writer, d *Digest) error {
+	var b strings.Builder
+
+	fmt.Fprintf(&b, "Digest since %s: %d changes, %d blocked issues, %d quiet issues\n",
+		d.Since.Format("Mon Jan 2 15:04"), d.Changes, d.Blocked, d.Quiet)
+
+	for _, issue := range d.Issues {
+		fmt.Fprintf(&b, "\n%s %s [%s]\n", issue.Key, issue.Title, issue.Status)
+		for _, c := range issue.Changes {
+			fmt.Fprintf(&b, "  %s  %s\n", c.At.Format("Mon 15:04"), c.Message)
+		}
+		for _, blocker := range issue.Blockers {
+			fmt.Fprintf(&b, "  blocked    %s\n", blocker.Message)
+		}
+	}
+
+	_, err := io.WriteString(w, b.String())
+	return err
+}
+
+var digestHTMLTemplate = template.Must(template.New("digest").Funcs(template.FuncMap{
+	"when": func(t time.Time) string { return t.Format("Mon 15:04") },
+}).Parse(`<!DOCTYPE html>
+<html>
+<head>
+<meta charset="utf-8">
+<title>{{.Subject}}</title>
+</head>
+<body style="font-family: sans-serif;">
+<h2>Digest since {{.Since.Format "Mon Jan 2 15:04"}}</h2>
+<p><strong>{{.Changes}}</strong> changes, <strong>{{.Blocked}}</strong> blocked issues, <strong>{{.Quiet}}</strong> quiet issues</p>
+{{range .Issues}}
+<h3><a href="{{.JiraURL}}">{{.Key}}</a> {{.Title}} <small style="color: #666;">{{.Status}}</small></h3>
+<ul>
+{{range .Changes}}<li>{{when .At}} {{if .URL}}<a href="{{.URL}}">{{.Message}}</a>{{else}}{{.Message}}{{end}}</li>
+{{end}}{{range .Blockers}}<li style="color: #b71c1c;">blocked: {{.Message}}</li>
+{{end}}</ul>
+{{else}}
+<p>Nothing changed and nothing is blocked.</p>
+{{end}}
+</body>
+</html>
+`))
@@ -0,0 +1,48 @@
+package report
+
+import (
+	"bytes"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func testDigest() *Digest {
+	at := time.Date(2024, 5, 1, 9, 30, 0, 0, time.UTC)
+	return &Digest{
+		UserID: 7,
+		Since:  at.Add(-24 * time.Hour),
+		Issues: []DigestIssue{{
+			Key: "OCPBUGS-1", Title: "Crash <on start>", Status: "ON_QA", JiraURL: "https://issues.redhat.com/browse/OCPBUGS-1",
+			Changes:  []DigestChange{{Kind: DigestPRMerged, At: at, Message: "org/repo#1 merged on main", URL: "https://github.com/org/repo/pull/1"}},
+			Blockers: []Blocker{{Kind: BlockerMissingBackport, Message: "no backport to release-4.16"}},
+		}},
+		Quiet:   2,
+		Changes: 1,
+		Blocked: 1,
+	}
+}
+
+func TestRenderDigestText(t *testing.T) {
+	var buf bytes.Buffer
+	require.NoError(t, RenderDigest(&buf, testDigest(), FormatText))
+	assert.Contains(t, buf.String(), "1 changes, 1 blocked issues, 2 quiet issues")
+	assert.Contains(t, buf.String(), "OCPBUGS-1 Crash <on start> [ON_QA]\n  Wed 09:30  org/repo#1 merged on main\n  blocked    no backport to release-4.16\n")
+}
+
+func TestRenderDigestHTML(t *testing.T) {
+	var buf bytes.Buffer
+	require.NoError(t, RenderDigest(&buf, testDigest(), FormatHTML))
+	assert.Contains(t, buf.String(), "Crash &lt;on start&gt;")
+	assert.Contains(t, buf.String(), `<a href="https://github.com/org/repo/pull/1">org/repo#1 merged on main</a>`)
+	assert.Contains(t, buf.String(), "blocked: no backport to release-4.16")
+}
+
+func TestRenderDigestMarkdown(t *testing.T) {
+	var buf bytes.Buffer
+	require.NoError(t, RenderDigest(&buf, testDigest(), FormatMarkdown))
+	assert.Contains(t, buf.String(), "## [OCPBUGS-1](https://issues.redhat.com/browse/OCPBUGS-1) Crash <on start>")
+	assert.Contains(t, buf.String(), "- Wed 09:30 [org/repo#1 merged on main](https://github.com/org/repo/pull/1)")
+}
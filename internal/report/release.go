@@ -0,0 +1,179 @@
+package report
+
+import (
+	"encoding/json"
+	"fmt"
+	"html/template"
+	"io"
+	"strings"
+	"time"
+)
+
+// BlockerKind identifies why an issue is not ready for a release
+type BlockerKind string
+
+const (
+	BlockerUnmergedPR      BlockerKind = "unmerged_pr"
+	BlockerIssueNotDone    BlockerKind = "issue_not_done"
+	BlockerMissingBackport BlockerKind = "missing_backport"
+)
+
+// Blocker describes a single reason preventing an issue from shipping
+type Blocker struct {
+	Kind    BlockerKind `json:"kind"`
+	Message string      `json:"message"`
+}
+
+// ReleaseIssue is the readiness state of a single issue in a release
+type ReleaseIssue struct {
+	Key          string                      `json:"key"`
+	Title        string                      `json:"title"`
+	Status       string                      `json:"status"`
+	JiraURL      string                      `json:"jira_url"`
+	PullRequests map[string][]PullRequestRef `json:"pull_requests"` // Keyed by target branch
+	Blockers     []Blocker                   `json:"blockers"`
+}
+
+// Ready reports whether the issue has no blockers
+func (i ReleaseIssue) Ready() bool {
+	return len(i.Blockers) == 0
+}
+
+// Branches returns the target branches of the issue pull requests in a stable order
+func (i ReleaseIssue) Branches() []string {
+	return sortedKeys(i.PullRequests)
+}
+
+// ReleaseReport is the readiness report of every tracked issue targeting a release
+type ReleaseReport struct {
+	Version     string         `json:"version"`
+	Branch      string         `json:"branch"` // Release branch backports are expected on
+	GeneratedAt time.Time      `json:"generated_at"`
+	Issues      []ReleaseIssue `json:"issues"`
+	Ready       int            `json:"ready"`
+	Blocked     int            `json:"blocked"`
+}
+
+// CheckReleaseFormat returns an error if RenderRelease does not support a
+// format, so that it can be rejected before the report is built
+func CheckReleaseFormat(format Format) error {
+	switch format {
+	case FormatJSON, FormatMarkdown, FormatHTML:
+		return nil
+	default:
+		return fmt.Errorf("unsupported report format: %s", format)
+	}
+}
+
+// RenderRelease writes the release report in the requested format
+func RenderRelease(w io.Writer, r *ReleaseReport, format Format) error {
+	switch format {
+	case FormatJSON:
+		enc := json.NewEncoder(w)
+		enc.SetIndent("", "  ")
+		return enc.Encode(r)
+	case FormatMarkdown:
+		return renderReleaseMarkdown(w, r)
+	case FormatHTML:
+		return releaseHTMLTemplate.Execute(w, r)
+	default:
+		return fmt.Errorf("unsupported report format: %s", format)
+	}
+}
+
+// renderReleaseMarkdown writes the release report as a Markdown document
+func renderReleaseMarkdown(w io.Writer, r *ReleaseReport) error {
+	var b strings.Builder
+
+	fmt.Fprintf(&b, "# Release readiness: %s\n\n", r.Version)
+	fmt.Fprintf(&b, "Release branch: `%s`  \n", r.Branch)
+	fmt.Fprintf(&b, "Generated at: %s\n\n", r.GeneratedAt.Format(time.RFC3339))
+	fmt.Fprintf(&b, "**%d** issues, **%d** ready, **%d** blocked\n\n", len(r.Issues), r.Ready, r.Blocked)
+
+	if len(r.Issues) == 0 {
+		b.WriteString("No tracked issues target this release.\n")
+		_, err := io.WriteString(w, b.String())
+		return err
+	}
+
+	b.WriteString("| Issue | Status | Pull requests | Ready |\n")
+	b.WriteString("|-------|--------|---------------|-------|\n")
+	for _, issue := range r.Issues {
+		var prs []string
+		for _, branch := range issue.Branches() {
+			for _, pr := range issue.PullRequests[branch] {
+				prs = append(prs, fmt.Sprintf("`%s`: [%s](%s) %s", branch, pr, pr.URL, pr.Status))
+			}
+		}
+		if len(prs) == 0 {
+			prs = append(prs, "-")
+		}
+
+		ready := "yes"
+		if !issue.Ready() {
+			ready = "no"
+		}
+
+		fmt.Fprintf(&b, "| [%s](%s) %s | %s | %s | %s |\n",
+			issue.Key, issue.JiraURL, markdownEscape(issue.Title), issue.Status, strings.Join(prs, "<br>"), ready)
+	}
+
+	if r.Blocked > 0 {
+		b.WriteString("\n## Blockers\n")
+		for _, issue := range r.Issues {
+			if issue.Ready() {
+				continue
+			}
+			fmt.Fprintf(&b, "\n### %s\n\n", issue.Key)
+			for _, blocker := range issue.Blockers {
+				fmt.Fprintf(&b, "- **%s**: %s\n", blocker.Kind, blocker.Message)
+			}
+		}
+	}
+
+	_, err := io.WriteString(w, b.String())
+	return err
+}
+
+// markdownEscape escapes characters that would break a Markdown table cell
+func markdownEscape(s string) string {
+	return strings.ReplaceAll(s, "|", `\|`)
+}
+
+var releaseHTMLTemplate = template.Must(template.New("release").Funcs(template.FuncMap{
+	"rfc3339": func(t time.Time) string { return t.Format(time.RFC3339) },
+}).Parse(`<!DOCTYPE html>
+<html>
+<head>
+<meta charset="utf-8">
+<title>Release readiness: {{.Version}}</title>
+<style>
+body { font-family: sans-serif; }
+table { border-collapse: collapse; }
+th, td { border: 1px solid #ccc; padding: 4px 8px; text-align: left; vertical-align: top; }
+.blocked { background: #fdecea; }
+.ready { background: #edf7ed; }
+</style>
+</head>
+<body>
+<h1>Release readiness: {{.Version}}</h1>
+<p>Release branch: <code>{{.Branch}}</code><br>Generated at: {{rfc3339 .GeneratedAt}}</p>
+<p><strong>{{len .Issues}}</strong> issues, <strong>{{.Ready}}</strong> ready, <strong>{{.Blocked}}</strong> blocked</p>
+{{if .Issues}}
+<table>
+<tr><th>Issue</th><th>Status</th><th>Pull requests</th><th>Blockers</th></tr>
+{{range .Issues}}
+<tr class="{{if .Ready}}ready{{else}}blocked{{end}}">
+<td><a href="{{.JiraURL}}">{{.Key}}</a> {{.Title}}</td>
+<td>{{.Status}}</td>
+<td>{{$prs := .PullRequests}}{{range .Branches}}{{$branch := .}}{{range index $prs $branch}}<code>{{$branch}}</code>: <a href="{{.URL}}">{{.String}}</a> {{.Status}}<br>{{end}}{{end}}</td>
+<td>{{range .Blockers}}{{.Message}}<br>{{else}}-{{end}}</td>
+</tr>
+{{end}}
+</table>
+{{else}}
+<p>No tracked issues target this release.</p>
+{{end}}
+</body>
+</html>
+`))
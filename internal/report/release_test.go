@@ -0,0 +1,84 @@
+package report
+
+import (
+	"bytes"
+	"encoding/json"
+	"testing"
+	"time"
+
+	"github.com/jparrill/devtrackr/internal/models"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func testReleaseReport() *ReleaseReport {
+	return &ReleaseReport{
+		Version:     "4.16",
+		Branch:      "release-4.16",
+		GeneratedAt: time.Date(2024, 5, 1, 10, 0, 0, 0, time.UTC),
+		Issues: []ReleaseIssue{
+			{
+				Key:     "TEST-1",
+				Title:   "Fix | pipes",
+				Status:  "POST",
+				JiraURL: "https://issues.redhat.com/browse/TEST-1",
+				PullRequests: map[string][]PullRequestRef{
+					"main": {{Number: 10, Repository: "org/repo", URL: "https://github.com/org/repo/pull/10", Status: models.PRStatusOpen}},
+				},
+				Blockers: []Blocker{{Kind: BlockerUnmergedPR, Message: "pull request org/repo#10 targeting main is open"}},
+			},
+		},
+		Blocked: 1,
+	}
+}
+
+func TestParseFormat(t *testing.T) {
+	format, err := ParseFormat("md")
+	assert.NoError(t, err)
+	assert.Equal(t, FormatMarkdown, format)
+
+	format, err = ParseFormat("")
+	assert.NoError(t, err)
+	assert.Equal(t, FormatJSON, format)
+
+	_, err = ParseFormat("pdf")
+	assert.Error(t, err)
+}
+
+func TestCheckReleaseFormat(t *testing.T) {
+	assert.NoError(t, CheckReleaseFormat(FormatHTML))
+	// Formats other reports support are rejected before building the report
+	assert.Error(t, CheckReleaseFormat(FormatCSV))
+	assert.Error(t, RenderRelease(&bytes.Buffer{}, testReleaseReport(), FormatCSV))
+}
+
+func TestRenderReleaseMarkdown(t *testing.T) {
+	var buf bytes.Buffer
+	require.NoError(t, RenderRelease(&buf, testReleaseReport(), FormatMarkdown))
+
+	out := buf.String()
+	assert.Contains(t, out, "# Release readiness: 4.16")
+	assert.Contains(t, out, `Fix \| pipes`)
+	assert.Contains(t, out, "[org/repo#10](https://github.com/org/repo/pull/10)")
+	assert.Contains(t, out, "- **unmerged_pr**: pull request org/repo#10 targeting main is open")
+}
+
+func TestRenderReleaseHTML(t *testing.T) {
+	var buf bytes.Buffer
+	require.NoError(t, RenderRelease(&buf, testReleaseReport(), FormatHTML))
+
+	out := buf.String()
+	assert.Contains(t, out, "<h1>Release readiness: 4.16</h1>")
+	assert.Contains(t, out, `<a href="https://github.com/org/repo/pull/10">org/repo#10</a>`)
+	assert.Contains(t, out, `class="blocked"`)
+}
+
+func TestRenderReleaseJSON(t *testing.T) {
+	var buf bytes.Buffer
+	require.NoError(t, RenderRelease(&buf, testReleaseReport(), FormatJSON))
+
+	var decoded ReleaseReport
+	require.NoError(t, json.Unmarshal(buf.Bytes(), &decoded))
+	assert.Equal(t, "4.16", decoded.Version)
+	assert.Len(t, decoded.Issues, 1)
+}
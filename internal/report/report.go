@@ -0,0 +1,104 @@
+package report
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/jparrill/devtrackr/internal/models"
+)
+
+// Format identifies the output format of a rendered report
+type Format string
+
+const (
+	FormatJSON     Format = "json"
+	FormatMarkdown Format = "markdown"
+	FormatHTML     Format = "html"
+	FormatTable    Format = "table"
+	FormatCSV      Format = "csv"
+	FormatText     Format = "text"
+	FormatXLSX     Format = "xlsx"
+)
+
+// ParseFormat converts a user supplied format name into a Format
+func ParseFormat(name string) (Format, error) {
+	switch strings.ToLower(name) {
+	case "", "json":
+		return FormatJSON, nil
+	case "markdown", "md":
+		return FormatMarkdown, nil
+	case "html":
+		return FormatHTML, nil
+	case "table":
+		return FormatTable, nil
+	case "csv":
+		return FormatCSV, nil
+	case "text", "txt":
+		return FormatText, nil
+	case "xlsx":
+		return FormatXLSX, nil
+	default:
+		return "", fmt.Errorf("unsupported report format: %s", name)
+	}
+}
+
+// ContentType returns the MIME type used when serving the format over HTTP
+func (f Format) ContentType() string {
+	switch f {
+	case FormatMarkdown:
+		return "text/markdown; charset=utf-8"
+	case FormatHTML:
+		return "text/html; charset=utf-8"
+	case FormatTable, FormatText:
+		return "text/plain; charset=utf-8"
+	case FormatCSV:
+		return "text/csv; charset=utf-8"
+	case FormatXLSX:
+		return "application/vnd.openxmlformats-officedocument.spreadsheetml.sheet"
+	default:
+		return "application/json"
+	}
+}
+
+// PullRequestRef is the summary of a pull request shown in reports
+type PullRequestRef struct {
+	Number       int             `json:"number"`
+	Repository   string          `json:"repository"`
+	Title        string          `json:"title"`
+	URL          string          `json:"url"`
+	Status       models.PRStatus `json:"status"`
+	TargetBranch string          `json:"target_branch"`
+	IsBackport   bool            `json:"is_backport"`
+}
+
+// NewPullRequestRef builds a report reference from a tracked pull request
+func NewPullRequestRef(pr *models.PullRequest) PullRequestRef {
+	return PullRequestRef{
+		Number:       pr.Number,
+		Repository:   pr.Repository,
+		Title:        pr.Title,
+		URL:          pr.URL,
+		Status:       pr.Status,
+		TargetBranch: pr.TargetBranch,
+		IsBackport:   pr.IsBackport,
+	}
+}
+
+// String returns a short human readable reference such as "org/repo#123"
+func (r PullRequestRef) String() string {
+	if r.Repository == "" {
+		return fmt.Sprintf("#%d", r.Number)
+	}
+	return fmt.Sprintf("%s#%d", r.Repository, r.Number)
+}
+
+// sortedKeys returns the keys of a branch map in a stable order
+func sortedKeys(m map[string][]PullRequestRef) []string {
+	keys := make([]string, 0, len(m))
+	for k := range m {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	return keys
+}
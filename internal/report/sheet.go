@@ -0,0 +1,328 @@
+package report
+
+import (
+	"encoding/csv"
+	"fmt"
+	"io"
+	"slices"
+	"strconv"
+	"strings"
+	"text/tabwriter"
+	"time"
+
+	"github.com/jparrill/devtrackr/internal/models"
+)
+
+// Sheet is a listing laid out as a spreadsheet: a header row and one row of
+// text cells per record
+type Sheet struct {
+	Name    string
+	Header  []string
+	Numeric []bool // Columns holding integers, per header entry
+	Rows    [][]string
+}
+
+// IssueRow is a tracked issue with its pull requests, listed as a sheet row
+type IssueRow struct {
+	Issue        *models.Issue         `json:"issue"`
+	PullRequests []*models.PullRequest `json:"pull_requests"`
+}
+
+// PullRequestRow is a tracked pull request, listed as a sheet row
+type PullRequestRow struct {
+	Issue       string // Qualified key of the issue
+	PullRequest *models.PullRequest
+	Original    string // The original of a backport as org/repo#12, if known
+}
+
+// column is a listed field
+type column[T any] struct {
+	numeric bool
+	value   func(T) string
+}
+
+// AttributeColumnPrefix prefixes issue columns showing a custom field
+// attribute, e.g. "attr.story_points"
+const AttributeColumnPrefix = "attr."
+
+// DefaultIssueColumns are listed when no issue column is selected
+var DefaultIssueColumns = []string{"key", "title", "status", "assignee", "fix_versions", "prs", "prs_merged", "updated_at"}
+
+// DefaultPullRequestColumns are listed when no pull request column is selected
+var DefaultPullRequestColumns = []string{"issue", "pull_request", "title", "status", "target_branch", "is_backport", "original", "url"}
+
+var issueColumns = map[string]column[IssueRow]{
+	"key":              {value: func(r IssueRow) string { return r.Issue.QualifiedKey() }},
+	"instance":         {value: func(r IssueRow) string { return r.Issue.Instance }},
+	"project":          {value: func(r IssueRow) string { return r.Issue.Project() }},
+	"title":            {value: func(r IssueRow) string { return r.Issue.Title }},
+	"status":           {value: func(r IssueRow) string { return r.Issue.Status }},
+	"status_category":  {value: func(r IssueRow) string { return r.Issue.StatusCategory }},
+	"assignee":         {value: func(r IssueRow) string { return r.Issue.Assignee }},
+	"reporter":         {value: func(r IssueRow) string { return r.Issue.Reporter }},
+	"priority":         {value: func(r IssueRow) string { return r.Issue.Priority }},
+	"issue_type":       {value: func(r IssueRow) string { return r.Issue.IssueType }},
+	"resolution":       {value: func(r IssueRow) string { return r.Issue.Resolution }},
+	"sprint":           {value: func(r IssueRow) string { return r.Issue.Sprint }},
+	"epic":             {value: func(r IssueRow) string { return r.Issue.EpicLink }},
+	"labels":           {value: func(r IssueRow) string { return joinCell(r.Issue.Labels) }},
+	"components":       {value: func(r IssueRow) string { return joinCell(r.Issue.Components) }},
+	"fix_versions":     {value: func(r IssueRow) string { return joinCell(r.Issue.FixVersions) }},
+	"affects_versions": {value: func(r IssueRow) string { return joinCell(r.Issue.AffectsVersions) }},
+	"jira_url":         {value: func(r IssueRow) string { return r.Issue.JiraURL }},
+	"due_date":         {value: func(r IssueRow) string { return dateCell(r.Issue.DueDate) }},
+	"created_at":       {value: func(r IssueRow) string { return timeCell(r.Issue.CreatedAt) }},
+	"updated_at":       {value: func(r IssueRow) string { return timeCell(r.Issue.UpdatedAt) }},
+	"jira_updated_at":  {value: func(r IssueRow) string { return timeCell(r.Issue.JiraUpdatedAt) }},
+	"last_polled_at":   {value: func(r IssueRow) string { return timeCell(r.Issue.LastPolledAt) }},
+
+	// Pull request rollups
+	"prs": {numeric: true, value: func(r IssueRow) string { return strconv.Itoa(len(r.PullRequests)) }},
+	"prs_open": {numeric: true, value: func(r IssueRow) string {
+		return countPullRequests(r.PullRequests, func(pr *models.PullRequest) bool {
+			return pr.Status != models.PRStatusMerged && pr.Status != models.PRStatusClosed
+		})
+	}},
+	"prs_merged": {numeric: true, value: func(r IssueRow) string {
+		return countPullRequests(r.PullRequests, func(pr *models.PullRequest) bool { return pr.Status == models.PRStatusMerged })
+	}},
+	"backports": {numeric: true, value: func(r IssueRow) string {
+		return countPullRequests(r.PullRequests, func(pr *models.PullRequest) bool { return pr.IsBackport })
+	}},
+	"pull_requests": {value: func(r IssueRow) string {
+		var refs []string
+		for _, pr := range r.PullRequests {
+			refs = append(refs, NewPullRequestRef(pr).String())
+		}
+		return joinCell(refs)
+	}},
+	"merged_branches": {value: func(r IssueRow) string {
+		var branches []string
+		for _, pr := range r.PullRequests {
+			if pr.Status == models.PRStatusMerged && !slices.Contains(branches, pr.TargetBranch) {
+				branches = append(branches, pr.TargetBranch)
+			}
+		}
+		slices.Sort(branches)
+		return joinCell(branches)
+	}},
+}
+
+var pullRequestColumns = map[string]column[PullRequestRow]{
+	"issue":         {value: func(r PullRequestRow) string { return r.Issue }},
+	"pull_request":  {value: func(r PullRequestRow) string { return NewPullRequestRef(r.PullRequest).String() }},
+	"repository":    {value: func(r PullRequestRow) string { return r.PullRequest.Repository }},
+	"number":        {numeric: true, value: func(r PullRequestRow) string { return strconv.Itoa(r.PullRequest.Number) }},
+	"title":         {value: func(r PullRequestRow) string { return r.PullRequest.Title }},
+	"status":        {value: func(r PullRequestRow) string { return string(r.PullRequest.Status) }},
+	"target_branch": {value: func(r PullRequestRow) string { return r.PullRequest.TargetBranch }},
+	"is_backport":   {value: func(r PullRequestRow) string { return strconv.FormatBool(r.PullRequest.IsBackport) }},
+	"original":      {value: func(r PullRequestRow) string { return r.Original }},
+	"labels":        {value: func(r PullRequestRow) string { return joinCell(r.PullRequest.Labels) }},
+	"ci_status":     {value: func(r PullRequestRow) string { return string(r.PullRequest.CIStatus) }},
+	"approvals":     {numeric: true, value: func(r PullRequestRow) string { return strconv.Itoa(r.PullRequest.Approvals) }},
+	"url":           {value: func(r PullRequestRow) string { return r.PullRequest.URL }},
+	"created_at":    {value: func(r PullRequestRow) string { return timeCell(r.PullRequest.CreatedAt) }},
+	"updated_at":    {value: func(r PullRequestRow) string { return timeCell(r.PullRequest.UpdatedAt) }},
+}
+
+// IssueColumns returns the names of the issue columns, without attributes
+func IssueColumns() []string {
+	return sortedColumns(issueColumns)
+}
+
+// PullRequestColumns returns the names of the pull request columns
+func PullRequestColumns() []string {
+	return sortedColumns(pullRequestColumns)
+}
+
+// IssueSheet lists issues with the given columns, or DefaultIssueColumns
+// when none is given. Columns prefixed with AttributeColumnPrefix show the
+// custom field attribute of that name.
+func IssueSheet(rows []IssueRow, columns []string) (Sheet, error) {
+	if len(columns) == 0 {
+		columns = DefaultIssueColumns
+	}
+
+	cols := make([]column[IssueRow], len(columns))
+	for i, name := range columns {
+		if attr, ok := strings.CutPrefix(name, AttributeColumnPrefix); ok && attr != "" {
+			cols[i] = column[IssueRow]{value: func(r IssueRow) string { return attributeCell(r.Issue.Attributes[attr]) }}
+			continue
+		}
+		col, ok := issueColumns[name]
+		if !ok {
+			return Sheet{}, fmt.Errorf("unknown issue column %q, expected one of %s or %s<name>",
+				name, strings.Join(IssueColumns(), ", "), AttributeColumnPrefix)
+		}
+		cols[i] = col
+	}
+	return newSheet("Issues", columns, cols, rows), nil
+}
+
+// PullRequestSheet lists pull requests with the given columns, or
+// DefaultPullRequestColumns when none is given
+func PullRequestSheet(rows []PullRequestRow, columns []string) (Sheet, error) {
+	if len(columns) == 0 {
+		columns = DefaultPullRequestColumns
+	}
+
+	cols := make([]column[PullRequestRow], len(columns))
+	for i, name := range columns {
+		col, ok := pullRequestColumns[name]
+		if !ok {
+			return Sheet{}, fmt.Errorf("unknown pull request column %q, expected one of %s",
+				name, strings.Join(PullRequestColumns(), ", "))
+		}
+		cols[i] = col
+	}
+	return newSheet("Pull requests", columns, cols, rows), nil
+}
+
+// NewPullRequestRows lists the pull requests of issues, resolving the
+// originals of backports among them
+func NewPullRequestRows(rows []IssueRow) []PullRequestRow {
+	labels := map[int64]string{}
+	for _, row := range rows {
+		for _, pr := range row.PullRequests {
+			labels[pr.ID] = NewPullRequestRef(pr).String()
+		}
+	}
+
+	result := []PullRequestRow{}
+	for _, row := range rows {
+		for _, pr := range row.PullRequests {
+			entry := PullRequestRow{Issue: row.Issue.QualifiedKey(), PullRequest: pr}
+			if pr.OriginalPRID != nil {
+				entry.Original = labels[*pr.OriginalPRID]
+			}
+			result = append(result, entry)
+		}
+	}
+	return result
+}
+
+// newSheet evaluates the columns of every row
+func newSheet[T any](name string, header []string, cols []column[T], rows []T) Sheet {
+	sheet := Sheet{Name: name, Header: header, Numeric: make([]bool, len(cols)), Rows: [][]string{}}
+	for i, col := range cols {
+		sheet.Numeric[i] = col.numeric
+	}
+	for _, row := range rows {
+		values := make([]string, len(cols))
+		for i, col := range cols {
+			values[i] = col.value(row)
+		}
+		sheet.Rows = append(sheet.Rows, values)
+	}
+	return sheet
+}
+
+// RenderSheet writes a sheet in the requested format
+func RenderSheet(w io.Writer, sheet Sheet, format Format) error {
+	switch format {
+	case FormatCSV:
+		return renderSheetCSV(w, sheet)
+	case FormatTable:
+		return renderSheetTable(w, sheet)
+	case FormatXLSX:
+		return WriteXLSX(w, sheet)
+	default:
+		return fmt.Errorf("unsupported listing format: %s", format)
+	}
+}
+
+// renderSheetCSV writes a sheet as CSV. Text cells a spreadsheet would
+// evaluate as a formula are prefixed with a quote.
+func renderSheetCSV(w io.Writer, sheet Sheet) error {
+	cw := csv.NewWriter(w)
+	if err := cw.Write(sheet.Header); err != nil {
+		return err
+	}
+
+	for _, row := range sheet.Rows {
+		record := make([]string, len(row))
+		for i, value := range row {
+			if !sheet.Numeric[i] && value != "" && strings.ContainsRune("=+-@\t\r", rune(value[0])) {
+				value = "'" + value
+			}
+			record[i] = value
+		}
+		if err := cw.Write(record); err != nil {
+			return err
+		}
+	}
+
+	cw.Flush()
+	return cw.Error()
+}
+
+// renderSheetTable writes a sheet as an aligned plain text table
+func renderSheetTable(w io.Writer, sheet Sheet) error {
+	tw := tabwriter.NewWriter(w, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(tw, strings.ToUpper(strings.Join(sheet.Header, "\t")))
+	for _, row := range sheet.Rows {
+		fmt.Fprintln(tw, strings.Join(row, "\t"))
+	}
+	return tw.Flush()
+}
+
+// timeCell formats a time as ISO 8601 in UTC, empty when unset
+func timeCell(t time.Time) string {
+	if t.IsZero() {
+		return ""
+	}
+	return t.UTC().Format(time.RFC3339)
+}
+
+// dateCell formats a date as ISO 8601, empty when unset
+func dateCell(t time.Time) string {
+	if t.IsZero() {
+		return ""
+	}
+	return t.Format(time.DateOnly)
+}
+
+// joinCell lists the values of a multi-valued field in a single cell
+func joinCell(values []string) string {
+	return strings.Join(values, ", ")
+}
+
+// attributeCell formats a custom field attribute value
+func attributeCell(value any) string {
+	switch v := value.(type) {
+	case nil:
+		return ""
+	case float64:
+		return strconv.FormatFloat(v, 'f', -1, 64)
+	case []any:
+		values := make([]string, len(v))
+		for i, item := range v {
+			values[i] = fmt.Sprint(item)
+		}
+		return joinCell(values)
+	default:
+		return fmt.Sprint(v)
+	}
+}
+
+// countPullRequests counts the pull requests matching a predicate
+func countPullRequests(prs []*models.PullRequest, match func(*models.PullRequest) bool) string {
+	n := 0
+	for _, pr := range prs {
+		if match(pr) {
+			n++
+		}
+	}
+	return strconv.Itoa(n)
+}
+
+// sortedColumns returns the names of a column set in a stable order
+func sortedColumns[T any](cols map[string]column[T]) []string {
+	names := make([]string, 0, len(cols))
+	for name := range cols {
+		names = append(names, name)
+	}
+	slices.Sort(names)
+	return names
+}
@@ -0,0 +1,92 @@
+package report
+
+import (
+	"archive/zip"
+	"bytes"
+	"encoding/csv"
+	"io"
+	"testing"
+	"time"
+
+	"github.com/jparrill/devtrackr/internal/models"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func sheetRows() []IssueRow {
+	original := int64(1)
+	return []IssueRow{{
+		Issue: &models.Issue{
+			Key:        "OCPBUGS-1",
+			Title:      `Crash on "reconcile", again`,
+			Status:     "ON_QA",
+			Labels:     []string{"a", "b"},
+			UpdatedAt:  time.Date(2024, 5, 1, 12, 0, 0, 0, time.FixedZone("CEST", 2*3600)),
+			Attributes: map[string]any{"story_points": float64(3), "qa": "=HYPERLINK(\"x\")"},
+		},
+		PullRequests: []*models.PullRequest{
+			{ID: 1, Repository: "org/repo", Number: 10, Status: models.PRStatusMerged, TargetBranch: "main"},
+			{ID: 2, Repository: "org/repo", Number: 11, Status: models.PRStatusOpen, TargetBranch: "release-4.16", IsBackport: true, OriginalPRID: &original},
+		},
+	}}
+}
+
+func TestIssueSheetCSV(t *testing.T) {
+	sheet, err := IssueSheet(sheetRows(), []string{"key", "title", "labels", "updated_at", "prs", "prs_merged", "merged_branches", "attr.story_points", "attr.qa"})
+	require.NoError(t, err)
+
+	var buf bytes.Buffer
+	require.NoError(t, RenderSheet(&buf, sheet, FormatCSV))
+
+	records, err := csv.NewReader(&buf).ReadAll()
+	require.NoError(t, err)
+	require.Len(t, records, 2)
+	assert.Equal(t, []string{
+		"OCPBUGS-1", `Crash on "reconcile", again`, "a, b", "2024-05-01T10:00:00Z", "2", "1", "main", "3", `'=HYPERLINK("x")`,
+	}, records[1])
+
+	_, err = IssueSheet(nil, []string{"nope"})
+	assert.Error(t, err)
+}
+
+func TestPullRequestRows(t *testing.T) {
+	rows := NewPullRequestRows(sheetRows())
+	require.Len(t, rows, 2)
+	assert.Equal(t, "OCPBUGS-1", rows[1].Issue)
+	assert.Equal(t, "org/repo#10", rows[1].Original)
+}
+
+func TestWriteXLSX(t *testing.T) {
+	rows := sheetRows()
+	issues, err := IssueSheet(rows, nil)
+	require.NoError(t, err)
+	prs, err := PullRequestSheet(NewPullRequestRows(rows), nil)
+	require.NoError(t, err)
+
+	var buf bytes.Buffer
+	require.NoError(t, WriteXLSX(&buf, issues, prs))
+
+	zr, err := zip.NewReader(bytes.NewReader(buf.Bytes()), int64(buf.Len()))
+	require.NoError(t, err)
+	files := map[string]string{}
+	for _, f := range zr.File {
+		rc, err := f.Open()
+		require.NoError(t, err)
+		content, err := io.ReadAll(rc)
+		require.NoError(t, err)
+		rc.Close()
+		files[f.Name] = string(content)
+	}
+
+	assert.Contains(t, files["xl/workbook.xml"], `<sheet name="Issues" sheetId="1" r:id="rId1"/>`)
+	assert.Contains(t, files["xl/workbook.xml"], `<sheet name="Pull requests" sheetId="2" r:id="rId2"/>`)
+	assert.Contains(t, files["xl/worksheets/sheet1.xml"], "Crash on &#34;reconcile&#34;, again")
+	assert.Contains(t, files["xl/worksheets/sheet1.xml"], `<c r="F2"><v>2</v></c>`, "rollups are numbers")
+}
+
+func TestColumnName(t *testing.T) {
+	assert.Equal(t, "A", columnName(0))
+	assert.Equal(t, "Z", columnName(25))
+	assert.Equal(t, "AA", columnName(26))
+	assert.Equal(t, "AZ", columnName(51))
+}
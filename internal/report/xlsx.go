@@ -0,0 +1,126 @@
+package report
+
+import (
+	"archive/zip"
+	"encoding/xml"
+	"fmt"
+	"io"
+	"strings"
+)
+
+// WriteXLSX writes sheets as an Office Open XML workbook, one worksheet per
+// sheet. Cells hold inline strings, except integers in numeric columns, and
+// the header row is bold.
+func WriteXLSX(w io.Writer, sheets ...Sheet) error {
+	zw := zip.NewWriter(w)
+
+	var overrides, workbookSheets, rels strings.Builder
+	for i, sheet := range sheets {
+		n := i + 1
+		fmt.Fprintf(&overrides, `<Override PartName="/xl/worksheets/sheet%d.xml" ContentType="application/vnd.openxmlformats-officedocument.spreadsheetml.worksheet+xml"/>`, n)
+		fmt.Fprintf(&workbookSheets, `<sheet name="%s" sheetId="%d" r:id="rId%d"/>`, xmlEscape(sheetName(sheet.Name, n)), n, n)
+		fmt.Fprintf(&rels, `<Relationship Id="rId%d" Type="http://schemas.openxmlformats.org/officeDocument/2006/relationships/worksheet" Target="worksheets/sheet%d.xml"/>`, n, n)
+	}
+	fmt.Fprintf(&rels, `<Relationship Id="rId%d" Type="http://schemas.openxmlformats.org/officeDocument/2006/relationships/styles" Target="styles.xml"/>`, len(sheets)+1)
+
+	parts := []struct{ name, content string }{
+		{"[Content_Types].xml", xml.Header + `<Types xmlns="http://schemas.openxmlformats.org/package/2006/content-types">` +
+			`<Default Extension="rels" ContentType="application/vnd.openxmlformats-package.relationships+xml"/>` +
+			`<Default Extension="xml" ContentType="application/xml"/>` +
+			`<Override PartName="/xl/workbook.xml" ContentType="application/vnd.openxmlformats-officedocument.spreadsheetml.sheet.main+xml"/>` +
+			`<Override PartName="/xl/styles.xml" ContentType="application/vnd.openxmlformats-officedocument.spreadsheetml.styles+xml"/>` +
+			overrides.String() + `</Types>`},
+		{"_rels/.rels", xml.Header + `<Relationships xmlns="http://schemas.openxmlformats.org/package/2006/relationships">` +
+			`<Relationship Id="rId1" Type="http://schemas.openxmlformats.org/officeDocument/2006/relationships/officeDocument" Target="xl/workbook.xml"/>` +
+			`</Relationships>`},
+		{"xl/workbook.xml", xml.Header + `<workbook xmlns="http://schemas.openxmlformats.org/spreadsheetml/2006/main" xmlns:r="http://schemas.openxmlformats.org/officeDocument/2006/relationships">` +
+			`<sheets>` + workbookSheets.String() + `</sheets></workbook>`},
+		{"xl/_rels/workbook.xml.rels", xml.Header + `<Relationships xmlns="http://schemas.openxmlformats.org/package/2006/relationships">` +
+			rels.String() + `</Relationships>`},
+		{"xl/styles.xml", xml.Header + `<styleSheet xmlns="http://schemas.openxmlformats.org/spreadsheetml/2006/main">` +
+			`<fonts count="2"><font><sz val="11"/><name val="Calibri"/></font><font><b/><sz val="11"/><name val="Calibri"/></font></fonts>` +
+			`<fills count="2"><fill><patternFill patternType="none"/></fill><fill><patternFill patternType="gray125"/></fill></fills>` +
+			`<borders count="1"><border><left/><right/><top/><bottom/><diagonal/></border></borders>` +
+			`<cellStyleXfs count="1"><xf numFmtId="0" fontId="0" fillId="0" borderId="0"/></cellStyleXfs>` +
+			`<cellXfs count="2"><xf numFmtId="0" fontId="0" fillId="0" borderId="0" xfId="0"/><xf numFmtId="0" fontId="1" fillId="0" borderId="0" xfId="0" applyFont="1"/></cellXfs>` +
+			`</styleSheet>`},
+	}
+	for i, sheet := range sheets {
+		parts = append(parts, struct{ name, content string }{fmt.Sprintf("xl/worksheets/sheet%d.xml", i+1), worksheetXML(sheet)})
+	}
+
+	for _, part := range parts {
+		f, err := zw.Create(part.name)
+		if err != nil {
+			return err
+		}
+		if _, err := io.WriteString(f, part.content); err != nil {
+			return err
+		}
+	}
+	return zw.Close()
+}
+
+// worksheetXML lays out the header and rows of a sheet as worksheet cells
+func worksheetXML(sheet Sheet) string {
+	var b strings.Builder
+	b.WriteString(xml.Header + `<worksheet xmlns="http://schemas.openxmlformats.org/spreadsheetml/2006/main"><sheetData>`)
+
+	b.WriteString(`<row r="1">`)
+	for i, name := range sheet.Header {
+		fmt.Fprintf(&b, `<c r="%s1" s="1" t="inlineStr"><is><t>%s</t></is></c>`, columnName(i), xmlEscape(name))
+	}
+	b.WriteString(`</row>`)
+
+	for r, row := range sheet.Rows {
+		fmt.Fprintf(&b, `<row r="%d">`, r+2)
+		for i, value := range row {
+			ref := fmt.Sprintf("%s%d", columnName(i), r+2)
+			switch {
+			case value == "":
+			case sheet.Numeric[i]:
+				fmt.Fprintf(&b, `<c r="%s"><v>%s</v></c>`, ref, xmlEscape(value))
+			default:
+				fmt.Fprintf(&b, `<c r="%s" t="inlineStr"><is><t xml:space="preserve">%s</t></is></c>`, ref, xmlEscape(value))
+			}
+		}
+		b.WriteString(`</row>`)
+	}
+
+	b.WriteString(`</sheetData></worksheet>`)
+	return b.String()
+}
+
+// columnName returns the spreadsheet name of a zero based column, e.g. "AB"
+func columnName(i int) string {
+	name := ""
+	for i++; i > 0; i = (i - 1) / 26 {
+		name = string(rune('A'+(i-1)%26)) + name
+	}
+	return name
+}
+
+// sheetName returns a valid worksheet name: at most 31 characters, none of
+// them forbidden, and not empty
+func sheetName(name string, n int) string {
+	name = strings.Map(func(r rune) rune {
+		if strings.ContainsRune(`[]:*?/\`, r) {
+			return '_'
+		}
+		return r
+	}, name)
+	if runes := []rune(name); len(runes) > 31 {
+		name = string(runes[:31])
+	}
+	if name == "" {
+		name = fmt.Sprintf("Sheet%d", n)
+	}
+	return name
+}
+
+// xmlEscape escapes text for XML, replacing characters XML cannot hold
+func xmlEscape(s string) string {
+	var b strings.Builder
+	xml.EscapeText(&b, []byte(s))
+	return b.String()
+}
@@ -0,0 +1,216 @@
+package rules
+
+import (
+	"fmt"
+	"path"
+	"slices"
+	"strconv"
+	"strings"
+
+	"github.com/jparrill/devtrackr/internal/models"
+)
+
+// Facts is what rules know about an issue when they are evaluated. It is also
+// the data of action templates, e.g. {{.Issue.Key}}.
+type Facts struct {
+	Issue        *models.Issue
+	PullRequests []*models.PullRequest
+	// PullRequest is the pull request that triggered the rule, if any
+	PullRequest *models.PullRequest
+	// MissingBackports lists the branches the issue is expected to land on
+	// without a pull request, merged or not
+	MissingBackports []string
+}
+
+// issueFields reads the single-valued fields of an issue by condition name
+var issueFields = map[string]func(*models.Issue) string{
+	"issue.key":             func(i *models.Issue) string { return i.Key },
+	"issue.project":         func(i *models.Issue) string { return i.Project() },
+	"issue.instance":        func(i *models.Issue) string { return i.Instance },
+	"issue.status":          func(i *models.Issue) string { return i.Status },
+	"issue.status_category": func(i *models.Issue) string { return i.StatusCategory },
+	"issue.assignee":        func(i *models.Issue) string { return i.Assignee },
+	"issue.reporter":        func(i *models.Issue) string { return i.Reporter },
+	"issue.priority":        func(i *models.Issue) string { return i.Priority },
+	"issue.type":            func(i *models.Issue) string { return i.IssueType },
+	"issue.resolution":      func(i *models.Issue) string { return i.Resolution },
+	"issue.sprint":          func(i *models.Issue) string { return i.Sprint },
+	"issue.epic_link":       func(i *models.Issue) string { return i.EpicLink },
+}
+
+// issueListFields reads the multi-valued fields of an issue by condition name
+var issueListFields = map[string]func(*models.Issue) []string{
+	"issue.labels":           func(i *models.Issue) []string { return i.Labels },
+	"issue.components":       func(i *models.Issue) []string { return i.Components },
+	"issue.fix_versions":     func(i *models.Issue) []string { return i.FixVersions },
+	"issue.affects_versions": func(i *models.Issue) []string { return i.AffectsVersions },
+}
+
+// pullRequestFields reads the fields of the triggering pull request
+var pullRequestFields = map[string]func(*models.PullRequest) string{
+	"pr.status":        func(pr *models.PullRequest) string { return string(pr.Status) },
+	"pr.repository":    func(pr *models.PullRequest) string { return pr.Repository },
+	"pr.target_branch": func(pr *models.PullRequest) string { return pr.TargetBranch },
+	"pr.ci_status":     func(pr *models.PullRequest) string { return string(pr.CIStatus) },
+	"pr.approvals":     func(pr *models.PullRequest) string { return strconv.Itoa(pr.Approvals) },
+	"pr.is_backport":   func(pr *models.PullRequest) string { return strconv.FormatBool(pr.IsBackport) },
+}
+
+// pullRequestCounts count the pull requests of an issue in some states
+var pullRequestCounts = map[string]func(*models.PullRequest) bool{
+	"prs.total": func(*models.PullRequest) bool { return true },
+	"prs.open": func(pr *models.PullRequest) bool {
+		return pr.Status != models.PRStatusMerged && pr.Status != models.PRStatusClosed
+	},
+	"prs.merged": func(pr *models.PullRequest) bool { return pr.Status == models.PRStatusMerged },
+	"prs.closed": func(pr *models.PullRequest) bool { return pr.Status == models.PRStatusClosed },
+}
+
+// attributePrefix selects custom field attributes, e.g. issue.attributes.qa_contact
+const attributePrefix = "issue.attributes."
+
+// knownField reports whether a condition can read a field
+func knownField(field string) bool {
+	_, scalar := issueFields[field]
+	_, list := issueListFields[field]
+	_, pr := pullRequestFields[field]
+	_, count := pullRequestCounts[field]
+	return scalar || list || pr || count || field == "backports.missing" ||
+		(strings.HasPrefix(field, attributePrefix) && len(field) > len(attributePrefix))
+}
+
+// values returns the values of a field. Single-valued fields return their
+// value even when empty.
+func (f *Facts) values(field, branch string) []string {
+	if get, ok := issueFields[field]; ok {
+		return []string{get(f.Issue)}
+	}
+	if get, ok := issueListFields[field]; ok {
+		return get(f.Issue)
+	}
+	if get, ok := pullRequestFields[field]; ok {
+		if f.PullRequest == nil {
+			return []string{""}
+		}
+		return []string{get(f.PullRequest)}
+	}
+	if counts, ok := pullRequestCounts[field]; ok {
+		n := 0
+		for _, pr := range f.PullRequests {
+			if branch != "" {
+				if ok, _ := path.Match(branch, pr.TargetBranch); !ok {
+					continue
+				}
+			}
+			if counts(pr) {
+				n++
+			}
+		}
+		return []string{strconv.Itoa(n)}
+	}
+	if field == "backports.missing" {
+		return f.MissingBackports
+	}
+
+	switch v := f.Issue.Attributes[strings.TrimPrefix(field, attributePrefix)].(type) {
+	case nil:
+		return []string{""}
+	case []any:
+		list := make([]string, 0, len(v))
+		for _, item := range v {
+			list = append(list, fmt.Sprint(item))
+		}
+		return list
+	case float64:
+		return []string{strconv.FormatFloat(v, 'f', -1, 64)}
+	default:
+		return []string{fmt.Sprint(v)}
+	}
+}
+
+// Condition tests a field of the issue, its pull requests or the pull
+// request that triggered the rule. Exactly one comparison must be set. Text
+// comparisons ignore case, and multi-valued fields match when any value does.
+type Condition struct {
+	// Field is e.g. issue.status, issue.labels, issue.attributes.<name>,
+	// pr.target_branch, prs.open or backports.missing
+	Field string `yaml:"field"`
+	// Branch limits prs.* counts to pull requests targeting a matching
+	// branch, e.g. "main" or "release-*"
+	Branch string `yaml:"branch"`
+
+	Equals    any      `yaml:"equals"`
+	NotEquals any      `yaml:"not_equals"`
+	In        []string `yaml:"in"`
+	NotIn     []string `yaml:"not_in"`
+	Contains  string   `yaml:"contains"`
+	Empty     *bool    `yaml:"empty"`
+	AtLeast   *float64 `yaml:"at_least"`
+	AtMost    *float64 `yaml:"at_most"`
+}
+
+// validate checks the field and that exactly one comparison is set
+func (c Condition) validate() error {
+	if !knownField(c.Field) {
+		return fmt.Errorf("unknown condition field %q", c.Field)
+	}
+	if c.Branch != "" {
+		if _, ok := pullRequestCounts[c.Field]; !ok {
+			return fmt.Errorf("condition branch only applies to prs.* fields, not %q", c.Field)
+		}
+		if _, err := path.Match(c.Branch, ""); err != nil {
+			return fmt.Errorf("invalid condition branch %q: %w", c.Branch, err)
+		}
+	}
+
+	set := 0
+	for _, ok := range []bool{c.Equals != nil, c.NotEquals != nil, c.In != nil, c.NotIn != nil, c.Contains != "", c.Empty != nil, c.AtLeast != nil, c.AtMost != nil} {
+		if ok {
+			set++
+		}
+	}
+	if set != 1 {
+		return fmt.Errorf("condition on %s needs exactly one of equals, not_equals, in, not_in, contains, empty, at_least and at_most", c.Field)
+	}
+	return nil
+}
+
+// Holds reports whether the condition holds for the facts
+func (c Condition) Holds(f *Facts) bool {
+	values := f.values(c.Field, c.Branch)
+
+	switch {
+	case c.Equals != nil:
+		return anyEqual(values, fmt.Sprint(c.Equals))
+	case c.NotEquals != nil:
+		return !anyEqual(values, fmt.Sprint(c.NotEquals))
+	case c.In != nil:
+		return slices.ContainsFunc(c.In, func(v string) bool { return anyEqual(values, v) })
+	case c.NotIn != nil:
+		return !slices.ContainsFunc(c.NotIn, func(v string) bool { return anyEqual(values, v) })
+	case c.Contains != "":
+		want := strings.ToLower(c.Contains)
+		return slices.ContainsFunc(values, func(v string) bool { return strings.Contains(strings.ToLower(v), want) })
+	case c.Empty != nil:
+		empty := !slices.ContainsFunc(values, func(v string) bool { return v != "" })
+		return empty == *c.Empty
+	case c.AtLeast != nil, c.AtMost != nil:
+		if len(values) != 1 {
+			return false
+		}
+		n, err := strconv.ParseFloat(values[0], 64)
+		if err != nil {
+			return false
+		}
+		if c.AtLeast != nil {
+			return n >= *c.AtLeast
+		}
+		return n <= *c.AtMost
+	}
+	return false
+}
+
+// anyEqual reports whether any of values equals want, ignoring case
+func anyEqual(values []string, want string) bool {
+	return slices.ContainsFunc(values, func(v string) bool { return strings.EqualFold(v, want) })
+}
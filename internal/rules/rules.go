@@ -0,0 +1,319 @@
+// Package rules matches automation rules against changes of tracked issues
+// and their pull requests.
+package rules
+
+import (
+	"cmp"
+	"fmt"
+	"path"
+	"strings"
+	"text/template"
+	"time"
+)
+
+// TriggerType represents what makes a rule run
+type TriggerType string
+
+const (
+	TriggerIssueStatus TriggerType = "issue_status"
+	TriggerPullRequest TriggerType = "pr_state"
+	TriggerSchedule    TriggerType = "schedule"
+)
+
+// Any matches every status in triggers
+const Any = "*"
+
+// Rule runs its actions on an issue when its trigger fires and every
+// condition holds
+type Rule struct {
+	Name       string      `yaml:"name"`
+	Trigger    Trigger     `yaml:"trigger"`
+	Conditions []Condition `yaml:"conditions"`
+	Actions    []Action    `yaml:"actions"`
+	// DryRun records the changes to Jira in the audit log without sending
+	// them, and only logs notifications
+	DryRun bool `yaml:"dry_run"`
+}
+
+// Trigger sets when a rule runs. Exactly one of IssueStatus, PullRequest and
+// Schedule must be set.
+type Trigger struct {
+	// IssueStatus fires when an issue moves to this Jira status, or to any
+	// status with "*"
+	IssueStatus string `yaml:"issue_status"`
+	// PullRequest fires when a pull request of the issue moves to this
+	// state (open, draft, review, approved, merged, closed), or any with "*"
+	PullRequest string `yaml:"pr_state"`
+	// From restricts status and pull request triggers to changes from this value
+	From string `yaml:"from"`
+	// Branch restricts pull request triggers to pull requests targeting a
+	// matching branch, e.g. "release-*"
+	Branch string `yaml:"branch"`
+	// Schedule runs the rule on every tracked issue this often
+	Schedule time.Duration `yaml:"schedule"`
+}
+
+// Type returns the kind of trigger
+func (t Trigger) Type() TriggerType {
+	switch {
+	case t.IssueStatus != "":
+		return TriggerIssueStatus
+	case t.PullRequest != "":
+		return TriggerPullRequest
+	case t.Schedule > 0:
+		return TriggerSchedule
+	}
+	return ""
+}
+
+// validate checks that exactly one kind of trigger is set
+func (t Trigger) validate() error {
+	set := 0
+	for _, ok := range []bool{t.IssueStatus != "", t.PullRequest != "", t.Schedule > 0} {
+		if ok {
+			set++
+		}
+	}
+	if set != 1 {
+		return fmt.Errorf("trigger needs exactly one of issue_status, pr_state and schedule")
+	}
+	if t.Branch != "" {
+		if t.PullRequest == "" {
+			return fmt.Errorf("trigger branch only applies to pr_state")
+		}
+		if _, err := path.Match(t.Branch, ""); err != nil {
+			return fmt.Errorf("invalid trigger branch %q: %w", t.Branch, err)
+		}
+	}
+	return nil
+}
+
+// Event is a change that may trigger rules
+type Event struct {
+	Type TriggerType
+	From string
+	To   string
+	// Branch is the target branch of the pull request that changed
+	Branch string
+}
+
+// fires reports whether the trigger matches an event
+func (t Trigger) fires(ev Event) bool {
+	if ev.Type != t.Type() {
+		return false
+	}
+
+	var to string
+	switch ev.Type {
+	case TriggerIssueStatus:
+		to = t.IssueStatus
+	case TriggerPullRequest:
+		to = t.PullRequest
+		if t.Branch != "" {
+			if ok, _ := path.Match(t.Branch, ev.Branch); !ok {
+				return false
+			}
+		}
+	default:
+		return true
+	}
+
+	if to != Any && !strings.EqualFold(to, ev.To) {
+		return false
+	}
+	return t.From == "" || strings.EqualFold(t.From, ev.From)
+}
+
+// Action is a single step run by a rule. Exactly one field must be set.
+// Texts are Go templates over the Facts of the issue.
+type Action struct {
+	// Transition moves the issue through the Jira transition with this
+	// name, or leading to the status with this name
+	Transition string `yaml:"transition"`
+	// Comment posts this text on the issue in Jira
+	Comment string `yaml:"comment"`
+	// CommentPullRequests posts the state of the linked pull requests on
+	// the issue in Jira
+	CommentPullRequests bool `yaml:"comment_pull_requests"`
+	// Notify sends a notification about the issue
+	Notify *Notify `yaml:"notify"`
+	// SetPolling overrides the polling interval of the issue; zero returns
+	// it to the configured policies
+	SetPolling *time.Duration `yaml:"set_polling"`
+}
+
+// Notify describes a notification sent by a rule
+type Notify struct {
+	// To is assignee, reporter or a fixed recipient (default: assignee)
+	To      string `yaml:"to"`
+	Message string `yaml:"message"`
+}
+
+// Recipient resolves who a notification is for
+func (n Notify) Recipient(f *Facts) string {
+	switch strings.ToLower(n.To) {
+	case "", "assignee":
+		return f.Issue.Assignee
+	case "reporter":
+		return f.Issue.Reporter
+	}
+	return n.To
+}
+
+// validate checks that exactly one step is set and its templates parse
+func (a Action) validate() error {
+	set := 0
+	for _, ok := range []bool{a.Transition != "", a.Comment != "", a.CommentPullRequests, a.Notify != nil, a.SetPolling != nil} {
+		if ok {
+			set++
+		}
+	}
+	if set != 1 {
+		return fmt.Errorf("action needs exactly one of transition, comment, comment_pull_requests, notify and set_polling")
+	}
+
+	for _, text := range []string{a.Transition, a.Comment} {
+		if _, err := parseTemplate(text); err != nil {
+			return err
+		}
+	}
+	if a.Notify != nil {
+		if a.Notify.Message == "" {
+			return fmt.Errorf("notify needs a message")
+		}
+		if _, err := parseTemplate(a.Notify.Message); err != nil {
+			return err
+		}
+	}
+	if a.SetPolling != nil && *a.SetPolling < 0 {
+		return fmt.Errorf("set_polling cannot be negative")
+	}
+	return nil
+}
+
+// Describe summarizes what the action does for an issue, for logs and dry runs
+func (a Action) Describe(f *Facts) string {
+	switch {
+	case a.Transition != "":
+		return fmt.Sprintf("transition to %q", Render(a.Transition, f))
+	case a.Comment != "":
+		return fmt.Sprintf("comment %q", Render(a.Comment, f))
+	case a.CommentPullRequests:
+		return "comment the state of the pull requests"
+	case a.Notify != nil:
+		to := a.Notify.Recipient(f)
+		if to == "" {
+			to = "nobody (no " + cmp.Or(strings.ToLower(a.Notify.To), "assignee") + ")"
+		}
+		return fmt.Sprintf("notify %s: %q", to, Render(a.Notify.Message, f))
+	case a.SetPolling != nil:
+		if *a.SetPolling == 0 {
+			return "reset the polling interval"
+		}
+		return fmt.Sprintf("set the polling interval to %v", *a.SetPolling)
+	}
+	return "nothing"
+}
+
+// templateFuncs are available in action texts
+var templateFuncs = template.FuncMap{
+	"join": strings.Join,
+}
+
+// parseTemplate parses an action text
+func parseTemplate(text string) (*template.Template, error) {
+	t, err := template.New("action").Funcs(templateFuncs).Option("missingkey=zero").Parse(text)
+	if err != nil {
+		return nil, fmt.Errorf("invalid template %q: %w", text, err)
+	}
+	return t, nil
+}
+
+// Render expands an action text for an issue. Texts are checked when rules
+// are loaded, so errors only leave the text as is.
+func Render(text string, f *Facts) string {
+	t, err := parseTemplate(text)
+	if err != nil {
+		return text
+	}
+	var b strings.Builder
+	if err := t.Execute(&b, f); err != nil {
+		return text
+	}
+	return b.String()
+}
+
+// Engine holds validated rules and matches them against events
+type Engine struct {
+	rules []Rule
+}
+
+// NewEngine validates rules and returns an engine matching them
+func NewEngine(rules []Rule) (*Engine, error) {
+	names := map[string]bool{}
+	for i, r := range rules {
+		if r.Name == "" {
+			return nil, fmt.Errorf("rule %d needs a name", i+1)
+		}
+		if names[r.Name] {
+			return nil, fmt.Errorf("duplicate rule %q", r.Name)
+		}
+		names[r.Name] = true
+
+		if err := r.Trigger.validate(); err != nil {
+			return nil, fmt.Errorf("rule %q: %w", r.Name, err)
+		}
+		for _, c := range r.Conditions {
+			if err := c.validate(); err != nil {
+				return nil, fmt.Errorf("rule %q: %w", r.Name, err)
+			}
+		}
+		if len(r.Actions) == 0 {
+			return nil, fmt.Errorf("rule %q has no actions", r.Name)
+		}
+		for _, a := range r.Actions {
+			if err := a.validate(); err != nil {
+				return nil, fmt.Errorf("rule %q: %w", r.Name, err)
+			}
+		}
+	}
+	return &Engine{rules: rules}, nil
+}
+
+// Rules returns every rule of the engine
+func (e *Engine) Rules() []Rule {
+	return e.rules
+}
+
+// Scheduled returns the rules run on a schedule
+func (e *Engine) Scheduled() []Rule {
+	var scheduled []Rule
+	for _, r := range e.rules {
+		if r.Trigger.Type() == TriggerSchedule {
+			scheduled = append(scheduled, r)
+		}
+	}
+	return scheduled
+}
+
+// Match returns the rules triggered by an event whose conditions hold for
+// the facts of the issue, in configuration order
+func (e *Engine) Match(ev Event, f *Facts) []Rule {
+	var matched []Rule
+	for _, r := range e.rules {
+		if r.Trigger.fires(ev) && r.Holds(f) {
+			matched = append(matched, r)
+		}
+	}
+	return matched
+}
+
+// Holds reports whether every condition of the rule holds for the facts
+func (r Rule) Holds(f *Facts) bool {
+	for _, c := range r.Conditions {
+		if !c.Holds(f) {
+			return false
+		}
+	}
+	return true
+}
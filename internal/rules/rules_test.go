@@ -0,0 +1,171 @@
+package rules
+
+import (
+	"testing"
+	"time"
+
+	"github.com/jparrill/devtrackr/internal/models"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"gopkg.in/yaml.v3"
+)
+
+const exampleRules = `
+- name: review-on-first-pr
+  trigger:
+    pr_state: open
+  conditions:
+    - field: prs.total
+      equals: 1
+    - field: issue.status_category
+      not_equals: done
+  actions:
+    - transition: Code Review
+- name: on-qa-when-main-merged
+  trigger:
+    pr_state: merged
+    branch: main
+  conditions:
+    - field: prs.open
+      branch: main
+      equals: 0
+  actions:
+    - transition: ON_QA
+    - comment_pull_requests: true
+- name: missing-backports
+  trigger:
+    schedule: 24h
+  conditions:
+    - field: issue.status
+      in: [ON_QA, Verified]
+    - field: backports.missing
+      empty: false
+  actions:
+    - notify:
+        message: "backports missing for {{ join .MissingBackports \", \" }}"
+`
+
+func loadRules(t *testing.T, text string) *Engine {
+	var rules []Rule
+	require.NoError(t, yaml.Unmarshal([]byte(text), &rules))
+	engine, err := NewEngine(rules)
+	require.NoError(t, err)
+	return engine
+}
+
+func names(rules []Rule) []string {
+	var result []string
+	for _, r := range rules {
+		result = append(result, r.Name)
+	}
+	return result
+}
+
+func TestMatch(t *testing.T) {
+	engine := loadRules(t, exampleRules)
+	issue := &models.Issue{Key: "OCPBUGS-1", Status: "New", StatusCategory: models.StatusCategoryToDo, Assignee: "alice"}
+	mainPR := &models.PullRequest{Number: 1, TargetBranch: "main", Status: models.PRStatusOpen}
+
+	facts := &Facts{Issue: issue, PullRequests: []*models.PullRequest{mainPR}, PullRequest: mainPR}
+	matched := engine.Match(Event{Type: TriggerPullRequest, From: "", To: "open", Branch: "main"}, facts)
+	assert.Equal(t, []string{"review-on-first-pr"}, names(matched))
+
+	// A second pull request does not move the issue again
+	backport := &models.PullRequest{Number: 2, TargetBranch: "release-4.16", Status: models.PRStatusOpen}
+	facts = &Facts{Issue: issue, PullRequests: []*models.PullRequest{mainPR, backport}, PullRequest: backport}
+	assert.Empty(t, engine.Match(Event{Type: TriggerPullRequest, To: "open", Branch: "release-4.16"}, facts))
+
+	// Merging on main only counts pull requests targeting main
+	mainPR.Status = models.PRStatusMerged
+	facts = &Facts{Issue: issue, PullRequests: []*models.PullRequest{mainPR, backport}, PullRequest: mainPR}
+	matched = engine.Match(Event{Type: TriggerPullRequest, From: "open", To: "merged", Branch: "main"}, facts)
+	assert.Equal(t, []string{"on-qa-when-main-merged"}, names(matched))
+
+	// Merges elsewhere do not fire the rule
+	backport.Status = models.PRStatusMerged
+	facts.PullRequest = backport
+	assert.Empty(t, engine.Match(Event{Type: TriggerPullRequest, From: "open", To: "merged", Branch: "release-4.16"}, facts))
+
+	assert.Equal(t, []string{"missing-backports"}, names(engine.Scheduled()))
+}
+
+func TestConditions(t *testing.T) {
+	issue := &models.Issue{
+		Key:        "OCPBUGS-1",
+		Status:     "ON_QA",
+		Labels:     []string{"Triaged", "ui"},
+		Attributes: map[string]any{"story_points": float64(3), "target_version": []any{"4.16.z"}},
+	}
+	facts := &Facts{Issue: issue, MissingBackports: []string{"release-4.15"}}
+	yes, no := true, false
+	three := float64(3)
+
+	tests := []struct {
+		name      string
+		condition Condition
+		holds     bool
+	}{
+		{"equals ignores case", Condition{Field: "issue.status", Equals: "on_qa"}, true},
+		{"in", Condition{Field: "issue.status", In: []string{"New", "ON_QA"}}, true},
+		{"not in", Condition{Field: "issue.status", NotIn: []string{"ON_QA"}}, false},
+		{"list equals any value", Condition{Field: "issue.labels", Equals: "triaged"}, true},
+		{"contains", Condition{Field: "issue.labels", Contains: "UI"}, true},
+		{"empty scalar", Condition{Field: "issue.assignee", Empty: &yes}, true},
+		{"empty list", Condition{Field: "backports.missing", Empty: &no}, true},
+		{"numeric attribute", Condition{Field: "issue.attributes.story_points", AtLeast: &three}, true},
+		{"array attribute", Condition{Field: "issue.attributes.target_version", Equals: "4.16.z"}, true},
+		{"missing attribute", Condition{Field: "issue.attributes.qa_contact", Empty: &yes}, true},
+		{"no triggering pull request", Condition{Field: "pr.status", Equals: "merged"}, false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			require.NoError(t, tt.condition.validate())
+			assert.Equal(t, tt.holds, tt.condition.Holds(facts))
+		})
+	}
+}
+
+func TestNewEngineValidates(t *testing.T) {
+	hour := time.Hour
+	valid := Rule{Name: "r", Trigger: Trigger{IssueStatus: "*"}, Actions: []Action{{SetPolling: &hour}}}
+
+	tests := []struct {
+		name   string
+		modify func(*Rule)
+	}{
+		{"no name", func(r *Rule) { r.Name = "" }},
+		{"two triggers", func(r *Rule) { r.Trigger.Schedule = time.Hour }},
+		{"no trigger", func(r *Rule) { r.Trigger = Trigger{} }},
+		{"branch on a status trigger", func(r *Rule) { r.Trigger.Branch = "main" }},
+		{"unknown field", func(r *Rule) { r.Conditions = []Condition{{Field: "issue.color", Equals: "red"}} }},
+		{"no comparison", func(r *Rule) { r.Conditions = []Condition{{Field: "issue.status"}} }},
+		{"no actions", func(r *Rule) { r.Actions = nil }},
+		{"two steps in an action", func(r *Rule) { r.Actions = []Action{{Transition: "Done", Comment: "done"}} }},
+		{"bad template", func(r *Rule) { r.Actions = []Action{{Comment: "{{ .Issue.Key"}} }},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			r := valid
+			tt.modify(&r)
+			_, err := NewEngine([]Rule{r})
+			assert.Error(t, err)
+		})
+	}
+
+	_, err := NewEngine([]Rule{valid, valid})
+	assert.Error(t, err, "duplicate names")
+}
+
+func TestDescribe(t *testing.T) {
+	engine := loadRules(t, exampleRules)
+	facts := &Facts{
+		Issue:            &models.Issue{Key: "OCPBUGS-1", Assignee: "alice"},
+		MissingBackports: []string{"release-4.15", "release-4.14"},
+	}
+
+	notify := engine.Scheduled()[0].Actions[0]
+	assert.Equal(t, `notify alice: "backports missing for release-4.15, release-4.14"`, notify.Describe(facts))
+
+	facts.Issue.Assignee = ""
+	assert.Equal(t, `notify nobody (no assignee): "backports missing for release-4.15, release-4.14"`, notify.Describe(facts))
+}
@@ -0,0 +1,229 @@
+package services
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"slices"
+	"sort"
+	"time"
+
+	"github.com/jparrill/devtrackr/internal/alerts"
+	"github.com/jparrill/devtrackr/internal/models"
+)
+
+// StaleAlerts looks for stale work among the tracked issues passing a filter
+// and their pull requests: open pull requests without activity, issues stuck
+// in one status, backports still missing after the original merged, and
+// issues that have not been polled successfully for a while. Thresholds come
+// from the alerts configuration of each project.
+func (s *TrackingService) StaleAlerts(ctx context.Context, filter models.IssueFilter) ([]alerts.Alert, error) {
+	issues, err := s.ListIssuesMatching(ctx, filter)
+	if err != nil {
+		return nil, err
+	}
+
+	now := time.Now()
+	policy := s.config.PollingPolicy()
+	result := []alerts.Alert{}
+	for _, issue := range issues {
+		prs, err := s.storage.ListPullRequests(ctx, issue.ID)
+		if err != nil {
+			return nil, fmt.Errorf("failed to list pull requests for %s: %w", issue.Key, err)
+		}
+		events, err := s.storage.ListEvents(ctx, issue.ID)
+		if err != nil {
+			return nil, fmt.Errorf("failed to list events for %s: %w", issue.Key, err)
+		}
+
+		t := s.config.Alerts.ThresholdsFor(issue.Project())
+		newAlert := func(kind alerts.Kind, since time.Time, message string) alerts.Alert {
+			return alerts.Alert{
+				Kind:     kind,
+				Issue:    issue.QualifiedKey(),
+				Title:    issue.Title,
+				URL:      issue.JiraURL,
+				Assignee: issue.Assignee,
+				Since:    since,
+				Message:  message,
+			}
+		}
+
+		if t.PullRequestIdle > 0 {
+			for _, pr := range prs {
+				since := pr.CreatedAt
+				if pr.UpdatedAt.After(since) {
+					since = pr.UpdatedAt
+				}
+				if !isPendingPullRequest(pr) || now.Sub(since) < t.PullRequestIdle {
+					continue
+				}
+				alert := newAlert(alerts.KindPullRequestIdle, since,
+					fmt.Sprintf("%s is %s with no activity since %s", prLabel(pr), pr.Status, since.Format(time.DateOnly)))
+				alert.URL, alert.PullRequest = pr.URL, prLabel(pr)
+				result = append(result, alert)
+			}
+		}
+
+		if t.StatusStuck > 0 && !isDoneStatus(issue.Status, issue.StatusCategory) {
+			since := statusSince(issue, events)
+			if now.Sub(since) >= t.StatusStuck {
+				result = append(result, newAlert(alerts.KindStatusStuck, since,
+					fmt.Sprintf("in %s since %s", issue.Status, since.Format(time.DateOnly))))
+			}
+		}
+
+		if t.BackportMissing > 0 {
+			if merged, ok := s.originalMerged(prs, events); ok && now.Sub(merged) >= t.BackportMissing {
+				expected := s.config.ExpectedBranches(issue.Project())
+				for _, branch := range newFacts(issue, prs, expected).MissingBackports {
+					if slices.Contains(s.config.Backport.DefaultBranches, branch) {
+						continue
+					}
+					alert := newAlert(alerts.KindBackportMissing, merged,
+						fmt.Sprintf("no backport to %s since the original merged on %s", branch, merged.Format(time.DateOnly)))
+					alert.Branch = branch
+					result = append(result, alert)
+				}
+			}
+		}
+
+		// Issues no longer polled by their policy are not expected to be fresh.
+		// Issues never polled successfully are stale since they were tracked.
+		polled, message := issue.LastPolledAt, "last polled successfully on %s"
+		if polled.IsZero() {
+			polled, message = issue.CreatedAt, "never polled successfully since tracked on %s"
+		}
+		if t.PollStale > 0 && now.Sub(polled) >= t.PollStale {
+			hasUnmerged := slices.ContainsFunc(prs, isPendingPullRequest)
+			if !policy.Evaluate(issue, hasUnmerged, now).Stopped {
+				result = append(result, newAlert(alerts.KindPollStale, polled,
+					fmt.Sprintf(message, polled.Format(time.DateOnly))))
+			}
+		}
+	}
+
+	sort.SliceStable(result, func(i, j int) bool {
+		return result[i].Since.Before(result[j].Since)
+	})
+	return result, nil
+}
+
+// statusSince returns when an issue entered its current status: its last
+// recorded status change, or when it was tracked
+func statusSince(issue *models.Issue, events []*models.Event) time.Time {
+	since := issue.CreatedAt
+	for _, e := range events {
+		if e.Type == models.EventStatusChanged && e.OccurredAt.After(since) {
+			since = e.OccurredAt
+		}
+	}
+	return since
+}
+
+// originalMerged returns when the first original pull request of an issue
+// merged on a development branch, from its timeline or, for pull requests
+// tracked already merged, from when they were last updated
+func (s *TrackingService) originalMerged(prs []*models.PullRequest, events []*models.Event) (time.Time, bool) {
+	var first time.Time
+	for _, pr := range prs {
+		if pr.IsBackport || pr.Status != models.PRStatusMerged ||
+			!slices.Contains(s.config.Backport.DefaultBranches, pr.TargetBranch) {
+			continue
+		}
+
+		merged := pr.UpdatedAt
+		for _, e := range events {
+			if e.Type == models.EventPullRequestChanged && e.Field == prLabel(pr) && e.To == string(models.PRStatusMerged) {
+				merged = e.OccurredAt
+				break
+			}
+		}
+		if first.IsZero() || merged.Before(first) {
+			first = merged
+		}
+	}
+	return first, !first.IsZero()
+}
+
+// AlertNotification builds the notification nudging the assignee about an alert
+func AlertNotification(alert alerts.Alert) Notification {
+	return Notification{
+		Rule:      string(alert.Kind),
+		Issue:     alert.Issue,
+		URL:       alert.URL,
+		Recipient: alert.Assignee,
+		Message:   alert.Message,
+	}
+}
+
+// AlertService runs the stale work detector on a schedule and sends the
+// alerts it did not find on its previous run through a notifier. The alerts
+// sent are only remembered in memory, so the alerts still open are sent once
+// more after a restart.
+type AlertService struct {
+	trackingService *TrackingService
+	interval        time.Duration
+	notifier        Notifier
+	stop            chan struct{}
+}
+
+// NewAlertService creates a new alert service. A nil notifier only logs how
+// many alerts are open.
+func NewAlertService(trackingService *TrackingService, interval time.Duration, notifier Notifier) *AlertService {
+	return &AlertService{
+		trackingService: trackingService,
+		interval:        interval,
+		notifier:        notifier,
+		stop:            make(chan struct{}),
+	}
+}
+
+// Start runs the detector immediately and then on every interval
+func (s *AlertService) Start(ctx context.Context) error {
+	log.Printf("Starting alert service every %v", s.interval)
+
+	ticker := time.NewTicker(s.interval)
+	defer ticker.Stop()
+
+	seen := s.check(ctx, nil)
+	for {
+		select {
+		case <-ctx.Done():
+			return nil
+		case <-s.stop:
+			return nil
+		case <-ticker.C:
+			seen = s.check(ctx, seen)
+		}
+	}
+}
+
+// Stop stops the alert service
+func (s *AlertService) Stop() {
+	close(s.stop)
+}
+
+// check runs the detector and notifies the alerts missing from seen. It
+// returns the alerts found, or seen unchanged when the detector fails.
+func (s *AlertService) check(ctx context.Context, seen map[string]bool) map[string]bool {
+	found, err := s.trackingService.StaleAlerts(ctx, models.IssueFilter{})
+	if err != nil {
+		log.Printf("Error looking for stale work: %v", err)
+		return seen
+	}
+	log.Printf("Found %d stale work alerts", len(found))
+
+	current := make(map[string]bool, len(found))
+	for _, alert := range found {
+		current[alert.ID()] = true
+		if seen[alert.ID()] || s.notifier == nil {
+			continue
+		}
+
+		if err := s.notifier.Notify(ctx, AlertNotification(alert)); err != nil {
+			log.Printf("Error sending %s alert for %s: %v", alert.Kind, alert.Issue, err)
+		}
+	}
+	return current
+}
@@ -0,0 +1,60 @@
+package services
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/jparrill/devtrackr/internal/alerts"
+	"github.com/jparrill/devtrackr/internal/config"
+	"github.com/jparrill/devtrackr/internal/jira"
+	"github.com/jparrill/devtrackr/internal/models"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestStaleAlerts(t *testing.T) {
+	ctx := context.Background()
+	mockStorage := &MockStorage{}
+	cfg := config.Default()
+	cfg.Branches = []string{"main", "release-4.16", "release-4.15"}
+	cfg.Alerts.Projects["QUIET"] = alerts.Thresholds{StatusStuck: 365 * 24 * time.Hour}
+	service := NewTrackingService(mockStorage, jira.NewMockClient("New"), WithConfig(cfg))
+
+	now := time.Now()
+	daysAgo := func(days int) time.Time { return now.Add(-time.Duration(days) * 24 * time.Hour) }
+
+	mockStorage.On("ListIssues").Return([]models.Issue{
+		{ID: 1, Key: "OCPBUGS-1", Status: "POST", CreatedAt: daysAgo(30), LastPolledAt: now, UpdatedAt: now},
+		{ID: 2, Key: "QUIET-1", Status: "New", CreatedAt: daysAgo(30), LastPolledAt: daysAgo(2), UpdatedAt: daysAgo(2)},
+		// Never polled, but tracked too recently to be stale
+		{ID: 3, Key: "OCPBUGS-3", Status: "New", CreatedAt: now, UpdatedAt: now},
+	}, nil)
+	mockStorage.On("ListEvents", ctx, int64(1)).Return([]*models.Event{
+		{Type: models.EventStatusChanged, From: "New", To: "POST", OccurredAt: daysAgo(20)},
+		{Type: models.EventPullRequestChanged, Field: "org/repo#1", From: "open", To: "merged", OccurredAt: daysAgo(5)},
+	}, nil)
+	mockStorage.On("ListEvents", ctx, int64(2)).Return([]*models.Event{}, nil)
+	mockStorage.On("ListEvents", ctx, int64(3)).Return([]*models.Event{}, nil)
+	mockStorage.On("ListPullRequests", ctx, int64(1)).Return([]*models.PullRequest{
+		{Number: 1, Repository: "org/repo", TargetBranch: "main", Status: models.PRStatusMerged, CreatedAt: daysAgo(10), UpdatedAt: daysAgo(1)},
+		{Number: 2, Repository: "org/repo", TargetBranch: "release-4.16", Status: models.PRStatusOpen, IsBackport: true,
+			CreatedAt: daysAgo(9), UpdatedAt: daysAgo(8)},
+	}, nil)
+	mockStorage.On("ListPullRequests", ctx, int64(2)).Return([]*models.PullRequest{}, nil)
+	mockStorage.On("ListPullRequests", ctx, int64(3)).Return([]*models.PullRequest{}, nil)
+
+	found, err := service.StaleAlerts(ctx, models.IssueFilter{})
+	require.NoError(t, err)
+
+	var got []string
+	for _, a := range found {
+		got = append(got, a.ID())
+	}
+	assert.Equal(t, []string{
+		"status_stuck/OCPBUGS-1//",
+		"pull_request_idle/OCPBUGS-1/org/repo#2/",
+		"backport_missing/OCPBUGS-1//release-4.15",
+		"poll_stale/QUIET-1//",
+	}, got, "alerts are ordered by how long the work has been idle")
+}
@@ -0,0 +1,159 @@
+package services
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/jparrill/devtrackr/internal/analytics"
+	"github.com/jparrill/devtrackr/internal/models"
+)
+
+// Analytics measures a metric over the tracked issues passing a filter and
+// summarizes it by group. Times are the ones DevTrackr observed: issues start
+// when they are tracked, and merges are taken from the issue timelines, so
+// pull requests merged before being tracked are left out.
+func (s *TrackingService) Analytics(ctx context.Context, metric analytics.Metric, by analytics.GroupBy, filter models.IssueFilter) (*analytics.Report, error) {
+	issues, err := s.ListIssuesMatching(ctx, filter)
+	if err != nil {
+		return nil, err
+	}
+
+	now := time.Now()
+	var samples []analytics.Sample
+	for _, issue := range issues {
+		events, err := s.storage.ListEvents(ctx, issue.ID)
+		if err != nil {
+			return nil, fmt.Errorf("failed to list events for %s: %w", issue.Key, err)
+		}
+
+		base := analytics.Sample{
+			Issue:    issue.QualifiedKey(),
+			Project:  issue.Project(),
+			Assignee: issue.Assignee,
+			Releases: issueReleases(issue),
+		}
+
+		switch metric {
+		case analytics.MetricTimeInStatus:
+			for status, d := range timeInStatus(issue, events, now) {
+				sample := base
+				sample.Label, sample.Duration = status, d
+				samples = append(samples, sample)
+			}
+		case analytics.MetricLeadTime:
+			if d, ok := leadTime(issue, events); ok {
+				sample := base
+				sample.Duration = d
+				samples = append(samples, sample)
+			}
+		case analytics.MetricMergeTime, analytics.MetricBackportLag:
+			prs, err := s.storage.ListPullRequests(ctx, issue.ID)
+			if err != nil {
+				return nil, fmt.Errorf("failed to list pull requests for %s: %w", issue.Key, err)
+			}
+			for label, d := range pullRequestDurations(metric, prs, events) {
+				sample := base
+				sample.Label, sample.Duration = label, d
+				samples = append(samples, sample)
+			}
+		default:
+			return nil, fmt.Errorf("unknown metric %q", metric)
+		}
+	}
+
+	return analytics.NewReport(metric, by, samples), nil
+}
+
+// issueReleases returns the minor releases an issue targets through its fix
+// versions, e.g. 4.16 for 4.16.0 and 4.16.z
+func issueReleases(issue *models.Issue) []string {
+	var releases []string
+	seen := map[string]bool{}
+	for _, v := range issue.FixVersions {
+		release := minorRelease(v)
+		if !seen[release] {
+			seen[release] = true
+			releases = append(releases, release)
+		}
+	}
+	return releases
+}
+
+// timeInStatus adds up the time an issue spent in each status, from when it
+// was tracked until now. The status before the first recorded change is the
+// one that change started from.
+func timeInStatus(issue *models.Issue, events []*models.Event, now time.Time) map[string]time.Duration {
+	durations := map[string]time.Duration{}
+	since, current := issue.CreatedAt, issue.Status
+	first := true
+	for _, e := range events {
+		if e.Type != models.EventStatusChanged {
+			continue
+		}
+		if first {
+			current, first = e.From, false
+		}
+		if e.OccurredAt.After(since) {
+			durations[current] += e.OccurredAt.Sub(since)
+			since = e.OccurredAt
+		}
+		current = e.To
+	}
+	if now.After(since) {
+		durations[current] += now.Sub(since)
+	}
+	return durations
+}
+
+// leadTime returns the time from tracking an issue to its first change to a
+// done status, if it reached one while tracked. Events do not record status
+// categories, so done statuses are told by name.
+func leadTime(issue *models.Issue, events []*models.Event) (time.Duration, bool) {
+	for _, e := range events {
+		if e.Type == models.EventStatusChanged && isDoneStatus(e.To, "") && e.OccurredAt.After(issue.CreatedAt) {
+			return e.OccurredAt.Sub(issue.CreatedAt), true
+		}
+	}
+	return 0, false
+}
+
+// pullRequestDurations measures the merge time of every pull request merged
+// while tracked, or the lag of every backport behind its original, keyed by
+// pull request
+func pullRequestDurations(metric analytics.Metric, prs []*models.PullRequest, events []*models.Event) map[string]time.Duration {
+	merged := map[string]time.Time{}
+	for _, e := range events {
+		if e.Type == models.EventPullRequestChanged && e.To == string(models.PRStatusMerged) {
+			if _, ok := merged[e.Field]; !ok {
+				merged[e.Field] = e.OccurredAt
+			}
+		}
+	}
+	byID := map[int64]*models.PullRequest{}
+	for _, pr := range prs {
+		byID[pr.ID] = pr
+	}
+
+	durations := map[string]time.Duration{}
+	for _, pr := range prs {
+		mergedAt, ok := merged[prLabel(pr)]
+		if !ok {
+			continue
+		}
+
+		start := pr.CreatedAt
+		if metric == analytics.MetricBackportLag {
+			if !pr.IsBackport || pr.OriginalPRID == nil || byID[*pr.OriginalPRID] == nil {
+				continue
+			}
+			if start, ok = merged[prLabel(byID[*pr.OriginalPRID])]; !ok {
+				continue
+			}
+		}
+		if mergedAt.After(start) {
+			durations[prLabel(pr)] = mergedAt.Sub(start)
+		}
+	}
+	return durations
+}
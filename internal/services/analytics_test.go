@@ -0,0 +1,74 @@
+package services
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/jparrill/devtrackr/internal/analytics"
+	"github.com/jparrill/devtrackr/internal/jira"
+	"github.com/jparrill/devtrackr/internal/models"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestTimeInStatus(t *testing.T) {
+	start := time.Date(2024, 5, 1, 0, 0, 0, 0, time.UTC)
+	issue := &models.Issue{Status: "Closed", CreatedAt: start}
+	events := []*models.Event{
+		{Type: models.EventStatusChanged, From: "New", To: "In Progress", OccurredAt: start.Add(2 * time.Hour)},
+		{Type: models.EventFieldChanged, OccurredAt: start.Add(3 * time.Hour)},
+		{Type: models.EventStatusChanged, From: "In Progress", To: "Closed", OccurredAt: start.Add(10 * time.Hour)},
+	}
+
+	durations := timeInStatus(issue, events, start.Add(12*time.Hour))
+	assert.Equal(t, map[string]time.Duration{
+		"New":         2 * time.Hour,
+		"In Progress": 8 * time.Hour,
+		"Closed":      2 * time.Hour,
+	}, durations)
+
+	d, ok := leadTime(issue, events)
+	require.True(t, ok)
+	assert.Equal(t, 10*time.Hour, d)
+}
+
+func TestAnalyticsPullRequests(t *testing.T) {
+	ctx := context.Background()
+	mockStorage := &MockStorage{}
+	service := NewTrackingService(mockStorage, jira.NewMockClient("New"))
+
+	start := time.Date(2024, 5, 1, 0, 0, 0, 0, time.UTC)
+	original := int64(10)
+	mockStorage.On("ListIssues").Return([]models.Issue{
+		{ID: 1, Key: "OCPBUGS-1", Assignee: "alice", FixVersions: []string{"4.16.0", "4.16.z"}},
+		{ID: 2, Key: "OTHER-1", Assignee: "bob"},
+	}, nil)
+	mockStorage.On("ListEvents", ctx, int64(1)).Return([]*models.Event{
+		{Type: models.EventPullRequestChanged, Field: "org/repo#1", From: "open", To: "merged", OccurredAt: start.Add(6 * time.Hour)},
+		{Type: models.EventPullRequestChanged, Field: "org/repo#2", From: "open", To: "merged", OccurredAt: start.Add(30 * time.Hour)},
+	}, nil)
+	mockStorage.On("ListPullRequests", ctx, int64(1)).Return([]*models.PullRequest{
+		{ID: 10, Number: 1, Repository: "org/repo", TargetBranch: "main", CreatedAt: start},
+		{ID: 11, Number: 2, Repository: "org/repo", TargetBranch: "release-4.16", CreatedAt: start.Add(12 * time.Hour),
+			IsBackport: true, OriginalPRID: &original},
+		{ID: 12, Number: 3, Repository: "org/repo", TargetBranch: "release-4.15", CreatedAt: start,
+			IsBackport: true, OriginalPRID: &original},
+	}, nil)
+
+	filter := models.IssueFilter{Project: []string{"OCPBUGS"}}
+	rep, err := service.Analytics(ctx, analytics.MetricMergeTime, analytics.GroupByRelease, filter)
+	require.NoError(t, err)
+	require.Len(t, rep.Rows, 1)
+	assert.Equal(t, "4.16", rep.Rows[0].Group)
+	assert.Equal(t, 2, rep.Rows[0].Count, "the unmerged backport has no merge time")
+	assert.Equal(t, 6.0, rep.Rows[0].Min)
+	assert.Equal(t, 18.0, rep.Rows[0].Max)
+
+	rep, err = service.Analytics(ctx, analytics.MetricBackportLag, analytics.GroupByAssignee, filter)
+	require.NoError(t, err)
+	require.Len(t, rep.Rows, 1)
+	assert.Equal(t, "alice", rep.Rows[0].Group)
+	assert.Equal(t, 1, rep.Rows[0].Count)
+	assert.Equal(t, 24.0, rep.Rows[0].Mean)
+}
@@ -0,0 +1,463 @@
+package services
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/jparrill/devtrackr/internal/archive"
+	"github.com/jparrill/devtrackr/internal/models"
+)
+
+// Export builds an archive of the tracked issues passing a filter, with their
+// children and links, pull requests, subscriptions, timeline, Jira audit log
+// and commits. Storage IDs are cleared; backports reference their original
+// by repository and number.
+func (s *TrackingService) Export(ctx context.Context, filter models.IssueFilter) (*archive.Archive, error) {
+	issues, err := s.ListIssuesMatching(ctx, filter)
+	if err != nil {
+		return nil, err
+	}
+	sort.Slice(issues, func(i, j int) bool { return issues[i].QualifiedKey() < issues[j].QualifiedKey() })
+
+	a := archive.New()
+	labels := map[int64]string{}
+	var originals []*int64
+	for _, issue := range issues {
+		record, err := s.exportIssue(ctx, issue)
+		if err != nil {
+			return nil, fmt.Errorf("failed to export %s: %w", issue.Key, err)
+		}
+		for i := range record.PullRequests {
+			pr := &record.PullRequests[i]
+			labels[pr.ID] = prLabel(&pr.PullRequest)
+			originals = append(originals, pr.OriginalPRID)
+		}
+		a.Issues = append(a.Issues, record)
+	}
+
+	// Resolve backport links once every pull request is known, as originals
+	// may belong to another issue. Links to originals left out by the filter
+	// are dropped.
+	n := 0
+	for i := range a.Issues {
+		for j := range a.Issues[i].PullRequests {
+			pr := &a.Issues[i].PullRequests[j]
+			if id := originals[n]; id != nil {
+				pr.Original = labels[*id]
+			}
+			pr.ID, pr.IssueID, pr.OriginalPRID = 0, 0, nil
+			n++
+		}
+	}
+	return a, nil
+}
+
+// exportIssue gathers everything recorded about an issue. Pull requests keep
+// their IDs, cleared by Export once backport links are resolved.
+func (s *TrackingService) exportIssue(ctx context.Context, issue *models.Issue) (archive.Issue, error) {
+	record := archive.Issue{
+		Issue:         *issue,
+		PullRequests:  []archive.PullRequest{},
+		Subscriptions: []archive.Subscription{},
+		Events:        []models.Event{},
+		Audit:         []models.AuditEntry{},
+		Commits:       []models.Commit{},
+	}
+	record.Issue.ID, record.Issue.Polling, record.Issue.RenderedAttributes = 0, nil, nil
+
+	children, err := s.storage.ListIssueChildren(ctx, issue.ID)
+	if err != nil {
+		return record, err
+	}
+	links, err := s.storage.ListIssueLinks(ctx, issue.ID)
+	if err != nil {
+		return record, err
+	}
+	record.Issue.Children, record.Issue.Links = nil, nil
+	for _, c := range children {
+		c.ID, c.IssueID = 0, 0
+		record.Issue.Children = append(record.Issue.Children, c)
+	}
+	for _, l := range links {
+		l.ID, l.IssueID = 0, 0
+		record.Issue.Links = append(record.Issue.Links, l)
+	}
+
+	prs, err := s.storage.ListPullRequests(ctx, issue.ID)
+	if err != nil {
+		return record, err
+	}
+	for _, pr := range prs {
+		record.PullRequests = append(record.PullRequests, archive.PullRequest{PullRequest: *pr})
+	}
+
+	subs, err := s.storage.ListIssueSubscriptions(ctx, issue.ID)
+	if err != nil {
+		return record, err
+	}
+	for _, sub := range subs {
+		record.Subscriptions = append(record.Subscriptions, archive.Subscription{
+			UserID:    sub.UserID,
+			Active:    sub.Active,
+			CreatedAt: sub.CreatedAt,
+			UpdatedAt: sub.UpdatedAt,
+		})
+	}
+
+	events, err := s.storage.ListEvents(ctx, issue.ID)
+	if err != nil {
+		return record, err
+	}
+	for _, e := range events {
+		e.ID, e.IssueID = 0, 0
+		record.Events = append(record.Events, *e)
+	}
+
+	entries, err := s.storage.ListAuditEntries(ctx, issue.ID)
+	if err != nil {
+		return record, err
+	}
+	for _, entry := range entries {
+		entry.ID, entry.IssueID = 0, 0
+		record.Audit = append(record.Audit, *entry)
+	}
+
+	commits, err := s.storage.ListCommits(ctx, issue.ID)
+	if err != nil {
+		return record, err
+	}
+	for _, c := range commits {
+		c.ID, c.IssueID = 0, 0
+		record.Commits = append(record.Commits, *c)
+	}
+
+	return record, nil
+}
+
+// ImportResult counts what an import changed
+type ImportResult struct {
+	Created       int `json:"created"`   // Issues not tracked before
+	Updated       int `json:"updated"`   // Tracked issues overwritten by the archive
+	Unchanged     int `json:"unchanged"` // Tracked issues kept as they were
+	Deleted       int `json:"deleted"`   // Tracked issues missing from the archive, with the replace strategy
+	PullRequests  int `json:"pull_requests"`
+	Subscriptions int `json:"subscriptions"`
+	Events        int `json:"events"`
+	AuditEntries  int `json:"audit_entries"`
+	Commits       int `json:"commits"`
+}
+
+// Import loads an archive. Issues are matched by qualified key, pull
+// requests by repository and number, and history entries by their content,
+// so importing the same archive twice changes nothing the second time. When
+// an issue is already tracked, conflict decides whether its fields and pull
+// requests are overwritten; records missing on either side are always added.
+func (s *TrackingService) Import(ctx context.Context, a *archive.Archive, strategy archive.Strategy, conflict archive.Conflict) (*ImportResult, error) {
+	result := &ImportResult{}
+
+	// With the replace strategy, issues missing from the archive are only
+	// deleted once everything else was imported, so a failed import can be
+	// run again without having lost them. Their pull requests are not linked
+	// as originals meanwhile.
+	var remove []*models.Issue
+	removed := map[int64]bool{}
+	if strategy == archive.StrategyReplace {
+		keep := map[string]bool{}
+		for _, record := range a.Issues {
+			keep[record.Issue.QualifiedKey()] = true
+		}
+		issues, err := s.storage.ListIssues()
+		if err != nil {
+			return nil, fmt.Errorf("failed to list issues: %w", err)
+		}
+		for i := range issues {
+			if !keep[issues[i].QualifiedKey()] {
+				remove = append(remove, &issues[i])
+				removed[issues[i].ID] = true
+			}
+		}
+	}
+
+	// Backports whose original is imported later are linked at the end
+	type pendingLink struct {
+		pr       *models.PullRequest
+		original string
+	}
+	var pending []pendingLink
+
+	for _, record := range a.Issues {
+		issue, theirs, err := s.importIssue(ctx, record, conflict, result)
+		if err != nil {
+			return nil, fmt.Errorf("failed to import %s: %w", record.Issue.QualifiedKey(), err)
+		}
+
+		linked, err := s.importPullRequests(ctx, issue, record.PullRequests, theirs, removed, result)
+		if err != nil {
+			return nil, fmt.Errorf("failed to import pull requests of %s: %w", issue.Key, err)
+		}
+		for pr, original := range linked {
+			pending = append(pending, pendingLink{pr, original})
+		}
+
+		if err := s.importHistory(ctx, issue, record, theirs, result); err != nil {
+			return nil, fmt.Errorf("failed to import history of %s: %w", issue.Key, err)
+		}
+	}
+
+	for _, link := range pending {
+		original, err := s.findPullRequestByLabel(ctx, nil, link.original, removed)
+		if err != nil || original == nil {
+			continue
+		}
+		link.pr.OriginalPRID = &original.ID
+		if err := s.storage.UpdatePullRequest(ctx, link.pr); err != nil {
+			return nil, fmt.Errorf("failed to link backport %s: %w", prLabel(link.pr), err)
+		}
+	}
+
+	for _, issue := range remove {
+		if err := s.storage.DeleteIssue(ctx, issue.QualifiedKey()); err != nil {
+			return nil, err
+		}
+		s.issueChanged(issue.QualifiedKey())
+		result.Deleted++
+	}
+
+	for _, record := range a.Issues {
+		s.issueChanged(record.Issue.QualifiedKey())
+	}
+	return result, nil
+}
+
+// importIssue creates or resolves the conflict of an issue of an archive. It
+// returns the tracked issue and whether the archive won.
+func (s *TrackingService) importIssue(ctx context.Context, record archive.Issue, conflict archive.Conflict, result *ImportResult) (*models.Issue, bool, error) {
+	issue := record.Issue
+	issue.ID, issue.Polling, issue.RenderedAttributes = 0, nil, nil
+
+	existing, err := s.storage.GetIssue(issue.QualifiedKey())
+	if err != nil {
+		if err := s.storage.CreateIssue(&issue); err != nil {
+			return nil, false, err
+		}
+		// Creating an issue does not record its webhook time
+		if !issue.LastWebhookAt.IsZero() {
+			if err := s.storage.UpdateIssue(&issue); err != nil {
+				return nil, false, err
+			}
+		}
+		if err := s.storage.ReplaceIssueRelations(ctx, issue.ID, issue.Children, issue.Links); err != nil {
+			return nil, false, err
+		}
+		result.Created++
+		return &issue, true, nil
+	}
+
+	theirs := conflict == archive.ConflictTheirs ||
+		(conflict == archive.ConflictNewer && issue.UpdatedAt.After(existing.UpdatedAt))
+	if !theirs {
+		result.Unchanged++
+		return existing, false, nil
+	}
+
+	issue.ID = existing.ID
+	if err := s.storage.UpdateIssue(&issue); err != nil {
+		return nil, false, err
+	}
+	if err := s.storage.ReplaceIssueRelations(ctx, issue.ID, issue.Children, issue.Links); err != nil {
+		return nil, false, err
+	}
+	result.Updated++
+	return &issue, true, nil
+}
+
+// importPullRequests adds the pull requests of an archive missing from an
+// issue, and overwrites the others when the archive won. Originals are
+// created before their backports so links are set right away; it returns the
+// backports whose original could not be found yet. Originals are never looked
+// up in the issues of skip.
+func (s *TrackingService) importPullRequests(ctx context.Context, issue *models.Issue, prs []archive.PullRequest, theirs bool, skip map[int64]bool, result *ImportResult) (map[*models.PullRequest]string, error) {
+	existing, err := s.storage.ListPullRequests(ctx, issue.ID)
+	if err != nil {
+		return nil, err
+	}
+
+	ordered := append([]archive.PullRequest{}, prs...)
+	sort.SliceStable(ordered, func(i, j int) bool {
+		return !ordered[i].IsBackport && ordered[j].IsBackport
+	})
+
+	unlinked := map[*models.PullRequest]string{}
+	for _, record := range ordered {
+		pr := record.PullRequest
+		pr.ID, pr.IssueID, pr.OriginalPRID = 0, issue.ID, nil
+
+		current := findPullRequest(existing, prLabel(&pr))
+		if current != nil && !theirs {
+			continue
+		}
+
+		if record.Original != "" {
+			original, err := s.findPullRequestByLabel(ctx, existing, record.Original, skip)
+			if err != nil {
+				return nil, err
+			}
+			if original != nil {
+				pr.OriginalPRID = &original.ID
+			}
+		}
+
+		if current != nil {
+			pr.ID = current.ID
+			if err := s.storage.UpdatePullRequest(ctx, &pr); err != nil {
+				return nil, err
+			}
+			*current = pr
+		} else {
+			created := pr
+			if err := s.storage.CreatePullRequest(ctx, &created); err != nil {
+				return nil, err
+			}
+			existing = append(existing, &created)
+			current = &created
+			result.PullRequests++
+		}
+
+		if record.Original != "" && current.OriginalPRID == nil {
+			unlinked[current] = record.Original
+		}
+	}
+	return unlinked, nil
+}
+
+// findPullRequestByLabel finds a pull request referenced as org/repo#12
+// among prs, or else among every tracked pull request. It returns nil when
+// none is tracked outside the issues of skip.
+func (s *TrackingService) findPullRequestByLabel(ctx context.Context, prs []*models.PullRequest, label string, skip map[int64]bool) (*models.PullRequest, error) {
+	if pr := findPullRequest(prs, label); pr != nil {
+		return pr, nil
+	}
+
+	i := strings.LastIndex(label, "#")
+	number, err := strconv.Atoi(label[i+1:])
+	if i <= 0 || err != nil {
+		return nil, nil
+	}
+	repository := label[:i]
+
+	matches, err := s.storage.ListPullRequestsByNumber(ctx, repository, number)
+	if err != nil {
+		return nil, err
+	}
+	for _, pr := range matches {
+		if !skip[pr.IssueID] {
+			return pr, nil
+		}
+	}
+	return nil, nil
+}
+
+// importHistory adds the subscriptions, timeline events, audit entries and
+// commits of an archive missing from an issue. Subscriptions present on both
+// sides take the state of the archive when it won.
+func (s *TrackingService) importHistory(ctx context.Context, issue *models.Issue, record archive.Issue, theirs bool, result *ImportResult) error {
+	for _, sub := range record.Subscriptions {
+		current, err := s.storage.GetSubscription(ctx, issue.ID, sub.UserID)
+		if err != nil {
+			if err := s.storage.CreateSubscription(ctx, &models.Subscription{
+				IssueID:   issue.ID,
+				UserID:    sub.UserID,
+				Active:    sub.Active,
+				CreatedAt: sub.CreatedAt,
+				UpdatedAt: sub.UpdatedAt,
+			}); err != nil {
+				return err
+			}
+			result.Subscriptions++
+			continue
+		}
+		if theirs && current.Active != sub.Active {
+			current.Active = sub.Active
+			if err := s.storage.UpdateSubscription(ctx, current); err != nil {
+				return err
+			}
+		}
+	}
+
+	events, err := s.storage.ListEvents(ctx, issue.ID)
+	if err != nil {
+		return err
+	}
+	seen := map[string]bool{}
+	for _, e := range events {
+		seen[eventIdentity(e)] = true
+	}
+	for _, e := range record.Events {
+		if seen[eventIdentity(&e)] {
+			continue
+		}
+		e.ID, e.IssueID = 0, issue.ID
+		if err := s.storage.CreateEvent(ctx, &e); err != nil {
+			return err
+		}
+		seen[eventIdentity(&e)] = true
+		result.Events++
+	}
+
+	entries, err := s.storage.ListAuditEntries(ctx, issue.ID)
+	if err != nil {
+		return err
+	}
+	seen = map[string]bool{}
+	for _, entry := range entries {
+		seen[auditIdentity(entry)] = true
+	}
+	for _, entry := range record.Audit {
+		if seen[auditIdentity(&entry)] {
+			continue
+		}
+		entry.ID, entry.IssueID = 0, issue.ID
+		if err := s.storage.CreateAuditEntry(ctx, &entry); err != nil {
+			return err
+		}
+		seen[auditIdentity(&entry)] = true
+		result.AuditEntries++
+	}
+
+	commits, err := s.storage.ListCommits(ctx, issue.ID)
+	if err != nil {
+		return err
+	}
+	known := map[string]bool{}
+	for _, c := range commits {
+		known[c.Repository+"@"+c.SHA] = true
+	}
+	for _, c := range record.Commits {
+		if known[c.Repository+"@"+c.SHA] && !theirs {
+			continue
+		}
+		if !known[c.Repository+"@"+c.SHA] {
+			result.Commits++
+		}
+		c.ID, c.IssueID = 0, issue.ID
+		if err := s.storage.UpsertCommit(ctx, &c); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// eventIdentity identifies a timeline event across instances. Times are
+// compared to the second, as storage backends keep different precisions.
+func eventIdentity(e *models.Event) string {
+	return fmt.Sprintf("%s|%s|%s|%s|%s", e.Type, e.Field, e.From, e.To, e.OccurredAt.UTC().Format(time.RFC3339))
+}
+
+// auditIdentity identifies a Jira audit entry across instances
+func auditIdentity(e *models.AuditEntry) string {
+	return fmt.Sprintf("%s|%s|%s|%t|%s", e.Action, e.Details, e.Actor, e.DryRun, e.CreatedAt.UTC().Format(time.RFC3339))
+}
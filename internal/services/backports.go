@@ -0,0 +1,67 @@
+package services
+
+import (
+	"context"
+	"fmt"
+	"log"
+
+	"github.com/jparrill/devtrackr/internal/backport"
+	"github.com/jparrill/devtrackr/internal/models"
+)
+
+// WithBackportDetector enables automatic backport detection when pull
+// requests are added or synced
+func WithBackportDetector(detector *backport.Detector) TrackingOption {
+	return func(s *TrackingService) {
+		s.detector = detector
+	}
+}
+
+// detectBackport marks pr as a backport and links it to its original when the
+// detector recognizes it. siblings are the pull requests already tracked for
+// the issue.
+func detectBackport(detector *backport.Detector, issue *models.Issue, pr *models.PullRequest, siblings []*models.PullRequest) {
+	if detector == nil {
+		return
+	}
+
+	result := detector.Detect(pr, issue.Key, siblings)
+	if result.Apply(pr) {
+		log.Printf("Detected %s as a backport for issue %s (%v)", prLabel(pr), issue.Key, result.Reasons)
+	}
+}
+
+// relinkBackports links tracked backports that have no original yet to a
+// newly added pull request, which may be the original they were waiting for
+func relinkBackports(ctx context.Context, storage Storage, detector *backport.Detector, issue *models.Issue, added *models.PullRequest, siblings []*models.PullRequest) error {
+	if detector == nil || added.IsBackport {
+		return nil
+	}
+
+	candidates := append([]*models.PullRequest{added}, siblings...)
+	for _, sibling := range siblings {
+		if !sibling.IsBackport || sibling.OriginalPRID != nil {
+			continue
+		}
+
+		result := detector.Detect(sibling, issue.Key, candidates)
+		if !result.Apply(sibling) {
+			continue
+		}
+
+		if err := storage.UpdatePullRequest(ctx, sibling); err != nil {
+			return fmt.Errorf("failed to link backport %s: %w", prLabel(sibling), err)
+		}
+		log.Printf("Linked backport %s to original %s for issue %s", prLabel(sibling), prLabel(added), issue.Key)
+	}
+
+	return nil
+}
+
+// prLabel returns a short reference to a pull request for log messages
+func prLabel(pr *models.PullRequest) string {
+	if pr.Repository == "" {
+		return fmt.Sprintf("#%d", pr.Number)
+	}
+	return fmt.Sprintf("%s#%d", pr.Repository, pr.Number)
+}
@@ -0,0 +1,92 @@
+package services
+
+import (
+	"context"
+	"testing"
+
+	"github.com/jparrill/devtrackr/internal/backport"
+	"github.com/jparrill/devtrackr/internal/jira"
+	"github.com/jparrill/devtrackr/internal/models"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+)
+
+func TestAddPullRequestDetectsBackport(t *testing.T) {
+	// Create mocks
+	mockStorage := &MockStorage{}
+	mockJira := jira.NewMockClient("In Progress")
+
+	detector, err := backport.NewDetector(backport.DefaultConfig())
+	require.NoError(t, err)
+
+	// Create service
+	service := NewTrackingService(mockStorage, mockJira, WithBackportDetector(detector))
+
+	ctx := context.Background()
+	issue := &models.Issue{ID: 1, Key: "TEST-123"}
+	original := &models.PullRequest{ID: 10, IssueID: 1, Number: 123, Repository: "org/repo", TargetBranch: "main", Status: models.PRStatusMerged}
+
+	mockStorage.On("GetIssue", "TEST-123").Return(issue, nil)
+	mockStorage.On("ListPullRequests", ctx, issue.ID).Return([]*models.PullRequest{original}, nil).Once()
+	mockStorage.On("CreatePullRequest", ctx, mock.Anything).Return(nil).Once()
+
+	pr, err := service.AddPullRequest(ctx, "TEST-123", &models.PullRequest{
+		Number:       456,
+		Repository:   "org/repo",
+		Title:        "[release-4.15] TEST-123: Fix",
+		Body:         "This is an automated cherry-pick of #123",
+		TargetBranch: "release-4.15",
+	})
+	require.NoError(t, err)
+	assert.True(t, pr.IsBackport)
+	require.NotNil(t, pr.OriginalPRID)
+	assert.Equal(t, original.ID, *pr.OriginalPRID)
+
+	mockStorage.AssertExpectations(t)
+}
+
+func TestAddPullRequestLinksWaitingBackports(t *testing.T) {
+	// Create mocks
+	mockStorage := &MockStorage{}
+	mockJira := jira.NewMockClient("In Progress")
+
+	detector, err := backport.NewDetector(backport.DefaultConfig())
+	require.NoError(t, err)
+
+	// Create service
+	service := NewTrackingService(mockStorage, mockJira, WithBackportDetector(detector))
+
+	ctx := context.Background()
+	issue := &models.Issue{ID: 1, Key: "TEST-123"}
+	waiting := &models.PullRequest{
+		ID:           20,
+		IssueID:      1,
+		Number:       456,
+		Repository:   "org/repo",
+		Title:        "[release-4.15] TEST-123: Fix",
+		Body:         "This is an automated cherry-pick of #123",
+		TargetBranch: "release-4.15",
+		IsBackport:   true,
+	}
+
+	mockStorage.On("GetIssue", "TEST-123").Return(issue, nil)
+	mockStorage.On("ListPullRequests", ctx, issue.ID).Return([]*models.PullRequest{waiting}, nil).Once()
+	mockStorage.On("CreatePullRequest", ctx, mock.Anything).Run(func(args mock.Arguments) {
+		args.Get(1).(*models.PullRequest).ID = 30
+	}).Return(nil).Once()
+	mockStorage.On("UpdatePullRequest", ctx, mock.MatchedBy(func(pr *models.PullRequest) bool {
+		return pr.ID == waiting.ID && pr.OriginalPRID != nil && *pr.OriginalPRID == 30
+	})).Return(nil).Once()
+
+	pr, err := service.AddPullRequest(ctx, "TEST-123", &models.PullRequest{
+		Number:       123,
+		Repository:   "org/repo",
+		Title:        "TEST-123: Fix",
+		TargetBranch: "main",
+	})
+	require.NoError(t, err)
+	assert.False(t, pr.IsBackport)
+
+	mockStorage.AssertExpectations(t)
+}
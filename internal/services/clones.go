@@ -0,0 +1,303 @@
+package services
+
+import (
+	"context"
+	"fmt"
+	"slices"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/jparrill/devtrackr/internal/models"
+	"github.com/jparrill/devtrackr/internal/report"
+)
+
+// cloneMember is an issue found in the clone chain of a bug
+type cloneMember struct {
+	key string
+	// issue is nil when the clone is not tracked; link then holds what the
+	// tracked issue linking to it last saw of it
+	issue    *models.Issue
+	link     models.IssueLink
+	original string // Key of the issue this one was cloned from, if known
+}
+
+// CloneChain builds the single view of a bug across the z-stream releases it
+// was cloned for. The chain is discovered through the clone links of tracked
+// issues starting at any issue of the chain. Each clone is paired with the
+// pull requests of the chain targeting the release branch of its target
+// version; the original is also paired with the development branches.
+func (s *TrackingService) CloneChain(ctx context.Context, key string) (*report.CloneChain, error) {
+	start, err := s.storage.GetIssue(key)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get issue: %w", err)
+	}
+
+	members, err := s.cloneMembers(ctx, start)
+	if err != nil {
+		return nil, err
+	}
+
+	// The original is the first issue found that was not cloned from another
+	originalKey := members[0].key
+	for _, m := range members {
+		if m.original == "" {
+			originalKey = m.key
+			break
+		}
+	}
+
+	// Pool the pull requests of the chain, as a backport is often attached
+	// to the original bug rather than to its clone
+	var pool []*models.PullRequest
+	seen := map[string]bool{}
+	for _, m := range members {
+		if m.issue == nil {
+			continue
+		}
+		prs, err := s.storage.ListPullRequests(ctx, m.issue.ID)
+		if err != nil {
+			return nil, fmt.Errorf("failed to list pull requests for %s: %w", m.key, err)
+		}
+		for _, pr := range prs {
+			if pr.Status == models.PRStatusClosed || seen[prLabel(pr)] {
+				continue
+			}
+			seen[prLabel(pr)] = true
+			pool = append(pool, pr)
+		}
+	}
+
+	chain := &report.CloneChain{
+		GeneratedAt: time.Now(),
+		Clones:      []report.CloneEntry{},
+		Unpaired:    []report.PullRequestRef{},
+	}
+
+	branchOwners := map[string][]string{}
+	claimed := map[*models.PullRequest]bool{}
+	for _, m := range members {
+		entry := s.cloneEntry(m, m.key == originalKey)
+		if entry.Branch != "" {
+			branchOwners[entry.Branch] = append(branchOwners[entry.Branch], entry.Key)
+		}
+
+		branches := []string{entry.Branch}
+		if entry.Original {
+			branches = append(branches, s.config.Backport.DefaultBranches...)
+		}
+		for _, pr := range pool {
+			if pr.TargetBranch != "" && slices.Contains(branches, pr.TargetBranch) {
+				claimed[pr] = true
+				entry.PullRequests = append(entry.PullRequests, report.NewPullRequestRef(pr))
+			}
+		}
+
+		chain.Clones = append(chain.Clones, entry)
+	}
+
+	for i := range chain.Clones {
+		entry := &chain.Clones[i]
+		for _, other := range branchOwners[entry.Branch] {
+			if other != entry.Key {
+				entry.Problems = append(entry.Problems, report.CloneProblem{
+					Kind:    report.CloneDuplicateRelease,
+					Message: fmt.Sprintf("%s is also targeted by %s", entry.Branch, other),
+				})
+			}
+		}
+		entry.Problems = append(entry.Problems, clonePullRequestProblems(entry)...)
+		chain.Problems += len(entry.Problems)
+
+		if entry.Original {
+			chain.Key, chain.Title = entry.Key, entry.Title
+		}
+	}
+
+	for _, pr := range pool {
+		if !claimed[pr] {
+			chain.Unpaired = append(chain.Unpaired, report.NewPullRequestRef(pr))
+		}
+	}
+	chain.Problems += len(chain.Unpaired)
+
+	sort.SliceStable(chain.Clones, func(i, j int) bool {
+		a, b := chain.Clones[i], chain.Clones[j]
+		if a.Original != b.Original {
+			return a.Original
+		}
+		return compareVersions(a.TargetVersion, b.TargetVersion) > 0
+	})
+
+	return chain, nil
+}
+
+// cloneMembers walks the clone links from an issue in both directions and
+// returns every issue of the chain, the given one first. Untracked clones are
+// included but not walked through.
+func (s *TrackingService) cloneMembers(ctx context.Context, start *models.Issue) ([]*cloneMember, error) {
+	first := &cloneMember{key: start.Key, issue: start}
+	members := []*cloneMember{first}
+	byKey := map[string]*cloneMember{start.Key: first}
+
+	for queue := []*cloneMember{first}; len(queue) > 0; queue = queue[1:] {
+		m := queue[0]
+		links, err := s.storage.ListIssueLinks(ctx, m.issue.ID)
+		if err != nil {
+			return nil, fmt.Errorf("failed to list links of %s: %w", m.key, err)
+		}
+
+		for _, link := range links {
+			if !strings.EqualFold(link.Type, s.config.Clones.LinkType) {
+				continue
+			}
+
+			other, ok := byKey[link.Key]
+			if !ok {
+				other = &cloneMember{key: link.Key, link: link}
+				if tracked, err := s.storage.GetIssueByKey(models.QualifyKey(m.issue.Instance, link.Key)); err == nil {
+					other.issue = tracked
+					queue = append(queue, other)
+				}
+				byKey[link.Key] = other
+				members = append(members, other)
+			}
+
+			// The outward side of a clone link is the clone
+			if link.Outward {
+				m.original = link.Key
+			} else {
+				other.original = m.key
+			}
+		}
+	}
+	return members, nil
+}
+
+// cloneEntry describes a member of a clone chain, before pairing
+func (s *TrackingService) cloneEntry(m *cloneMember, original bool) report.CloneEntry {
+	entry := report.CloneEntry{
+		Key:          m.key,
+		Original:     original,
+		PullRequests: []report.PullRequestRef{},
+		Problems:     []report.CloneProblem{},
+	}
+
+	if m.issue == nil {
+		entry.Title, entry.Status, entry.StatusCategory = m.link.Title, m.link.Status, m.link.StatusCategory
+		entry.Problems = append(entry.Problems, report.CloneProblem{
+			Kind:    report.CloneNotTracked,
+			Message: "not tracked, so its target version and pull requests are unknown",
+		})
+		return entry
+	}
+
+	entry.Title = m.issue.Title
+	entry.Status = m.issue.Status
+	entry.StatusCategory = m.issue.StatusCategory
+	entry.JiraURL = m.issue.JiraURL
+	entry.Tracked = true
+	entry.TargetVersion = targetVersion(m.issue, s.config.Clones.TargetVersion)
+	if entry.TargetVersion != "" {
+		entry.Branch = ReleaseBranch(minorRelease(entry.TargetVersion))
+	} else if !original {
+		entry.Problems = append(entry.Problems, report.CloneProblem{
+			Kind:    report.CloneNoTargetVersion,
+			Message: "no target version",
+		})
+	}
+	return entry
+}
+
+// clonePullRequestProblems flags a tracked clone without a pull request, and
+// pull requests whose state does not match the status of the clone
+func clonePullRequestProblems(entry *report.CloneEntry) []report.CloneProblem {
+	if !entry.Tracked || (entry.Branch == "" && !entry.Original) {
+		return nil
+	}
+
+	var problems []report.CloneProblem
+	if len(entry.PullRequests) == 0 {
+		problems = append(problems, report.CloneProblem{
+			Kind:    report.CloneMissingPR,
+			Message: fmt.Sprintf("no pull request targets %s", branchOrDevelopment(entry.Branch)),
+		})
+	}
+
+	isNew := strings.EqualFold(entry.Status, "New")
+	done := isDoneStatus(entry.Status, entry.StatusCategory)
+	for _, pr := range entry.PullRequests {
+		switch {
+		case pr.Status == models.PRStatusMerged && isNew:
+			problems = append(problems, report.CloneProblem{
+				Kind:    report.ClonePRMergedIssueNew,
+				Message: fmt.Sprintf("%s merged while the issue is still %s", pr, entry.Status),
+			})
+		case pr.Status != models.PRStatusMerged && done:
+			problems = append(problems, report.CloneProblem{
+				Kind:    report.CloneIssueDonePROpen,
+				Message: fmt.Sprintf("issue is %s but %s is %s", entry.Status, pr, pr.Status),
+			})
+		}
+	}
+	return problems
+}
+
+// branchOrDevelopment names the branch of a clone, or the development branches
+// for an original without a target version
+func branchOrDevelopment(branch string) string {
+	if branch == "" {
+		return "the development branch"
+	}
+	return branch
+}
+
+// targetVersion returns the release an issue targets: the first value of the
+// target version attribute, or its first fix version
+func targetVersion(issue *models.Issue, attribute string) string {
+	switch v := issue.Attributes[attribute].(type) {
+	case string:
+		if v != "" {
+			return v
+		}
+	case []any:
+		if len(v) > 0 {
+			return fmt.Sprint(v[0])
+		}
+	}
+	if len(issue.FixVersions) > 0 {
+		return issue.FixVersions[0]
+	}
+	return ""
+}
+
+// minorRelease trims a version to its minor release, e.g. "4.15" for "4.15.z"
+// or "4.15.3"
+func minorRelease(version string) string {
+	parts := strings.SplitN(version, ".", 3)
+	if len(parts) < 2 {
+		return version
+	}
+	return parts[0] + "." + parts[1]
+}
+
+// compareVersions compares dotted versions part by part, numerically where
+// both parts are numbers
+func compareVersions(a, b string) int {
+	pa, pb := strings.Split(a, "."), strings.Split(b, ".")
+	for i := 0; i < len(pa) && i < len(pb); i++ {
+		na, errA := strconv.Atoi(pa[i])
+		nb, errB := strconv.Atoi(pb[i])
+		switch {
+		case errA == nil && errB == nil && na != nb:
+			if na < nb {
+				return -1
+			}
+			return 1
+		case (errA != nil || errB != nil) && pa[i] != pb[i]:
+			return strings.Compare(pa[i], pb[i])
+		}
+	}
+	return len(pa) - len(pb)
+}
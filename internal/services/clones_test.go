@@ -0,0 +1,89 @@
+package services
+
+import (
+	"context"
+	"testing"
+
+	"github.com/jparrill/devtrackr/internal/jira"
+	"github.com/jparrill/devtrackr/internal/models"
+	"github.com/jparrill/devtrackr/internal/report"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+)
+
+func TestCloneChain(t *testing.T) {
+	ctx := context.Background()
+	mockStorage := &MockStorage{}
+	service := NewTrackingService(mockStorage, jira.NewMockClient("New"))
+
+	original := &models.Issue{ID: 1, Key: "OCPBUGS-100", Title: "Crash", Status: "ON_QA", FixVersions: []string{"4.16.0"}}
+	clone415 := &models.Issue{ID: 2, Key: "OCPBUGS-200", Title: "Crash [4.15]", Status: "New",
+		Attributes: map[string]any{"target_version": []any{"4.15.z"}}}
+	clone414 := &models.Issue{ID: 3, Key: "OCPBUGS-201", Title: "Crash [4.14]", Status: "ASSIGNED",
+		Attributes: map[string]any{"target_version": "4.14.z"}}
+
+	mockStorage.On("GetIssue", "OCPBUGS-200").Return(clone415, nil)
+	mockStorage.On("GetIssueByKey", "OCPBUGS-100").Return(original, nil)
+	mockStorage.On("GetIssueByKey", "OCPBUGS-201").Return(clone414, nil)
+	mockStorage.On("GetIssueByKey", mock.Anything).Return(nil, assert.AnError)
+	mockStorage.On("ListIssueLinks", ctx, int64(1)).Return([]models.IssueLink{
+		{Type: "Cloners", Relation: "is cloned by", Key: "OCPBUGS-200"},
+		{Type: "Cloners", Relation: "is cloned by", Key: "OCPBUGS-201"},
+		{Type: "Cloners", Relation: "is cloned by", Key: "OCPBUGS-202", Status: "New"},
+		{Type: "Blocks", Relation: "blocks", Outward: true, Key: "OTHER-1"},
+	}, nil)
+	mockStorage.On("ListIssueLinks", ctx, int64(2)).Return([]models.IssueLink{
+		{Type: "Cloners", Relation: "clones", Outward: true, Key: "OCPBUGS-100"},
+	}, nil)
+	mockStorage.On("ListIssueLinks", ctx, int64(3)).Return([]models.IssueLink{
+		{Type: "Cloners", Relation: "clones", Outward: true, Key: "OCPBUGS-100"},
+	}, nil)
+	// Backports are attached to the original bug, as their titles mention it
+	mockStorage.On("ListPullRequests", ctx, int64(1)).Return([]*models.PullRequest{
+		{Number: 1, Repository: "org/repo", TargetBranch: "main", Status: models.PRStatusMerged},
+		{Number: 2, Repository: "org/repo", TargetBranch: "release-4.15", Status: models.PRStatusMerged},
+		{Number: 3, Repository: "org/repo", TargetBranch: "release-4.13", Status: models.PRStatusOpen},
+	}, nil)
+	mockStorage.On("ListPullRequests", ctx, int64(2)).Return([]*models.PullRequest{
+		{Number: 2, Repository: "org/repo", TargetBranch: "release-4.15", Status: models.PRStatusMerged},
+	}, nil)
+	mockStorage.On("ListPullRequests", ctx, int64(3)).Return([]*models.PullRequest{}, nil)
+
+	chain, err := service.CloneChain(ctx, "OCPBUGS-200")
+	require.NoError(t, err)
+	assert.Equal(t, "OCPBUGS-100", chain.Key)
+
+	var keys []string
+	problems := map[string][]report.CloneProblemKind{}
+	for _, e := range chain.Clones {
+		keys = append(keys, e.Key)
+		for _, p := range e.Problems {
+			problems[e.Key] = append(problems[e.Key], p.Kind)
+		}
+	}
+	assert.Equal(t, []string{"OCPBUGS-100", "OCPBUGS-200", "OCPBUGS-201", "OCPBUGS-202"}, keys)
+
+	assert.Equal(t, "release-4.15", chain.Clones[1].Branch)
+	require.Len(t, chain.Clones[1].PullRequests, 1, "the pull request attached to both issues is paired once")
+	assert.Equal(t, 2, chain.Clones[1].PullRequests[0].Number)
+	require.Len(t, chain.Clones[0].PullRequests, 1)
+	assert.Equal(t, "main", chain.Clones[0].PullRequests[0].TargetBranch)
+
+	assert.Equal(t, map[string][]report.CloneProblemKind{
+		"OCPBUGS-200": {report.ClonePRMergedIssueNew},
+		"OCPBUGS-201": {report.CloneMissingPR},
+		"OCPBUGS-202": {report.CloneNotTracked},
+	}, problems)
+
+	require.Len(t, chain.Unpaired, 1)
+	assert.Equal(t, "release-4.13", chain.Unpaired[0].TargetBranch)
+	assert.Equal(t, 4, chain.Problems)
+}
+
+func TestCompareVersions(t *testing.T) {
+	assert.Positive(t, compareVersions("4.15.z", "4.9.z"))
+	assert.Negative(t, compareVersions("4.14.1", "4.14.10"))
+	assert.Zero(t, compareVersions("4.16", "4.16"))
+	assert.Equal(t, "4.15", minorRelease("4.15.z"))
+}
@@ -0,0 +1,328 @@
+package services
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"log"
+	"mime/multipart"
+	"net"
+	"net/smtp"
+	"net/textproto"
+	"slices"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/jparrill/devtrackr/internal/config"
+	"github.com/jparrill/devtrackr/internal/models"
+	"github.com/jparrill/devtrackr/internal/report"
+)
+
+// Digest summarizes what changed since a given time on the issues a user is
+// actively subscribed to: status transitions, pull requests opened, merged
+// or closed and new backports, together with what still blocks each issue.
+// Changes come from the recorded timelines, so only what polling or webhooks
+// observed is included.
+func (s *TrackingService) Digest(ctx context.Context, userID int64, since time.Time) (*report.Digest, error) {
+	subs, err := s.storage.ListSubscriptions(ctx, userID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list subscriptions: %w", err)
+	}
+	subscribed := map[int64]bool{}
+	for _, sub := range subs {
+		if sub.Active {
+			subscribed[sub.IssueID] = true
+		}
+	}
+
+	issues, err := s.storage.ListIssues()
+	if err != nil {
+		return nil, fmt.Errorf("failed to list issues: %w", err)
+	}
+	sort.Slice(issues, func(i, j int) bool { return issues[i].Key < issues[j].Key })
+
+	digest := &report.Digest{
+		UserID:      userID,
+		Since:       since,
+		GeneratedAt: time.Now(),
+		Issues:      []report.DigestIssue{},
+	}
+	for i := range issues {
+		issue := &issues[i]
+		if !subscribed[issue.ID] {
+			continue
+		}
+
+		entry, err := s.digestIssue(ctx, issue, since)
+		if err != nil {
+			return nil, err
+		}
+		if len(entry.Changes) == 0 && len(entry.Blockers) == 0 {
+			digest.Quiet++
+			continue
+		}
+
+		digest.Changes += len(entry.Changes)
+		if len(entry.Blockers) > 0 {
+			digest.Blocked++
+		}
+		digest.Issues = append(digest.Issues, entry)
+	}
+
+	return digest, nil
+}
+
+// digestIssue gathers the changes of an issue since a given time, and its
+// outstanding blockers
+func (s *TrackingService) digestIssue(ctx context.Context, issue *models.Issue, since time.Time) (report.DigestIssue, error) {
+	prs, err := s.storage.ListPullRequests(ctx, issue.ID)
+	if err != nil {
+		return report.DigestIssue{}, fmt.Errorf("failed to list pull requests for %s: %w", issue.Key, err)
+	}
+	events, err := s.storage.ListEvents(ctx, issue.ID)
+	if err != nil {
+		return report.DigestIssue{}, fmt.Errorf("failed to list events for %s: %w", issue.Key, err)
+	}
+
+	entry := report.DigestIssue{
+		Key:      issue.QualifiedKey(),
+		Title:    issue.Title,
+		Status:   issue.Status,
+		JiraURL:  issue.JiraURL,
+		Changes:  []report.DigestChange{},
+		Blockers: []report.Blocker{},
+	}
+
+	for _, e := range events {
+		if e.OccurredAt.Before(since) {
+			continue
+		}
+		switch e.Type {
+		case models.EventStatusChanged:
+			entry.Changes = append(entry.Changes, report.DigestChange{
+				Kind:    report.DigestStatusChanged,
+				At:      e.OccurredAt,
+				Message: fmt.Sprintf("moved from %s to %s", e.From, e.To),
+			})
+		case models.EventPullRequestChanged:
+			kind := report.DigestPRMerged
+			switch e.To {
+			case string(models.PRStatusMerged):
+			case string(models.PRStatusClosed):
+				kind = report.DigestPRClosed
+			default:
+				continue
+			}
+			change := report.DigestChange{Kind: kind, At: e.OccurredAt, Message: fmt.Sprintf("%s %s", e.Field, e.To)}
+			if pr := findPullRequest(prs, e.Field); pr != nil {
+				change.Message = fmt.Sprintf("%s %s on %s", e.Field, e.To, pr.TargetBranch)
+				change.URL = pr.URL
+			}
+			entry.Changes = append(entry.Changes, change)
+		}
+	}
+
+	for _, pr := range prs {
+		if pr.CreatedAt.Before(since) {
+			continue
+		}
+		change := report.DigestChange{
+			Kind:    report.DigestPROpened,
+			At:      pr.CreatedAt,
+			Message: fmt.Sprintf("%s opened on %s", prLabel(pr), pr.TargetBranch),
+			URL:     pr.URL,
+		}
+		if pr.IsBackport {
+			change.Kind = report.DigestBackport
+			change.Message = fmt.Sprintf("backport %s opened on %s", prLabel(pr), pr.TargetBranch)
+		}
+		entry.Changes = append(entry.Changes, change)
+	}
+	sort.SliceStable(entry.Changes, func(i, j int) bool {
+		return entry.Changes[i].At.Before(entry.Changes[j].At)
+	})
+
+	for _, pr := range prs {
+		if !isPendingPullRequest(pr) {
+			continue
+		}
+		if blockers := pr.MergeBlockers(); len(blockers) > 0 {
+			entry.Blockers = append(entry.Blockers, report.Blocker{
+				Kind:    report.BlockerUnmergedPR,
+				Message: fmt.Sprintf("%s on %s: %s", prLabel(pr), pr.TargetBranch, strings.Join(blockers, "; ")),
+			})
+		}
+	}
+	if _, merged := s.originalMerged(prs, events); merged {
+		expected := s.config.ExpectedBranches(issue.Project())
+		for _, branch := range newFacts(issue, prs, expected).MissingBackports {
+			if !slices.Contains(s.config.Backport.DefaultBranches, branch) {
+				entry.Blockers = append(entry.Blockers, report.Blocker{
+					Kind:    report.BlockerMissingBackport,
+					Message: fmt.Sprintf("no backport to %s", branch),
+				})
+			}
+		}
+	}
+
+	return entry, nil
+}
+
+// DigestSender delivers a digest to a user
+type DigestSender interface {
+	SendDigest(ctx context.Context, to config.DigestRecipient, digest *report.Digest) error
+}
+
+// DigestDelivery emails digests as HTML with a plain text alternative to
+// recipients with an address when SMTP is configured, and posts them as
+// plain text to the notification webhook otherwise. Without a webhook the
+// digest is logged.
+type DigestDelivery struct {
+	smtp    config.SMTPConfig
+	webhook *WebhookNotifier
+}
+
+// NewDigestDelivery creates a digest sender. webhook may be nil.
+func NewDigestDelivery(smtp config.SMTPConfig, webhook *WebhookNotifier) *DigestDelivery {
+	return &DigestDelivery{smtp: smtp, webhook: webhook}
+}
+
+// SendDigest delivers the digest to a recipient
+func (d *DigestDelivery) SendDigest(ctx context.Context, to config.DigestRecipient, digest *report.Digest) error {
+	var text bytes.Buffer
+	if err := report.RenderDigest(&text, digest, report.FormatText); err != nil {
+		return err
+	}
+
+	switch {
+	case to.Email != "" && d.smtp.Addr != "":
+		return d.email(to.Email, digest, text.String())
+	case d.webhook != nil:
+		return d.webhook.post(ctx, map[string]any{"user_id": to.UserID, "text": text.String()})
+	default:
+		log.Printf("Digest for user %d:\n%s", to.UserID, text.String())
+		return nil
+	}
+}
+
+// email sends the digest as a multipart message with text and HTML parts
+func (d *DigestDelivery) email(to string, digest *report.Digest, text string) error {
+	var html bytes.Buffer
+	if err := report.RenderDigest(&html, digest, report.FormatHTML); err != nil {
+		return err
+	}
+
+	var body bytes.Buffer
+	mw := multipart.NewWriter(&body)
+	for _, part := range []struct{ contentType, content string }{
+		{"text/plain; charset=utf-8", text},
+		{"text/html; charset=utf-8", html.String()},
+	} {
+		pw, err := mw.CreatePart(textproto.MIMEHeader{"Content-Type": {part.contentType}})
+		if err != nil {
+			return err
+		}
+		if _, err := pw.Write([]byte(part.content)); err != nil {
+			return err
+		}
+	}
+	if err := mw.Close(); err != nil {
+		return err
+	}
+
+	var msg bytes.Buffer
+	fmt.Fprintf(&msg, "From: %s\r\nTo: %s\r\nSubject: %s\r\n", d.smtp.From, to, digest.Subject())
+	fmt.Fprintf(&msg, "MIME-Version: 1.0\r\nContent-Type: multipart/alternative; boundary=%s\r\n\r\n", mw.Boundary())
+	msg.Write(body.Bytes())
+
+	var auth smtp.Auth
+	if d.smtp.Username != "" {
+		host, _, err := net.SplitHostPort(d.smtp.Addr)
+		if err != nil {
+			return fmt.Errorf("invalid SMTP address %q: %w", d.smtp.Addr, err)
+		}
+		auth = smtp.PlainAuth("", d.smtp.Username, d.smtp.Password(), host)
+	}
+	if err := smtp.SendMail(d.smtp.Addr, auth, d.smtp.From, []string{to}, msg.Bytes()); err != nil {
+		return fmt.Errorf("failed to email digest to %s: %w", to, err)
+	}
+	return nil
+}
+
+// DigestService sends the digest of every configured recipient once a day
+type DigestService struct {
+	trackingService *TrackingService
+	cfg             config.DigestConfig
+	sender          DigestSender
+	stop            chan struct{}
+}
+
+// NewDigestService creates a new digest service
+func NewDigestService(trackingService *TrackingService, cfg config.DigestConfig, sender DigestSender) *DigestService {
+	return &DigestService{
+		trackingService: trackingService,
+		cfg:             cfg,
+		sender:          sender,
+		stop:            make(chan struct{}),
+	}
+}
+
+// Start sends the digests every day at the configured time of day
+func (s *DigestService) Start(ctx context.Context) error {
+	next, err := nextDigest(s.cfg.At, time.Now())
+	if err != nil {
+		return err
+	}
+	log.Printf("Starting digest service for %d recipients, next at %s", len(s.cfg.Recipients), next.Format(time.RFC3339))
+
+	timer := time.NewTimer(time.Until(next))
+	defer timer.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return nil
+		case <-s.stop:
+			return nil
+		case now := <-timer.C:
+			s.sendAll(ctx, now)
+			next, _ = nextDigest(s.cfg.At, now)
+			timer.Reset(time.Until(next))
+		}
+	}
+}
+
+// Stop stops the digest service
+func (s *DigestService) Stop() {
+	close(s.stop)
+}
+
+// sendAll sends the digest of every recipient, logging failures
+func (s *DigestService) sendAll(ctx context.Context, now time.Time) {
+	for _, to := range s.cfg.Recipients {
+		digest, err := s.trackingService.Digest(ctx, to.UserID, now.Add(-s.cfg.Period))
+		if err != nil {
+			log.Printf("Error building digest for user %d: %v", to.UserID, err)
+			continue
+		}
+		if err := s.sender.SendDigest(ctx, to, digest); err != nil {
+			log.Printf("Error sending digest to user %d: %v", to.UserID, err)
+		}
+	}
+}
+
+// nextDigest returns the first time after now at the given local time of
+// day, written as "15:04"
+func nextDigest(at string, now time.Time) (time.Time, error) {
+	t, err := time.ParseInLocation("15:04", at, now.Location())
+	if err != nil {
+		return time.Time{}, fmt.Errorf("invalid digest time %q: %w", at, err)
+	}
+
+	next := time.Date(now.Year(), now.Month(), now.Day(), t.Hour(), t.Minute(), 0, 0, now.Location())
+	if !next.After(now) {
+		next = next.AddDate(0, 0, 1)
+	}
+	return next, nil
+}
@@ -0,0 +1,75 @@
+package services
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/jparrill/devtrackr/internal/config"
+	"github.com/jparrill/devtrackr/internal/jira"
+	"github.com/jparrill/devtrackr/internal/models"
+	"github.com/jparrill/devtrackr/internal/report"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestDigest(t *testing.T) {
+	ctx := context.Background()
+	mockStorage := &MockStorage{}
+	cfg := config.Default()
+	cfg.Branches = []string{"main", "release-4.16"}
+	service := NewTrackingService(mockStorage, jira.NewMockClient("New"), WithConfig(cfg))
+
+	now := time.Now()
+	since := now.Add(-24 * time.Hour)
+	mockStorage.On("ListSubscriptions", ctx, int64(7)).Return([]models.Subscription{
+		{IssueID: 1, UserID: 7, Active: true},
+		{IssueID: 2, UserID: 7, Active: true},
+		{IssueID: 3, UserID: 7, Active: false},
+	}, nil)
+	mockStorage.On("ListIssues").Return([]models.Issue{
+		{ID: 1, Key: "OCPBUGS-1", Status: "ON_QA"},
+		{ID: 2, Key: "OCPBUGS-2", Status: "New"},
+		{ID: 3, Key: "OCPBUGS-3", Status: "New"},
+	}, nil)
+	mockStorage.On("ListEvents", ctx, int64(1)).Return([]*models.Event{
+		{Type: models.EventStatusChanged, From: "New", To: "POST", OccurredAt: now.Add(-48 * time.Hour)},
+		{Type: models.EventStatusChanged, From: "POST", To: "ON_QA", OccurredAt: now.Add(-2 * time.Hour)},
+		{Type: models.EventPullRequestChanged, Field: "org/repo#1", From: "open", To: "merged", OccurredAt: now.Add(-3 * time.Hour)},
+	}, nil)
+	mockStorage.On("ListEvents", ctx, int64(2)).Return([]*models.Event{}, nil)
+	mockStorage.On("ListPullRequests", ctx, int64(1)).Return([]*models.PullRequest{
+		{Number: 1, Repository: "org/repo", TargetBranch: "main", Status: models.PRStatusMerged, CreatedAt: now.Add(-72 * time.Hour)},
+	}, nil)
+	mockStorage.On("ListPullRequests", ctx, int64(2)).Return([]*models.PullRequest{}, nil)
+
+	digest, err := service.Digest(ctx, 7, since)
+	require.NoError(t, err)
+	assert.Equal(t, 1, digest.Quiet)
+	assert.Equal(t, 2, digest.Changes)
+	assert.Equal(t, 1, digest.Blocked)
+	require.Len(t, digest.Issues, 1)
+
+	issue := digest.Issues[0]
+	assert.Equal(t, "OCPBUGS-1", issue.Key)
+	require.Len(t, issue.Changes, 2)
+	assert.Equal(t, report.DigestPRMerged, issue.Changes[0].Kind)
+	assert.Equal(t, "org/repo#1 merged on main", issue.Changes[0].Message)
+	assert.Equal(t, "moved from POST to ON_QA", issue.Changes[1].Message)
+	assert.Equal(t, []report.Blocker{{Kind: report.BlockerMissingBackport, Message: "no backport to release-4.16"}}, issue.Blockers)
+}
+
+func TestNextDigest(t *testing.T) {
+	now := time.Date(2024, 5, 1, 9, 0, 0, 0, time.UTC)
+
+	next, err := nextDigest("08:30", now)
+	require.NoError(t, err)
+	assert.Equal(t, time.Date(2024, 5, 2, 8, 30, 0, 0, time.UTC), next)
+
+	next, err = nextDigest("17:00", now)
+	require.NoError(t, err)
+	assert.Equal(t, time.Date(2024, 5, 1, 17, 0, 0, 0, time.UTC), next)
+
+	_, err = nextDigest("8am", now)
+	assert.Error(t, err)
+}
@@ -0,0 +1,231 @@
+package services
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"slices"
+	"time"
+
+	"github.com/jparrill/devtrackr/internal/backport"
+	"github.com/jparrill/devtrackr/internal/forge"
+	"github.com/jparrill/devtrackr/internal/models"
+)
+
+// WithForges enables fetching pull request state from GitHub, GitLab and
+// other forges when pull requests are added or synced
+func WithForges(registry *forge.Registry) TrackingOption {
+	return func(s *TrackingService) {
+		s.forges = registry
+	}
+}
+
+// SyncPullRequests refreshes every pull request of an issue from its forge
+func (s *TrackingService) SyncPullRequests(ctx context.Context, key string) ([]*models.PullRequest, error) {
+	if s.forges == nil {
+		return nil, fmt.Errorf("no forges configured")
+	}
+
+	issue, err := s.storage.GetIssue(key)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get issue: %w", err)
+	}
+
+	if _, err := syncPullRequests(ctx, s.storage, s.forges, s.detector, issue); err != nil {
+		return nil, err
+	}
+
+	return s.storage.ListPullRequests(ctx, issue.ID)
+}
+
+// fillFromForge completes a pull request being added with the state found
+// on its forge. URLs of hosts without a configured forge are left alone.
+func (s *TrackingService) fillFromForge(ctx context.Context, pr *models.PullRequest) error {
+	if s.forges == nil || pr.URL == "" || !s.forges.Supports(pr.URL) {
+		return nil
+	}
+
+	remote, err := s.forges.GetPullRequest(ctx, pr.URL)
+	if err != nil {
+		return fmt.Errorf("failed to get pull request from forge: %w", err)
+	}
+
+	applyRemote(pr, remote)
+	return nil
+}
+
+// syncPullRequests refreshes the pull requests of an issue that are not merged
+// yet. Pull requests changing state are added to the timeline of the issue,
+// and the recorded events returned.
+func syncPullRequests(ctx context.Context, storage Storage, forges *forge.Registry, detector *backport.Detector, issue *models.Issue) ([]*models.Event, error) {
+	prs, err := storage.ListPullRequests(ctx, issue.ID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list pull requests: %w", err)
+	}
+
+	var events []*models.Event
+	for _, pr := range prs {
+		if pr.Status == models.PRStatusMerged || pr.URL == "" || !forges.Supports(pr.URL) {
+			continue
+		}
+
+		remote, err := forges.GetPullRequest(ctx, pr.URL)
+		if err != nil {
+			log.Printf("Error syncing %s for issue %s: %v", prLabel(pr), issue.Key, err)
+			continue
+		}
+
+		_, event, err := updatePullRequestState(ctx, storage, detector, issue, pr, prs, remote, applyRemote, models.EventSourceForge)
+		if err != nil {
+			return events, err
+		}
+		if event != nil {
+			events = append(events, event)
+		}
+	}
+
+	return events, nil
+}
+
+// updatePullRequestState applies the state of a pull request seen on its
+// forge or in a webhook delivery to the tracked pull request with apply, and
+// stores it when anything changed. A change of status is recorded in the
+// timeline of the issue and returned, nil otherwise.
+func updatePullRequestState(ctx context.Context, storage Storage, detector *backport.Detector, issue *models.Issue,
+	pr *models.PullRequest, siblings []*models.PullRequest, state *models.PullRequest,
+	apply func(pr, state *models.PullRequest) bool, source string) (bool, *models.Event, error) {
+	previous := pr.Status
+	if !apply(pr, state) {
+		return false, nil, nil
+	}
+
+	detectBackport(detector, issue, pr, siblings)
+	pr.UpdatedAt = time.Now()
+	if err := storage.UpdatePullRequest(ctx, pr); err != nil {
+		return false, nil, fmt.Errorf("failed to update %s: %w", prLabel(pr), err)
+	}
+
+	if pr.Status == previous {
+		return true, nil, nil
+	}
+	event := &models.Event{
+		IssueID:    issue.ID,
+		Type:       models.EventPullRequestChanged,
+		Field:      prLabel(pr),
+		From:       string(previous),
+		To:         string(pr.Status),
+		Source:     source,
+		OccurredAt: pr.UpdatedAt,
+	}
+	if err := storage.CreateEvent(ctx, event); err != nil {
+		log.Printf("Error recording state change of %s for issue %s: %v", prLabel(pr), issue.Key, err)
+		return true, nil, nil
+	}
+	return true, event, nil
+}
+
+// applyRemote copies the state fetched from a forge onto a tracked pull
+// request and reports whether anything changed. Backport links are kept.
+func applyRemote(pr, remote *models.PullRequest) bool {
+	changed := applyMetadata(pr, remote)
+	return applyReviewState(pr, remote) || changed
+}
+
+// applyMetadata copies the number, title, description, target branch, labels
+// and status of a pull request. Empty values other than labels are ignored.
+func applyMetadata(pr, remote *models.PullRequest) bool {
+	changed := false
+	set := func(dst *string, value string) {
+		if value != "" && *dst != value {
+			*dst = value
+			changed = true
+		}
+	}
+
+	if remote.Number != 0 && pr.Number != remote.Number {
+		pr.Number = remote.Number
+		changed = true
+	}
+	set(&pr.Repository, remote.Repository)
+	set(&pr.Title, remote.Title)
+	set(&pr.Body, remote.Body)
+	set(&pr.TargetBranch, remote.TargetBranch)
+	if remote.Status != "" && pr.Status != remote.Status {
+		pr.Status = remote.Status
+		changed = true
+	}
+	if !slices.Equal(pr.Labels, remote.Labels) {
+		pr.Labels = remote.Labels
+		changed = true
+	}
+
+	return changed
+}
+
+// applyReviewState copies the CI and review state of a pull request. It is
+// replaced as a whole, since an empty value is meaningful.
+func applyReviewState(pr, remote *models.PullRequest) bool {
+	changed := false
+	if pr.CIStatus != remote.CIStatus {
+		pr.CIStatus = remote.CIStatus
+		changed = true
+	}
+	if !slices.Equal(pr.FailingChecks, remote.FailingChecks) {
+		pr.FailingChecks = remote.FailingChecks
+		changed = true
+	}
+	if !slices.Equal(pr.RequiredReviewers, remote.RequiredReviewers) {
+		pr.RequiredReviewers = remote.RequiredReviewers
+		changed = true
+	}
+	if pr.Approvals != remote.Approvals {
+		pr.Approvals = remote.Approvals
+		changed = true
+	}
+	if !equalBoolPtr(pr.Mergeable, remote.Mergeable) {
+		pr.Mergeable = remote.Mergeable
+		changed = true
+	}
+
+	return changed
+}
+
+// equalBoolPtr reports whether two optional booleans hold the same value
+func equalBoolPtr(a, b *bool) bool {
+	if a == nil || b == nil {
+		return a == b
+	}
+	return *a == *b
+}
+
+// PullRequestStatus is a tracked pull request together with everything
+// known to keep it from merging
+type PullRequestStatus struct {
+	*models.PullRequest
+	Blockers []string `json:"blockers"`
+}
+
+// MergeStatus explains why the pull requests of an issue are not merged yet.
+// With sync set, their state is refreshed from the forges first.
+func (s *TrackingService) MergeStatus(ctx context.Context, key string, sync bool) ([]PullRequestStatus, error) {
+	var prs []*models.PullRequest
+	var err error
+	if sync {
+		prs, err = s.SyncPullRequests(ctx, key)
+	} else {
+		prs, err = s.ListPullRequests(ctx, key)
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	result := make([]PullRequestStatus, 0, len(prs))
+	for _, pr := range prs {
+		blockers := pr.MergeBlockers()
+		if blockers == nil {
+			blockers = []string{}
+		}
+		result = append(result, PullRequestStatus{PullRequest: pr, Blockers: blockers})
+	}
+	return result, nil
+}
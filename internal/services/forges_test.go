@@ -0,0 +1,131 @@
+package services
+
+import (
+	"context"
+	"testing"
+
+	"github.com/jparrill/devtrackr/internal/forge"
+	"github.com/jparrill/devtrackr/internal/jira"
+	"github.com/jparrill/devtrackr/internal/models"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+)
+
+// fakeForge returns canned pull requests keyed by URL
+type fakeForge struct {
+	prs map[string]*models.PullRequest
+}
+
+func (f *fakeForge) Name() string {
+	return "fake"
+}
+
+func (f *fakeForge) GetPullRequest(ctx context.Context, prURL string) (*models.PullRequest, error) {
+	pr := *f.prs[prURL]
+	return &pr, nil
+}
+
+func TestAddPullRequestFillsFromForge(t *testing.T) {
+	// Create mocks
+	mockStorage := &MockStorage{}
+	mockJira := jira.NewMockClient("In Progress")
+
+	mrURL := "https://gitlab.example.com/team/tool/-/merge_requests/42"
+	registry := forge.NewRegistry()
+	registry.Register("gitlab.example.com", &fakeForge{prs: map[string]*models.PullRequest{
+		mrURL: {
+			Number:       42,
+			Repository:   "team/tool",
+			Title:        "TEST-123: Fix",
+			URL:          mrURL,
+			Status:       models.PRStatusApproved,
+			TargetBranch: "main",
+		},
+	}})
+
+	// Create service
+	service := NewTrackingService(mockStorage, mockJira, WithForges(registry))
+
+	ctx := context.Background()
+	issue := &models.Issue{ID: 1, Key: "TEST-123"}
+
+	mockStorage.On("GetIssue", "TEST-123").Return(issue, nil)
+	mockStorage.On("CreatePullRequest", ctx, mock.Anything).Return(nil).Once()
+
+	pr, err := service.AddPullRequest(ctx, "TEST-123", &models.PullRequest{URL: mrURL})
+	require.NoError(t, err)
+	assert.Equal(t, 42, pr.Number)
+	assert.Equal(t, "team/tool", pr.Repository)
+	assert.Equal(t, "TEST-123: Fix", pr.Title)
+	assert.Equal(t, models.PRStatusApproved, pr.Status)
+	assert.Equal(t, "main", pr.TargetBranch)
+	assert.Equal(t, issue.ID, pr.IssueID)
+
+	mockStorage.AssertExpectations(t)
+}
+
+func TestSyncPullRequests(t *testing.T) {
+	// Create mocks
+	mockStorage := &MockStorage{}
+	mockJira := jira.NewMockClient("In Progress")
+
+	prURL := "https://github.com/org/repo/pull/7"
+	registry := forge.NewRegistry()
+	registry.Register("github.com", &fakeForge{prs: map[string]*models.PullRequest{
+		prURL: {Number: 7, Repository: "org/repo", Title: "Fix", URL: prURL, Status: models.PRStatusMerged, TargetBranch: "main"},
+	}})
+
+	// Create service
+	service := NewTrackingService(mockStorage, mockJira, WithForges(registry))
+
+	ctx := context.Background()
+	issue := &models.Issue{ID: 1, Key: "TEST-123"}
+	tracked := &models.PullRequest{ID: 5, IssueID: 1, Number: 7, Repository: "org/repo", Title: "Fix", URL: prURL, Status: models.PRStatusOpen, TargetBranch: "main"}
+	merged := &models.PullRequest{ID: 6, IssueID: 1, Number: 8, URL: "https://github.com/org/repo/pull/8", Status: models.PRStatusMerged}
+	unknown := &models.PullRequest{ID: 7, IssueID: 1, Number: 9, URL: "https://bitbucket.org/org/repo/pull-requests/9", Status: models.PRStatusOpen}
+
+	mockStorage.On("GetIssue", "TEST-123").Return(issue, nil)
+	mockStorage.On("ListPullRequests", ctx, issue.ID).Return([]*models.PullRequest{tracked, merged, unknown}, nil)
+	mockStorage.On("UpdatePullRequest", ctx, mock.MatchedBy(func(pr *models.PullRequest) bool {
+		return pr.ID == tracked.ID && pr.Status == models.PRStatusMerged
+	})).Return(nil).Once()
+	mockStorage.On("CreateEvent", ctx, mock.MatchedBy(func(e *models.Event) bool {
+		return e.Type == models.EventPullRequestChanged && e.Field == "org/repo#7" &&
+			e.From == "open" && e.To == "merged" && e.Source == models.EventSourceForge
+	})).Return(nil).Once()
+
+	_, err := service.SyncPullRequests(ctx, "TEST-123")
+	require.NoError(t, err)
+
+	mockStorage.AssertExpectations(t)
+}
+
+func TestMergeStatus(t *testing.T) {
+	// Create mocks
+	mockStorage := &MockStorage{}
+	mockJira := jira.NewMockClient("In Progress")
+
+	// Create service
+	service := NewTrackingService(mockStorage, mockJira)
+
+	ctx := context.Background()
+	issue := &models.Issue{ID: 1, Key: "TEST-123"}
+	failing := &models.PullRequest{ID: 5, IssueID: 1, Number: 7, Status: models.PRStatusApproved, Approvals: 1,
+		CIStatus: models.CIStatusFailure, FailingChecks: []string{"unit"}}
+	merged := &models.PullRequest{ID: 6, IssueID: 1, Number: 8, Status: models.PRStatusMerged}
+
+	mockStorage.On("GetIssue", "TEST-123").Return(issue, nil)
+	mockStorage.On("ListPullRequests", ctx, issue.ID).Return([]*models.PullRequest{failing, merged}, nil)
+
+	statuses, err := service.MergeStatus(ctx, "TEST-123", false)
+	require.NoError(t, err)
+	require.Len(t, statuses, 2)
+	assert.Equal(t, []string{"CI is failing: unit"}, statuses[0].Blockers)
+	assert.Empty(t, statuses[1].Blockers)
+
+	_, err = service.MergeStatus(ctx, "TEST-123", true)
+	assert.Error(t, err, "syncing requires forges")
+
+	mockStorage.AssertExpectations(t)
+}
@@ -0,0 +1,137 @@
+package services
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"path/filepath"
+	"time"
+
+	"github.com/jparrill/devtrackr/internal/config"
+	"github.com/jparrill/devtrackr/internal/gitscan"
+	"github.com/jparrill/devtrackr/internal/models"
+)
+
+// ScanGitRepository scans the history of a local git repository for commits
+// mentioning tracked issue keys and records which of the branches matching
+// the given patterns, and which tags, contain each of them
+func (s *TrackingService) ScanGitRepository(ctx context.Context, repoPath string, branches []string) ([]*models.Commit, error) {
+	repoPath, err := filepath.Abs(repoPath)
+	if err != nil {
+		return nil, fmt.Errorf("invalid repository path: %w", err)
+	}
+
+	issues, err := s.storage.ListIssues()
+	if err != nil {
+		return nil, fmt.Errorf("failed to list issues: %w", err)
+	}
+
+	// The same key can be tracked from several Jira instances, commits
+	// mentioning it are recorded for each of those issues
+	issueIDs := make(map[string][]int64, len(issues))
+	keys := make([]string, 0, len(issues))
+	for _, issue := range issues {
+		if _, ok := issueIDs[issue.Key]; !ok {
+			keys = append(keys, issue.Key)
+		}
+		issueIDs[issue.Key] = append(issueIDs[issue.Key], issue.ID)
+	}
+
+	findings, err := s.git.Scan(ctx, repoPath, keys, gitscan.Options{Branches: branches})
+	if err != nil {
+		return nil, fmt.Errorf("failed to scan %s: %w", repoPath, err)
+	}
+
+	now := time.Now()
+	commits := make([]*models.Commit, 0, len(findings))
+	for _, f := range findings {
+		for _, issueID := range issueIDs[f.IssueKey] {
+			commit := &models.Commit{
+				IssueID:     issueID,
+				Repository:  repoPath,
+				SHA:         f.Commit,
+				Subject:     f.Subject,
+				CommittedAt: f.CommittedAt,
+				Branches:    f.Branches,
+				Tags:        f.Tags,
+				ScannedAt:   now,
+			}
+			if err := s.storage.UpsertCommit(ctx, commit); err != nil {
+				return nil, fmt.Errorf("failed to record commit %s for %s: %w", f.Commit, f.IssueKey, err)
+			}
+			commits = append(commits, commit)
+		}
+	}
+
+	return commits, nil
+}
+
+// ListCommits returns the commits found in local git repositories for an issue
+func (s *TrackingService) ListCommits(ctx context.Context, key string) ([]*models.Commit, error) {
+	issue, err := s.storage.GetIssue(key)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get issue: %w", err)
+	}
+
+	return s.storage.ListCommits(ctx, issue.ID)
+}
+
+// GitScanService periodically scans the configured local git repositories
+type GitScanService struct {
+	trackingService *TrackingService
+	repositories    []config.GitRepository
+	interval        time.Duration
+	stop            chan struct{}
+}
+
+// NewGitScanService creates a new git scan service. Intervals that are not
+// positive fall back to the default of the configuration.
+func NewGitScanService(trackingService *TrackingService, cfg config.GitScanConfig) *GitScanService {
+	interval := cfg.Interval
+	if interval <= 0 {
+		interval = config.Default().GitScan.Interval
+	}
+	return &GitScanService{
+		trackingService: trackingService,
+		repositories:    cfg.Repositories,
+		interval:        interval,
+		stop:            make(chan struct{}),
+	}
+}
+
+// Start scans every repository immediately and then on every interval
+func (s *GitScanService) Start(ctx context.Context) error {
+	log.Printf("Starting git scan service for %d repositories every %v", len(s.repositories), s.interval)
+
+	ticker := time.NewTicker(s.interval)
+	defer ticker.Stop()
+
+	s.scanAll(ctx)
+	for {
+		select {
+		case <-ctx.Done():
+			return nil
+		case <-s.stop:
+			return nil
+		case <-ticker.C:
+			s.scanAll(ctx)
+		}
+	}
+}
+
+// Stop stops the git scan service
+func (s *GitScanService) Stop() {
+	close(s.stop)
+}
+
+// scanAll scans every configured repository, logging failures
+func (s *GitScanService) scanAll(ctx context.Context) {
+	for _, repo := range s.repositories {
+		commits, err := s.trackingService.ScanGitRepository(ctx, repo.Path, repo.Branches)
+		if err != nil {
+			log.Printf("Error scanning git repository %s: %v", repo.Path, err)
+			continue
+		}
+		log.Printf("Scanned git repository %s: %d commits reference tracked issues", repo.Path, len(commits))
+	}
+}
@@ -0,0 +1,22 @@
+package services
+
+import (
+	"testing"
+	"time"
+
+	"github.com/jparrill/devtrackr/internal/config"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestNewGitScanServiceInterval(t *testing.T) {
+	repos := []config.GitRepository{{Path: "."}}
+
+	service := NewGitScanService(nil, config.GitScanConfig{Interval: 30 * time.Minute, Repositories: repos})
+	assert.Equal(t, 30*time.Minute, service.interval)
+
+	// Unset or negative intervals would make the ticker panic
+	for _, interval := range []time.Duration{0, -time.Minute} {
+		service := NewGitScanService(nil, config.GitScanConfig{Interval: interval, Repositories: repos})
+		assert.Equal(t, time.Hour, service.interval)
+	}
+}
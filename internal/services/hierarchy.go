@@ -0,0 +1,226 @@
+package services
+
+import (
+	"context"
+	"fmt"
+	"math"
+	"strings"
+
+	"github.com/jparrill/devtrackr/internal/jira"
+	"github.com/jparrill/devtrackr/internal/models"
+)
+
+// IssueTreeNode is an issue in the hierarchy below a tracked issue. Children
+// that are not tracked themselves only carry what their parent last saw of
+// them in Jira.
+type IssueTreeNode struct {
+	Key            string           `json:"key"`
+	Title          string           `json:"title"`
+	Status         string           `json:"status"`
+	StatusCategory string           `json:"status_category"`
+	Kind           models.ChildKind `json:"kind,omitempty"` // How the issue sits below its parent, empty for the root
+	Tracked        bool             `json:"tracked"`
+	Done           bool             `json:"done"`
+	// PullRequests and MergedPullRequests count the pull requests of the
+	// issue itself, leaving out closed ones
+	PullRequests       int                `json:"pull_requests"`
+	MergedPullRequests int                `json:"merged_pull_requests"`
+	Progress           TreeProgress       `json:"progress"`
+	Links              []models.IssueLink `json:"links,omitempty"`
+	Children           []*IssueTreeNode   `json:"children,omitempty"`
+}
+
+// TreeProgress is the completion of an issue rolled up from every issue below
+// it, at any depth. Issues with no children count themselves.
+type TreeProgress struct {
+	Issues             int     `json:"issues"`
+	DoneIssues         int     `json:"done_issues"`
+	DonePercent        float64 `json:"done_percent"`
+	PullRequests       int     `json:"pull_requests"` // Pull requests of the issue and every issue below it
+	MergedPullRequests int     `json:"merged_pull_requests"`
+	MergedPercent      float64 `json:"merged_percent"`
+}
+
+// syncRelations stores the children and links read from Jira for an issue.
+// Jira does not list the issues of an epic on the epic, so they are searched
+// for when the client supports it.
+func syncRelations(ctx context.Context, storage Storage, client jira.JiraClient, issue *models.Issue) error {
+	if lister, ok := client.(jira.ChildLister); ok && strings.EqualFold(issue.IssueType, "Epic") {
+		children, err := lister.GetEpicChildren(ctx, issue.JiraURL)
+		if err != nil {
+			return fmt.Errorf("failed to get children of epic %s: %w", issue.Key, err)
+		}
+		issue.Children = mergeChildren(issue.Children, children)
+	}
+
+	if err := storage.ReplaceIssueRelations(ctx, issue.ID, issue.Children, issue.Links); err != nil {
+		return fmt.Errorf("failed to store children and links of %s: %w", issue.Key, err)
+	}
+	return nil
+}
+
+// mergeChildren appends the children not listed yet
+func mergeChildren(children, more []models.IssueChild) []models.IssueChild {
+	seen := map[string]bool{}
+	for _, c := range children {
+		seen[c.Key] = true
+	}
+	for _, c := range more {
+		if !seen[c.Key] {
+			seen[c.Key] = true
+			children = append(children, c)
+		}
+	}
+	return children
+}
+
+// TrackIssueWithChildren tracks a Jira issue and every issue below it, walking
+// down epics and sub-tasks. It returns the tracked issues, the given one first.
+func (s *TrackingService) TrackIssueWithChildren(ctx context.Context, jiraURL string) ([]*models.Issue, error) {
+	root, err := s.TrackIssue(ctx, jiraURL)
+	if err != nil {
+		return nil, err
+	}
+
+	tracked := []*models.Issue{root}
+	seen := map[string]bool{root.QualifiedKey(): true}
+	for queue := []*models.Issue{root}; len(queue) > 0; queue = queue[1:] {
+		parent := queue[0]
+		for _, child := range parent.Children {
+			key := models.QualifyKey(parent.Instance, child.Key)
+			if seen[key] {
+				continue
+			}
+			seen[key] = true
+
+			issue, err := s.TrackIssue(ctx, childURL(parent.JiraURL, child.Key))
+			if err != nil {
+				return tracked, fmt.Errorf("failed to track %s below %s: %w", child.Key, parent.Key, err)
+			}
+			tracked = append(tracked, issue)
+			queue = append(queue, issue)
+		}
+	}
+	return tracked, nil
+}
+
+// childURL builds the URL of an issue on the same Jira instance as parentURL,
+// replacing the key in its last path segment
+func childURL(parentURL, key string) string {
+	return parentURL[:strings.LastIndex(parentURL, "/")+1] + key
+}
+
+// IssueTree returns the hierarchy below a tracked issue, with status and pull
+// request completion rolled up from the children to their parents
+func (s *TrackingService) IssueTree(ctx context.Context, key string) (*IssueTreeNode, error) {
+	issue, err := s.storage.GetIssue(key)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get issue: %w", err)
+	}
+	return s.trackedNode(ctx, issue, "", map[string]bool{issue.QualifiedKey(): true})
+}
+
+// trackedNode builds the node of a tracked issue and the nodes below it.
+// Issues already in the tree are not expanded again, so cycles end.
+func (s *TrackingService) trackedNode(ctx context.Context, issue *models.Issue, kind models.ChildKind, seen map[string]bool) (*IssueTreeNode, error) {
+	node := &IssueTreeNode{
+		Key:            issue.Key,
+		Title:          issue.Title,
+		Status:         issue.Status,
+		StatusCategory: issue.StatusCategory,
+		Kind:           kind,
+		Tracked:        true,
+	}
+
+	prs, err := s.storage.ListPullRequests(ctx, issue.ID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list pull requests of %s: %w", issue.Key, err)
+	}
+	for _, pr := range prs {
+		switch pr.Status {
+		case models.PRStatusClosed:
+			continue
+		case models.PRStatusMerged:
+			node.MergedPullRequests++
+		}
+		node.PullRequests++
+	}
+
+	if node.Links, err = s.storage.ListIssueLinks(ctx, issue.ID); err != nil {
+		return nil, fmt.Errorf("failed to list links of %s: %w", issue.Key, err)
+	}
+	children, err := s.storage.ListIssueChildren(ctx, issue.ID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list children of %s: %w", issue.Key, err)
+	}
+
+	for _, child := range children {
+		qualified := models.QualifyKey(issue.Instance, child.Key)
+		tracked, err := s.storage.GetIssueByKey(qualified)
+		if err != nil || seen[qualified] {
+			node.Children = append(node.Children, childNode(child))
+			continue
+		}
+
+		seen[qualified] = true
+		below, err := s.trackedNode(ctx, tracked, child.Kind, seen)
+		if err != nil {
+			return nil, err
+		}
+		node.Children = append(node.Children, below)
+	}
+
+	node.rollUp()
+	return node, nil
+}
+
+// childNode builds the leaf node of a child that is not tracked
+func childNode(child models.IssueChild) *IssueTreeNode {
+	node := &IssueTreeNode{
+		Key:            child.Key,
+		Title:          child.Title,
+		Status:         child.Status,
+		StatusCategory: child.StatusCategory,
+		Kind:           child.Kind,
+	}
+	node.rollUp()
+	return node
+}
+
+// rollUp computes the progress of a node from its own state and the progress
+// of its children
+func (n *IssueTreeNode) rollUp() {
+	n.Done = isDoneStatus(n.Status, n.StatusCategory)
+
+	p := TreeProgress{PullRequests: n.PullRequests, MergedPullRequests: n.MergedPullRequests}
+	if len(n.Children) == 0 {
+		p.Issues = 1
+		if n.Done {
+			p.DoneIssues = 1
+		}
+	}
+	for _, child := range n.Children {
+		p.Issues++
+		if child.Done {
+			p.DoneIssues++
+		}
+		if len(child.Children) > 0 {
+			p.Issues += child.Progress.Issues
+			p.DoneIssues += child.Progress.DoneIssues
+		}
+		p.PullRequests += child.Progress.PullRequests
+		p.MergedPullRequests += child.Progress.MergedPullRequests
+	}
+	p.DonePercent = percent(p.DoneIssues, p.Issues)
+	p.MergedPercent = percent(p.MergedPullRequests, p.PullRequests)
+	n.Progress = p
+}
+
+// percent returns part as a percentage of total rounded to one decimal, or
+// zero when there is nothing to count
+func percent(part, total int) float64 {
+	if total == 0 {
+		return 0
+	}
+	return math.Round(float64(part)*1000/float64(total)) / 10
+}
@@ -0,0 +1,88 @@
+package services
+
+import (
+	"context"
+	"testing"
+
+	"github.com/jparrill/devtrackr/internal/jira"
+	"github.com/jparrill/devtrackr/internal/models"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+)
+
+func TestIssueTree(t *testing.T) {
+	ctx := context.Background()
+	mockStorage := &MockStorage{}
+	service := NewTrackingService(mockStorage, jira.NewMockClient("New"))
+
+	epic := &models.Issue{ID: 1, Key: "PROJ-1", Title: "Epic", Status: "In Progress"}
+	story := &models.Issue{ID: 2, Key: "PROJ-2", Title: "Story", Status: "In Progress"}
+	link := models.IssueLink{IssueID: 1, Type: "Blocks", Relation: "blocks", Key: "OTHER-1", Status: "New"}
+
+	mockStorage.On("GetIssue", "PROJ-1").Return(epic, nil)
+	mockStorage.On("GetIssueByKey", "PROJ-2").Return(story, nil)
+	mockStorage.On("GetIssueByKey", mock.Anything).Return(nil, assert.AnError)
+	mockStorage.On("ListPullRequests", ctx, int64(1)).Return([]*models.PullRequest{}, nil)
+	mockStorage.On("ListPullRequests", ctx, int64(2)).Return([]*models.PullRequest{
+		{Number: 1, Status: models.PRStatusMerged},
+		{Number: 2, Status: models.PRStatusOpen},
+		{Number: 3, Status: models.PRStatusClosed},
+	}, nil)
+	mockStorage.On("ListIssueLinks", ctx, int64(1)).Return([]models.IssueLink{link}, nil)
+	mockStorage.On("ListIssueLinks", ctx, int64(2)).Return([]models.IssueLink{}, nil)
+	mockStorage.On("ListIssueChildren", ctx, int64(1)).Return([]models.IssueChild{
+		{Key: "PROJ-2", Kind: models.ChildEpic},
+		{Key: "PROJ-3", Kind: models.ChildEpic, Status: "Closed"},
+		// A cycle back to the epic is not expanded again
+		{Key: "PROJ-1", Kind: models.ChildSubtask, Status: "In Progress"},
+	}, nil)
+	mockStorage.On("ListIssueChildren", ctx, int64(2)).Return([]models.IssueChild{
+		{Key: "PROJ-4", Kind: models.ChildSubtask, Status: "Done", StatusCategory: models.StatusCategoryDone},
+	}, nil)
+
+	tree, err := service.IssueTree(ctx, "PROJ-1")
+	require.NoError(t, err)
+
+	assert.Equal(t, []models.IssueLink{link}, tree.Links)
+	require.Len(t, tree.Children, 3)
+
+	storyNode := tree.Children[0]
+	assert.True(t, storyNode.Tracked)
+	assert.Equal(t, models.ChildEpic, storyNode.Kind)
+	assert.Equal(t, 2, storyNode.PullRequests, "closed pull requests are left out")
+	assert.Equal(t, TreeProgress{Issues: 1, DoneIssues: 1, DonePercent: 100, PullRequests: 2, MergedPullRequests: 1, MergedPercent: 50}, storyNode.Progress)
+
+	assert.False(t, tree.Children[1].Tracked)
+	assert.True(t, tree.Children[1].Done)
+	assert.Empty(t, tree.Children[2].Children)
+
+	// The story, its sub-task, the untracked story and the cycle
+	assert.Equal(t, TreeProgress{Issues: 4, DoneIssues: 2, DonePercent: 50, PullRequests: 2, MergedPullRequests: 1, MergedPercent: 50}, tree.Progress)
+}
+
+func TestTrackIssueWithChildren(t *testing.T) {
+	ctx := context.Background()
+	mockStorage := &MockStorage{}
+	mockJira := jira.NewMockClient("New")
+	mockJira.Children = map[string][]models.IssueChild{
+		"PROJ-1": {{Key: "PROJ-2", Kind: models.ChildSubtask}, {Key: "PROJ-3", Kind: models.ChildSubtask}},
+		"PROJ-3": {{Key: "PROJ-4", Kind: models.ChildSubtask}, {Key: "PROJ-1", Kind: models.ChildSubtask}},
+	}
+	service := NewTrackingService(mockStorage, mockJira)
+
+	mockStorage.On("GetIssueByKey", mock.Anything).Return(nil, assert.AnError)
+	mockStorage.On("CreateIssue", mock.Anything).Return(nil)
+	mockStorage.On("ReplaceIssueRelations", ctx, mock.Anything, mock.Anything, mock.Anything).Return(nil)
+
+	tracked, err := service.TrackIssueWithChildren(ctx, "https://jira.example.com/browse/PROJ-1")
+	require.NoError(t, err)
+
+	var keys, urls []string
+	for _, issue := range tracked {
+		keys = append(keys, issue.Key)
+		urls = append(urls, issue.JiraURL)
+	}
+	assert.Equal(t, []string{"PROJ-1", "PROJ-2", "PROJ-3", "PROJ-4"}, keys)
+	assert.Equal(t, "https://jira.example.com/browse/PROJ-4", urls[3])
+}
@@ -0,0 +1,234 @@
+package services
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/jparrill/devtrackr/internal/jira"
+	"github.com/jparrill/devtrackr/internal/models"
+)
+
+// WriteOptions controls how a change is written back to Jira
+type WriteOptions struct {
+	DryRun bool   // Record the change in the audit log without sending it
+	Actor  string // Who asked for the change, e.g. cli:alice
+}
+
+// ListTransitions lists the workflow transitions currently available on an issue
+func (s *TrackingService) ListTransitions(ctx context.Context, key string) ([]jira.Transition, error) {
+	issue, writer, err := s.issueWriter(key)
+	if err != nil {
+		return nil, err
+	}
+	return writer.GetTransitions(ctx, issue.JiraURL)
+}
+
+// TransitionIssue moves an issue through the transition whose name or target
+// status matches name. The transitions available on the issue are looked up
+// first, so an unknown name fails before anything is written, even in dry-run
+// mode.
+func (s *TrackingService) TransitionIssue(ctx context.Context, key, name string, opts WriteOptions) (*models.AuditEntry, error) {
+	issue, writer, err := s.issueWriter(key)
+	if err != nil {
+		return nil, err
+	}
+
+	transitions, err := writer.GetTransitions(ctx, issue.JiraURL)
+	if err != nil {
+		return nil, err
+	}
+	transition, err := findTransition(transitions, name)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", issue.QualifiedKey(), err)
+	}
+
+	details := fmt.Sprintf("%s (id %s) -> %s", transition.Name, transition.ID, transition.To)
+	entry, err := s.writeToJira(ctx, issue, models.AuditTransition, details, opts, func() error {
+		return writer.TransitionIssue(ctx, issue.JiraURL, transition.ID)
+	})
+	if err != nil || opts.DryRun {
+		return entry, err
+	}
+
+	// Read the issue back rather than assuming the target status, since
+	// post functions of the workflow may change more than the status
+	jiraIssue, err := s.jira.GetIssue(ctx, issue.JiraURL)
+	if err != nil {
+		return entry, fmt.Errorf("transitioned, but failed to refresh issue: %w", err)
+	}
+	previous := issue.Status
+	applyJiraIssue(issue, jiraIssue)
+	if err := s.storage.UpdateIssue(issue); err != nil {
+		return entry, fmt.Errorf("failed to update issue: %w", err)
+	}
+	if issue.Status != previous {
+		if _, err := recordStatusChange(ctx, s.storage, issue, previous, opts.Actor, models.EventSourceWriteBack, time.Now()); err != nil {
+			return entry, err
+		}
+	}
+	s.issueChanged(issue.QualifiedKey())
+	return entry, nil
+}
+
+// CommentIssue posts a comment on an issue
+func (s *TrackingService) CommentIssue(ctx context.Context, key, body string, opts WriteOptions) (*models.AuditEntry, error) {
+	issue, writer, err := s.issueWriter(key)
+	if err != nil {
+		return nil, err
+	}
+	return s.comment(ctx, issue, writer, body, opts)
+}
+
+// CommentPullRequestSummary posts a comment on an issue summarizing the state
+// of its linked pull requests
+func (s *TrackingService) CommentPullRequestSummary(ctx context.Context, key string, opts WriteOptions) (*models.AuditEntry, error) {
+	issue, writer, err := s.issueWriter(key)
+	if err != nil {
+		return nil, err
+	}
+
+	prs, err := s.storage.ListPullRequests(ctx, issue.ID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list pull requests: %w", err)
+	}
+
+	return s.comment(ctx, issue, writer, pullRequestSummary(prs), opts)
+}
+
+// comment posts an audited comment on an issue
+func (s *TrackingService) comment(ctx context.Context, issue *models.Issue, writer jira.IssueWriter, body string, opts WriteOptions) (*models.AuditEntry, error) {
+	if strings.TrimSpace(body) == "" {
+		return nil, fmt.Errorf("comment is empty")
+	}
+	return s.writeToJira(ctx, issue, models.AuditComment, body, opts, func() error {
+		return writer.AddComment(ctx, issue.JiraURL, body)
+	})
+}
+
+// UpdateLabels adds and removes labels of an issue, keeping the tracked copy
+// of the labels in sync
+func (s *TrackingService) UpdateLabels(ctx context.Context, key string, add, remove []string, opts WriteOptions) (*models.AuditEntry, error) {
+	if len(add) == 0 && len(remove) == 0 {
+		return nil, fmt.Errorf("no labels to add or remove")
+	}
+
+	issue, writer, err := s.issueWriter(key)
+	if err != nil {
+		return nil, err
+	}
+
+	var changes []string
+	if len(add) > 0 {
+		changes = append(changes, "add: "+strings.Join(add, ", "))
+	}
+	if len(remove) > 0 {
+		changes = append(changes, "remove: "+strings.Join(remove, ", "))
+	}
+
+	entry, err := s.writeToJira(ctx, issue, models.AuditLabels, strings.Join(changes, "; "), opts, func() error {
+		return writer.UpdateLabels(ctx, issue.JiraURL, add, remove)
+	})
+	if err != nil || opts.DryRun {
+		return entry, err
+	}
+
+	issue.Labels = models.ApplyLabels(issue.Labels, add, remove)
+	if err := s.storage.UpdateIssue(issue); err != nil {
+		return entry, fmt.Errorf("failed to update issue: %w", err)
+	}
+	s.issueChanged(issue.QualifiedKey())
+	return entry, nil
+}
+
+// ListAuditEntries lists the changes made to an issue in Jira, oldest first
+func (s *TrackingService) ListAuditEntries(ctx context.Context, key string) ([]*models.AuditEntry, error) {
+	issue, err := s.storage.GetIssue(key)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get issue: %w", err)
+	}
+	return s.storage.ListAuditEntries(ctx, issue.ID)
+}
+
+// issueWriter looks up a tracked issue and the Jira client able to change it
+func (s *TrackingService) issueWriter(key string) (*models.Issue, jira.IssueWriter, error) {
+	writer, ok := s.jira.(jira.IssueWriter)
+	if !ok {
+		return nil, nil, fmt.Errorf("jira client does not support changing issues")
+	}
+
+	issue, err := s.storage.GetIssue(key)
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to get issue: %w", err)
+	}
+	return issue, writer, nil
+}
+
+// writeToJira runs write unless in dry-run mode and records the outcome in
+// the audit log. A rejected write is audited too and its error returned.
+func (s *TrackingService) writeToJira(ctx context.Context, issue *models.Issue, action models.AuditAction, details string, opts WriteOptions, write func() error) (*models.AuditEntry, error) {
+	entry := &models.AuditEntry{
+		IssueID: issue.ID,
+		Action:  action,
+		Details: details,
+		Actor:   opts.Actor,
+		DryRun:  opts.DryRun,
+	}
+
+	var writeErr error
+	if !opts.DryRun {
+		writeErr = write()
+		if writeErr != nil {
+			entry.Error = writeErr.Error()
+		}
+	}
+
+	if err := s.storage.CreateAuditEntry(ctx, entry); err != nil {
+		return nil, fmt.Errorf("failed to record audit entry: %w", err)
+	}
+	return entry, writeErr
+}
+
+// findTransition picks the transition named name, or else the one leading to
+// the status name, ignoring case
+func findTransition(transitions []jira.Transition, name string) (jira.Transition, error) {
+	for _, t := range transitions {
+		if strings.EqualFold(t.Name, name) {
+			return t, nil
+		}
+	}
+	for _, t := range transitions {
+		if strings.EqualFold(t.To, name) {
+			return t, nil
+		}
+	}
+
+	available := make([]string, 0, len(transitions))
+	for _, t := range transitions {
+		available = append(available, fmt.Sprintf("%q", t.Name))
+	}
+	if len(available) == 0 {
+		return jira.Transition{}, fmt.Errorf("no transitions available")
+	}
+	return jira.Transition{}, fmt.Errorf("no transition %q, available: %s", name, strings.Join(available, ", "))
+}
+
+// pullRequestSummary renders the state of pull requests as a Jira comment in
+// wiki markup
+func pullRequestSummary(prs []*models.PullRequest) string {
+	if len(prs) == 0 {
+		return "No pull requests are linked to this issue yet."
+	}
+
+	var b strings.Builder
+	b.WriteString("Pull request status:\n")
+	for _, pr := range prs {
+		fmt.Fprintf(&b, "* [%s#%d|%s] into %s: %s", pr.Repository, pr.Number, pr.URL, pr.TargetBranch, pr.Status)
+		if blockers := pr.MergeBlockers(); len(blockers) > 0 {
+			fmt.Fprintf(&b, " (%s)", strings.Join(blockers, "; "))
+		}
+		b.WriteString("\n")
+	}
+	return strings.TrimSuffix(b.String(), "\n")
+}
@@ -0,0 +1,117 @@
+package services
+
+import (
+	"context"
+	"testing"
+
+	"github.com/jparrill/devtrackr/internal/jira"
+	"github.com/jparrill/devtrackr/internal/models"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+)
+
+func TestTransitionIssue(t *testing.T) {
+	ctx := context.Background()
+	opts := WriteOptions{Actor: "cli:alice"}
+	newIssue := func() *models.Issue {
+		return &models.Issue{ID: 1, Key: "TEST-1", Status: "New", JiraURL: "https://issues.redhat.com/browse/TEST-1"}
+	}
+
+	t.Run("dry run", func(t *testing.T) {
+		mockStorage := &MockStorage{}
+		mockJira := jira.NewMockClient("New")
+		service := NewTrackingService(mockStorage, mockJira)
+
+		mockStorage.On("GetIssue", "TEST-1").Return(newIssue(), nil)
+		mockStorage.On("CreateAuditEntry", ctx, mock.MatchedBy(func(e *models.AuditEntry) bool {
+			return e.DryRun && e.Action == models.AuditTransition && e.Actor == "cli:alice"
+		})).Return(nil)
+
+		entry, err := service.TransitionIssue(ctx, "TEST-1", "code review", WriteOptions{DryRun: true, Actor: "cli:alice"})
+		require.NoError(t, err)
+		assert.Equal(t, "Code Review (id 11) -> Code Review", entry.Details)
+
+		issue, err := mockJira.GetIssue(ctx, "https://issues.redhat.com/browse/TEST-1")
+		require.NoError(t, err)
+		assert.Equal(t, "New", issue.Status, "dry runs must not reach Jira")
+		mockStorage.AssertNotCalled(t, "UpdateIssue", mock.Anything)
+	})
+
+	t.Run("by target status", func(t *testing.T) {
+		mockStorage := &MockStorage{}
+		service := NewTrackingService(mockStorage, jira.NewMockClient("New"))
+
+		mockStorage.On("GetIssue", "TEST-1").Return(newIssue(), nil)
+		mockStorage.On("CreateAuditEntry", ctx, mock.Anything).Return(nil)
+		mockStorage.On("UpdateIssue", mock.MatchedBy(func(i *models.Issue) bool {
+			return i.Status == "Closed"
+		})).Return(nil)
+		mockStorage.On("CreateEvent", ctx, mock.MatchedBy(func(e *models.Event) bool {
+			return e.From == "New" && e.To == "Closed" && e.Source == models.EventSourceWriteBack && e.Author == "cli:alice"
+		})).Return(nil)
+
+		entry, err := service.TransitionIssue(ctx, "TEST-1", "closed", opts)
+		require.NoError(t, err)
+		assert.False(t, entry.DryRun)
+		assert.Empty(t, entry.Error)
+		mockStorage.AssertExpectations(t)
+	})
+
+	t.Run("unknown transition", func(t *testing.T) {
+		mockStorage := &MockStorage{}
+		service := NewTrackingService(mockStorage, jira.NewMockClient("New"))
+
+		mockStorage.On("GetIssue", "TEST-1").Return(newIssue(), nil)
+
+		_, err := service.TransitionIssue(ctx, "TEST-1", "Verified", opts)
+		require.Error(t, err)
+		assert.Contains(t, err.Error(), `"Code Review", "Close"`)
+		mockStorage.AssertNotCalled(t, "CreateAuditEntry", mock.Anything, mock.Anything)
+	})
+}
+
+func TestCommentPullRequestSummary(t *testing.T) {
+	ctx := context.Background()
+	mockStorage := &MockStorage{}
+	mockJira := jira.NewMockClient("New")
+	service := NewTrackingService(mockStorage, mockJira)
+
+	issue := &models.Issue{ID: 1, Key: "TEST-1", JiraURL: "https://issues.redhat.com/browse/TEST-1"}
+	mockStorage.On("GetIssue", "TEST-1").Return(issue, nil)
+	mockStorage.On("ListPullRequests", ctx, int64(1)).Return([]*models.PullRequest{
+		{Repository: "org/repo", Number: 1, URL: "https://github.com/org/repo/pull/1", TargetBranch: "main", Status: models.PRStatusMerged},
+		{Repository: "org/repo", Number: 2, URL: "https://github.com/org/repo/pull/2", TargetBranch: "release-4.16", Status: models.PRStatusApproved, CIStatus: models.CIStatusFailure, FailingChecks: []string{"e2e"}},
+	}, nil)
+	mockStorage.On("CreateAuditEntry", ctx, mock.Anything).Return(nil)
+
+	entry, err := service.CommentPullRequestSummary(ctx, "TEST-1", WriteOptions{Actor: "api"})
+	require.NoError(t, err)
+
+	expected := "Pull request status:\n" +
+		"* [org/repo#1|https://github.com/org/repo/pull/1] into main: merged\n" +
+		"* [org/repo#2|https://github.com/org/repo/pull/2] into release-4.16: approved (CI is failing: e2e)"
+	assert.Equal(t, expected, entry.Details)
+	assert.Equal(t, []string{expected}, mockJira.Comments)
+}
+
+func TestUpdateLabels(t *testing.T) {
+	ctx := context.Background()
+	mockStorage := &MockStorage{}
+	mockJira := jira.NewMockClient("New")
+	service := NewTrackingService(mockStorage, mockJira)
+
+	issue := &models.Issue{ID: 1, Key: "TEST-1", JiraURL: "https://issues.redhat.com/browse/TEST-1", Labels: []string{"blocked", "ui"}}
+	mockStorage.On("GetIssue", "TEST-1").Return(issue, nil)
+	mockStorage.On("CreateAuditEntry", ctx, mock.Anything).Return(nil)
+	mockStorage.On("UpdateIssue", mock.Anything).Return(nil)
+
+	entry, err := service.UpdateLabels(ctx, "TEST-1", []string{"ready"}, []string{"blocked"}, WriteOptions{Actor: "api"})
+	require.NoError(t, err)
+	assert.Equal(t, "add: ready; remove: blocked", entry.Details)
+	assert.Equal(t, []string{"ui", "ready"}, issue.Labels)
+	assert.Equal(t, []string{"ready"}, mockJira.Labels)
+
+	_, err = service.UpdateLabels(ctx, "TEST-1", nil, nil, WriteOptions{})
+	assert.Error(t, err)
+}
@@ -0,0 +1,159 @@
+package services
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"os"
+	"path/filepath"
+	"sort"
+	"time"
+
+	"github.com/jparrill/devtrackr/internal/config"
+)
+
+// HistoryPruner is implemented by storage backends that can delete history
+// past its retention
+type HistoryPruner interface {
+	PruneEvents(ctx context.Context, before time.Time) (int64, error)
+	PruneAuditEntries(ctx context.Context, before time.Time) (int64, error)
+}
+
+// DatabaseMaintainer is implemented by storage backends that can also be
+// backed up while in use
+type DatabaseMaintainer interface {
+	HistoryPruner
+	Backup(ctx context.Context, path string) error
+}
+
+// backupPattern matches the backups written by RotateBackup
+const backupPattern = "devtrackr-*.db"
+
+// RotateBackup writes a backup named after the current time, such as
+// devtrackr-20240501T120000Z.db, to dir and then deletes the oldest backups
+// beyond keep. keep 0 keeps every backup. It returns the path written.
+func RotateBackup(ctx context.Context, db DatabaseMaintainer, dir string, keep int, now time.Time) (string, error) {
+	if err := os.MkdirAll(dir, 0o755); err != nil {
+		return "", fmt.Errorf("failed to create backup directory: %w", err)
+	}
+
+	path := filepath.Join(dir, "devtrackr-"+now.UTC().Format("20060102T150405Z")+".db")
+	if err := db.Backup(ctx, path); err != nil {
+		return "", err
+	}
+	if keep <= 0 {
+		return path, nil
+	}
+
+	// Names sort in the order backups were taken
+	backups, err := filepath.Glob(filepath.Join(dir, backupPattern))
+	if err != nil {
+		return path, err
+	}
+	sort.Strings(backups)
+	for len(backups) > keep {
+		if err := os.Remove(backups[0]); err != nil {
+			return path, fmt.Errorf("failed to delete old backup: %w", err)
+		}
+		backups = backups[1:]
+	}
+	return path, nil
+}
+
+// PruneResult counts the history deleted by the retention policy
+type PruneResult struct {
+	Events       int64 `json:"events"`
+	AuditEntries int64 `json:"audit_entries"`
+}
+
+// PruneHistory deletes the timeline events and Jira audit entries older than
+// the retention policy keeps
+func PruneHistory(ctx context.Context, db HistoryPruner, retention config.RetentionConfig, now time.Time) (PruneResult, error) {
+	var result PruneResult
+	var err error
+	if retention.Events > 0 {
+		if result.Events, err = db.PruneEvents(ctx, now.Add(-retention.Events)); err != nil {
+			return result, err
+		}
+	}
+	if retention.Audit > 0 {
+		if result.AuditEntries, err = db.PruneAuditEntries(ctx, now.Add(-retention.Audit)); err != nil {
+			return result, err
+		}
+	}
+	return result, nil
+}
+
+// MaintenanceService takes scheduled backups of the database and prunes its
+// history daily according to the retention policy
+type MaintenanceService struct {
+	db   HistoryPruner
+	cfg  config.DatabaseConfig
+	stop chan struct{}
+}
+
+// NewMaintenanceService creates a new database maintenance service. Backups
+// are only taken of storage implementing DatabaseMaintainer.
+func NewMaintenanceService(db HistoryPruner, cfg config.DatabaseConfig) *MaintenanceService {
+	return &MaintenanceService{
+		db:   db,
+		cfg:  cfg,
+		stop: make(chan struct{}),
+	}
+}
+
+// Start prunes the history immediately and then every day, and takes a
+// backup on every backup interval. Either is skipped when not configured.
+func (s *MaintenanceService) Start(ctx context.Context) error {
+	var backups, prunes <-chan time.Time
+	maintainer, canBackup := s.db.(DatabaseMaintainer)
+	switch {
+	case s.cfg.Backup.Dir == "" || s.cfg.Backup.Interval <= 0:
+	case !canBackup:
+		log.Printf("The storage backend cannot be backed up by DevTrackr, use its own tools")
+	default:
+		ticker := time.NewTicker(s.cfg.Backup.Interval)
+		defer ticker.Stop()
+		backups = ticker.C
+		log.Printf("Starting database backups to %s every %v", s.cfg.Backup.Dir, s.cfg.Backup.Interval)
+	}
+	if s.cfg.Retention.Events > 0 || s.cfg.Retention.Audit > 0 {
+		ticker := time.NewTicker(24 * time.Hour)
+		defer ticker.Stop()
+		prunes = ticker.C
+		s.prune(ctx, time.Now())
+	}
+
+	for {
+		select {
+		case <-ctx.Done():
+			return nil
+		case <-s.stop:
+			return nil
+		case now := <-backups:
+			path, err := RotateBackup(ctx, maintainer, s.cfg.Backup.Dir, s.cfg.Backup.Keep, now)
+			if err != nil {
+				log.Printf("Error backing up database: %v", err)
+				continue
+			}
+			log.Printf("Backed up database to %s", path)
+		case now := <-prunes:
+			s.prune(ctx, now)
+		}
+	}
+}
+
+// Stop stops the maintenance service
+func (s *MaintenanceService) Stop() {
+	close(s.stop)
+}
+
+// prune applies the retention policy, logging what it deleted
+func (s *MaintenanceService) prune(ctx context.Context, now time.Time) {
+	result, err := PruneHistory(ctx, s.db, s.cfg.Retention, now)
+	if err != nil {
+		log.Printf("Error pruning database history: %v", err)
+		return
+	}
+	log.Printf("Pruned %d events and %d audit entries past retention", result.Events, result.AuditEntries)
+}
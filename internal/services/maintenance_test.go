@@ -0,0 +1,62 @@
+package services
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/jparrill/devtrackr/internal/config"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// fakeMaintainer writes empty backups and records prune cutoffs
+type fakeMaintainer struct {
+	eventsBefore, auditBefore time.Time
+}
+
+func (f *fakeMaintainer) Backup(ctx context.Context, path string) error {
+	return os.WriteFile(path, nil, 0o644)
+}
+
+func (f *fakeMaintainer) PruneEvents(ctx context.Context, before time.Time) (int64, error) {
+	f.eventsBefore = before
+	return 3, nil
+}
+
+func (f *fakeMaintainer) PruneAuditEntries(ctx context.Context, before time.Time) (int64, error) {
+	f.auditBefore = before
+	return 1, nil
+}
+
+func TestRotateBackup(t *testing.T) {
+	dir := filepath.Join(t.TempDir(), "backups")
+	db := &fakeMaintainer{}
+	start := time.Date(2024, 5, 1, 12, 0, 0, 0, time.UTC)
+
+	for i := 0; i < 4; i++ {
+		path, err := RotateBackup(context.Background(), db, dir, 2, start.Add(time.Duration(i)*time.Hour))
+		require.NoError(t, err)
+		assert.FileExists(t, path)
+	}
+
+	backups, err := filepath.Glob(filepath.Join(dir, backupPattern))
+	require.NoError(t, err)
+	assert.Equal(t, []string{
+		filepath.Join(dir, "devtrackr-20240501T140000Z.db"),
+		filepath.Join(dir, "devtrackr-20240501T150000Z.db"),
+	}, backups)
+}
+
+func TestPruneHistory(t *testing.T) {
+	now := time.Date(2024, 5, 1, 12, 0, 0, 0, time.UTC)
+
+	db := &fakeMaintainer{}
+	result, err := PruneHistory(context.Background(), db, config.RetentionConfig{Events: 48 * time.Hour}, now)
+	require.NoError(t, err)
+	assert.Equal(t, PruneResult{Events: 3}, result)
+	assert.Equal(t, now.Add(-48*time.Hour), db.eventsBefore)
+	assert.True(t, db.auditBefore.IsZero(), "no audit retention keeps the audit log")
+}
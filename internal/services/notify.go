@@ -0,0 +1,95 @@
+package services
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"log"
+	"net/http"
+	"time"
+)
+
+// Notification is a message about an issue sent by an automation rule or
+// the stale work detector
+type Notification struct {
+	Rule      string `json:"rule"` // Rule, or kind of stale work alert, that sent it
+	Issue     string `json:"issue"`
+	URL       string `json:"url"`
+	Recipient string `json:"recipient"` // Who the message is for, empty when unknown
+	Message   string `json:"message"`
+}
+
+// Text renders the notification as a single line of chat text
+func (n Notification) Text() string {
+	text := fmt.Sprintf("%s: %s (%s)", n.Issue, n.Message, n.URL)
+	if n.Recipient != "" {
+		text = "@" + n.Recipient + " " + text
+	}
+	return text
+}
+
+// Notifier delivers notifications
+type Notifier interface {
+	Notify(ctx context.Context, n Notification) error
+}
+
+// LogNotifier writes notifications to the log. It is used when no other
+// notifier is configured.
+type LogNotifier struct{}
+
+// Notify logs the notification
+func (LogNotifier) Notify(ctx context.Context, n Notification) error {
+	log.Printf("Notification from rule %q: %s", n.Rule, n.Text())
+	return nil
+}
+
+// WebhookNotifier posts notifications as JSON to a URL. The payload carries
+// the rendered message in "text", as expected by Slack incoming webhooks,
+// next to the fields of the notification.
+type WebhookNotifier struct {
+	url        string
+	httpClient *http.Client
+}
+
+// NewWebhookNotifier creates a notifier posting to url
+func NewWebhookNotifier(url string) *WebhookNotifier {
+	return &WebhookNotifier{
+		url:        url,
+		httpClient: &http.Client{Timeout: 10 * time.Second},
+	}
+}
+
+// Notify posts the notification to the webhook
+func (w *WebhookNotifier) Notify(ctx context.Context, n Notification) error {
+	payload := struct {
+		Notification
+		Text string `json:"text"`
+	}{n, n.Text()}
+	return w.post(ctx, payload)
+}
+
+// post sends a JSON payload to the webhook
+func (w *WebhookNotifier) post(ctx context.Context, payload any) error {
+	data, err := json.Marshal(payload)
+	if err != nil {
+		return err
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, w.url, bytes.NewReader(data))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", "application/json")
+
+	resp, err := w.httpClient.Do(req)
+	if err != nil {
+		return fmt.Errorf("failed to send notification: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		return fmt.Errorf("failed to send notification: status code %d", resp.StatusCode)
+	}
+	return nil
+}
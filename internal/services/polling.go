@@ -4,9 +4,14 @@ import (
 	"context"
 	"fmt"
 	"log"
+	"slices"
 	"time"
 
+	"github.com/jparrill/devtrackr/internal/backport"
+	"github.com/jparrill/devtrackr/internal/forge"
 	"github.com/jparrill/devtrackr/internal/jira"
+	"github.com/jparrill/devtrackr/internal/models"
+	"github.com/jparrill/devtrackr/internal/polling"
 )
 
 // PollingService handles the background polling of issues
@@ -14,107 +19,289 @@ type PollingService struct {
 	storage         Storage
 	jira            jira.JiraClient
 	stop            chan struct{}
-	pollingInterval time.Duration
+	forges          *forge.Registry
+	detector        *backport.Detector
+	webhookInterval time.Duration
+	policy          *polling.Engine
+	listener        EventListener
+
+	// schedule is only accessed by the goroutine running Start
+	schedule *schedule
+	changes  chan string
+	resync   chan struct{}
+}
+
+// EventListener is told about every change observed while polling or
+// delivered by webhooks, once it is recorded in the timeline of the issue
+type EventListener interface {
+	HandleEvent(ctx context.Context, issue *models.Issue, event *models.Event)
+}
+
+// PollingOption configures optional dependencies of the polling service
+type PollingOption func(*PollingService)
+
+// WithPollingForges makes the polling service sync the pull requests of
+// polled issues from their forge, picked by the host of each URL
+func WithPollingForges(registry *forge.Registry) PollingOption {
+	return func(s *PollingService) {
+		s.forges = registry
+	}
+}
+
+// WithPollingBackportDetector runs backport detection on synced pull requests
+func WithPollingBackportDetector(detector *backport.Detector) PollingOption {
+	return func(s *PollingService) {
+		s.detector = detector
+	}
+}
+
+// WithWebhookPollingInterval sets the interval used for issues whose updates
+// arrive through Jira webhooks. Polling only reconciles missed deliveries for
+// them, so it can be much less frequent.
+func WithWebhookPollingInterval(interval time.Duration) PollingOption {
+	return func(s *PollingService) {
+		s.webhookInterval = interval
+	}
+}
+
+// WithPollingPolicy sets the engine picking the polling interval of each
+// issue. Without it, every issue is polled at the default interval.
+func WithPollingPolicy(engine *polling.Engine) PollingOption {
+	return func(s *PollingService) {
+		s.policy = engine
+	}
 }
 
 // NewPollingService creates a new polling service
-func NewPollingService(storage Storage, jira jira.JiraClient, pollingInterval time.Duration) *PollingService {
-	return &PollingService{
-		storage:         storage,
-		jira:            jira,
-		stop:            make(chan struct{}),
-		pollingInterval: pollingInterval,
+func NewPollingService(storage Storage, jira jira.JiraClient, pollingInterval time.Duration, opts ...PollingOption) *PollingService {
+	s := &PollingService{
+		storage:  storage,
+		jira:     jira,
+		stop:     make(chan struct{}),
+		schedule: newSchedule(),
+		changes:  make(chan string, 64),
+		resync:   make(chan struct{}, 1),
+	}
+	for _, opt := range opts {
+		opt(s)
 	}
+	if s.policy == nil {
+		s.policy = polling.NewEngine(polling.Config{Interval: pollingInterval}, s.webhookInterval)
+	}
+	return s
 }
 
-// Start begins the polling service
+// resyncInterval is how often the schedule is rebuilt from storage, to pick up
+// changes made by other processes such as the set-polling command
+const resyncInterval = 10 * time.Minute
+
+// Start begins the polling service. Issues are kept in a queue ordered by the
+// time they are next due, computed from their persisted last poll time and
+// interval, and each one is polled as soon as it is due.
 func (s *PollingService) Start(ctx context.Context) error {
-	log.Printf("Starting polling service with default interval of %v", s.pollingInterval)
+	log.Printf("Starting polling service with default interval of %v", s.policy.Interval())
 
-	// Start periodic polling
-	ticker := time.NewTicker(1 * time.Minute) // Check every minute
-	defer ticker.Stop()
+	if err := s.reload(); err != nil {
+		return err
+	}
+
+	timer := time.NewTimer(0)
+	defer timer.Stop()
+	resync := time.NewTicker(resyncInterval)
+	defer resync.Stop()
 
 	for {
+		wait := resyncInterval
+		if due, ok := s.schedule.next(); ok {
+			wait = max(time.Until(due), 0)
+		}
+		timer.Reset(wait)
+
 		select {
 		case <-ctx.Done():
 			return nil
 		case <-s.stop:
 			return nil
-		case <-ticker.C:
-			if err := s.pollIssues(ctx); err != nil {
-				log.Printf("Error polling issues: %v", err)
+		case key := <-s.changes:
+			s.reschedule(key)
+		case <-s.resync:
+			if err := s.reload(); err != nil {
+				log.Printf("Error reloading polling schedule: %v", err)
+			}
+		case <-resync.C:
+			if err := s.reload(); err != nil {
+				log.Printf("Error reloading polling schedule: %v", err)
 			}
+		case <-timer.C:
+			s.pollDue(ctx)
 		}
 	}
 }
 
+// SetEventListener forwards the changes observed while polling to l. It must
+// be called before Start; it is not an option because listeners such as the
+// rule service depend on a tracking service notifying this polling service.
+func (s *PollingService) SetEventListener(l EventListener) {
+	s.listener = l
+}
+
 // Stop stops the polling service
 func (s *PollingService) Stop() {
 	close(s.stop)
 }
 
-// pollIssues polls all issues
-func (s *PollingService) pollIssues(ctx context.Context) error {
-	log.Printf("Starting polling cycle...")
+// IssueChanged tells the scheduler that an issue was added, deleted or had
+// its polling settings changed, so it is rescheduled right away. It never
+// blocks; if too many changes are pending, the whole schedule is reloaded.
+func (s *PollingService) IssueChanged(key string) {
+	select {
+	case s.changes <- key:
+	default:
+		select {
+		case s.resync <- struct{}{}:
+		default:
+		}
+	}
+}
 
-	// Get all issues
+// reload rebuilds the schedule from the issues in storage
+func (s *PollingService) reload() error {
 	issues, err := s.storage.ListIssues()
 	if err != nil {
 		return fmt.Errorf("failed to list issues: %w", err)
 	}
 
-	log.Printf("Found %d issues to check...", len(issues))
-
-	now := time.Now()
-	updated := 0
-	unchanged := 0
-	skipped := 0
-
-	for _, issue := range issues {
-		// Check if it's time to poll this issue
-		interval := time.Duration(issue.PollingInterval) * time.Second
-		if interval == 0 {
-			interval = s.pollingInterval
+	s.schedule = newSchedule()
+	for i := range issues {
+		if due, ok := s.nextPoll(&issues[i]); ok {
+			s.schedule.set(issues[i].QualifiedKey(), due)
 		}
+	}
 
-		// Skip if not enough time has passed since last poll
-		if !issue.LastPolledAt.IsZero() && now.Sub(issue.LastPolledAt) < interval {
-			skipped++
+	log.Printf("Scheduled %d issues for polling", s.schedule.len())
+	return nil
+}
+
+// reschedule refreshes the slot of a single issue, dropping deleted issues
+func (s *PollingService) reschedule(key string) {
+	issue, err := s.storage.GetIssue(key)
+	if err != nil {
+		s.schedule.remove(key)
+		return
+	}
+	if due, ok := s.nextPoll(issue); ok {
+		s.schedule.set(issue.QualifiedKey(), due)
+	} else {
+		s.schedule.remove(issue.QualifiedKey())
+	}
+}
+
+// nextPoll returns when an issue is due, based on its last poll, and false
+// when its policy stopped polling it
+func (s *PollingService) nextPoll(issue *models.Issue) (time.Time, bool) {
+	decision := s.decide(issue)
+	if decision.Stopped {
+		return time.Time{}, false
+	}
+	if issue.LastPolledAt.IsZero() {
+		return time.Now(), true
+	}
+	return issue.LastPolledAt.Add(decision.Duration()), true
+}
+
+// pollDue polls every issue that is due and schedules its next poll
+func (s *PollingService) pollDue(ctx context.Context) {
+	for _, key := range s.schedule.popDue(time.Now()) {
+		issue, err := s.storage.GetIssue(key)
+		if err != nil {
+			// The issue is no longer tracked
 			continue
 		}
 
-		// Get latest issue data from Jira
-		jiraIssue, err := s.jira.GetIssue(ctx, issue.JiraURL)
-		if err != nil {
-			log.Printf("Error getting issue %s from Jira: %v", issue.Key, err)
+		if err := s.pollIssue(ctx, issue); err != nil {
+			log.Printf("Error polling issue %s: %v", key, err)
+		}
+
+		decision := s.decide(issue)
+		if decision.Stopped {
+			log.Printf("Stopped polling issue %s: %s", key, decision.Reason)
 			continue
 		}
 
-		// Update issue if status has changed
-		if jiraIssue.Status != issue.Status {
-			issue.Status = jiraIssue.Status
-			issue.Title = jiraIssue.Title
-			issue.UpdatedAt = now
-			issue.LastPolledAt = now
+		// Failed polls are retried after a full interval rather than hammering
+		// Jira. Polling may have recorded the instance of older issues, which
+		// changes their key.
+		s.schedule.set(issue.QualifiedKey(), time.Now().Add(decision.Duration()))
+	}
+}
 
-			if err := s.storage.UpdateIssue(&issue); err != nil {
-				log.Printf("Error updating issue %s: %v", issue.Key, err)
-				continue
-			}
+// pollIssue refreshes a single issue from Jira and syncs its pull requests
+func (s *PollingService) pollIssue(ctx context.Context, issue *models.Issue) error {
+	now := time.Now()
 
-			log.Printf("Updated issue %s: %s -> %s", issue.Key, issue.Status, jiraIssue.Status)
-			updated++
+	// Get latest issue data from Jira
+	jiraIssue, err := s.jira.GetIssue(ctx, issue.JiraURL)
+	if err != nil {
+		return fmt.Errorf("failed to get issue from Jira: %w", err)
+	}
+
+	previousStatus := issue.Status
+	applyJiraIssue(issue, jiraIssue)
+	issue.LastPolledAt = now
+	if issue.Status != previousStatus {
+		issue.UpdatedAt = now
+	}
+
+	if err := s.storage.UpdateIssue(issue); err != nil {
+		return fmt.Errorf("failed to update issue: %w", err)
+	}
+	if err := syncRelations(ctx, s.storage, s.jira, issue); err != nil {
+		log.Printf("Error syncing children and links of issue %s: %v", issue.Key, err)
+	}
+
+	var events []*models.Event
+	if issue.Status != previousStatus {
+		event, err := recordStatusChange(ctx, s.storage, issue, previousStatus, "", models.EventSourcePolling, now)
+		if err != nil {
+			log.Printf("Error recording status change of issue %s: %v", issue.Key, err)
 		} else {
-			// Update LastPolledAt even if status hasn't changed
-			issue.LastPolledAt = now
-			if err := s.storage.UpdateIssue(&issue); err != nil {
-				log.Printf("Error updating LastPolledAt for issue %s: %v", issue.Key, err)
-			}
-			unchanged++
+			events = append(events, event)
+		}
+		log.Printf("Updated issue %s: %s -> %s", issue.Key, previousStatus, issue.Status)
+	}
+
+	if s.forges != nil {
+		prEvents, err := syncPullRequests(ctx, s.storage, s.forges, s.detector, issue)
+		if err != nil {
+			log.Printf("Error syncing pull requests of issue %s: %v", issue.Key, err)
+		}
+		events = append(events, prEvents...)
+	}
+
+	// Listeners see the issue and its pull requests fully synced
+	if s.listener != nil {
+		for _, event := range events {
+			s.listener.HandleEvent(ctx, issue, event)
 		}
 	}
 
-	log.Printf("Polling cycle complete: %d issues updated, %d issues unchanged, %d issues skipped", updated, unchanged, skipped)
 	return nil
 }
+
+// decide returns the polling decision of an issue under the policy engine
+func (s *PollingService) decide(issue *models.Issue) models.PollingDecision {
+	return decidePolling(s.storage, s.policy, issue)
+}
+
+// decidePolling evaluates the polling policy of an issue, looking up its
+// pending pull requests only when the policy depends on them. Closed pull
+// requests are abandoned and do not keep the issue polled, as in StaleAlerts.
+func decidePolling(storage Storage, engine *polling.Engine, issue *models.Issue) models.PollingDecision {
+	hasUnmerged := false
+	if engine.NeedsPullRequests(issue) {
+		prs, err := storage.ListPullRequests(context.Background(), issue.ID)
+		// Keep polling when in doubt
+		hasUnmerged = err != nil || slices.ContainsFunc(prs, isPendingPullRequest)
+	}
+	return engine.Evaluate(issue, hasUnmerged, time.Now())
+}
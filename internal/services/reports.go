@@ -0,0 +1,281 @@
+package services
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/jparrill/devtrackr/internal/models"
+	"github.com/jparrill/devtrackr/internal/report"
+)
+
+// doneStatuses lists the Jira statuses (lower case) that mean an issue is
+// finished, for issues whose status category is unknown
+var doneStatuses = map[string]bool{
+	"closed":          true,
+	"done":            true,
+	"verified":        true,
+	"resolved":        true,
+	"release pending": true,
+}
+
+// isDoneStatus reports whether a Jira status means the issue is finished. The
+// status category decides when known, the status name otherwise.
+func isDoneStatus(status, category string) bool {
+	if category != "" {
+		return category == models.StatusCategoryDone
+	}
+	return doneStatuses[strings.ToLower(status)]
+}
+
+// isPendingPullRequest reports whether a pull request still has to be merged.
+// Closed pull requests were abandoned and are not considered pending.
+func isPendingPullRequest(pr *models.PullRequest) bool {
+	return pr.Status != models.PRStatusMerged && pr.Status != models.PRStatusClosed
+}
+
+// ReleaseBranch returns the branch backports for a release are expected on
+func ReleaseBranch(version string) string {
+	return "release-" + version
+}
+
+// ReleaseReport builds the readiness report of every tracked issue whose fix
+// versions target the given release. If branch is empty, the conventional
+// release branch for the version is used.
+func (s *TrackingService) ReleaseReport(ctx context.Context, version, branch string) (*report.ReleaseReport, error) {
+	if version == "" {
+		return nil, fmt.Errorf("release version is required")
+	}
+	if branch == "" {
+		branch = ReleaseBranch(version)
+	}
+
+	issues, err := s.storage.ListIssues()
+	if err != nil {
+		return nil, fmt.Errorf("failed to list issues: %w", err)
+	}
+
+	result := &report.ReleaseReport{
+		Version:     version,
+		Branch:      branch,
+		GeneratedAt: time.Now(),
+		Issues:      []report.ReleaseIssue{},
+	}
+
+	for i := range issues {
+		issue := &issues[i]
+		if !issue.TargetsRelease(version) {
+			continue
+		}
+
+		prs, err := s.storage.ListPullRequests(ctx, issue.ID)
+		if err != nil {
+			return nil, fmt.Errorf("failed to list pull requests for %s: %w", issue.Key, err)
+		}
+
+		entry := releaseIssue(issue, prs, branch)
+		if entry.Ready() {
+			result.Ready++
+		} else {
+			result.Blocked++
+		}
+		result.Issues = append(result.Issues, entry)
+	}
+
+	sort.Slice(result.Issues, func(i, j int) bool {
+		return result.Issues[i].Key < result.Issues[j].Key
+	})
+
+	return result, nil
+}
+
+// releaseIssue computes the readiness of a single issue for a release branch
+func releaseIssue(issue *models.Issue, prs []*models.PullRequest, branch string) report.ReleaseIssue {
+	entry := report.ReleaseIssue{
+		Key:          issue.Key,
+		Title:        issue.Title,
+		Status:       issue.Status,
+		JiraURL:      issue.JiraURL,
+		PullRequests: map[string][]report.PullRequestRef{},
+		Blockers:     []report.Blocker{},
+	}
+
+	if !isDoneStatus(issue.Status, issue.StatusCategory) {
+		entry.Blockers = append(entry.Blockers, report.Blocker{
+			Kind:    report.BlockerIssueNotDone,
+			Message: fmt.Sprintf("issue is in status %q", issue.Status),
+		})
+	}
+
+	onReleaseBranch := false
+	for _, pr := range prs {
+		ref := report.NewPullRequestRef(pr)
+		entry.PullRequests[pr.TargetBranch] = append(entry.PullRequests[pr.TargetBranch], ref)
+
+		if pr.TargetBranch == branch && pr.Status != models.PRStatusClosed {
+			onReleaseBranch = true
+		}
+
+		if isPendingPullRequest(pr) {
+			entry.Blockers = append(entry.Blockers, report.Blocker{
+				Kind:    report.BlockerUnmergedPR,
+				Message: fmt.Sprintf("pull request %s targeting %s is %s", ref, pr.TargetBranch, pr.Status),
+			})
+		}
+	}
+
+	// A fix that exists on other branches but not on the release branch still needs a backport
+	if len(prs) > 0 && !onReleaseBranch {
+		entry.Blockers = append(entry.Blockers, report.Blocker{
+			Kind:    report.BlockerMissingBackport,
+			Message: fmt.Sprintf("no pull request targets %s", branch),
+		})
+	}
+
+	return entry
+}
+
+// BackportMatrix builds the backport matrix of tracked issues. When branches
+// is empty, each issue row uses the branches configured for its project and
+// the matrix columns are the union of them. An empty project includes issues
+// of every project.
+func (s *TrackingService) BackportMatrix(ctx context.Context, branches []string, project string) (*report.BackportMatrix, error) {
+	issues, err := s.storage.ListIssues()
+	if err != nil {
+		return nil, fmt.Errorf("failed to list issues: %w", err)
+	}
+
+	matrix := &report.BackportMatrix{
+		Branches:    append([]string{}, branches...),
+		GeneratedAt: time.Now(),
+		Rows:        []report.MatrixRow{},
+	}
+
+	columns := map[string]bool{}
+	for _, branch := range matrix.Branches {
+		columns[branch] = true
+	}
+	addColumn := func(branch string) {
+		if !columns[branch] {
+			columns[branch] = true
+			matrix.Branches = append(matrix.Branches, branch)
+		}
+	}
+
+	// rowPRs holds the pull requests of each row, in row order
+	var rowPRs [][]*models.PullRequest
+	for i := range issues {
+		issue := &issues[i]
+		if project != "" && issue.Project() != project {
+			continue
+		}
+
+		prs, err := s.storage.ListPullRequests(ctx, issue.ID)
+		if err != nil {
+			return nil, fmt.Errorf("failed to list pull requests for %s: %w", issue.Key, err)
+		}
+
+		expected := branches
+		if len(expected) == 0 {
+			expected = s.config.ExpectedBranches(issue.Project())
+			for _, branch := range expected {
+				addColumn(branch)
+			}
+		}
+
+		row := report.MatrixRow{
+			Key:     issue.Key,
+			Title:   issue.Title,
+			Status:  issue.Status,
+			JiraURL: issue.JiraURL,
+			Cells:   map[string]report.MatrixCell{},
+		}
+		for _, branch := range expected {
+			row.Cells[branch] = report.MatrixCell{Expected: true}
+		}
+		matrix.Rows = append(matrix.Rows, row)
+		rowPRs = append(rowPRs, prs)
+	}
+
+	// Without explicit or configured branches, show every branch seen on a pull request
+	if len(matrix.Branches) == 0 {
+		seen := map[string]bool{}
+		for _, prs := range rowPRs {
+			for _, pr := range prs {
+				if pr.TargetBranch != "" {
+					seen[pr.TargetBranch] = true
+				}
+			}
+		}
+		for branch := range seen {
+			matrix.Branches = append(matrix.Branches, branch)
+		}
+		sort.Strings(matrix.Branches)
+	}
+
+	for i := range matrix.Rows {
+		row := &matrix.Rows[i]
+		for _, pr := range rowPRs[i] {
+			if len(columns) > 0 && !columns[pr.TargetBranch] {
+				continue
+			}
+			cell := row.Cells[pr.TargetBranch]
+			cell.Count++
+			// Closed pull requests were abandoned and leave an expected
+			// branch missing, as in the rules and alerts
+			abandoned := pr.Status == models.PRStatusClosed && cell.Expected
+			if !abandoned && (cell.PullRequest == nil || prStatusRank(pr.Status) > prStatusRank(cell.PullRequest.Status)) {
+				ref := report.NewPullRequestRef(pr)
+				cell.PullRequest = &ref
+			}
+			row.Cells[pr.TargetBranch] = cell
+		}
+	}
+
+	sort.Slice(matrix.Rows, func(i, j int) bool {
+		return matrix.Rows[i].Key < matrix.Rows[j].Key
+	})
+
+	return matrix, nil
+}
+
+// prStatusRank orders pull request states by how far along they are, so the
+// matrix shows the most relevant pull request when a branch has several
+func prStatusRank(status models.PRStatus) int {
+	switch status {
+	case models.PRStatusMerged:
+		return 5
+	case models.PRStatusApproved:
+		return 4
+	case models.PRStatusReview:
+		return 3
+	case models.PRStatusOpen:
+		return 2
+	case models.PRStatusDraft:
+		return 1
+	default:
+		return 0
+	}
+}
+
+// IssueRows returns the tracked issues passing a filter with their pull
+// requests, sorted by key, to be listed as a sheet
+func (s *TrackingService) IssueRows(ctx context.Context, filter models.IssueFilter) ([]report.IssueRow, error) {
+	issues, err := s.ListIssuesMatching(ctx, filter)
+	if err != nil {
+		return nil, err
+	}
+	sort.Slice(issues, func(i, j int) bool { return issues[i].QualifiedKey() < issues[j].QualifiedKey() })
+
+	rows := make([]report.IssueRow, 0, len(issues))
+	for _, issue := range issues {
+		prs, err := s.storage.ListPullRequests(ctx, issue.ID)
+		if err != nil {
+			return nil, fmt.Errorf("failed to list pull requests for %s: %w", issue.Key, err)
+		}
+		rows = append(rows, report.IssueRow{Issue: issue, PullRequests: prs})
+	}
+	return rows, nil
+}
@@ -0,0 +1,127 @@
+package services
+
+import (
+	"context"
+	"testing"
+
+	"github.com/jparrill/devtrackr/internal/config"
+	"github.com/jparrill/devtrackr/internal/jira"
+	"github.com/jparrill/devtrackr/internal/models"
+	"github.com/jparrill/devtrackr/internal/report"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestReleaseReport(t *testing.T) {
+	// Create mocks
+	mockStorage := &MockStorage{}
+	mockJira := jira.NewMockClient("In Progress")
+
+	// Create service
+	service := NewTrackingService(mockStorage, mockJira)
+
+	ctx := context.Background()
+	issues := []models.Issue{
+		{ID: 1, Key: "TEST-1", Title: "Ready fix", Status: "Verified", FixVersions: []string{"4.16.0"}},
+		{ID: 2, Key: "TEST-2", Title: "Blocked fix", Status: "POST", FixVersions: []string{"4.16.z"}},
+		{ID: 3, Key: "TEST-3", Title: "Other release", Status: "Closed", FixVersions: []string{"4.15.0"}},
+	}
+
+	mockStorage.On("ListIssues").Return(issues, nil).Once()
+	mockStorage.On("ListPullRequests", ctx, int64(1)).Return([]*models.PullRequest{
+		{Number: 10, Repository: "org/repo", TargetBranch: "main", Status: models.PRStatusMerged},
+		{Number: 11, Repository: "org/repo", TargetBranch: "release-4.16", Status: models.PRStatusMerged, IsBackport: true},
+	}, nil).Once()
+	mockStorage.On("ListPullRequests", ctx, int64(2)).Return([]*models.PullRequest{
+		{Number: 20, Repository: "org/repo", TargetBranch: "main", Status: models.PRStatusOpen},
+	}, nil).Once()
+
+	rep, err := service.ReleaseReport(ctx, "4.16", "")
+	require.NoError(t, err)
+	assert.Equal(t, "release-4.16", rep.Branch)
+	require.Len(t, rep.Issues, 2)
+	assert.Equal(t, 1, rep.Ready)
+	assert.Equal(t, 1, rep.Blocked)
+
+	ready := rep.Issues[0]
+	assert.Equal(t, "TEST-1", ready.Key)
+	assert.True(t, ready.Ready())
+	assert.Len(t, ready.PullRequests["main"], 1)
+	assert.Len(t, ready.PullRequests["release-4.16"], 1)
+
+	blocked := rep.Issues[1]
+	assert.Equal(t, "TEST-2", blocked.Key)
+	var kinds []report.BlockerKind
+	for _, b := range blocked.Blockers {
+		kinds = append(kinds, b.Kind)
+	}
+	assert.ElementsMatch(t, []report.BlockerKind{
+		report.BlockerIssueNotDone,
+		report.BlockerUnmergedPR,
+		report.BlockerMissingBackport,
+	}, kinds)
+
+	mockStorage.AssertExpectations(t)
+}
+
+func TestIsDoneStatus(t *testing.T) {
+	tests := []struct {
+		status   string
+		category string
+		done     bool
+	}{
+		{"Closed", models.StatusCategoryDone, true},
+		{"Shipped", models.StatusCategoryDone, true},
+		{"Verified", models.StatusCategoryInProgress, false},
+		{"Verified", "", true},
+		{"POST", "", false},
+	}
+	for _, tt := range tests {
+		assert.Equal(t, tt.done, isDoneStatus(tt.status, tt.category), "%s (%s)", tt.status, tt.category)
+	}
+}
+
+func TestBackportMatrix(t *testing.T) {
+	// Create mocks
+	mockStorage := &MockStorage{}
+	mockJira := jira.NewMockClient("In Progress")
+
+	// Create service with per-project branches
+	cfg := config.Default()
+	cfg.Projects["TEST"] = config.ProjectConfig{Branches: []string{"main", "release-4.16", "release-4.15"}}
+	service := NewTrackingService(mockStorage, mockJira, WithConfig(cfg))
+
+	ctx := context.Background()
+	issues := []models.Issue{
+		{ID: 1, Key: "TEST-1", Status: "POST"},
+		{ID: 2, Key: "OTHER-1", Status: "New"},
+	}
+
+	mockStorage.On("ListIssues").Return(issues, nil)
+	mockStorage.On("ListPullRequests", ctx, int64(1)).Return([]*models.PullRequest{
+		{Number: 10, TargetBranch: "main", Status: models.PRStatusMerged},
+		{Number: 12, TargetBranch: "release-4.16", Status: models.PRStatusClosed},
+		{Number: 13, TargetBranch: "release-4.16", Status: models.PRStatusOpen},
+		{Number: 14, TargetBranch: "release-4.15", Status: models.PRStatusClosed},
+	}, nil)
+	mockStorage.On("ListPullRequests", ctx, int64(2)).Return([]*models.PullRequest{}, nil)
+
+	matrix, err := service.BackportMatrix(ctx, nil, "TEST")
+	require.NoError(t, err)
+	assert.Equal(t, []string{"main", "release-4.16", "release-4.15"}, matrix.Branches)
+	require.Len(t, matrix.Rows, 1)
+
+	row := matrix.Rows[0]
+	assert.Equal(t, "#10 merged", row.Cells["main"].String())
+	assert.Equal(t, "#13 open (+1)", row.Cells["release-4.16"].String())
+	assert.True(t, row.Cells["release-4.15"].Missing(), "a closed pull request does not cover its branch")
+	assert.Equal(t, "missing", row.Cells["release-4.15"].String())
+
+	// Explicit branches apply to every project
+	matrix, err = service.BackportMatrix(ctx, []string{"main"}, "")
+	require.NoError(t, err)
+	assert.Equal(t, []string{"main"}, matrix.Branches)
+	require.Len(t, matrix.Rows, 2)
+	assert.Equal(t, "OTHER-1", matrix.Rows[0].Key)
+	assert.Equal(t, "missing", matrix.Rows[0].Cells["main"].String())
+}
@@ -0,0 +1,323 @@
+package services
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/jparrill/devtrackr/internal/models"
+	"github.com/jparrill/devtrackr/internal/rules"
+)
+
+// scheduleTick is how often the rule service checks for scheduled rules due
+const scheduleTick = time.Minute
+
+// RuleService runs automation rules on the changes observed by the polling
+// service or delivered by webhooks, and scheduled rules on every tracked issue
+type RuleService struct {
+	trackingService *TrackingService
+	engine          *rules.Engine
+	notifier        Notifier
+	stop            chan struct{}
+}
+
+// RuleOption configures optional dependencies of the rule service
+type RuleOption func(*RuleService)
+
+// WithNotifier sets where rule notifications are delivered. Without it they
+// are logged.
+func WithNotifier(n Notifier) RuleOption {
+	return func(s *RuleService) {
+		s.notifier = n
+	}
+}
+
+// NewRuleService creates a new rule service
+func NewRuleService(trackingService *TrackingService, engine *rules.Engine, opts ...RuleOption) *RuleService {
+	s := &RuleService{
+		trackingService: trackingService,
+		engine:          engine,
+		notifier:        LogNotifier{},
+		stop:            make(chan struct{}),
+	}
+	for _, opt := range opts {
+		opt(s)
+	}
+	return s
+}
+
+// RuleMatch is a rule that fired, or would have fired, on an issue
+type RuleMatch struct {
+	Issue   string        `json:"issue"`
+	Rule    string        `json:"rule"`
+	Event   *models.Event `json:"event,omitempty"` // Nil for scheduled rules
+	Actions []string      `json:"actions"`         // What the actions do, rendered for the issue
+}
+
+// HandleEvent runs the rules triggered by a change of an issue
+func (s *RuleService) HandleEvent(ctx context.Context, issue *models.Issue, event *models.Event) {
+	facts, err := s.facts(ctx, issue)
+	if err != nil {
+		log.Printf("Error evaluating rules for issue %s: %v", issue.Key, err)
+		return
+	}
+
+	ev, ok := ruleEvent(event, facts)
+	if !ok {
+		return
+	}
+	for _, rule := range s.engine.Match(ev, facts) {
+		s.run(ctx, rule, facts)
+	}
+}
+
+// Start runs the scheduled rules on every tracked issue whenever their
+// interval elapses, starting one interval from now
+func (s *RuleService) Start(ctx context.Context) error {
+	scheduled := s.engine.Scheduled()
+	if len(scheduled) == 0 {
+		return nil
+	}
+	log.Printf("Starting rule service with %d scheduled rules", len(scheduled))
+
+	lastRun := map[string]time.Time{}
+	for _, rule := range scheduled {
+		lastRun[rule.Name] = time.Now()
+	}
+
+	ticker := time.NewTicker(scheduleTick)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return nil
+		case <-s.stop:
+			return nil
+		case now := <-ticker.C:
+			for _, rule := range scheduled {
+				if now.Sub(lastRun[rule.Name]) < rule.Trigger.Schedule {
+					continue
+				}
+				lastRun[rule.Name] = now
+				if err := s.runScheduled(ctx, rule); err != nil {
+					log.Printf("Error running rule %q: %v", rule.Name, err)
+				}
+			}
+		}
+	}
+}
+
+// Stop stops running scheduled rules
+func (s *RuleService) Stop() {
+	close(s.stop)
+}
+
+// runScheduled runs a scheduled rule on every tracked issue meeting its conditions
+func (s *RuleService) runScheduled(ctx context.Context, rule rules.Rule) error {
+	issues, err := s.trackingService.storage.ListIssues()
+	if err != nil {
+		return fmt.Errorf("failed to list issues: %w", err)
+	}
+
+	for i := range issues {
+		facts, err := s.facts(ctx, &issues[i])
+		if err != nil {
+			return err
+		}
+		if rule.Holds(facts) {
+			s.run(ctx, rule, facts)
+		}
+	}
+	return nil
+}
+
+// run runs the actions of a rule on an issue, stopping at the first failure
+func (s *RuleService) run(ctx context.Context, rule rules.Rule, facts *rules.Facts) {
+	key := facts.Issue.QualifiedKey()
+	for _, action := range rule.Actions {
+		log.Printf("Rule %q on %s: %s", rule.Name, key, action.Describe(facts))
+		if err := s.runAction(ctx, rule, action, facts); err != nil {
+			log.Printf("Error running rule %q on %s: %v", rule.Name, key, err)
+			return
+		}
+	}
+}
+
+// runAction runs a single action. In dry-run mode, Jira changes are only
+// audited, and notifications and polling changes only logged.
+func (s *RuleService) runAction(ctx context.Context, rule rules.Rule, action rules.Action, facts *rules.Facts) error {
+	issue := facts.Issue
+	key := issue.QualifiedKey()
+	opts := WriteOptions{DryRun: rule.DryRun, Actor: "rule:" + rule.Name}
+
+	var err error
+	switch {
+	case action.Transition != "":
+		target := rules.Render(action.Transition, facts)
+		if strings.EqualFold(issue.Status, target) {
+			return nil
+		}
+		_, err = s.trackingService.TransitionIssue(ctx, key, target, opts)
+	case action.Comment != "":
+		_, err = s.trackingService.CommentIssue(ctx, key, rules.Render(action.Comment, facts), opts)
+	case action.CommentPullRequests:
+		_, err = s.trackingService.CommentPullRequestSummary(ctx, key, opts)
+	case action.Notify != nil && !rule.DryRun:
+		err = s.notifier.Notify(ctx, Notification{
+			Rule:      rule.Name,
+			Issue:     key,
+			URL:       issue.JiraURL,
+			Recipient: action.Notify.Recipient(facts),
+			Message:   rules.Render(action.Notify.Message, facts),
+		})
+	case action.SetPolling != nil && !rule.DryRun:
+		err = s.trackingService.UpdateIssuePollingInterval(ctx, key, int(action.SetPolling.Seconds()))
+	}
+	return err
+}
+
+// Replay runs the recorded timeline of an issue, or of every tracked issue
+// when key is empty, through the rules without running any action, and
+// returns the rules that would have fired. Issue and pull request states are
+// rewound to before the first recorded change and moved forward event by
+// event; other fields keep their current value. Scheduled rules are not
+// replayed.
+func (s *RuleService) Replay(ctx context.Context, key string) ([]RuleMatch, error) {
+	var issues []*models.Issue
+	if key != "" {
+		issue, err := s.trackingService.storage.GetIssue(key)
+		if err != nil {
+			return nil, fmt.Errorf("failed to get issue: %w", err)
+		}
+		issues = append(issues, issue)
+	} else {
+		all, err := s.trackingService.storage.ListIssues()
+		if err != nil {
+			return nil, fmt.Errorf("failed to list issues: %w", err)
+		}
+		for i := range all {
+			issues = append(issues, &all[i])
+		}
+	}
+
+	var matches []RuleMatch
+	for _, issue := range issues {
+		replayed, err := s.replayIssue(ctx, issue)
+		if err != nil {
+			return nil, err
+		}
+		matches = append(matches, replayed...)
+	}
+	return matches, nil
+}
+
+// replayIssue replays the timeline of a single issue
+func (s *RuleService) replayIssue(ctx context.Context, issue *models.Issue) ([]RuleMatch, error) {
+	events, err := s.trackingService.storage.ListEvents(ctx, issue.ID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list events of %s: %w", issue.Key, err)
+	}
+	stored, err := s.trackingService.storage.ListPullRequests(ctx, issue.ID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list pull requests of %s: %w", issue.Key, err)
+	}
+
+	// Work on copies, rewound to before the first event
+	replayed := *issue
+	prs := make([]*models.PullRequest, 0, len(stored))
+	for _, pr := range stored {
+		clone := *pr
+		prs = append(prs, &clone)
+	}
+	expected := s.trackingService.config.ExpectedBranches(issue.Project())
+	for i := len(events) - 1; i >= 0; i-- {
+		applyEvent(&replayed, prs, events[i], events[i].From)
+	}
+
+	var matches []RuleMatch
+	for _, event := range events {
+		applyEvent(&replayed, prs, event, event.To)
+		facts := newFacts(&replayed, prs, expected)
+		ev, ok := ruleEvent(event, facts)
+		if !ok {
+			continue
+		}
+		for _, rule := range s.engine.Match(ev, facts) {
+			match := RuleMatch{Issue: issue.QualifiedKey(), Rule: rule.Name, Event: event}
+			for _, action := range rule.Actions {
+				match.Actions = append(match.Actions, action.Describe(facts))
+			}
+			matches = append(matches, match)
+		}
+	}
+	return matches, nil
+}
+
+// applyEvent sets the status of the issue, or of the pull request an event
+// is about, to value
+func applyEvent(issue *models.Issue, prs []*models.PullRequest, event *models.Event, value string) {
+	switch event.Type {
+	case models.EventStatusChanged:
+		issue.Status = value
+	case models.EventPullRequestChanged:
+		if pr := findPullRequest(prs, event.Field); pr != nil {
+			pr.Status = models.PRStatus(value)
+		}
+	}
+}
+
+// facts gathers what rules know about an issue from storage
+func (s *RuleService) facts(ctx context.Context, issue *models.Issue) (*rules.Facts, error) {
+	prs, err := s.trackingService.storage.ListPullRequests(ctx, issue.ID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list pull requests: %w", err)
+	}
+	return newFacts(issue, prs, s.trackingService.config.ExpectedBranches(issue.Project())), nil
+}
+
+// newFacts builds the facts of an issue, given the branches its fix is
+// expected to land on
+func newFacts(issue *models.Issue, prs []*models.PullRequest, expected []string) *rules.Facts {
+	facts := &rules.Facts{Issue: issue, PullRequests: prs, MissingBackports: []string{}}
+	for _, branch := range expected {
+		covered := slices.ContainsFunc(prs, func(pr *models.PullRequest) bool {
+			return pr.TargetBranch == branch && pr.Status != models.PRStatusClosed
+		})
+		if !covered {
+			facts.MissingBackports = append(facts.MissingBackports, branch)
+		}
+	}
+	return facts
+}
+
+// ruleEvent converts a timeline event into a rule trigger, setting the
+// pull request it is about on the facts. Events that trigger no rules,
+// such as changes of other Jira fields, are reported as not ok.
+func ruleEvent(event *models.Event, facts *rules.Facts) (rules.Event, bool) {
+	switch event.Type {
+	case models.EventStatusChanged:
+		return rules.Event{Type: rules.TriggerIssueStatus, From: event.From, To: event.To}, true
+	case models.EventPullRequestChanged:
+		pr := findPullRequest(facts.PullRequests, event.Field)
+		if pr == nil {
+			return rules.Event{}, false
+		}
+		facts.PullRequest = pr
+		return rules.Event{Type: rules.TriggerPullRequest, From: event.From, To: event.To, Branch: pr.TargetBranch}, true
+	}
+	return rules.Event{}, false
+}
+
+// findPullRequest finds a pull request by its label, e.g. org/repo#12
+func findPullRequest(prs []*models.PullRequest, label string) *models.PullRequest {
+	for _, pr := range prs {
+		if prLabel(pr) == label {
+			return pr
+		}
+	}
+	return nil
+}
@@ -0,0 +1,127 @@
+package services
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/jparrill/devtrackr/internal/config"
+	"github.com/jparrill/devtrackr/internal/jira"
+	"github.com/jparrill/devtrackr/internal/models"
+	"github.com/jparrill/devtrackr/internal/rules"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+)
+
+// recordingNotifier keeps the notifications it is asked to deliver
+type recordingNotifier struct {
+	sent []Notification
+}
+
+func (n *recordingNotifier) Notify(ctx context.Context, notification Notification) error {
+	n.sent = append(n.sent, notification)
+	return nil
+}
+
+func testRuleEngine(t *testing.T) *rules.Engine {
+	engine, err := rules.NewEngine([]rules.Rule{
+		{
+			Name:       "review-on-first-pr",
+			Trigger:    rules.Trigger{PullRequest: "open"},
+			Conditions: []rules.Condition{{Field: "prs.total", AtMost: ptr(1.0)}},
+			Actions:    []rules.Action{{Transition: "Code Review"}},
+		},
+		{
+			Name:       "ping-missing-backports",
+			Trigger:    rules.Trigger{IssueStatus: "Closed"},
+			Conditions: []rules.Condition{{Field: "prs.open", AtMost: ptr(0.0)}},
+			Actions: []rules.Action{{Notify: &rules.Notify{
+				Message: "please backport to {{ join .MissingBackports \", \" }}",
+			}}},
+		},
+	})
+	require.NoError(t, err)
+	return engine
+}
+
+func ptr[T any](v T) *T {
+	return &v
+}
+
+func TestRuleServiceHandleEvent(t *testing.T) {
+	ctx := context.Background()
+	mockStorage := &MockStorage{}
+	mockJira := jira.NewMockClient("New")
+	cfg := config.Default()
+	cfg.Branches = []string{"main", "release-4.16"}
+	trackingService := NewTrackingService(mockStorage, mockJira, WithConfig(cfg))
+	notifier := &recordingNotifier{}
+	service := NewRuleService(trackingService, testRuleEngine(t), WithNotifier(notifier))
+
+	issue := &models.Issue{ID: 1, Key: "TEST-1", Status: "New", Assignee: "alice", JiraURL: "https://issues.redhat.com/browse/TEST-1"}
+	pr := &models.PullRequest{IssueID: 1, Repository: "org/repo", Number: 7, TargetBranch: "main", Status: models.PRStatusOpen}
+
+	mockStorage.On("GetIssue", "TEST-1").Return(issue, nil)
+	mockStorage.On("ListPullRequests", ctx, int64(1)).Return([]*models.PullRequest{pr}, nil)
+	mockStorage.On("CreateAuditEntry", ctx, mock.MatchedBy(func(e *models.AuditEntry) bool {
+		return e.Actor == "rule:review-on-first-pr" && !e.DryRun
+	})).Return(nil)
+	mockStorage.On("UpdateIssue", mock.Anything).Return(nil)
+	mockStorage.On("CreateEvent", ctx, mock.Anything).Return(nil)
+
+	// Opening the first pull request moves the issue to review
+	service.HandleEvent(ctx, issue, &models.Event{Type: models.EventPullRequestChanged, Field: "org/repo#7", To: "open"})
+	assert.Equal(t, "Code Review", issue.Status)
+	assert.Empty(t, notifier.sent)
+
+	// Changes of other fields trigger nothing
+	service.HandleEvent(ctx, issue, &models.Event{Type: models.EventFieldChanged, Field: "priority", To: "Major"})
+
+	// Closing the issue with nothing open pings the assignee about the
+	// release branch without a pull request. The transition refreshed the
+	// issue from the mock Jira, which knows no assignee.
+	issue.Assignee = "alice"
+	pr.Status = models.PRStatusMerged
+	service.HandleEvent(ctx, issue, &models.Event{Type: models.EventStatusChanged, Field: "status", From: "Code Review", To: "Closed"})
+	require.Len(t, notifier.sent, 1)
+	assert.Equal(t, "alice", notifier.sent[0].Recipient)
+	assert.Equal(t, "please backport to release-4.16", notifier.sent[0].Message)
+
+	mockStorage.AssertExpectations(t)
+}
+
+func TestRuleServiceReplay(t *testing.T) {
+	ctx := context.Background()
+	mockStorage := &MockStorage{}
+	trackingService := NewTrackingService(mockStorage, jira.NewMockClient("Closed"))
+	service := NewRuleService(trackingService, testRuleEngine(t))
+
+	issue := &models.Issue{ID: 1, Key: "TEST-1", Status: "Closed", Assignee: "alice"}
+	pr := &models.PullRequest{IssueID: 1, Repository: "org/repo", Number: 7, TargetBranch: "main", Status: models.PRStatusMerged}
+	start := time.Now().Add(-48 * time.Hour)
+	events := []*models.Event{
+		{Type: models.EventPullRequestChanged, Field: "org/repo#7", From: "", To: "open", OccurredAt: start},
+		{Type: models.EventStatusChanged, Field: "status", From: "New", To: "Code Review", OccurredAt: start.Add(time.Minute)},
+		{Type: models.EventStatusChanged, Field: "status", From: "Code Review", To: "Closed", OccurredAt: start.Add(time.Hour)},
+		{Type: models.EventPullRequestChanged, Field: "org/repo#7", From: "open", To: "merged", OccurredAt: start.Add(2 * time.Hour)},
+	}
+
+	mockStorage.On("GetIssue", "TEST-1").Return(issue, nil)
+	mockStorage.On("ListEvents", ctx, int64(1)).Return(events, nil)
+	mockStorage.On("ListPullRequests", ctx, int64(1)).Return([]*models.PullRequest{pr}, nil)
+
+	matches, err := service.Replay(ctx, "TEST-1")
+	require.NoError(t, err)
+
+	// The pull request was still open when the issue closed, so only the
+	// review rule would have fired
+	require.Len(t, matches, 1)
+	assert.Equal(t, "review-on-first-pr", matches[0].Rule)
+	assert.Equal(t, events[0], matches[0].Event)
+	assert.Equal(t, []string{`transition to "Code Review"`}, matches[0].Actions)
+
+	// Replaying works on copies
+	assert.Equal(t, "Closed", issue.Status)
+	assert.Equal(t, models.PRStatusMerged, pr.Status)
+}
@@ -0,0 +1,102 @@
+package services
+
+import (
+	"container/heap"
+	"time"
+)
+
+// scheduledIssue is an entry of the polling schedule
+type scheduledIssue struct {
+	key   string
+	due   time.Time
+	index int // position in the heap, maintained by issueQueue
+}
+
+// issueQueue is a min-heap of scheduled issues ordered by due time
+type issueQueue []*scheduledIssue
+
+func (q issueQueue) Len() int { return len(q) }
+
+func (q issueQueue) Less(i, j int) bool { return q[i].due.Before(q[j].due) }
+
+func (q issueQueue) Swap(i, j int) {
+	q[i], q[j] = q[j], q[i]
+	q[i].index = i
+	q[j].index = j
+}
+
+func (q *issueQueue) Push(x any) {
+	entry := x.(*scheduledIssue)
+	entry.index = len(*q)
+	*q = append(*q, entry)
+}
+
+func (q *issueQueue) Pop() any {
+	old := *q
+	n := len(old)
+	entry := old[n-1]
+	old[n-1] = nil
+	entry.index = -1
+	*q = old[:n-1]
+	return entry
+}
+
+// schedule keeps the next due time of every tracked issue. It is not safe
+// for concurrent use.
+type schedule struct {
+	queue issueQueue
+	byKey map[string]*scheduledIssue
+}
+
+// newSchedule creates an empty schedule
+func newSchedule() *schedule {
+	return &schedule{byKey: map[string]*scheduledIssue{}}
+}
+
+// set schedules an issue at the given time, replacing its previous slot
+func (s *schedule) set(key string, due time.Time) {
+	if entry, ok := s.byKey[key]; ok {
+		entry.due = due
+		heap.Fix(&s.queue, entry.index)
+		return
+	}
+
+	entry := &scheduledIssue{key: key, due: due}
+	heap.Push(&s.queue, entry)
+	s.byKey[key] = entry
+}
+
+// remove drops an issue from the schedule
+func (s *schedule) remove(key string) {
+	entry, ok := s.byKey[key]
+	if !ok {
+		return
+	}
+	heap.Remove(&s.queue, entry.index)
+	delete(s.byKey, key)
+}
+
+// next returns the earliest due time, and false when nothing is scheduled
+func (s *schedule) next() (time.Time, bool) {
+	if len(s.queue) == 0 {
+		return time.Time{}, false
+	}
+	return s.queue[0].due, true
+}
+
+// popDue removes and returns the keys of the issues due at or before now,
+// earliest first
+func (s *schedule) popDue(now time.Time) []string {
+	var keys []string
+	for len(s.queue) > 0 && !s.queue[0].due.After(now) {
+		entry := heap.Pop(&s.queue).(*scheduledIssue)
+		delete(s.byKey, entry.key)
+		keys = append(keys, entry.key)
+	}
+	return keys
+}
+
+// len returns the number of scheduled issues
+func (s *schedule) len() int {
+	return len(s.queue)
+}
@@ -0,0 +1,136 @@
+package services
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/jparrill/devtrackr/internal/jira"
+	"github.com/jparrill/devtrackr/internal/models"
+	"github.com/jparrill/devtrackr/internal/polling"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+)
+
+func TestScheduleOrdersByDueTime(t *testing.T) {
+	now := time.Now()
+	s := newSchedule()
+	s.set("TEST-1", now.Add(3*time.Minute))
+	s.set("TEST-2", now.Add(time.Minute))
+	s.set("TEST-3", now.Add(2*time.Minute))
+
+	// Rescheduling moves an issue instead of adding it twice
+	s.set("TEST-1", now.Add(-time.Second))
+	s.remove("TEST-3")
+	assert.Equal(t, 2, s.len())
+
+	next, ok := s.next()
+	require.True(t, ok)
+	assert.Equal(t, now.Add(-time.Second), next)
+
+	assert.Equal(t, []string{"TEST-1"}, s.popDue(now))
+	assert.Equal(t, []string{"TEST-2"}, s.popDue(now.Add(time.Hour)))
+
+	_, ok = s.next()
+	assert.False(t, ok)
+}
+
+func TestPollingServiceSchedulesFromPersistedState(t *testing.T) {
+	mockStorage := &MockStorage{}
+	service := NewPollingService(mockStorage, jira.NewMockClient("New"), time.Hour)
+
+	lastPolled := time.Now().Add(-10 * time.Minute)
+	mockStorage.On("ListIssues").Return([]models.Issue{
+		{Key: "TEST-1", LastPolledAt: lastPolled},
+		{Key: "TEST-2", LastPolledAt: lastPolled, PollingInterval: 60},
+		{Key: "TEST-3"},
+	}, nil)
+
+	require.NoError(t, service.reload())
+	assert.Equal(t, lastPolled.Add(time.Hour), service.schedule.byKey["TEST-1"].due)
+	assert.Equal(t, lastPolled.Add(time.Minute), service.schedule.byKey["TEST-2"].due)
+
+	// Never polled issues are due right away
+	assert.WithinDuration(t, time.Now(), service.schedule.byKey["TEST-3"].due, time.Second)
+
+	next, ok := service.schedule.next()
+	require.True(t, ok)
+	assert.Equal(t, lastPolled.Add(time.Minute), next)
+}
+
+func TestPollingServiceWakesUpOnIssueChange(t *testing.T) {
+	mockStorage := &MockStorage{}
+	service := NewPollingService(mockStorage, jira.NewMockClient("New"), time.Hour)
+
+	issue := &models.Issue{ID: 1, Key: "TEST-1", Status: "New", JiraURL: "https://issues.example.com/browse/TEST-1"}
+	polled := make(chan struct{})
+
+	mockStorage.On("ListIssues").Return([]models.Issue{}, nil)
+	mockStorage.On("GetIssue", "TEST-1").Return(issue, nil)
+	mockStorage.On("UpdateIssue", mock.Anything).Run(func(args mock.Arguments) {
+		close(polled)
+	}).Return(nil).Once()
+	mockStorage.On("ReplaceIssueRelations", mock.Anything, int64(1), mock.Anything, mock.Anything).Return(nil).Maybe()
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	done := make(chan error)
+	go func() { done <- service.Start(ctx) }()
+
+	service.IssueChanged("TEST-1")
+
+	select {
+	case <-polled:
+	case <-time.After(5 * time.Second):
+		t.Fatal("issue was not polled after being added")
+	}
+
+	cancel()
+	require.NoError(t, <-done)
+	mockStorage.AssertExpectations(t)
+}
+
+func TestPollingIntervalRelaxedForWebhookIssues(t *testing.T) {
+	service := NewPollingService(&MockStorage{}, jira.NewMockClient("New"), 5*time.Minute,
+		WithWebhookPollingInterval(time.Hour))
+
+	tests := []struct {
+		issue    models.Issue
+		interval time.Duration
+		reason   string
+	}{
+		{models.Issue{}, 5 * time.Minute, "status category is in progress"},
+		{models.Issue{LastWebhookAt: time.Now()}, time.Hour, "relaxed because updates arrive through webhooks"},
+		{models.Issue{PollingInterval: 120}, 2 * time.Minute, "interval set manually"},
+		{models.Issue{PollingInterval: 120, LastWebhookAt: time.Now()}, 2 * time.Minute, "interval set manually"},
+		{models.Issue{PollingInterval: 7200, LastWebhookAt: time.Now()}, 2 * time.Hour, "interval set manually"},
+	}
+	for _, tt := range tests {
+		decision := service.decide(&tt.issue)
+		assert.Equal(t, tt.interval, decision.Duration())
+		assert.Contains(t, decision.Reason, tt.reason)
+	}
+}
+
+func TestPollingStopsDoneIssuesWithClosedPullRequests(t *testing.T) {
+	mockStorage := &MockStorage{}
+	service := NewPollingService(mockStorage, jira.NewMockClient("Closed"), 5*time.Minute,
+		WithPollingPolicy(polling.NewEngine(polling.DefaultConfig(), 0)))
+
+	issue := &models.Issue{ID: 1, Key: "TEST-1", StatusCategory: models.StatusCategoryDone,
+		UpdatedAt: time.Now().Add(-30 * 24 * time.Hour)}
+	mockStorage.On("ListPullRequests", mock.Anything, int64(1)).Return([]*models.PullRequest{
+		{Number: 1, Status: models.PRStatusMerged},
+		{Number: 2, Status: models.PRStatusClosed},
+	}, nil).Once()
+	assert.True(t, service.decide(issue).Stopped, "abandoned pull requests do not keep the issue polled")
+
+	mockStorage.On("ListPullRequests", mock.Anything, int64(1)).Return([]*models.PullRequest{
+		{Number: 1, Status: models.PRStatusMerged},
+		{Number: 3, Status: models.PRStatusReview},
+	}, nil).Once()
+	assert.False(t, service.decide(issue).Stopped)
+
+	mockStorage.AssertExpectations(t)
+}
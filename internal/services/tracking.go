@@ -5,6 +5,10 @@ import (
 	"fmt"
 	"time"
 
+	"github.com/jparrill/devtrackr/internal/backport"
+	"github.com/jparrill/devtrackr/internal/config"
+	"github.com/jparrill/devtrackr/internal/forge"
+	"github.com/jparrill/devtrackr/internal/gitscan"
 	"github.com/jparrill/devtrackr/internal/jira"
 	"github.com/jparrill/devtrackr/internal/models"
 )
@@ -24,24 +28,85 @@ type Storage interface {
 	GetPullRequest(ctx context.Context, issueID int64, prNumber int) (*models.PullRequest, error)
 	UpdatePullRequest(ctx context.Context, pr *models.PullRequest) error
 	GetUnmergedPullRequests(ctx context.Context, issueID int64) ([]*models.PullRequest, error)
+	ListPullRequestsByNumber(ctx context.Context, repository string, number int) ([]*models.PullRequest, error)
 	ListSubscriptions(ctx context.Context, userID int64) ([]models.Subscription, error)
+	ListIssueSubscriptions(ctx context.Context, issueID int64) ([]models.Subscription, error)
 	GetSubscriptionByID(ctx context.Context, id int64) (*models.Subscription, error)
 	UpdateSubscription(ctx context.Context, sub *models.Subscription) error
 	GetIssueByKey(key string) (*models.Issue, error)
+	UpsertCommit(ctx context.Context, commit *models.Commit) error
+	ListCommits(ctx context.Context, issueID int64) ([]*models.Commit, error)
+	CreateEvent(ctx context.Context, event *models.Event) error
+	ListEvents(ctx context.Context, issueID int64) ([]*models.Event, error)
+	CreateAuditEntry(ctx context.Context, entry *models.AuditEntry) error
+	ListAuditEntries(ctx context.Context, issueID int64) ([]*models.AuditEntry, error)
+	ReplaceIssueRelations(ctx context.Context, issueID int64, children []models.IssueChild, links []models.IssueLink) error
+	ListIssueChildren(ctx context.Context, issueID int64) ([]models.IssueChild, error)
+	ListIssueLinks(ctx context.Context, issueID int64) ([]models.IssueLink, error)
 }
 
 // TrackingService handles the business logic for tracking issues and pull requests
 type TrackingService struct {
-	storage Storage
-	jira    jira.JiraClient
+	storage  Storage
+	jira     jira.JiraClient
+	config   *config.Config
+	detector *backport.Detector
+	git      *gitscan.Scanner
+	forges   *forge.Registry
+	notifier IssueChangeNotifier
+	listener EventListener
+}
+
+// IssueChangeNotifier is told about changes that affect when an issue must
+// be polled next, such as the polling service scheduler
+type IssueChangeNotifier interface {
+	IssueChanged(key string)
+}
+
+// TrackingOption configures optional dependencies of the tracking service
+type TrackingOption func(*TrackingService)
+
+// WithConfig sets the configuration used by the tracking service
+func WithConfig(cfg *config.Config) TrackingOption {
+	return func(s *TrackingService) {
+		s.config = cfg
+	}
+}
+
+// WithIssueChangeNotifier notifies n whenever an issue is tracked, deleted
+// or has its polling settings changed
+func WithIssueChangeNotifier(n IssueChangeNotifier) TrackingOption {
+	return func(s *TrackingService) {
+		s.notifier = n
+	}
+}
+
+// SetEventListener forwards the changes delivered by webhooks to l, once
+// they are recorded in the timeline of the issue. It is not an option
+// because listeners such as the rule service depend on the tracking service.
+func (s *TrackingService) SetEventListener(l EventListener) {
+	s.listener = l
+}
+
+// dispatch tells the event listener, if any, about a recorded change
+func (s *TrackingService) dispatch(ctx context.Context, issue *models.Issue, event *models.Event) {
+	if s.listener != nil {
+		s.listener.HandleEvent(ctx, issue, event)
+	}
 }
 
 // NewTrackingService creates a new tracking service
-func NewTrackingService(storage Storage, jira jira.JiraClient) *TrackingService {
-	return &TrackingService{
+func NewTrackingService(storage Storage, jira jira.JiraClient, opts ...TrackingOption) *TrackingService {
+	s := &TrackingService{
 		storage: storage,
 		jira:    jira,
+		config:  config.Default(),
+		git:     gitscan.NewScanner(),
 	}
+	for _, opt := range opts {
+		opt(s)
+	}
+	return s
 }
 
 // TrackIssue tracks a Jira issue by its URL
@@ -52,17 +117,19 @@ func (s *TrackingService) TrackIssue(ctx context.Context, jiraURL string) (*mode
 		return nil, fmt.Errorf("failed to get issue from Jira: %w", err)
 	}
 
-	// Check if issue already exists
-	existingIssue, err := s.storage.GetIssueByKey(jiraIssue.Key)
+	// Check if issue already exists in the same Jira instance
+	existingIssue, err := s.storage.GetIssueByKey(jiraIssue.QualifiedKey())
 	if err == nil {
 		// Issue already exists, update it
-		existingIssue.Title = jiraIssue.Title
-		existingIssue.Status = jiraIssue.Status
+		applyJiraIssue(existingIssue, jiraIssue)
 		existingIssue.UpdatedAt = time.Now()
 
 		if err := s.storage.UpdateIssue(existingIssue); err != nil {
 			return nil, fmt.Errorf("failed to update issue: %w", err)
 		}
+		if err := syncRelations(ctx, s.storage, s.jira, existingIssue); err != nil {
+			return nil, err
+		}
 
 		return existingIssue, nil
 	}
@@ -70,18 +137,21 @@ func (s *TrackingService) TrackIssue(ctx context.Context, jiraURL string) (*mode
 	// Create new issue
 	issue := &models.Issue{
 		Key:             jiraIssue.Key,
-		Title:           jiraIssue.Title,
-		Status:          jiraIssue.Status,
 		JiraURL:         jiraURL,
 		CreatedAt:       time.Now(),
 		UpdatedAt:       time.Now(),
-		PollingInterval: 300, // Default to 5 minutes (300 seconds)
+		PollingInterval: 0, // Use the default interval of the polling service
 		LastPolledAt:    time.Now(),
 	}
+	applyJiraIssue(issue, jiraIssue)
 
 	if err := s.storage.CreateIssue(issue); err != nil {
 		return nil, fmt.Errorf("failed to create issue: %w", err)
 	}
+	if err := syncRelations(ctx, s.storage, s.jira, issue); err != nil {
+		return nil, err
+	}
+	s.issueChanged(issue.QualifiedKey())
 
 	return issue, nil
 }
@@ -101,12 +171,33 @@ func (s *TrackingService) ListIssues(ctx context.Context) ([]*models.Issue, erro
 	return result, nil
 }
 
-// GetIssue retrieves a tracked issue by its key
+// ListIssuesMatching returns the tracked issues passing a filter
+func (s *TrackingService) ListIssuesMatching(ctx context.Context, filter models.IssueFilter) ([]*models.Issue, error) {
+	issues, err := s.ListIssues(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	result := make([]*models.Issue, 0, len(issues))
+	for _, issue := range issues {
+		if filter.Matches(issue) {
+			result = append(result, issue)
+		}
+	}
+	return result, nil
+}
+
+// GetIssue retrieves a tracked issue by its key, together with the polling
+// interval its policy currently picks
 func (s *TrackingService) GetIssue(ctx context.Context, key string) (*models.Issue, error) {
 	issue, err := s.storage.GetIssue(key)
 	if err != nil {
 		return nil, fmt.Errorf("failed to get issue: %w", err)
 	}
+
+	decision := decidePolling(s.storage, s.config.PollingPolicy(), issue)
+	issue.Polling = &decision
+	issue.RenderedAttributes = renderAttributes(s.config.CustomFields(issue.Instance), issue.Attributes)
 	return issue, nil
 }
 
@@ -162,11 +253,29 @@ func (s *TrackingService) AddPullRequest(ctx context.Context, key string, pr *mo
 		return nil, fmt.Errorf("failed to get issue: %w", err)
 	}
 
+	// Pull requests are timed from when they start being tracked
 	pr.IssueID = issue.ID
+	pr.CreatedAt, pr.UpdatedAt = time.Now(), time.Now()
+	if err := s.fillFromForge(ctx, pr); err != nil {
+		return nil, err
+	}
+
+	var siblings []*models.PullRequest
+	if s.detector != nil {
+		if siblings, err = s.storage.ListPullRequests(ctx, issue.ID); err != nil {
+			return nil, fmt.Errorf("failed to list pull requests: %w", err)
+		}
+		detectBackport(s.detector, issue, pr, siblings)
+	}
+
 	if err := s.storage.CreatePullRequest(ctx, pr); err != nil {
 		return nil, fmt.Errorf("failed to create pull request: %w", err)
 	}
 
+	if err := relinkBackports(ctx, s.storage, s.detector, issue, pr, siblings); err != nil {
+		return nil, err
+	}
+
 	return pr, nil
 }
 
@@ -184,6 +293,15 @@ func (s *TrackingService) UpdatePullRequest(ctx context.Context, key string, prN
 
 	pr.ID = existingPR.ID
 	pr.IssueID = issue.ID
+
+	if s.detector != nil {
+		siblings, err := s.storage.ListPullRequests(ctx, issue.ID)
+		if err != nil {
+			return fmt.Errorf("failed to list pull requests: %w", err)
+		}
+		detectBackport(s.detector, issue, pr, siblings)
+	}
+
 	return s.storage.UpdatePullRequest(ctx, pr)
 }
 
@@ -232,12 +350,25 @@ func (s *TrackingService) HasUnmergedPullRequests(ctx context.Context, key strin
 
 // DeleteIssue deletes a tracked issue
 func (s *TrackingService) DeleteIssue(ctx context.Context, key string) error {
-	return s.storage.DeleteIssue(ctx, key)
+	issue, err := s.storage.GetIssue(key)
+	if err != nil {
+		return fmt.Errorf("failed to get issue: %w", err)
+	}
+
+	if err := s.storage.DeleteIssue(ctx, issue.QualifiedKey()); err != nil {
+		return err
+	}
+	s.issueChanged(issue.QualifiedKey())
+	return nil
 }
 
 // UpdateIssue updates an existing issue
 func (s *TrackingService) UpdateIssue(ctx context.Context, issue *models.Issue) error {
-	return s.storage.UpdateIssue(issue)
+	if err := s.storage.UpdateIssue(issue); err != nil {
+		return err
+	}
+	s.issueChanged(issue.QualifiedKey())
+	return nil
 }
 
 // UpdateIssueStatus updates the status of an issue
@@ -273,6 +404,86 @@ func (s *TrackingService) UpdateIssuePollingInterval(ctx context.Context, key st
 	if err := s.storage.UpdateIssue(issue); err != nil {
 		return fmt.Errorf("failed to update issue polling interval: %w", err)
 	}
+	s.issueChanged(issue.QualifiedKey())
 
 	return nil
 }
+
+// issueChanged forwards a change of an issue to the notifier, if any
+func (s *TrackingService) issueChanged(key string) {
+	if s.notifier != nil {
+		s.notifier.IssueChanged(key)
+	}
+}
+
+// ListEvents returns the timeline of an issue, oldest first
+func (s *TrackingService) ListEvents(ctx context.Context, key string) ([]*models.Event, error) {
+	issue, err := s.storage.GetIssue(key)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get issue: %w", err)
+	}
+
+	return s.storage.ListEvents(ctx, issue.ID)
+}
+
+// applyJiraIssue copies the fields fetched from Jira onto a tracked issue
+func applyJiraIssue(issue, jiraIssue *models.Issue) {
+	if jiraIssue.Instance != "" {
+		issue.Instance = jiraIssue.Instance
+	}
+	issue.Title = jiraIssue.Title
+	issue.Status = jiraIssue.Status
+	issue.StatusCategory = jiraIssue.StatusCategory
+	issue.FixVersions = jiraIssue.FixVersions
+	issue.AffectsVersions = jiraIssue.AffectsVersions
+	issue.Components = jiraIssue.Components
+	issue.Description = jiraIssue.Description
+	issue.Assignee = jiraIssue.Assignee
+	issue.Reporter = jiraIssue.Reporter
+	issue.Priority = jiraIssue.Priority
+	issue.IssueType = jiraIssue.IssueType
+	issue.Labels = jiraIssue.Labels
+	issue.Sprint = jiraIssue.Sprint
+	issue.EpicLink = jiraIssue.EpicLink
+	issue.Resolution = jiraIssue.Resolution
+	issue.DueDate = jiraIssue.DueDate
+	issue.JiraUpdatedAt = jiraIssue.JiraUpdatedAt
+	issue.Attributes = jiraIssue.Attributes
+	issue.Children = jiraIssue.Children
+	issue.Links = jiraIssue.Links
+}
+
+// renderAttributes formats the attributes of an issue as declared by their
+// custom field mapping, skipping attributes no longer mapped
+func renderAttributes(fields []jira.CustomField, attrs map[string]any) map[string]string {
+	if len(attrs) == 0 {
+		return nil
+	}
+
+	rendered := map[string]string{}
+	for _, f := range fields {
+		if v, ok := attrs[f.Name]; ok {
+			rendered[f.Heading()] = f.Render(v)
+		}
+	}
+	return rendered
+}
+
+// recordStatusChange adds a status change to the timeline of an issue and
+// returns the recorded event
+func recordStatusChange(ctx context.Context, storage Storage, issue *models.Issue, from, author, source string, at time.Time) (*models.Event, error) {
+	event := &models.Event{
+		IssueID:    issue.ID,
+		Type:       models.EventStatusChanged,
+		Field:      "status",
+		From:       from,
+		To:         issue.Status,
+		Author:     author,
+		Source:     source,
+		OccurredAt: at,
+	}
+	if err := storage.CreateEvent(ctx, event); err != nil {
+		return nil, fmt.Errorf("failed to record status change of %s: %w", issue.Key, err)
+	}
+	return event, nil
+}
@@ -103,6 +103,11 @@ func (m *MockStorage) ListSubscriptions(ctx context.Context, userID int64) ([]mo
 	return args.Get(0).([]models.Subscription), args.Error(1)
 }
 
+func (m *MockStorage) ListIssueSubscriptions(ctx context.Context, issueID int64) ([]models.Subscription, error) {
+	args := m.Called(ctx, issueID)
+	return args.Get(0).([]models.Subscription), args.Error(1)
+}
+
 func (m *MockStorage) GetSubscriptionByID(ctx context.Context, id int64) (*models.Subscription, error) {
 	args := m.Called(ctx, id)
 	if args.Get(0) == nil {
@@ -116,6 +121,56 @@ func (m *MockStorage) UpdateSubscription(ctx context.Context, sub *models.Subscr
 	return args.Error(0)
 }
 
+func (m *MockStorage) UpsertCommit(ctx context.Context, commit *models.Commit) error {
+	args := m.Called(ctx, commit)
+	return args.Error(0)
+}
+
+func (m *MockStorage) ListCommits(ctx context.Context, issueID int64) ([]*models.Commit, error) {
+	args := m.Called(ctx, issueID)
+	return args.Get(0).([]*models.Commit), args.Error(1)
+}
+
+func (m *MockStorage) ListPullRequestsByNumber(ctx context.Context, repository string, number int) ([]*models.PullRequest, error) {
+	args := m.Called(ctx, repository, number)
+	return args.Get(0).([]*models.PullRequest), args.Error(1)
+}
+
+func (m *MockStorage) CreateEvent(ctx context.Context, event *models.Event) error {
+	args := m.Called(ctx, event)
+	return args.Error(0)
+}
+
+func (m *MockStorage) ListEvents(ctx context.Context, issueID int64) ([]*models.Event, error) {
+	args := m.Called(ctx, issueID)
+	return args.Get(0).([]*models.Event), args.Error(1)
+}
+
+func (m *MockStorage) CreateAuditEntry(ctx context.Context, entry *models.AuditEntry) error {
+	args := m.Called(ctx, entry)
+	return args.Error(0)
+}
+
+func (m *MockStorage) ListAuditEntries(ctx context.Context, issueID int64) ([]*models.AuditEntry, error) {
+	args := m.Called(ctx, issueID)
+	return args.Get(0).([]*models.AuditEntry), args.Error(1)
+}
+
+func (m *MockStorage) ReplaceIssueRelations(ctx context.Context, issueID int64, children []models.IssueChild, links []models.IssueLink) error {
+	args := m.Called(ctx, issueID, children, links)
+	return args.Error(0)
+}
+
+func (m *MockStorage) ListIssueChildren(ctx context.Context, issueID int64) ([]models.IssueChild, error) {
+	args := m.Called(ctx, issueID)
+	return args.Get(0).([]models.IssueChild), args.Error(1)
+}
+
+func (m *MockStorage) ListIssueLinks(ctx context.Context, issueID int64) ([]models.IssueLink, error) {
+	args := m.Called(ctx, issueID)
+	return args.Get(0).([]models.IssueLink), args.Error(1)
+}
+
 func TestTrackIssue(t *testing.T) {
 	// Create mocks
 	mockStorage := &MockStorage{}
@@ -131,6 +186,7 @@ func TestTrackIssue(t *testing.T) {
 	// Mock storage to return error (issue doesn't exist)
 	mockStorage.On("GetIssueByKey", "TEST-123").Return(nil, assert.AnError)
 	mockStorage.On("CreateIssue", mock.Anything).Return(nil)
+	mockStorage.On("ReplaceIssueRelations", ctx, mock.Anything, mock.Anything, mock.Anything).Return(nil)
 
 	issue, err := service.TrackIssue(ctx, jiraURL)
 	assert.NoError(t, err)
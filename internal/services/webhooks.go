@@ -0,0 +1,219 @@
+package services
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"regexp"
+	"time"
+
+	"github.com/jparrill/devtrackr/internal/github"
+	"github.com/jparrill/devtrackr/internal/jira"
+	"github.com/jparrill/devtrackr/internal/models"
+)
+
+// issueKeyPattern matches anything shaped like a Jira issue key
+var issueKeyPattern = regexp.MustCompile(`\b[A-Z][A-Z0-9]+-[0-9]+\b`)
+
+// HandleGitHubEvent applies a GitHub webhook delivery to the tracked pull
+// requests it affects. New pull requests mentioning tracked issue keys in
+// their title or description are attached to those issues.
+func (s *TrackingService) HandleGitHubEvent(ctx context.Context, event *github.WebhookEvent) error {
+	if len(event.PullRequests) == 0 {
+		return nil
+	}
+
+	issues, err := s.ListIssues(ctx)
+	if err != nil {
+		return err
+	}
+	byID := make(map[int64]*models.Issue, len(issues))
+	// The same key can be tracked from several Jira instances
+	byKey := make(map[string][]*models.Issue, len(issues))
+	for _, issue := range issues {
+		byID[issue.ID] = issue
+		byKey[issue.Key] = append(byKey[issue.Key], issue)
+	}
+
+	for _, number := range event.PullRequests {
+		tracked, err := s.storage.ListPullRequestsByNumber(ctx, event.Repository, number)
+		if err != nil {
+			return fmt.Errorf("failed to find pull request %s#%d: %w", event.Repository, number, err)
+		}
+
+		attached := map[int64]bool{}
+		for _, pr := range tracked {
+			issue, ok := byID[pr.IssueID]
+			if !ok {
+				continue
+			}
+			attached[issue.ID] = true
+
+			if err := s.refreshFromWebhook(ctx, issue, pr, event.PullRequest); err != nil {
+				return err
+			}
+		}
+
+		if event.Type == github.EventPullRequest && event.PullRequest != nil {
+			if err := s.attachMentionedIssues(ctx, event.PullRequest, byKey, attached); err != nil {
+				return err
+			}
+		}
+	}
+
+	return nil
+}
+
+// refreshFromWebhook updates a tracked pull request after a webhook delivery.
+// Its full state is fetched from the forge when possible, otherwise the state
+// carried by the delivery is applied. Status changes are recorded in the
+// timeline of the issue and passed on to the event listener.
+func (s *TrackingService) refreshFromWebhook(ctx context.Context, issue *models.Issue, pr *models.PullRequest, delivered *models.PullRequest) error {
+	state, apply := delivered, applyMetadata
+	if s.forges != nil && pr.URL != "" && s.forges.Supports(pr.URL) {
+		remote, err := s.forges.GetPullRequest(ctx, pr.URL)
+		if err != nil {
+			return fmt.Errorf("failed to get %s from forge: %w", prLabel(pr), err)
+		}
+		state, apply = remote, applyRemote
+	}
+	if state == nil {
+		return nil
+	}
+
+	var siblings []*models.PullRequest
+	if s.detector != nil {
+		var err error
+		if siblings, err = s.storage.ListPullRequests(ctx, issue.ID); err != nil {
+			return fmt.Errorf("failed to list pull requests: %w", err)
+		}
+	}
+
+	// Activity delivered by webhook keeps the pull request from looking idle
+	changed, event, err := updatePullRequestState(ctx, s.storage, s.detector, issue, pr, siblings, state, apply, models.EventSourceGitHubWebhook)
+	if err != nil || !changed {
+		return err
+	}
+	log.Printf("Updated %s of issue %s from webhook", prLabel(pr), issue.Key)
+	if event != nil {
+		s.dispatch(ctx, issue, event)
+	}
+	return nil
+}
+
+// attachMentionedIssues adds a pull request to every tracked issue whose key
+// appears in its title or description and that does not track it yet. A key
+// tracked from several Jira instances attaches it to each of those issues.
+func (s *TrackingService) attachMentionedIssues(ctx context.Context, pr *models.PullRequest, issues map[string][]*models.Issue, attached map[int64]bool) error {
+	for _, key := range mentionedKeys(pr.Title + "\n" + pr.Body) {
+		for _, issue := range issues[key] {
+			if attached[issue.ID] {
+				continue
+			}
+
+			added := *pr
+			if added.Status == "" {
+				added.Status = models.PRStatusOpen
+			}
+			if _, err := s.AddPullRequest(ctx, issue.QualifiedKey(), &added); err != nil {
+				return fmt.Errorf("failed to attach %s to %s: %w", prLabel(pr), issue.QualifiedKey(), err)
+			}
+			attached[issue.ID] = true
+			log.Printf("Attached %s to issue %s from webhook", prLabel(pr), issue.QualifiedKey())
+		}
+	}
+	return nil
+}
+
+// mentionedKeys returns the distinct issue keys mentioned in a text, in order
+func mentionedKeys(text string) []string {
+	var keys []string
+	seen := map[string]bool{}
+	for _, key := range issueKeyPattern.FindAllString(text, -1) {
+		if !seen[key] {
+			seen[key] = true
+			keys = append(keys, key)
+		}
+	}
+	return keys
+}
+
+// HandleJiraEvent applies a Jira webhook delivery to the tracked issue it is
+// about and records its changelog in the issue timeline. Deliveries about
+// untracked issues are ignored.
+func (s *TrackingService) HandleJiraEvent(ctx context.Context, event *jira.WebhookEvent) error {
+	key := event.Key
+	if resolver, ok := s.jira.(jira.InstanceResolver); ok && event.Issue.JiraURL != "" {
+		if err := resolver.ResolveWebhook(event); err != nil {
+			log.Printf("Ignoring Jira %s event for %s: %v", event.Type, event.Key, err)
+			return nil
+		}
+		key = event.Issue.QualifiedKey()
+	}
+
+	issue, err := s.storage.GetIssueByKey(key)
+	if err != nil {
+		log.Printf("Ignoring Jira %s event for untracked issue %s", event.Type, event.Key)
+		return nil
+	}
+
+	switch event.Type {
+	case jira.EventIssueDeleted:
+		if err := s.storage.DeleteIssue(ctx, issue.QualifiedKey()); err != nil {
+			return fmt.Errorf("failed to delete issue %s: %w", issue.Key, err)
+		}
+		s.issueChanged(issue.QualifiedKey())
+		log.Printf("Stopped tracking %s, it was deleted in Jira", issue.Key)
+		return nil
+	case jira.EventIssueUpdated:
+	default:
+		return nil
+	}
+
+	previousStatus := issue.Status
+	applyJiraIssue(issue, event.Issue)
+	issue.UpdatedAt = time.Now()
+	issue.LastWebhookAt = time.Now()
+	if err := s.storage.UpdateIssue(issue); err != nil {
+		return fmt.Errorf("failed to update issue %s: %w", issue.Key, err)
+	}
+	// Webhook updates change the polling policy of the issue
+	s.issueChanged(issue.QualifiedKey())
+
+	var events []*models.Event
+	statusLogged := false
+	for _, change := range event.Changes {
+		e := &models.Event{
+			IssueID:    issue.ID,
+			Type:       models.EventFieldChanged,
+			Field:      change.Field,
+			From:       change.From,
+			To:         change.To,
+			Author:     event.Author,
+			Source:     models.EventSourceJiraWebhook,
+			OccurredAt: event.Timestamp,
+		}
+		if change.Field == "status" {
+			e.Type = models.EventStatusChanged
+			statusLogged = true
+		}
+		if err := s.storage.CreateEvent(ctx, e); err != nil {
+			return fmt.Errorf("failed to record event for %s: %w", issue.Key, err)
+		}
+		events = append(events, e)
+	}
+
+	// Deliveries without a changelog entry can still carry a new status
+	if !statusLogged && issue.Status != previousStatus {
+		e, err := recordStatusChange(ctx, s.storage, issue, previousStatus, event.Author, models.EventSourceJiraWebhook, event.Timestamp)
+		if err != nil {
+			return err
+		}
+		events = append(events, e)
+	}
+
+	for _, e := range events {
+		s.dispatch(ctx, issue, e)
+	}
+	return nil
+}
@@ -0,0 +1,236 @@
+package services
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/jparrill/devtrackr/internal/github"
+	"github.com/jparrill/devtrackr/internal/jira"
+	"github.com/jparrill/devtrackr/internal/models"
+	"github.com/jparrill/devtrackr/internal/rules"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+)
+
+// recordingListener keeps the events it is told about
+type recordingListener struct {
+	events []*models.Event
+}
+
+func (l *recordingListener) HandleEvent(ctx context.Context, issue *models.Issue, event *models.Event) {
+	l.events = append(l.events, event)
+}
+
+func TestHandleGitHubEventUpdatesTrackedPullRequest(t *testing.T) {
+	// Create mocks
+	mockStorage := &MockStorage{}
+	mockJira := jira.NewMockClient("In Progress")
+
+	// Create service
+	service := NewTrackingService(mockStorage, mockJira)
+
+	ctx := context.Background()
+	issue := models.Issue{ID: 1, Key: "TEST-123"}
+	tracked := &models.PullRequest{ID: 5, IssueID: 1, Number: 7, Repository: "org/repo", Title: "TEST-123: Fix",
+		Status: models.PRStatusApproved, TargetBranch: "main", Approvals: 1}
+
+	mockStorage.On("ListIssues").Return([]models.Issue{issue}, nil)
+	mockStorage.On("ListPullRequestsByNumber", ctx, "org/repo", 7).Return([]*models.PullRequest{tracked}, nil)
+	mockStorage.On("UpdatePullRequest", ctx, mock.MatchedBy(func(pr *models.PullRequest) bool {
+		return pr.ID == tracked.ID && pr.Status == models.PRStatusMerged && pr.Approvals == 1 &&
+			!pr.UpdatedAt.IsZero()
+	})).Return(nil).Once()
+	mockStorage.On("CreateEvent", ctx, mock.MatchedBy(func(e *models.Event) bool {
+		return e.Type == models.EventPullRequestChanged && e.Field == "org/repo#7" && e.From == "approved" &&
+			e.To == "merged" && e.Source == models.EventSourceGitHubWebhook
+	})).Return(nil).Once()
+
+	err := service.HandleGitHubEvent(ctx, &github.WebhookEvent{
+		Type:         github.EventPullRequest,
+		Action:       "closed",
+		Repository:   "org/repo",
+		PullRequests: []int{7},
+		PullRequest: &models.PullRequest{Number: 7, Repository: "org/repo", Title: "TEST-123: Fix",
+			Status: models.PRStatusMerged, TargetBranch: "main"},
+	})
+	require.NoError(t, err)
+
+	mockStorage.AssertExpectations(t)
+}
+
+func TestHandleGitHubEventRunsRules(t *testing.T) {
+	ctx := context.Background()
+	mockStorage := &MockStorage{}
+	service := NewTrackingService(mockStorage, jira.NewMockClient("In Progress"))
+	engine, err := rules.NewEngine([]rules.Rule{{
+		Name:    "announce-merges",
+		Trigger: rules.Trigger{PullRequest: "merged"},
+		Actions: []rules.Action{{Notify: &rules.Notify{Message: "merged to {{ .PullRequest.TargetBranch }}"}}},
+	}})
+	require.NoError(t, err)
+	notifier := &recordingNotifier{}
+	service.SetEventListener(NewRuleService(service, engine, WithNotifier(notifier)))
+
+	issue := models.Issue{ID: 1, Key: "TEST-123", Assignee: "alice"}
+	tracked := &models.PullRequest{ID: 5, IssueID: 1, Number: 7, Repository: "org/repo", Status: models.PRStatusOpen, TargetBranch: "main"}
+
+	mockStorage.On("ListIssues").Return([]models.Issue{issue}, nil)
+	mockStorage.On("ListPullRequestsByNumber", ctx, "org/repo", 7).Return([]*models.PullRequest{tracked}, nil)
+	mockStorage.On("ListPullRequests", ctx, issue.ID).Return([]*models.PullRequest{tracked}, nil)
+	mockStorage.On("UpdatePullRequest", ctx, mock.Anything).Return(nil)
+	mockStorage.On("CreateEvent", ctx, mock.Anything).Return(nil)
+
+	err = service.HandleGitHubEvent(ctx, &github.WebhookEvent{
+		Type:         github.EventPullRequest,
+		Action:       "closed",
+		Repository:   "org/repo",
+		PullRequests: []int{7},
+		PullRequest:  &models.PullRequest{Number: 7, Repository: "org/repo", Status: models.PRStatusMerged},
+	})
+	require.NoError(t, err)
+
+	// A merge delivered by webhook fires the rule like one found by polling
+	require.Len(t, notifier.sent, 1)
+	assert.Equal(t, "merged to main", notifier.sent[0].Message)
+	assert.Equal(t, "alice", notifier.sent[0].Recipient)
+}
+
+func TestHandleGitHubEventAttachesMentionedIssues(t *testing.T) {
+	// Create mocks
+	mockStorage := &MockStorage{}
+	mockJira := jira.NewMockClient("In Progress")
+
+	// Create service
+	service := NewTrackingService(mockStorage, mockJira)
+
+	ctx := context.Background()
+	tracked := models.Issue{ID: 1, Key: "TEST-123"}
+	other := models.Issue{ID: 2, Key: "TEST-456"}
+
+	mockStorage.On("ListIssues").Return([]models.Issue{tracked, other}, nil)
+	mockStorage.On("ListPullRequestsByNumber", ctx, "org/repo", 9).Return([]*models.PullRequest{}, nil)
+	mockStorage.On("GetIssue", "TEST-123").Return(&tracked, nil)
+	mockStorage.On("CreatePullRequest", ctx, mock.MatchedBy(func(pr *models.PullRequest) bool {
+		return pr.IssueID == tracked.ID && pr.Number == 9 && pr.Status == models.PRStatusOpen
+	})).Return(nil).Once()
+
+	err := service.HandleGitHubEvent(ctx, &github.WebhookEvent{
+		Type:         github.EventPullRequest,
+		Action:       "opened",
+		Repository:   "org/repo",
+		PullRequests: []int{9},
+		PullRequest: &models.PullRequest{Number: 9, Repository: "org/repo", Title: "TEST-123: Fix",
+			Body: "Also relates to UNTRACKED-1", URL: "https://github.com/org/repo/pull/9"},
+	})
+	require.NoError(t, err)
+
+	mockStorage.AssertExpectations(t)
+}
+
+func TestHandleGitHubEventAttachesKeyTrackedFromSeveralInstances(t *testing.T) {
+	// Create mocks
+	mockStorage := &MockStorage{}
+	mockJira := jira.NewMockClient("In Progress")
+
+	// Create service
+	service := NewTrackingService(mockStorage, mockJira)
+
+	ctx := context.Background()
+	redhat := models.Issue{ID: 1, Key: "TEST-123", Instance: "redhat"}
+	apache := models.Issue{ID: 2, Key: "TEST-123", Instance: "apache"}
+
+	mockStorage.On("ListIssues").Return([]models.Issue{redhat, apache}, nil)
+	mockStorage.On("ListPullRequestsByNumber", ctx, "org/repo", 9).Return([]*models.PullRequest{}, nil)
+	mockStorage.On("GetIssue", "redhat:TEST-123").Return(&redhat, nil)
+	mockStorage.On("GetIssue", "apache:TEST-123").Return(&apache, nil)
+	for _, issue := range []models.Issue{redhat, apache} {
+		mockStorage.On("CreatePullRequest", ctx, mock.MatchedBy(func(pr *models.PullRequest) bool {
+			return pr.IssueID == issue.ID && pr.Number == 9
+		})).Return(nil).Once()
+	}
+
+	err := service.HandleGitHubEvent(ctx, &github.WebhookEvent{
+		Type:         github.EventPullRequest,
+		Action:       "opened",
+		Repository:   "org/repo",
+		PullRequests: []int{9},
+		PullRequest: &models.PullRequest{Number: 9, Repository: "org/repo", Title: "TEST-123: Fix",
+			URL: "https://github.com/org/repo/pull/9"},
+	})
+	require.NoError(t, err)
+
+	mockStorage.AssertExpectations(t)
+}
+
+func TestMentionedKeys(t *testing.T) {
+	assert.Equal(t, []string{"OCPBUGS-1", "HOSTEDCP-22"}, mentionedKeys("OCPBUGS-1: fix (see HOSTEDCP-22, OCPBUGS-1)"))
+	assert.Empty(t, mentionedKeys("bump go-1.22 and k8s-1"))
+}
+
+func TestHandleJiraEventUpdatesIssue(t *testing.T) {
+	// Create mocks
+	mockStorage := &MockStorage{}
+	mockJira := jira.NewMockClient("In Progress")
+
+	// Create service
+	service := NewTrackingService(mockStorage, mockJira)
+	listener := &recordingListener{}
+	service.SetEventListener(listener)
+
+	ctx := context.Background()
+	at := time.Date(2024, 6, 10, 9, 0, 0, 0, time.UTC)
+	issue := &models.Issue{ID: 1, Key: "TEST-123", Title: "Old", Status: "POST", JiraURL: "https://issues.redhat.com/browse/TEST-123"}
+
+	mockStorage.On("GetIssueByKey", "TEST-123").Return(issue, nil)
+	mockStorage.On("UpdateIssue", mock.MatchedBy(func(i *models.Issue) bool {
+		return i.Status == "ON_QA" && i.Title == "New" && !i.LastWebhookAt.IsZero() &&
+			i.JiraURL == "https://issues.redhat.com/browse/TEST-123"
+	})).Return(nil).Once()
+	mockStorage.On("CreateEvent", ctx, mock.MatchedBy(func(e *models.Event) bool {
+		return e.Type == models.EventStatusChanged && e.From == "POST" && e.To == "ON_QA" &&
+			e.Author == "Alice" && e.Source == models.EventSourceJiraWebhook && e.OccurredAt.Equal(at)
+	})).Return(nil).Once()
+	mockStorage.On("CreateEvent", ctx, mock.MatchedBy(func(e *models.Event) bool {
+		return e.Type == models.EventFieldChanged && e.Field == "summary"
+	})).Return(nil).Once()
+
+	err := service.HandleJiraEvent(ctx, &jira.WebhookEvent{
+		Type:      jira.EventIssueUpdated,
+		Key:       "TEST-123",
+		Author:    "Alice",
+		Timestamp: at,
+		Issue:     &models.Issue{Key: "TEST-123", Title: "New", Status: "ON_QA"},
+		Changes: []jira.Change{
+			{Field: "status", From: "POST", To: "ON_QA"},
+			{Field: "summary", From: "Old", To: "New"},
+		},
+	})
+	require.NoError(t, err)
+
+	// Listeners are told about every recorded change
+	require.Len(t, listener.events, 2)
+	assert.Equal(t, models.EventStatusChanged, listener.events[0].Type)
+	assert.Equal(t, "ON_QA", listener.events[0].To)
+
+	mockStorage.AssertExpectations(t)
+}
+
+func TestHandleJiraEventDeletesIssue(t *testing.T) {
+	// Create mocks
+	mockStorage := &MockStorage{}
+	mockJira := jira.NewMockClient("In Progress")
+
+	// Create service
+	service := NewTrackingService(mockStorage, mockJira)
+
+	ctx := context.Background()
+	mockStorage.On("GetIssueByKey", "TEST-123").Return(&models.Issue{ID: 1, Key: "TEST-123"}, nil)
+	mockStorage.On("DeleteIssue", ctx, "TEST-123").Return(nil).Once()
+
+	err := service.HandleJiraEvent(ctx, &jira.WebhookEvent{Type: jira.EventIssueDeleted, Key: "TEST-123"})
+	require.NoError(t, err)
+
+	mockStorage.AssertExpectations(t)
+}
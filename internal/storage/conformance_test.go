@@ -0,0 +1,57 @@
+package storage_test
+
+import (
+	"database/sql"
+	"fmt"
+	"net/url"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/jparrill/devtrackr/internal/storage"
+	"github.com/jparrill/devtrackr/internal/storage/storagetest"
+	"github.com/stretchr/testify/require"
+)
+
+func TestSQLiteConformance(t *testing.T) {
+	storagetest.Run(t, func(t *testing.T) storage.Storage {
+		s, err := storage.Open("sqlite://" + filepath.Join(t.TempDir(), "devtrackr.db"))
+		require.NoError(t, err)
+		t.Cleanup(func() { s.Close() })
+		return s
+	})
+}
+
+// TestPostgresConformance runs against the server of DEVTRACKR_TEST_POSTGRES_DSN,
+// e.g. one started by make test-postgres, in a new schema for every test
+func TestPostgresConformance(t *testing.T) {
+	dsn := os.Getenv("DEVTRACKR_TEST_POSTGRES_DSN")
+	if dsn == "" {
+		t.Skip("DEVTRACKR_TEST_POSTGRES_DSN is not set")
+	}
+
+	admin, err := sql.Open("pgx", dsn)
+	require.NoError(t, err)
+	defer admin.Close()
+
+	schemas := 0
+	storagetest.Run(t, func(t *testing.T) storage.Storage {
+		schemas++
+		schema := fmt.Sprintf("devtrackr_test_%d_%d", os.Getpid(), schemas)
+		_, err := admin.Exec(`CREATE SCHEMA ` + schema)
+		require.NoError(t, err)
+		t.Cleanup(func() { admin.Exec(`DROP SCHEMA ` + schema + ` CASCADE`) })
+
+		// Unknown URL parameters are sent to the server as settings
+		u, err := url.Parse(dsn)
+		require.NoError(t, err)
+		q := u.Query()
+		q.Set("search_path", schema)
+		u.RawQuery = q.Encode()
+
+		s, err := storage.Open(u.String())
+		require.NoError(t, err)
+		t.Cleanup(func() { s.Close() })
+		return s
+	})
+}
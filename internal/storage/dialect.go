@@ -0,0 +1,60 @@
+package storage
+
+import (
+	"strconv"
+	"strings"
+)
+
+// dialect describes how a database differs from the SQLite flavour of SQL
+// the queries are written in
+type dialect struct {
+	name string
+	// migrations brings the schema of a database up to SchemaVersion
+	migrations []migration
+	// numbered rewrites ? placeholders as $1, $2...
+	numbered bool
+	// returning reads the ID of inserted rows with RETURNING id, for drivers
+	// without LastInsertId
+	returning bool
+	// lock and unlock, when set, keep nodes sharing the database from
+	// migrating it at the same time
+	lock, unlock string
+}
+
+// migrationLockID is the PostgreSQL advisory lock held while migrating
+const migrationLockID = "7310693"
+
+var sqliteDialect = &dialect{
+	name:       "sqlite",
+	migrations: migrations,
+}
+
+var postgresDialect = &dialect{
+	name:       "postgres",
+	migrations: postgresMigrations,
+	numbered:   true,
+	returning:  true,
+	lock:       `SELECT pg_advisory_lock(` + migrationLockID + `)`,
+	unlock:     `SELECT pg_advisory_unlock(` + migrationLockID + `)`,
+}
+
+// bind rewrites the placeholders of a query for the dialect. Queries must
+// not contain a literal question mark.
+func (d *dialect) bind(query string) string {
+	if !d.numbered {
+		return query
+	}
+
+	var b strings.Builder
+	n := 0
+	for _, r := range query {
+		if r != '?' {
+			b.WriteRune(r)
+			continue
+		}
+		n++
+		b.WriteByte('$')
+		b.WriteString(strconv.Itoa(n))
+	}
+	return b.String()
+}
@@ -0,0 +1,199 @@
+package storage
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"time"
+
+	"github.com/mattn/go-sqlite3"
+)
+
+// backupPagesPerStep is how many pages an online backup copies at a time.
+// Writers are only blocked while a step runs.
+const backupPagesPerStep = 256
+
+// Backup copies the database to path with the SQLite online backup API, so
+// it is consistent even while other connections write to it. The copy is
+// written next to path first and renamed into place once complete.
+func (s *SQLiteStorage) Backup(ctx context.Context, path string) error {
+	tmp := path + ".tmp"
+	if err := os.Remove(tmp); err != nil && !errors.Is(err, os.ErrNotExist) {
+		return fmt.Errorf("failed to remove stale backup: %w", err)
+	}
+
+	if err := s.backupTo(ctx, tmp); err != nil {
+		os.Remove(tmp)
+		return fmt.Errorf("failed to back up database: %w", err)
+	}
+	if err := os.Rename(tmp, path); err != nil {
+		os.Remove(tmp)
+		return fmt.Errorf("failed to move backup into place: %w", err)
+	}
+	return nil
+}
+
+// backupTo runs the online backup into a new database file
+func (s *SQLiteStorage) backupTo(ctx context.Context, path string) error {
+	dest, err := sql.Open("sqlite3", path)
+	if err != nil {
+		return err
+	}
+	defer dest.Close()
+
+	destConn, err := dest.Conn(ctx)
+	if err != nil {
+		return err
+	}
+	defer destConn.Close()
+	srcConn, err := s.db.Conn(ctx)
+	if err != nil {
+		return err
+	}
+	defer srcConn.Close()
+
+	return destConn.Raw(func(destDriver any) error {
+		return srcConn.Raw(func(srcDriver any) error {
+			destSQLite, ok := destDriver.(*sqlite3.SQLiteConn)
+			srcSQLite, ok2 := srcDriver.(*sqlite3.SQLiteConn)
+			if !ok || !ok2 {
+				return fmt.Errorf("online backup needs the sqlite3 driver")
+			}
+
+			backup, err := destSQLite.Backup("main", srcSQLite, "main")
+			if err != nil {
+				return err
+			}
+			for {
+				done, err := backup.Step(backupPagesPerStep)
+				if err == nil && !done {
+					err = ctx.Err()
+				}
+				if err != nil {
+					backup.Finish()
+					return err
+				}
+				if done {
+					return backup.Finish()
+				}
+			}
+		})
+	})
+}
+
+// Vacuum rebuilds the database file, reclaiming the space of deleted rows
+func (s *SQLiteStorage) Vacuum(ctx context.Context) error {
+	if _, err := s.db.ExecContext(ctx, `VACUUM`); err != nil {
+		return fmt.Errorf("failed to vacuum database: %w", err)
+	}
+	return nil
+}
+
+// PruneEvents deletes the timeline events that occurred before a time and
+// returns how many were deleted
+func (s *sqlStore) PruneEvents(ctx context.Context, before time.Time) (int64, error) {
+	result, err := s.db.ExecContext(ctx, s.bind(`DELETE FROM issue_events WHERE occurred_at < ?`), before.UTC())
+	if err != nil {
+		return 0, fmt.Errorf("failed to prune events: %w", err)
+	}
+	return result.RowsAffected()
+}
+
+// PruneAuditEntries deletes the Jira audit entries recorded before a time
+// and returns how many were deleted
+func (s *sqlStore) PruneAuditEntries(ctx context.Context, before time.Time) (int64, error) {
+	result, err := s.db.ExecContext(ctx, s.bind(`DELETE FROM jira_audit WHERE created_at < ?`), before.UTC())
+	if err != nil {
+		return 0, fmt.Errorf("failed to prune audit entries: %w", err)
+	}
+	return result.RowsAffected()
+}
+
+// CheckBackup opens a database file read-only, checks its integrity and
+// returns its schema version. Files written by a newer build of DevTrackr,
+// whose schema this build cannot migrate, are rejected.
+func CheckBackup(path string) (int, error) {
+	if _, err := os.Stat(path); err != nil {
+		return 0, err
+	}
+	db, err := sql.Open("sqlite3", "file:"+path+"?mode=ro")
+	if err != nil {
+		return 0, fmt.Errorf("failed to open %s: %w", path, err)
+	}
+	defer db.Close()
+
+	var integrity string
+	if err := db.QueryRow(`PRAGMA integrity_check`).Scan(&integrity); err != nil {
+		return 0, fmt.Errorf("%s is not a SQLite database: %w", path, err)
+	}
+	if integrity != "ok" {
+		return 0, fmt.Errorf("%s is corrupt: %s", path, integrity)
+	}
+
+	version, err := currentSchemaVersion(context.Background(), db)
+	if err != nil {
+		return 0, fmt.Errorf("%s is not a DevTrackr database: %w", path, err)
+	}
+	if version > SchemaVersion() {
+		return 0, fmt.Errorf("%s has schema version %d, newer than the %d of this build", path, version, SchemaVersion())
+	}
+	return version, nil
+}
+
+// Restore replaces the database at path with a backup once CheckBackup
+// accepts it, and returns the schema version of the backup. The replaced
+// database is kept as path.pre-restore. Older schemas are migrated the next
+// time the database is opened. No connection to the database may be open.
+func Restore(backup, path string) (int, error) {
+	version, err := CheckBackup(backup)
+	if err != nil {
+		return 0, err
+	}
+
+	// A leftover journal would be replayed into the restored database
+	for _, suffix := range []string{"-journal", "-wal", "-shm"} {
+		if _, err := os.Stat(path + suffix); err == nil {
+			return 0, fmt.Errorf("%s%s exists: the database is in use or was not closed cleanly", path, suffix)
+		}
+	}
+
+	tmp := path + ".restore"
+	if err := copyFile(backup, tmp); err != nil {
+		os.Remove(tmp)
+		return 0, fmt.Errorf("failed to copy backup: %w", err)
+	}
+	if err := os.Rename(path, path+".pre-restore"); err != nil && !errors.Is(err, os.ErrNotExist) {
+		os.Remove(tmp)
+		return 0, fmt.Errorf("failed to set the current database aside: %w", err)
+	}
+	if err := os.Rename(tmp, path); err != nil {
+		return 0, fmt.Errorf("failed to move backup into place: %w", err)
+	}
+	return version, nil
+}
+
+// copyFile copies a file and syncs the copy to disk
+func copyFile(src, dst string) error {
+	in, err := os.Open(src)
+	if err != nil {
+		return err
+	}
+	defer in.Close()
+
+	out, err := os.Create(dst)
+	if err != nil {
+		return err
+	}
+	if _, err := io.Copy(out, in); err != nil {
+		out.Close()
+		return err
+	}
+	if err := out.Sync(); err != nil {
+		out.Close()
+		return err
+	}
+	return out.Close()
+}
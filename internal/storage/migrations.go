@@ -0,0 +1,437 @@
+package storage
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+)
+
+// migration represents a versioned schema change applied on top of the base tables
+type migration struct {
+	version     int
+	description string
+	statements  []string
+}
+
+// migrations lists every schema change of SQLite databases in the order it
+// must be applied. New entries must always be appended with the next version
+// number, together with the same change to postgresMigrations;
+// TestSchemasInStep fails when both lists stop creating the same columns.
+var migrations = []migration{
+	{
+		version:     1,
+		description: "add release fields to issues and branch fields to pull requests",
+		statements: []string{
+			`ALTER TABLE issues ADD COLUMN fix_versions TEXT NOT NULL DEFAULT '[]'`,
+			`ALTER TABLE issues ADD COLUMN affects_versions TEXT NOT NULL DEFAULT '[]'`,
+			`ALTER TABLE issues ADD COLUMN components TEXT NOT NULL DEFAULT '[]'`,
+			`ALTER TABLE pull_requests ADD COLUMN repository TEXT NOT NULL DEFAULT ''`,
+			`ALTER TABLE pull_requests ADD COLUMN target_branch TEXT NOT NULL DEFAULT ''`,
+			`ALTER TABLE pull_requests ADD COLUMN is_backport BOOLEAN NOT NULL DEFAULT false`,
+			`ALTER TABLE pull_requests ADD COLUMN original_pr_id INTEGER`,
+		},
+	},
+	{
+		version:     2,
+		description: "add description and labels to pull requests",
+		statements: []string{
+			`ALTER TABLE pull_requests ADD COLUMN body TEXT NOT NULL DEFAULT ''`,
+			`ALTER TABLE pull_requests ADD COLUMN labels TEXT NOT NULL DEFAULT '[]'`,
+		},
+	},
+	{
+		version:     3,
+		description: "add issue_commits table for git history scans",
+		statements: []string{
+			`CREATE TABLE IF NOT EXISTS issue_commits (
+				id INTEGER PRIMARY KEY AUTOINCREMENT,
+				issue_id INTEGER NOT NULL,
+				repository TEXT NOT NULL,
+				sha TEXT NOT NULL,
+				subject TEXT NOT NULL,
+				committed_at TIMESTAMP NOT NULL,
+				branches TEXT NOT NULL DEFAULT '[]',
+				tags TEXT NOT NULL DEFAULT '[]',
+				scanned_at TIMESTAMP NOT NULL,
+				FOREIGN KEY (issue_id) REFERENCES issues(id),
+				UNIQUE (issue_id, repository, sha)
+			)`,
+		},
+	},
+	{
+		version:     4,
+		description: "add CI and review status to pull requests",
+		statements: []string{
+			`ALTER TABLE pull_requests ADD COLUMN ci_status TEXT NOT NULL DEFAULT ''`,
+			`ALTER TABLE pull_requests ADD COLUMN failing_checks TEXT NOT NULL DEFAULT '[]'`,
+			`ALTER TABLE pull_requests ADD COLUMN required_reviewers TEXT NOT NULL DEFAULT '[]'`,
+			`ALTER TABLE pull_requests ADD COLUMN approvals INTEGER NOT NULL DEFAULT 0`,
+			`ALTER TABLE pull_requests ADD COLUMN mergeable BOOLEAN`,
+		},
+	},
+	{
+		version:     5,
+		description: "add issue_events timeline and webhook tracking to issues",
+		statements: []string{
+			`ALTER TABLE issues ADD COLUMN last_webhook_at TIMESTAMP`,
+			`CREATE TABLE IF NOT EXISTS issue_events (
+				id INTEGER PRIMARY KEY AUTOINCREMENT,
+				issue_id INTEGER NOT NULL,
+				type TEXT NOT NULL,
+				field TEXT NOT NULL DEFAULT '',
+				from_value TEXT NOT NULL DEFAULT '',
+				to_value TEXT NOT NULL DEFAULT '',
+				author TEXT NOT NULL DEFAULT '',
+				source TEXT NOT NULL,
+				occurred_at TIMESTAMP NOT NULL,
+				created_at TIMESTAMP NOT NULL,
+				FOREIGN KEY (issue_id) REFERENCES issues(id)
+			)`,
+			`CREATE INDEX IF NOT EXISTS idx_issue_events_issue ON issue_events (issue_id, occurred_at)`,
+		},
+	},
+	{
+		version:     6,
+		description: "persist per-issue polling intervals",
+		statements: []string{
+			`ALTER TABLE issues ADD COLUMN polling_interval INTEGER NOT NULL DEFAULT 0`,
+		},
+	},
+	{
+		version:     7,
+		description: "add Jira status category to issues",
+		statements: []string{
+			`ALTER TABLE issues ADD COLUMN status_category TEXT NOT NULL DEFAULT ''`,
+		},
+	},
+	{
+		// SQLite cannot drop the unique constraint on key, so the table is rebuilt
+		version:     8,
+		description: "key issues by Jira instance and key",
+		statements: []string{
+			`CREATE TABLE issues_v8 (
+				id INTEGER PRIMARY KEY AUTOINCREMENT,
+				instance TEXT NOT NULL DEFAULT '',
+				key TEXT NOT NULL,
+				title TEXT NOT NULL,
+				status TEXT NOT NULL,
+				jira_url TEXT NOT NULL,
+				created_at TIMESTAMP NOT NULL,
+				updated_at TIMESTAMP NOT NULL,
+				last_polled_at TIMESTAMP NOT NULL,
+				fix_versions TEXT NOT NULL DEFAULT '[]',
+				affects_versions TEXT NOT NULL DEFAULT '[]',
+				components TEXT NOT NULL DEFAULT '[]',
+				last_webhook_at TIMESTAMP,
+				polling_interval INTEGER NOT NULL DEFAULT 0,
+				status_category TEXT NOT NULL DEFAULT '',
+				UNIQUE (instance, key)
+			)`,
+			`INSERT INTO issues_v8 (id, key, title, status, jira_url, created_at, updated_at, last_polled_at,
+				fix_versions, affects_versions, components, last_webhook_at, polling_interval, status_category)
+			SELECT id, key, title, status, jira_url, created_at, updated_at, last_polled_at,
+				fix_versions, affects_versions, components, last_webhook_at, polling_interval, status_category
+			FROM issues`,
+			`DROP TABLE issues`,
+			`ALTER TABLE issues_v8 RENAME TO issues`,
+		},
+	},
+	{
+		version:     9,
+		description: "add people, planning and resolution fields to issues",
+		statements: []string{
+			`ALTER TABLE issues ADD COLUMN description TEXT NOT NULL DEFAULT ''`,
+			`ALTER TABLE issues ADD COLUMN assignee TEXT NOT NULL DEFAULT ''`,
+			`ALTER TABLE issues ADD COLUMN reporter TEXT NOT NULL DEFAULT ''`,
+			`ALTER TABLE issues ADD COLUMN priority TEXT NOT NULL DEFAULT ''`,
+			`ALTER TABLE issues ADD COLUMN issue_type TEXT NOT NULL DEFAULT ''`,
+			`ALTER TABLE issues ADD COLUMN labels TEXT NOT NULL DEFAULT '[]'`,
+			`ALTER TABLE issues ADD COLUMN sprint TEXT NOT NULL DEFAULT ''`,
+			`ALTER TABLE issues ADD COLUMN epic_link TEXT NOT NULL DEFAULT ''`,
+			`ALTER TABLE issues ADD COLUMN resolution TEXT NOT NULL DEFAULT ''`,
+			`ALTER TABLE issues ADD COLUMN due_date TIMESTAMP`,
+			`ALTER TABLE issues ADD COLUMN jira_updated_at TIMESTAMP`,
+			`CREATE INDEX IF NOT EXISTS idx_issues_assignee ON issues (assignee)`,
+		},
+	},
+	{
+		version:     10,
+		description: "add custom field attributes to issues",
+		statements: []string{
+			`ALTER TABLE issues ADD COLUMN attributes TEXT NOT NULL DEFAULT '{}'`,
+		},
+	},
+	{
+		version:     11,
+		description: "add jira_audit table for changes written to Jira",
+		statements: []string{
+			`CREATE TABLE IF NOT EXISTS jira_audit (
+				id INTEGER PRIMARY KEY AUTOINCREMENT,
+				issue_id INTEGER NOT NULL,
+				action TEXT NOT NULL,
+				details TEXT NOT NULL DEFAULT '',
+				actor TEXT NOT NULL DEFAULT '',
+				dry_run BOOLEAN NOT NULL DEFAULT false,
+				error TEXT NOT NULL DEFAULT '',
+				created_at TIMESTAMP NOT NULL,
+				FOREIGN KEY (issue_id) REFERENCES issues(id)
+			)`,
+			`CREATE INDEX IF NOT EXISTS idx_jira_audit_issue ON jira_audit (issue_id, created_at)`,
+		},
+	},
+	{
+		version:     12,
+		description: "add issue_children and issue_links tables for the Jira hierarchy",
+		statements: []string{
+			`CREATE TABLE IF NOT EXISTS issue_children (
+				id INTEGER PRIMARY KEY AUTOINCREMENT,
+				issue_id INTEGER NOT NULL,
+				key TEXT NOT NULL,
+				kind TEXT NOT NULL,
+				title TEXT NOT NULL DEFAULT '',
+				status TEXT NOT NULL DEFAULT '',
+				status_category TEXT NOT NULL DEFAULT '',
+				FOREIGN KEY (issue_id) REFERENCES issues(id),
+				UNIQUE (issue_id, key)
+			)`,
+			`CREATE TABLE IF NOT EXISTS issue_links (
+				id INTEGER PRIMARY KEY AUTOINCREMENT,
+				issue_id INTEGER NOT NULL,
+				type TEXT NOT NULL,
+				relation TEXT NOT NULL DEFAULT '',
+				key TEXT NOT NULL,
+				title TEXT NOT NULL DEFAULT '',
+				status TEXT NOT NULL DEFAULT '',
+				status_category TEXT NOT NULL DEFAULT '',
+				FOREIGN KEY (issue_id) REFERENCES issues(id)
+			)`,
+			`CREATE INDEX IF NOT EXISTS idx_issue_links_issue ON issue_links (issue_id)`,
+		},
+	},
+	{
+		version:     13,
+		description: "add link direction to issue_links",
+		statements: []string{
+			`ALTER TABLE issue_links ADD COLUMN outward BOOLEAN NOT NULL DEFAULT false`,
+		},
+	},
+}
+
+// postgresMigrations lists every schema change of PostgreSQL databases. The
+// backend was added at version 13, so a single migration creates the schema
+// SQLite reached by then. Versions must stay in step with migrations.
+var postgresMigrations = []migration{
+	{
+		version:     13,
+		description: "create the schema of version 13",
+		statements: []string{
+			`CREATE TABLE IF NOT EXISTS issues (
+				id BIGSERIAL PRIMARY KEY,
+				instance TEXT NOT NULL DEFAULT '',
+				key TEXT NOT NULL,
+				title TEXT NOT NULL,
+				status TEXT NOT NULL,
+				jira_url TEXT NOT NULL,
+				created_at TIMESTAMPTZ NOT NULL,
+				updated_at TIMESTAMPTZ NOT NULL,
+				last_polled_at TIMESTAMPTZ NOT NULL,
+				fix_versions TEXT NOT NULL DEFAULT '[]',
+				affects_versions TEXT NOT NULL DEFAULT '[]',
+				components TEXT NOT NULL DEFAULT '[]',
+				last_webhook_at TIMESTAMPTZ,
+				polling_interval INTEGER NOT NULL DEFAULT 0,
+				status_category TEXT NOT NULL DEFAULT '',
+				description TEXT NOT NULL DEFAULT '',
+				assignee TEXT NOT NULL DEFAULT '',
+				reporter TEXT NOT NULL DEFAULT '',
+				priority TEXT NOT NULL DEFAULT '',
+				issue_type TEXT NOT NULL DEFAULT '',
+				labels TEXT NOT NULL DEFAULT '[]',
+				sprint TEXT NOT NULL DEFAULT '',
+				epic_link TEXT NOT NULL DEFAULT '',
+				resolution TEXT NOT NULL DEFAULT '',
+				due_date TIMESTAMPTZ,
+				jira_updated_at TIMESTAMPTZ,
+				attributes TEXT NOT NULL DEFAULT '{}',
+				UNIQUE (instance, key)
+			)`,
+			`CREATE INDEX IF NOT EXISTS idx_issues_assignee ON issues (assignee)`,
+			`CREATE TABLE IF NOT EXISTS subscriptions (
+				id BIGSERIAL PRIMARY KEY,
+				issue_id BIGINT NOT NULL REFERENCES issues(id),
+				user_id BIGINT NOT NULL,
+				active BOOLEAN NOT NULL DEFAULT true,
+				created_at TIMESTAMPTZ NOT NULL,
+				updated_at TIMESTAMPTZ NOT NULL
+			)`,
+			`CREATE TABLE IF NOT EXISTS pull_requests (
+				id BIGSERIAL PRIMARY KEY,
+				issue_id BIGINT NOT NULL REFERENCES issues(id),
+				number INTEGER NOT NULL,
+				repository TEXT NOT NULL DEFAULT '',
+				title TEXT NOT NULL,
+				body TEXT NOT NULL DEFAULT '',
+				labels TEXT NOT NULL DEFAULT '[]',
+				url TEXT NOT NULL,
+				status TEXT NOT NULL,
+				target_branch TEXT NOT NULL DEFAULT '',
+				is_backport BOOLEAN NOT NULL DEFAULT false,
+				original_pr_id BIGINT,
+				ci_status TEXT NOT NULL DEFAULT '',
+				failing_checks TEXT NOT NULL DEFAULT '[]',
+				required_reviewers TEXT NOT NULL DEFAULT '[]',
+				approvals INTEGER NOT NULL DEFAULT 0,
+				mergeable BOOLEAN,
+				created_at TIMESTAMPTZ NOT NULL,
+				updated_at TIMESTAMPTZ NOT NULL
+			)`,
+			`CREATE INDEX IF NOT EXISTS idx_pull_requests_issue ON pull_requests (issue_id)`,
+			`CREATE INDEX IF NOT EXISTS idx_pull_requests_number ON pull_requests (repository, number)`,
+			`CREATE TABLE IF NOT EXISTS issue_commits (
+				id BIGSERIAL PRIMARY KEY,
+				issue_id BIGINT NOT NULL REFERENCES issues(id),
+				repository TEXT NOT NULL,
+				sha TEXT NOT NULL,
+				subject TEXT NOT NULL,
+				committed_at TIMESTAMPTZ NOT NULL,
+				branches TEXT NOT NULL DEFAULT '[]',
+				tags TEXT NOT NULL DEFAULT '[]',
+				scanned_at TIMESTAMPTZ NOT NULL,
+				UNIQUE (issue_id, repository, sha)
+			)`,
+			`CREATE TABLE IF NOT EXISTS issue_events (
+				id BIGSERIAL PRIMARY KEY,
+				issue_id BIGINT NOT NULL REFERENCES issues(id),
+				type TEXT NOT NULL,
+				field TEXT NOT NULL DEFAULT '',
+				from_value TEXT NOT NULL DEFAULT '',
+				to_value TEXT NOT NULL DEFAULT '',
+				author TEXT NOT NULL DEFAULT '',
+				source TEXT NOT NULL,
+				occurred_at TIMESTAMPTZ NOT NULL,
+				created_at TIMESTAMPTZ NOT NULL
+			)`,
+			`CREATE INDEX IF NOT EXISTS idx_issue_events_issue ON issue_events (issue_id, occurred_at)`,
+			`CREATE TABLE IF NOT EXISTS jira_audit (
+				id BIGSERIAL PRIMARY KEY,
+				issue_id BIGINT NOT NULL REFERENCES issues(id),
+				action TEXT NOT NULL,
+				details TEXT NOT NULL DEFAULT '',
+				actor TEXT NOT NULL DEFAULT '',
+				dry_run BOOLEAN NOT NULL DEFAULT false,
+				error TEXT NOT NULL DEFAULT '',
+				created_at TIMESTAMPTZ NOT NULL
+			)`,
+			`CREATE INDEX IF NOT EXISTS idx_jira_audit_issue ON jira_audit (issue_id, created_at)`,
+			`CREATE TABLE IF NOT EXISTS issue_children (
+				id BIGSERIAL PRIMARY KEY,
+				issue_id BIGINT NOT NULL REFERENCES issues(id),
+				key TEXT NOT NULL,
+				kind TEXT NOT NULL,
+				title TEXT NOT NULL DEFAULT '',
+				status TEXT NOT NULL DEFAULT '',
+				status_category TEXT NOT NULL DEFAULT '',
+				UNIQUE (issue_id, key)
+			)`,
+			`CREATE TABLE IF NOT EXISTS issue_links (
+				id BIGSERIAL PRIMARY KEY,
+				issue_id BIGINT NOT NULL REFERENCES issues(id),
+				type TEXT NOT NULL,
+				relation TEXT NOT NULL DEFAULT '',
+				outward BOOLEAN NOT NULL DEFAULT false,
+				key TEXT NOT NULL,
+				title TEXT NOT NULL DEFAULT '',
+				status TEXT NOT NULL DEFAULT '',
+				status_category TEXT NOT NULL DEFAULT ''
+			)`,
+			`CREATE INDEX IF NOT EXISTS idx_issue_links_issue ON issue_links (issue_id)`,
+		},
+	},
+}
+
+// SchemaVersion returns the schema version this build of DevTrackr expects
+func SchemaVersion() int {
+	if len(migrations) == 0 {
+		return 0
+	}
+	return migrations[len(migrations)-1].version
+}
+
+// migrate applies the pending migrations of a dialect to the database
+func migrate(db *sql.DB, d *dialect) error {
+	ctx := context.Background()
+	conn, err := db.Conn(ctx)
+	if err != nil {
+		return fmt.Errorf("failed to connect to database: %w", err)
+	}
+	defer conn.Close()
+
+	if d.lock != "" {
+		if _, err := conn.ExecContext(ctx, d.lock); err != nil {
+			return fmt.Errorf("failed to lock migrations: %w", err)
+		}
+		defer conn.ExecContext(ctx, d.unlock)
+	}
+
+	_, err = conn.ExecContext(ctx, `
+		CREATE TABLE IF NOT EXISTS schema_migrations (
+			version INTEGER PRIMARY KEY,
+			description TEXT NOT NULL,
+			applied_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP
+		)
+	`)
+	if err != nil {
+		return fmt.Errorf("failed to create schema_migrations table: %w", err)
+	}
+
+	current, err := currentSchemaVersion(ctx, conn)
+	if err != nil {
+		return err
+	}
+
+	for _, m := range d.migrations {
+		if m.version <= current {
+			continue
+		}
+
+		tx, err := conn.BeginTx(ctx, nil)
+		if err != nil {
+			return fmt.Errorf("failed to begin migration %d: %w", m.version, err)
+		}
+
+		for _, stmt := range m.statements {
+			if _, err := tx.Exec(stmt); err != nil {
+				tx.Rollback()
+				return fmt.Errorf("failed to apply migration %d (%s): %w", m.version, m.description, err)
+			}
+		}
+
+		if _, err := tx.Exec(
+			d.bind(`INSERT INTO schema_migrations (version, description) VALUES (?, ?)`),
+			m.version, m.description,
+		); err != nil {
+			tx.Rollback()
+			return fmt.Errorf("failed to record migration %d: %w", m.version, err)
+		}
+
+		if err := tx.Commit(); err != nil {
+			return fmt.Errorf("failed to commit migration %d: %w", m.version, err)
+		}
+	}
+
+	return nil
+}
+
+// rowQuerier is implemented by *sql.DB and *sql.Conn
+type rowQuerier interface {
+	QueryRowContext(ctx context.Context, query string, args ...interface{}) *sql.Row
+}
+
+// currentSchemaVersion returns the highest migration version applied to the database
+func currentSchemaVersion(ctx context.Context, db rowQuerier) (int, error) {
+	var version sql.NullInt64
+	if err := db.QueryRowContext(ctx, `SELECT MAX(version) FROM schema_migrations`).Scan(&version); err != nil {
+		return 0, fmt.Errorf("failed to read schema version: %w", err)
+	}
+	return int(version.Int64), nil
+}
@@ -0,0 +1,967 @@
+package storage
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"fmt"
+	"time"
+
+	"github.com/jparrill/devtrackr/internal/models"
+)
+
+// sqlStore implements the Storage interface on top of database/sql, shared by
+// the SQLite and PostgreSQL backends. Queries are written with ? placeholders
+// and bound to the dialect of the database before running.
+type sqlStore struct {
+	db      *sql.DB
+	dialect *dialect
+}
+
+// bind rewrites the placeholders of a query for the dialect of the database
+func (s *sqlStore) bind(query string) string {
+	return s.dialect.bind(query)
+}
+
+// insert runs an INSERT statement and returns the ID of the row inserted
+func (s *sqlStore) insert(ctx context.Context, query string, args ...interface{}) (int64, error) {
+	if s.dialect.returning {
+		var id int64
+		err := s.db.QueryRowContext(ctx, s.bind(query)+` RETURNING id`, args...).Scan(&id)
+		return id, err
+	}
+
+	result, err := s.db.ExecContext(ctx, s.bind(query), args...)
+	if err != nil {
+		return 0, err
+	}
+	return result.LastInsertId()
+}
+
+// issueColumns lists the columns read by scanIssue, in order
+const issueColumns = `id, instance, key, title, status, jira_url, created_at, updated_at, last_polled_at,
+	fix_versions, affects_versions, components, last_webhook_at, polling_interval,
+	status_category, description, assignee, reporter, priority, issue_type, labels, sprint,
+	epic_link, resolution, due_date, jira_updated_at, attributes`
+
+// rowScanner is implemented by both *sql.Row and *sql.Rows
+type rowScanner interface {
+	Scan(dest ...interface{}) error
+}
+
+// scanIssue scans a row selected with issueColumns into an issue
+func scanIssue(row rowScanner) (*models.Issue, error) {
+	var issue models.Issue
+	var fixVersions, affectsVersions, components, labels, attributes string
+	var lastWebhookAt, dueDate, jiraUpdatedAt sql.NullTime
+
+	err := row.Scan(
+		&issue.ID,
+		&issue.Instance,
+		&issue.Key,
+		&issue.Title,
+		&issue.Status,
+		&issue.JiraURL,
+		&issue.CreatedAt,
+		&issue.UpdatedAt,
+		&issue.LastPolledAt,
+		&fixVersions,
+		&affectsVersions,
+		&components,
+		&lastWebhookAt,
+		&issue.PollingInterval,
+		&issue.StatusCategory,
+		&issue.Description,
+		&issue.Assignee,
+		&issue.Reporter,
+		&issue.Priority,
+		&issue.IssueType,
+		&labels,
+		&issue.Sprint,
+		&issue.EpicLink,
+		&issue.Resolution,
+		&dueDate,
+		&jiraUpdatedAt,
+		&attributes,
+	)
+	if err != nil {
+		return nil, err
+	}
+
+	if lastWebhookAt.Valid {
+		issue.LastWebhookAt = lastWebhookAt.Time
+	}
+	if dueDate.Valid {
+		issue.DueDate = dueDate.Time
+	}
+	if jiraUpdatedAt.Valid {
+		issue.JiraUpdatedAt = jiraUpdatedAt.Time
+	}
+
+	if issue.FixVersions, err = decodeStrings(fixVersions); err != nil {
+		return nil, fmt.Errorf("failed to parse fix_versions: %w", err)
+	}
+	if issue.AffectsVersions, err = decodeStrings(affectsVersions); err != nil {
+		return nil, fmt.Errorf("failed to parse affects_versions: %w", err)
+	}
+	if issue.Components, err = decodeStrings(components); err != nil {
+		return nil, fmt.Errorf("failed to parse components: %w", err)
+	}
+	if issue.Labels, err = decodeStrings(labels); err != nil {
+		return nil, fmt.Errorf("failed to parse labels: %w", err)
+	}
+	if issue.Attributes, err = decodeAttributes(attributes); err != nil {
+		return nil, fmt.Errorf("failed to parse attributes: %w", err)
+	}
+
+	return &issue, nil
+}
+
+// CreateIssue creates a new issue
+func (s *sqlStore) CreateIssue(issue *models.Issue) error {
+	id, err := s.insert(context.Background(), `
+		INSERT INTO issues (instance, key, title, status, jira_url, created_at, updated_at, last_polled_at,
+			fix_versions, affects_versions, components, polling_interval, status_category,
+			description, assignee, reporter, priority, issue_type, labels, sprint, epic_link, resolution,
+			due_date, jira_updated_at, attributes)
+		VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?)
+	`, issue.Instance, issue.Key, issue.Title, issue.Status, issue.JiraURL, issue.CreatedAt, issue.UpdatedAt, issue.LastPolledAt,
+		encodeStrings(issue.FixVersions), encodeStrings(issue.AffectsVersions), encodeStrings(issue.Components),
+		issue.PollingInterval, issue.StatusCategory,
+		issue.Description, issue.Assignee, issue.Reporter, issue.Priority, issue.IssueType, encodeStrings(issue.Labels),
+		issue.Sprint, issue.EpicLink, issue.Resolution, nullTime(issue.DueDate), nullTime(issue.JiraUpdatedAt),
+		encodeAttributes(issue.Attributes))
+	if err != nil {
+		return fmt.Errorf("failed to create issue: %w", err)
+	}
+	issue.ID = id
+	return nil
+}
+
+// GetIssue retrieves an issue by its key, optionally qualified with its Jira
+// instance (e.g. "redhat:OCPBUGS-1234"). Bare keys must be unique across
+// instances. Issues tracked before instances were recorded match any instance.
+func (s *sqlStore) GetIssue(ref string) (*models.Issue, error) {
+	instance, key := models.SplitQualifiedKey(ref)
+
+	var rows *sql.Rows
+	var err error
+	if instance != "" {
+		rows, err = s.db.Query(s.bind(`SELECT `+issueColumns+` FROM issues
+			WHERE key = ? AND instance IN (?, '') ORDER BY instance DESC LIMIT 1`), key, instance)
+	} else {
+		rows, err = s.db.Query(s.bind(`SELECT `+issueColumns+` FROM issues WHERE key = ? LIMIT 2`), key)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to get issue: %w", err)
+	}
+	defer rows.Close()
+
+	var issues []*models.Issue
+	for rows.Next() {
+		issue, err := scanIssue(rows)
+		if err != nil {
+			return nil, fmt.Errorf("failed to get issue: %w", err)
+		}
+		issues = append(issues, issue)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("failed to get issue: %w", err)
+	}
+
+	switch len(issues) {
+	case 0:
+		return nil, fmt.Errorf("issue not found: %s", ref)
+	case 1:
+		return issues[0], nil
+	default:
+		return nil, fmt.Errorf("issue key %s exists in several Jira instances, qualify it as %s", key,
+			models.QualifyKey("<instance>", key))
+	}
+}
+
+// GetIssueByKey is an alias for GetIssue
+func (s *sqlStore) GetIssueByKey(key string) (*models.Issue, error) {
+	return s.GetIssue(key)
+}
+
+// ListIssues returns all issues
+func (s *sqlStore) ListIssues() ([]models.Issue, error) {
+	rows, err := s.db.Query(s.bind(`SELECT ` + issueColumns + ` FROM issues ORDER BY id`))
+	if err != nil {
+		return nil, fmt.Errorf("failed to list issues: %w", err)
+	}
+	defer rows.Close()
+
+	var issues []models.Issue
+	for rows.Next() {
+		issue, err := scanIssue(rows)
+		if err != nil {
+			return nil, fmt.Errorf("failed to scan issue: %w", err)
+		}
+		issues = append(issues, *issue)
+	}
+	return issues, nil
+}
+
+// UpdateIssue updates an issue
+func (s *sqlStore) UpdateIssue(issue *models.Issue) error {
+	_, err := s.db.Exec(s.bind(`
+		UPDATE issues
+		SET title = ?, status = ?, updated_at = ?, last_polled_at = ?,
+			fix_versions = ?, affects_versions = ?, components = ?, last_webhook_at = ?,
+			polling_interval = ?, status_category = ?, instance = ?,
+			description = ?, assignee = ?, reporter = ?, priority = ?, issue_type = ?, labels = ?,
+			sprint = ?, epic_link = ?, resolution = ?, due_date = ?, jira_updated_at = ?, attributes = ?
+		WHERE id = ?
+	`), issue.Title, issue.Status, issue.UpdatedAt, issue.LastPolledAt,
+		encodeStrings(issue.FixVersions), encodeStrings(issue.AffectsVersions), encodeStrings(issue.Components),
+		nullTime(issue.LastWebhookAt), issue.PollingInterval, issue.StatusCategory, issue.Instance,
+		issue.Description, issue.Assignee, issue.Reporter, issue.Priority, issue.IssueType, encodeStrings(issue.Labels),
+		issue.Sprint, issue.EpicLink, issue.Resolution, nullTime(issue.DueDate), nullTime(issue.JiraUpdatedAt),
+		encodeAttributes(issue.Attributes), issue.ID)
+	if err != nil {
+		return fmt.Errorf("failed to update issue: %w", err)
+	}
+	return nil
+}
+
+// DeleteIssue deletes an issue together with the records attached to it
+func (s *sqlStore) DeleteIssue(ctx context.Context, key string) error {
+	issue, err := s.GetIssue(key)
+	if err != nil {
+		return fmt.Errorf("failed to delete issue: %w", err)
+	}
+
+	tx, err := s.db.BeginTx(ctx, nil)
+	if err != nil {
+		return fmt.Errorf("failed to delete issue: %w", err)
+	}
+	defer tx.Rollback()
+
+	for _, table := range []string{"issue_children", "issue_links", "jira_audit", "issue_events", "issue_commits", "pull_requests", "subscriptions"} {
+		if _, err := tx.ExecContext(ctx,
+			s.bind(`DELETE FROM `+table+` WHERE issue_id = ?`), issue.ID); err != nil {
+			return fmt.Errorf("failed to delete %s of issue: %w", table, err)
+		}
+	}
+
+	if _, err := tx.ExecContext(ctx, s.bind("DELETE FROM issues WHERE id = ?"), issue.ID); err != nil {
+		return fmt.Errorf("failed to delete issue: %w", err)
+	}
+	return tx.Commit()
+}
+
+// CreateSubscription creates a new subscription and sets its ID. Unset
+// timestamps default to now.
+func (s *sqlStore) CreateSubscription(ctx context.Context, sub *models.Subscription) error {
+	if sub.CreatedAt.IsZero() {
+		sub.CreatedAt = time.Now()
+	}
+	if sub.UpdatedAt.IsZero() {
+		sub.UpdatedAt = sub.CreatedAt
+	}
+
+	id, err := s.insert(ctx,
+		`INSERT INTO subscriptions (issue_id, user_id, active, created_at, updated_at)
+		VALUES (?, ?, ?, ?, ?)`,
+		sub.IssueID,
+		sub.UserID,
+		sub.Active,
+		sub.CreatedAt.Format(time.RFC3339),
+		sub.UpdatedAt.Format(time.RFC3339),
+	)
+	if err != nil {
+		return err
+	}
+
+	sub.ID = id
+	return nil
+}
+
+// GetSubscription retrieves a subscription by issue ID and user ID
+func (s *sqlStore) GetSubscription(ctx context.Context, issueID, userID int64) (*models.Subscription, error) {
+	var sub models.Subscription
+	var createdAt, updatedAt string
+
+	err := s.db.QueryRowContext(ctx,
+		s.bind(`SELECT id, issue_id, user_id, active, created_at, updated_at
+		FROM subscriptions
+		WHERE issue_id = ? AND user_id = ?`),
+		issueID,
+		userID,
+	).Scan(
+		&sub.ID,
+		&sub.IssueID,
+		&sub.UserID,
+		&sub.Active,
+		&createdAt,
+		&updatedAt,
+	)
+
+	if err != nil {
+		return nil, err
+	}
+
+	// Parse timestamps
+	sub.CreatedAt, err = time.Parse(time.RFC3339, createdAt)
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse created_at: %w", err)
+	}
+
+	sub.UpdatedAt, err = time.Parse(time.RFC3339, updatedAt)
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse updated_at: %w", err)
+	}
+
+	return &sub, nil
+}
+
+// GetSubscriptionByID retrieves a subscription by its ID
+func (s *sqlStore) GetSubscriptionByID(ctx context.Context, id int64) (*models.Subscription, error) {
+	var sub models.Subscription
+	var createdAt, updatedAt string
+
+	err := s.db.QueryRowContext(ctx,
+		s.bind(`SELECT id, issue_id, user_id, active, created_at, updated_at
+		FROM subscriptions
+		WHERE id = ?`),
+		id,
+	).Scan(
+		&sub.ID,
+		&sub.IssueID,
+		&sub.UserID,
+		&sub.Active,
+		&createdAt,
+		&updatedAt,
+	)
+
+	if err != nil {
+		return nil, err
+	}
+
+	// Parse timestamps
+	sub.CreatedAt, err = time.Parse(time.RFC3339, createdAt)
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse created_at: %w", err)
+	}
+
+	sub.UpdatedAt, err = time.Parse(time.RFC3339, updatedAt)
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse updated_at: %w", err)
+	}
+
+	return &sub, nil
+}
+
+// ListSubscriptions retrieves all subscriptions for a user
+func (s *sqlStore) ListSubscriptions(ctx context.Context, userID int64) ([]models.Subscription, error) {
+	return s.querySubscriptions(ctx,
+		`SELECT id, issue_id, user_id, active, created_at, updated_at
+		FROM subscriptions
+		WHERE user_id = ?
+		ORDER BY created_at DESC`,
+		userID,
+	)
+}
+
+// ListIssueSubscriptions retrieves the subscriptions of every user to an issue
+func (s *sqlStore) ListIssueSubscriptions(ctx context.Context, issueID int64) ([]models.Subscription, error) {
+	return s.querySubscriptions(ctx,
+		`SELECT id, issue_id, user_id, active, created_at, updated_at
+		FROM subscriptions
+		WHERE issue_id = ?
+		ORDER BY user_id`,
+		issueID,
+	)
+}
+
+// querySubscriptions runs a query selecting subscription columns
+func (s *sqlStore) querySubscriptions(ctx context.Context, query string, args ...interface{}) ([]models.Subscription, error) {
+	rows, err := s.db.QueryContext(ctx, s.bind(query), args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var subscriptions []models.Subscription
+	for rows.Next() {
+		var sub models.Subscription
+		var createdAt, updatedAt string
+
+		err := rows.Scan(
+			&sub.ID,
+			&sub.IssueID,
+			&sub.UserID,
+			&sub.Active,
+			&createdAt,
+			&updatedAt,
+		)
+		if err != nil {
+			return nil, err
+		}
+
+		// Parse timestamps
+		sub.CreatedAt, err = time.Parse(time.RFC3339, createdAt)
+		if err != nil {
+			return nil, fmt.Errorf("failed to parse created_at: %w", err)
+		}
+
+		sub.UpdatedAt, err = time.Parse(time.RFC3339, updatedAt)
+		if err != nil {
+			return nil, fmt.Errorf("failed to parse updated_at: %w", err)
+		}
+
+		subscriptions = append(subscriptions, sub)
+	}
+
+	if err = rows.Err(); err != nil {
+		return nil, err
+	}
+
+	return subscriptions, nil
+}
+
+// UpdateSubscription updates a subscription
+func (s *sqlStore) UpdateSubscription(ctx context.Context, sub *models.Subscription) error {
+	_, err := s.db.ExecContext(ctx,
+		s.bind(`UPDATE subscriptions
+		SET active = ?, updated_at = ?
+		WHERE id = ?`),
+		sub.Active,
+		time.Now().Format(time.RFC3339),
+		sub.ID,
+	)
+	return err
+}
+
+// DeleteSubscription deletes a subscription
+func (s *sqlStore) DeleteSubscription(ctx context.Context, id int64) error {
+	_, err := s.db.ExecContext(ctx, s.bind("DELETE FROM subscriptions WHERE id = ?"), id)
+	return err
+}
+
+// pullRequestColumns lists the columns read by scanPullRequest, in order
+const pullRequestColumns = `id, issue_id, number, repository, title, body, labels, url, status,
+	target_branch, is_backport, original_pr_id, ci_status, failing_checks, required_reviewers,
+	approvals, mergeable, created_at, updated_at`
+
+// scanPullRequest scans a row selected with pullRequestColumns into a pull request
+func scanPullRequest(row rowScanner) (*models.PullRequest, error) {
+	var pr models.PullRequest
+	var originalPRID sql.NullInt64
+	var mergeable sql.NullBool
+	var labels, failingChecks, requiredReviewers, createdAt, updatedAt string
+
+	err := row.Scan(
+		&pr.ID,
+		&pr.IssueID,
+		&pr.Number,
+		&pr.Repository,
+		&pr.Title,
+		&pr.Body,
+		&labels,
+		&pr.URL,
+		&pr.Status,
+		&pr.TargetBranch,
+		&pr.IsBackport,
+		&originalPRID,
+		&pr.CIStatus,
+		&failingChecks,
+		&requiredReviewers,
+		&pr.Approvals,
+		&mergeable,
+		&createdAt,
+		&updatedAt,
+	)
+	if err != nil {
+		return nil, err
+	}
+
+	if originalPRID.Valid {
+		pr.OriginalPRID = &originalPRID.Int64
+	}
+	if mergeable.Valid {
+		pr.Mergeable = &mergeable.Bool
+	}
+
+	if pr.Labels, err = decodeStrings(labels); err != nil {
+		return nil, fmt.Errorf("failed to parse labels: %w", err)
+	}
+	if pr.FailingChecks, err = decodeStrings(failingChecks); err != nil {
+		return nil, fmt.Errorf("failed to parse failing_checks: %w", err)
+	}
+	if pr.RequiredReviewers, err = decodeStrings(requiredReviewers); err != nil {
+		return nil, fmt.Errorf("failed to parse required_reviewers: %w", err)
+	}
+
+	// Parse timestamps
+	pr.CreatedAt, err = time.Parse(time.RFC3339, createdAt)
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse created_at: %w", err)
+	}
+
+	pr.UpdatedAt, err = time.Parse(time.RFC3339, updatedAt)
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse updated_at: %w", err)
+	}
+
+	return &pr, nil
+}
+
+// queryPullRequests runs a query selecting pullRequestColumns and scans every row
+func (s *sqlStore) queryPullRequests(ctx context.Context, query string, args ...interface{}) ([]*models.PullRequest, error) {
+	rows, err := s.db.QueryContext(ctx, s.bind(query), args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var prs []*models.PullRequest
+	for rows.Next() {
+		pr, err := scanPullRequest(rows)
+		if err != nil {
+			return nil, err
+		}
+		prs = append(prs, pr)
+	}
+
+	if err = rows.Err(); err != nil {
+		return nil, err
+	}
+
+	return prs, nil
+}
+
+// CreatePullRequest creates a new pull request and sets its ID. Unset
+// timestamps default to now.
+func (s *sqlStore) CreatePullRequest(ctx context.Context, pr *models.PullRequest) error {
+	if pr.CreatedAt.IsZero() {
+		pr.CreatedAt = time.Now()
+	}
+	if pr.UpdatedAt.IsZero() {
+		pr.UpdatedAt = pr.CreatedAt
+	}
+
+	id, err := s.insert(ctx,
+		`INSERT INTO pull_requests (issue_id, number, repository, title, body, labels, url, status,
+			target_branch, is_backport, original_pr_id, ci_status, failing_checks, required_reviewers,
+			approvals, mergeable, created_at, updated_at)
+		VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?)`,
+		pr.IssueID,
+		pr.Number,
+		pr.Repository,
+		pr.Title,
+		pr.Body,
+		encodeStrings(pr.Labels),
+		pr.URL,
+		pr.Status,
+		pr.TargetBranch,
+		pr.IsBackport,
+		pr.OriginalPRID,
+		pr.CIStatus,
+		encodeStrings(pr.FailingChecks),
+		encodeStrings(pr.RequiredReviewers),
+		pr.Approvals,
+		pr.Mergeable,
+		pr.CreatedAt.Format(time.RFC3339),
+		pr.UpdatedAt.Format(time.RFC3339),
+	)
+	if err != nil {
+		return err
+	}
+
+	pr.ID = id
+	return nil
+}
+
+// GetPullRequest retrieves a pull request by issue ID and PR number
+func (s *sqlStore) GetPullRequest(ctx context.Context, issueID int64, number int) (*models.PullRequest, error) {
+	return scanPullRequest(s.db.QueryRowContext(ctx,
+		s.bind(`SELECT `+pullRequestColumns+`
+		FROM pull_requests
+		WHERE issue_id = ? AND number = ?`),
+		issueID,
+		number,
+	))
+}
+
+// ListPullRequests retrieves all pull requests for an issue
+func (s *sqlStore) ListPullRequests(ctx context.Context, issueID int64) ([]*models.PullRequest, error) {
+	return s.queryPullRequests(ctx,
+		`SELECT `+pullRequestColumns+`
+		FROM pull_requests
+		WHERE issue_id = ?
+		ORDER BY created_at DESC`,
+		issueID,
+	)
+}
+
+// UpdatePullRequest updates a pull request
+func (s *sqlStore) UpdatePullRequest(ctx context.Context, pr *models.PullRequest) error {
+	_, err := s.db.ExecContext(ctx,
+		s.bind(`UPDATE pull_requests
+		SET repository = ?, title = ?, body = ?, labels = ?, url = ?, status = ?, target_branch = ?,
+			is_backport = ?, original_pr_id = ?, ci_status = ?, failing_checks = ?,
+			required_reviewers = ?, approvals = ?, mergeable = ?, updated_at = ?
+		WHERE id = ?`),
+		pr.Repository,
+		pr.Title,
+		pr.Body,
+		encodeStrings(pr.Labels),
+		pr.URL,
+		pr.Status,
+		pr.TargetBranch,
+		pr.IsBackport,
+		pr.OriginalPRID,
+		pr.CIStatus,
+		encodeStrings(pr.FailingChecks),
+		encodeStrings(pr.RequiredReviewers),
+		pr.Approvals,
+		pr.Mergeable,
+		time.Now().Format(time.RFC3339),
+		pr.ID,
+	)
+	return err
+}
+
+// GetUnmergedPullRequests retrieves all unmerged pull requests for an issue
+func (s *sqlStore) GetUnmergedPullRequests(ctx context.Context, issueID int64) ([]*models.PullRequest, error) {
+	return s.queryPullRequests(ctx,
+		`SELECT `+pullRequestColumns+`
+		FROM pull_requests
+		WHERE issue_id = ? AND status != 'merged'
+		ORDER BY created_at DESC`,
+		issueID,
+	)
+}
+
+// CreateEvent records a timeline event of an issue and sets its ID
+func (s *sqlStore) CreateEvent(ctx context.Context, e *models.Event) error {
+	if e.CreatedAt.IsZero() {
+		e.CreatedAt = time.Now()
+	}
+
+	id, err := s.insert(ctx,
+		`INSERT INTO issue_events (issue_id, type, field, from_value, to_value, author, source, occurred_at, created_at)
+		VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?)`,
+		e.IssueID,
+		e.Type,
+		e.Field,
+		e.From,
+		e.To,
+		e.Author,
+		e.Source,
+		e.OccurredAt.UTC(),
+		e.CreatedAt.UTC(),
+	)
+	if err != nil {
+		return fmt.Errorf("failed to create event: %w", err)
+	}
+
+	e.ID = id
+	return nil
+}
+
+// ListEvents retrieves the timeline of an issue, oldest first
+func (s *sqlStore) ListEvents(ctx context.Context, issueID int64) ([]*models.Event, error) {
+	rows, err := s.db.QueryContext(ctx,
+		s.bind(`SELECT id, issue_id, type, field, from_value, to_value, author, source, occurred_at, created_at
+		FROM issue_events
+		WHERE issue_id = ?
+		ORDER BY occurred_at, id`),
+		issueID,
+	)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list events: %w", err)
+	}
+	defer rows.Close()
+
+	var events []*models.Event
+	for rows.Next() {
+		var e models.Event
+		if err := rows.Scan(&e.ID, &e.IssueID, &e.Type, &e.Field, &e.From, &e.To, &e.Author, &e.Source,
+			&e.OccurredAt, &e.CreatedAt); err != nil {
+			return nil, fmt.Errorf("failed to scan event: %w", err)
+		}
+		events = append(events, &e)
+	}
+
+	return events, rows.Err()
+}
+
+// CreateAuditEntry records a change made to an issue in Jira
+func (s *sqlStore) CreateAuditEntry(ctx context.Context, entry *models.AuditEntry) error {
+	if entry.CreatedAt.IsZero() {
+		entry.CreatedAt = time.Now()
+	}
+
+	id, err := s.insert(ctx,
+		`INSERT INTO jira_audit (issue_id, action, details, actor, dry_run, error, created_at)
+		VALUES (?, ?, ?, ?, ?, ?, ?)`,
+		entry.IssueID,
+		entry.Action,
+		entry.Details,
+		entry.Actor,
+		entry.DryRun,
+		entry.Error,
+		entry.CreatedAt.UTC(),
+	)
+	if err != nil {
+		return fmt.Errorf("failed to create audit entry: %w", err)
+	}
+
+	entry.ID = id
+	return nil
+}
+
+// ListAuditEntries retrieves the changes made to an issue in Jira, oldest first
+func (s *sqlStore) ListAuditEntries(ctx context.Context, issueID int64) ([]*models.AuditEntry, error) {
+	rows, err := s.db.QueryContext(ctx,
+		s.bind(`SELECT id, issue_id, action, details, actor, dry_run, error, created_at
+		FROM jira_audit
+		WHERE issue_id = ?
+		ORDER BY created_at, id`),
+		issueID,
+	)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list audit entries: %w", err)
+	}
+	defer rows.Close()
+
+	var entries []*models.AuditEntry
+	for rows.Next() {
+		var e models.AuditEntry
+		if err := rows.Scan(&e.ID, &e.IssueID, &e.Action, &e.Details, &e.Actor, &e.DryRun, &e.Error,
+			&e.CreatedAt); err != nil {
+			return nil, fmt.Errorf("failed to scan audit entry: %w", err)
+		}
+		entries = append(entries, &e)
+	}
+
+	return entries, rows.Err()
+}
+
+// ReplaceIssueRelations replaces the children and links of an issue with the
+// ones last read from Jira
+func (s *sqlStore) ReplaceIssueRelations(ctx context.Context, issueID int64, children []models.IssueChild, links []models.IssueLink) error {
+	tx, err := s.db.BeginTx(ctx, nil)
+	if err != nil {
+		return fmt.Errorf("failed to replace issue relations: %w", err)
+	}
+	defer tx.Rollback()
+
+	for _, table := range []string{"issue_children", "issue_links"} {
+		if _, err := tx.ExecContext(ctx, s.bind(`DELETE FROM `+table+` WHERE issue_id = ?`), issueID); err != nil {
+			return fmt.Errorf("failed to clear %s of issue: %w", table, err)
+		}
+	}
+
+	for _, c := range children {
+		if _, err := tx.ExecContext(ctx,
+			s.bind(`INSERT INTO issue_children (issue_id, key, kind, title, status, status_category)
+			VALUES (?, ?, ?, ?, ?, ?)
+			ON CONFLICT (issue_id, key) DO NOTHING`),
+			issueID, c.Key, c.Kind, c.Title, c.Status, c.StatusCategory,
+		); err != nil {
+			return fmt.Errorf("failed to create issue child: %w", err)
+		}
+	}
+	for _, l := range links {
+		if _, err := tx.ExecContext(ctx,
+			s.bind(`INSERT INTO issue_links (issue_id, type, relation, outward, key, title, status, status_category)
+			VALUES (?, ?, ?, ?, ?, ?, ?, ?)`),
+			issueID, l.Type, l.Relation, l.Outward, l.Key, l.Title, l.Status, l.StatusCategory,
+		); err != nil {
+			return fmt.Errorf("failed to create issue link: %w", err)
+		}
+	}
+	return tx.Commit()
+}
+
+// ListIssueChildren retrieves the children of an issue in the order Jira
+// listed them
+func (s *sqlStore) ListIssueChildren(ctx context.Context, issueID int64) ([]models.IssueChild, error) {
+	rows, err := s.db.QueryContext(ctx,
+		s.bind(`SELECT id, issue_id, key, kind, title, status, status_category
+		FROM issue_children
+		WHERE issue_id = ?
+		ORDER BY id`),
+		issueID,
+	)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list issue children: %w", err)
+	}
+	defer rows.Close()
+
+	var children []models.IssueChild
+	for rows.Next() {
+		var c models.IssueChild
+		if err := rows.Scan(&c.ID, &c.IssueID, &c.Key, &c.Kind, &c.Title, &c.Status, &c.StatusCategory); err != nil {
+			return nil, fmt.Errorf("failed to scan issue child: %w", err)
+		}
+		children = append(children, c)
+	}
+
+	return children, rows.Err()
+}
+
+// ListIssueLinks retrieves the links of an issue in the order Jira listed them
+func (s *sqlStore) ListIssueLinks(ctx context.Context, issueID int64) ([]models.IssueLink, error) {
+	rows, err := s.db.QueryContext(ctx,
+		s.bind(`SELECT id, issue_id, type, relation, outward, key, title, status, status_category
+		FROM issue_links
+		WHERE issue_id = ?
+		ORDER BY id`),
+		issueID,
+	)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list issue links: %w", err)
+	}
+	defer rows.Close()
+
+	var links []models.IssueLink
+	for rows.Next() {
+		var l models.IssueLink
+		if err := rows.Scan(&l.ID, &l.IssueID, &l.Type, &l.Relation, &l.Outward, &l.Key, &l.Title, &l.Status, &l.StatusCategory); err != nil {
+			return nil, fmt.Errorf("failed to scan issue link: %w", err)
+		}
+		links = append(links, l)
+	}
+
+	return links, rows.Err()
+}
+
+// ListPullRequestsByNumber retrieves the pull requests tracked for any issue
+// with the given repository and number
+func (s *sqlStore) ListPullRequestsByNumber(ctx context.Context, repository string, number int) ([]*models.PullRequest, error) {
+	return s.queryPullRequests(ctx,
+		`SELECT `+pullRequestColumns+`
+		FROM pull_requests
+		WHERE repository = ? AND number = ?
+		ORDER BY created_at DESC`,
+		repository,
+		number,
+	)
+}
+
+// UpsertCommit records a commit referencing an issue, refreshing the branches
+// and tags containing it when it was already known
+func (s *sqlStore) UpsertCommit(ctx context.Context, c *models.Commit) error {
+	_, err := s.db.ExecContext(ctx,
+		s.bind(`INSERT INTO issue_commits (issue_id, repository, sha, subject, committed_at, branches, tags, scanned_at)
+		VALUES (?, ?, ?, ?, ?, ?, ?, ?)
+		ON CONFLICT (issue_id, repository, sha) DO UPDATE SET
+			subject = excluded.subject,
+			branches = excluded.branches,
+			tags = excluded.tags,
+			scanned_at = excluded.scanned_at`),
+		c.IssueID,
+		c.Repository,
+		c.SHA,
+		c.Subject,
+		c.CommittedAt.UTC(),
+		encodeStrings(c.Branches),
+		encodeStrings(c.Tags),
+		c.ScannedAt.UTC(),
+	)
+	if err != nil {
+		return fmt.Errorf("failed to upsert commit: %w", err)
+	}
+	return nil
+}
+
+// ListCommits retrieves the commits referencing an issue
+func (s *sqlStore) ListCommits(ctx context.Context, issueID int64) ([]*models.Commit, error) {
+	rows, err := s.db.QueryContext(ctx,
+		s.bind(`SELECT id, issue_id, repository, sha, subject, committed_at, branches, tags, scanned_at
+		FROM issue_commits
+		WHERE issue_id = ?
+		ORDER BY committed_at`),
+		issueID,
+	)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list commits: %w", err)
+	}
+	defer rows.Close()
+
+	var commits []*models.Commit
+	for rows.Next() {
+		var c models.Commit
+		var branches, tags string
+		if err := rows.Scan(&c.ID, &c.IssueID, &c.Repository, &c.SHA, &c.Subject, &c.CommittedAt, &branches, &tags, &c.ScannedAt); err != nil {
+			return nil, fmt.Errorf("failed to scan commit: %w", err)
+		}
+		if c.Branches, err = decodeStrings(branches); err != nil {
+			return nil, fmt.Errorf("failed to parse branches: %w", err)
+		}
+		if c.Tags, err = decodeStrings(tags); err != nil {
+			return nil, fmt.Errorf("failed to parse tags: %w", err)
+		}
+		commits = append(commits, &c)
+	}
+
+	if err = rows.Err(); err != nil {
+		return nil, err
+	}
+
+	return commits, nil
+}
+
+// Close closes the database connection
+func (s *sqlStore) Close() error {
+	return s.db.Close()
+}
+
+// encodeStrings serializes a string list into the JSON text stored in list columns
+func encodeStrings(values []string) string {
+	if len(values) == 0 {
+		return "[]"
+	}
+	data, err := json.Marshal(values)
+	if err != nil {
+		return "[]"
+	}
+	return string(data)
+}
+
+// decodeStrings parses a JSON list column back into a string list
+func decodeStrings(data string) ([]string, error) {
+	if data == "" {
+		return nil, nil
+	}
+	var values []string
+	if err := json.Unmarshal([]byte(data), &values); err != nil {
+		return nil, err
+	}
+	return values, nil
+}
+
+// encodeAttributes serializes issue attributes into a JSON object column
+func encodeAttributes(attrs map[string]any) string {
+	if len(attrs) == 0 {
+		return "{}"
+	}
+	data, err := json.Marshal(attrs)
+	if err != nil {
+		return "{}"
+	}
+	return string(data)
+}
+
+// decodeAttributes parses a JSON object column back into issue attributes
+func decodeAttributes(data string) (map[string]any, error) {
+	if data == "" || data == "{}" {
+		return nil, nil
+	}
+	var attrs map[string]any
+	if err := json.Unmarshal([]byte(data), &attrs); err != nil {
+		return nil, err
+	}
+	return attrs, nil
+}
+
+// nullTime converts a zero time into NULL so optional timestamps stay empty
+func nullTime(t time.Time) sql.NullTime {
+	return sql.NullTime{Time: t, Valid: !t.IsZero()}
+}
@@ -2,6 +2,8 @@ package storage
 
 import (
 	"context"
+	"fmt"
+	"strings"
 
 	"github.com/jparrill/devtrackr/internal/models"
 )
@@ -21,9 +23,69 @@ type Storage interface {
 	GetPullRequest(ctx context.Context, issueID int64, prNumber int) (*models.PullRequest, error)
 	UpdatePullRequest(ctx context.Context, pr *models.PullRequest) error
 	GetUnmergedPullRequests(ctx context.Context, issueID int64) ([]*models.PullRequest, error)
+	ListPullRequestsByNumber(ctx context.Context, repository string, number int) ([]*models.PullRequest, error)
 	ListSubscriptions(ctx context.Context, userID int64) ([]models.Subscription, error)
+	ListIssueSubscriptions(ctx context.Context, issueID int64) ([]models.Subscription, error)
 	GetSubscriptionByID(ctx context.Context, id int64) (*models.Subscription, error)
 	UpdateSubscription(ctx context.Context, sub *models.Subscription) error
 	GetIssueByKey(key string) (*models.Issue, error)
+	UpsertCommit(ctx context.Context, commit *models.Commit) error
+	ListCommits(ctx context.Context, issueID int64) ([]*models.Commit, error)
+	CreateEvent(ctx context.Context, event *models.Event) error
+	ListEvents(ctx context.Context, issueID int64) ([]*models.Event, error)
+	CreateAuditEntry(ctx context.Context, entry *models.AuditEntry) error
+	ListAuditEntries(ctx context.Context, issueID int64) ([]*models.AuditEntry, error)
+	ReplaceIssueRelations(ctx context.Context, issueID int64, children []models.IssueChild, links []models.IssueLink) error
+	ListIssueChildren(ctx context.Context, issueID int64) ([]models.IssueChild, error)
+	ListIssueLinks(ctx context.Context, issueID int64) ([]models.IssueLink, error)
 	Close() error
 }
+
+// Storage backends selected by a DSN
+const (
+	BackendSQLite   = "sqlite"
+	BackendPostgres = "postgres"
+)
+
+// ParseDSN returns the backend a DSN selects and the data source handed to
+// it: the path of sqlite:///var/lib/devtrackr.db or of the relative
+// sqlite://devtrackr.db, or the whole URL of postgres:// and postgresql://
+func ParseDSN(dsn string) (backend, source string, err error) {
+	switch {
+	case strings.HasPrefix(dsn, "sqlite://"):
+		path := strings.TrimPrefix(dsn, "sqlite://")
+		if path == "" {
+			return "", "", fmt.Errorf("database DSN sqlite:// has no path")
+		}
+		return BackendSQLite, path, nil
+	case strings.HasPrefix(dsn, "postgres://"), strings.HasPrefix(dsn, "postgresql://"):
+		return BackendPostgres, dsn, nil
+	}
+
+	// The rest of the DSN may hold a password
+	scheme, _, _ := strings.Cut(dsn, ":")
+	return "", "", fmt.Errorf("unsupported database DSN scheme %q, use sqlite:///path or postgres://...", scheme)
+}
+
+// Open opens the storage backend selected by a DSN
+func Open(dsn string) (Storage, error) {
+	backend, source, err := ParseDSN(dsn)
+	if err != nil {
+		return nil, err
+	}
+
+	switch backend {
+	case BackendPostgres:
+		s, err := NewPostgresStorage(source)
+		if err != nil {
+			return nil, err
+		}
+		return s, nil
+	default:
+		s, err := NewSQLiteStorage(source)
+		if err != nil {
+			return nil, err
+		}
+		return s, nil
+	}
+}
@@ -0,0 +1,122 @@
+package storage
+
+import (
+	"database/sql"
+	"fmt"
+	"net/url"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestParseDSN(t *testing.T) {
+	tests := []struct {
+		dsn     string
+		backend string
+		source  string
+	}{
+		{"sqlite:///var/lib/devtrackr/devtrackr.db", BackendSQLite, "/var/lib/devtrackr/devtrackr.db"},
+		{"sqlite://devtrackr.db", BackendSQLite, "devtrackr.db"},
+		{"postgres://devtrackr:secret@db:5432/devtrackr?sslmode=disable", BackendPostgres,
+			"postgres://devtrackr:secret@db:5432/devtrackr?sslmode=disable"},
+		{"postgresql://db/devtrackr", BackendPostgres, "postgresql://db/devtrackr"},
+	}
+	for _, tt := range tests {
+		backend, source, err := ParseDSN(tt.dsn)
+		require.NoError(t, err, tt.dsn)
+		assert.Equal(t, tt.backend, backend, tt.dsn)
+		assert.Equal(t, tt.source, source, tt.dsn)
+	}
+
+	_, _, err := ParseDSN("sqlite://")
+	assert.Error(t, err)
+	_, _, err = ParseDSN("mysql://root:secret@db/devtrackr")
+	require.Error(t, err)
+	assert.NotContains(t, err.Error(), "secret")
+	_, _, err = ParseDSN("devtrackr.db")
+	assert.Error(t, err)
+}
+
+func TestBind(t *testing.T) {
+	query := `SELECT id FROM issues WHERE key = ? AND instance IN (?, '')`
+	assert.Equal(t, query, sqliteDialect.bind(query))
+	assert.Equal(t, `SELECT id FROM issues WHERE key = $1 AND instance IN ($2, '')`, postgresDialect.bind(query))
+}
+
+func TestMigrationsInStep(t *testing.T) {
+	last := postgresMigrations[len(postgresMigrations)-1]
+	assert.Equal(t, SchemaVersion(), last.version, "every migration must be added to both backends")
+}
+
+// tableColumns returns the columns of every table of a migrated database
+func tableColumns(t *testing.T, db *sql.DB, d *dialect) map[string][]string {
+	query := `SELECT m.name, p.name FROM sqlite_master m, pragma_table_info(m.name) p
+		WHERE m.type = 'table' AND m.name NOT LIKE 'sqlite_%' ORDER BY m.name, p.name`
+	if d == postgresDialect {
+		query = `SELECT table_name, column_name FROM information_schema.columns
+			WHERE table_schema = current_schema() ORDER BY table_name, column_name`
+	}
+	rows, err := db.Query(query)
+	require.NoError(t, err)
+	defer rows.Close()
+
+	columns := map[string][]string{}
+	for rows.Next() {
+		var table, column string
+		require.NoError(t, rows.Scan(&table, &column))
+		columns[table] = append(columns[table], column)
+	}
+	require.NoError(t, rows.Err())
+	return columns
+}
+
+// TestSchemasInStep applies the PostgreSQL migrations to SQLite, which accepts
+// their column types, and checks they create the same tables and columns as
+// the SQLite migrations
+func TestSchemasInStep(t *testing.T) {
+	sqlite, err := NewSQLiteStorage(filepath.Join(t.TempDir(), "sqlite.db"))
+	require.NoError(t, err)
+	defer sqlite.Close()
+
+	db, err := sql.Open("sqlite3", filepath.Join(t.TempDir(), "postgres.db"))
+	require.NoError(t, err)
+	defer db.Close()
+	require.NoError(t, migrate(db, &dialect{name: "postgres", migrations: postgresMigrations}))
+
+	assert.Equal(t, tableColumns(t, sqlite.db, sqliteDialect), tableColumns(t, db, sqliteDialect))
+}
+
+// TestPostgresSchemaInStep compares the schema a PostgreSQL server of
+// DEVTRACKR_TEST_POSTGRES_DSN is migrated to with the SQLite one
+func TestPostgresSchemaInStep(t *testing.T) {
+	dsn := os.Getenv("DEVTRACKR_TEST_POSTGRES_DSN")
+	if dsn == "" {
+		t.Skip("DEVTRACKR_TEST_POSTGRES_DSN is not set")
+	}
+
+	admin, err := sql.Open("pgx", dsn)
+	require.NoError(t, err)
+	defer admin.Close()
+	schema := fmt.Sprintf("devtrackr_schema_%d", os.Getpid())
+	_, err = admin.Exec(`CREATE SCHEMA ` + schema)
+	require.NoError(t, err)
+	defer admin.Exec(`DROP SCHEMA ` + schema + ` CASCADE`)
+
+	u, err := url.Parse(dsn)
+	require.NoError(t, err)
+	q := u.Query()
+	q.Set("search_path", schema)
+	u.RawQuery = q.Encode()
+	postgres, err := NewPostgresStorage(u.String())
+	require.NoError(t, err)
+	defer postgres.Close()
+
+	sqlite, err := NewSQLiteStorage(filepath.Join(t.TempDir(), "sqlite.db"))
+	require.NoError(t, err)
+	defer sqlite.Close()
+
+	assert.Equal(t, tableColumns(t, sqlite.db, sqliteDialect), tableColumns(t, postgres.db, postgresDialect))
+}
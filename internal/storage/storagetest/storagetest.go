@@ -0,0 +1,488 @@
+// Package storagetest provides the conformance suite every storage backend
+// must pass, so that backends are interchangeable.
+package storagetest
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/jparrill/devtrackr/internal/models"
+	"github.com/jparrill/devtrackr/internal/storage"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// Opener returns an empty storage, closed by the caller's test cleanup
+type Opener func(t *testing.T) storage.Storage
+
+// pruner is implemented by backends that delete history past its retention
+type pruner interface {
+	PruneEvents(ctx context.Context, before time.Time) (int64, error)
+	PruneAuditEntries(ctx context.Context, before time.Time) (int64, error)
+}
+
+// base is a whole second, as some columns only keep seconds
+var base = time.Date(2024, 5, 1, 12, 0, 0, 0, time.UTC)
+
+// Run runs the conformance suite, opening a new storage for every test
+func Run(t *testing.T, open Opener) {
+	tests := []struct {
+		name string
+		run  func(t *testing.T, s storage.Storage)
+	}{
+		{"Issues", testIssues},
+		{"IssueInstances", testIssueInstances},
+		{"DeleteIssue", testDeleteIssue},
+		{"Subscriptions", testSubscriptions},
+		{"PullRequests", testPullRequests},
+		{"EventsAndAudit", testEventsAndAudit},
+		{"Relations", testRelations},
+		{"Commits", testCommits},
+		{"Prune", testPrune},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tt.run(t, open(t))
+		})
+	}
+}
+
+// createIssue stores a minimal issue
+func createIssue(t *testing.T, s storage.Storage, instance, key string) *models.Issue {
+	t.Helper()
+	issue := &models.Issue{
+		Instance:     instance,
+		Key:          key,
+		Title:        "Title of " + key,
+		Status:       "New",
+		JiraURL:      "https://issues.redhat.com/browse/" + key,
+		CreatedAt:    base,
+		UpdatedAt:    base,
+		LastPolledAt: base,
+	}
+	require.NoError(t, s.CreateIssue(issue))
+	require.NotZero(t, issue.ID)
+	return issue
+}
+
+// assertTime checks two times are the same instant, whatever their location
+func assertTime(t *testing.T, want, got time.Time, field string) {
+	t.Helper()
+	assert.True(t, want.Equal(got), "%s: want %v, got %v", field, want, got)
+}
+
+func testIssues(t *testing.T, s storage.Storage) {
+	issue := createIssue(t, s, "redhat", "OCPBUGS-1")
+	second := createIssue(t, s, "redhat", "OCPBUGS-2")
+	assert.NotEqual(t, issue.ID, second.ID)
+
+	got, err := s.GetIssue("OCPBUGS-1")
+	require.NoError(t, err)
+	assert.Equal(t, issue.ID, got.ID)
+	assert.Equal(t, "redhat", got.Instance)
+	assert.Empty(t, got.FixVersions)
+	assert.Nil(t, got.Attributes)
+	assert.True(t, got.LastWebhookAt.IsZero())
+	assert.True(t, got.DueDate.IsZero())
+
+	_, err = s.GetIssue("OCPBUGS-404")
+	assert.Error(t, err)
+
+	// Every field round-trips
+	updated := *got
+	updated.Title = "Crash on start"
+	updated.Status = "Closed"
+	updated.StatusCategory = models.StatusCategoryDone
+	updated.UpdatedAt = base.Add(time.Hour)
+	updated.LastPolledAt = base.Add(2 * time.Hour)
+	updated.FixVersions = []string{"4.16.0", "4.15.3"}
+	updated.AffectsVersions = []string{"4.15"}
+	updated.Components = []string{"HyperShift"}
+	updated.LastWebhookAt = base.Add(3 * time.Hour)
+	updated.PollingInterval = 600
+	updated.Description = "It crashes"
+	updated.Assignee = "Alex"
+	updated.Reporter = "Sam"
+	updated.Priority = "Major"
+	updated.IssueType = "Bug"
+	updated.Labels = []string{"triaged"}
+	updated.Sprint = "Sprint 42"
+	updated.EpicLink = "OCPBUGS-100"
+	updated.Resolution = "Done"
+	updated.DueDate = base.Add(72 * time.Hour)
+	updated.JiraUpdatedAt = base.Add(4 * time.Hour)
+	updated.Attributes = map[string]any{"team": "HyperShift", "points": float64(3), "flags": []any{"a", "b"}}
+	require.NoError(t, s.UpdateIssue(&updated))
+
+	got, err = s.GetIssueByKey("redhat:OCPBUGS-1")
+	require.NoError(t, err)
+	assertTime(t, updated.UpdatedAt, got.UpdatedAt, "updated_at")
+	assertTime(t, updated.LastPolledAt, got.LastPolledAt, "last_polled_at")
+	assertTime(t, updated.LastWebhookAt, got.LastWebhookAt, "last_webhook_at")
+	assertTime(t, updated.DueDate, got.DueDate, "due_date")
+	assertTime(t, updated.JiraUpdatedAt, got.JiraUpdatedAt, "jira_updated_at")
+	assertTime(t, issue.CreatedAt, got.CreatedAt, "created_at")
+	for _, field := range []*time.Time{&updated.CreatedAt, &updated.UpdatedAt, &updated.LastPolledAt,
+		&updated.LastWebhookAt, &updated.DueDate, &updated.JiraUpdatedAt} {
+		*field = time.Time{}
+	}
+	for _, field := range []*time.Time{&got.CreatedAt, &got.UpdatedAt, &got.LastPolledAt,
+		&got.LastWebhookAt, &got.DueDate, &got.JiraUpdatedAt} {
+		*field = time.Time{}
+	}
+	assert.Equal(t, updated, *got)
+
+	issues, err := s.ListIssues()
+	require.NoError(t, err)
+	require.Len(t, issues, 2)
+	assert.Equal(t, []string{"OCPBUGS-1", "OCPBUGS-2"}, []string{issues[0].Key, issues[1].Key},
+		"issues are listed in the order they were tracked")
+}
+
+func testIssueInstances(t *testing.T, s storage.Storage) {
+	redhat := createIssue(t, s, "redhat", "PROJ-1")
+	other := createIssue(t, s, "other", "PROJ-1")
+	legacy := createIssue(t, s, "", "LEGACY-1")
+
+	_, err := s.GetIssue("PROJ-1")
+	assert.ErrorContains(t, err, "several Jira instances", "bare keys must be unique")
+
+	got, err := s.GetIssue("other:PROJ-1")
+	require.NoError(t, err)
+	assert.Equal(t, other.ID, got.ID)
+	got, err = s.GetIssue("redhat:PROJ-1")
+	require.NoError(t, err)
+	assert.Equal(t, redhat.ID, got.ID)
+
+	// Issues tracked before instances were recorded match any instance
+	got, err = s.GetIssue("redhat:LEGACY-1")
+	require.NoError(t, err)
+	assert.Equal(t, legacy.ID, got.ID)
+
+	assert.Error(t, s.CreateIssue(&models.Issue{Instance: "redhat", Key: "PROJ-1", Title: "Duplicate",
+		Status: "New", JiraURL: "x", CreatedAt: base, UpdatedAt: base, LastPolledAt: base}))
+}
+
+func testDeleteIssue(t *testing.T, s storage.Storage) {
+	ctx := context.Background()
+	issue := createIssue(t, s, "redhat", "OCPBUGS-1")
+	kept := createIssue(t, s, "redhat", "OCPBUGS-2")
+
+	for _, id := range []int64{issue.ID, kept.ID} {
+		require.NoError(t, s.CreateSubscription(ctx, &models.Subscription{IssueID: id, UserID: 1, Active: true}))
+		require.NoError(t, s.CreatePullRequest(ctx, &models.PullRequest{IssueID: id, Number: 1, Repository: "org/repo",
+			Title: "Fix", URL: "https://github.com/org/repo/pull/1", Status: models.PRStatusOpen}))
+		require.NoError(t, s.CreateEvent(ctx, &models.Event{IssueID: id, Type: models.EventStatusChanged,
+			Source: models.EventSourcePolling, OccurredAt: base}))
+		require.NoError(t, s.CreateAuditEntry(ctx, &models.AuditEntry{IssueID: id, Action: models.AuditComment}))
+		require.NoError(t, s.UpsertCommit(ctx, &models.Commit{IssueID: id, Repository: "/src/repo", SHA: "abc",
+			CommittedAt: base, ScannedAt: base}))
+		require.NoError(t, s.ReplaceIssueRelations(ctx, id,
+			[]models.IssueChild{{Key: "OCPBUGS-10", Kind: models.ChildSubtask}},
+			[]models.IssueLink{{Type: "Blocks", Key: "OCPBUGS-20"}}))
+	}
+
+	require.NoError(t, s.DeleteIssue(ctx, "OCPBUGS-1"))
+	_, err := s.GetIssue("OCPBUGS-1")
+	assert.Error(t, err)
+	assert.Error(t, s.DeleteIssue(ctx, "OCPBUGS-1"))
+
+	// Records of the deleted issue are gone, the others kept
+	for id, want := range map[int64]int{issue.ID: 0, kept.ID: 1} {
+		subs, err := s.ListIssueSubscriptions(ctx, id)
+		require.NoError(t, err)
+		assert.Len(t, subs, want)
+		prs, err := s.ListPullRequests(ctx, id)
+		require.NoError(t, err)
+		assert.Len(t, prs, want)
+		events, err := s.ListEvents(ctx, id)
+		require.NoError(t, err)
+		assert.Len(t, events, want)
+		entries, err := s.ListAuditEntries(ctx, id)
+		require.NoError(t, err)
+		assert.Len(t, entries, want)
+		commits, err := s.ListCommits(ctx, id)
+		require.NoError(t, err)
+		assert.Len(t, commits, want)
+		children, err := s.ListIssueChildren(ctx, id)
+		require.NoError(t, err)
+		assert.Len(t, children, want)
+		links, err := s.ListIssueLinks(ctx, id)
+		require.NoError(t, err)
+		assert.Len(t, links, want)
+	}
+}
+
+func testSubscriptions(t *testing.T, s storage.Storage) {
+	ctx := context.Background()
+	first := createIssue(t, s, "redhat", "OCPBUGS-1")
+	second := createIssue(t, s, "redhat", "OCPBUGS-2")
+
+	sub := &models.Subscription{IssueID: first.ID, UserID: 7, Active: true, CreatedAt: base}
+	require.NoError(t, s.CreateSubscription(ctx, sub))
+	require.NotZero(t, sub.ID)
+	assertTime(t, base, sub.UpdatedAt, "updated_at defaults to created_at")
+	require.NoError(t, s.CreateSubscription(ctx, &models.Subscription{IssueID: second.ID, UserID: 7, Active: true,
+		CreatedAt: base.Add(time.Hour)}))
+	require.NoError(t, s.CreateSubscription(ctx, &models.Subscription{IssueID: first.ID, UserID: 3, Active: true}))
+
+	got, err := s.GetSubscription(ctx, first.ID, 7)
+	require.NoError(t, err)
+	assert.Equal(t, sub.ID, got.ID)
+	assert.True(t, got.Active)
+	assertTime(t, base, got.CreatedAt, "created_at")
+	got, err = s.GetSubscriptionByID(ctx, sub.ID)
+	require.NoError(t, err)
+	assert.Equal(t, int64(7), got.UserID)
+	_, err = s.GetSubscription(ctx, second.ID, 3)
+	assert.Error(t, err)
+
+	subs, err := s.ListSubscriptions(ctx, 7)
+	require.NoError(t, err)
+	require.Len(t, subs, 2)
+	assert.Equal(t, second.ID, subs[0].IssueID, "newest subscription first")
+
+	subs, err = s.ListIssueSubscriptions(ctx, first.ID)
+	require.NoError(t, err)
+	require.Len(t, subs, 2)
+	assert.Equal(t, []int64{3, 7}, []int64{subs[0].UserID, subs[1].UserID})
+
+	sub.Active = false
+	require.NoError(t, s.UpdateSubscription(ctx, sub))
+	got, err = s.GetSubscriptionByID(ctx, sub.ID)
+	require.NoError(t, err)
+	assert.False(t, got.Active)
+	assert.True(t, got.UpdatedAt.After(base))
+
+	require.NoError(t, s.DeleteSubscription(ctx, sub.ID))
+	_, err = s.GetSubscriptionByID(ctx, sub.ID)
+	assert.Error(t, err)
+}
+
+func testPullRequests(t *testing.T, s storage.Storage) {
+	ctx := context.Background()
+	issue := createIssue(t, s, "redhat", "OCPBUGS-1")
+	other := createIssue(t, s, "redhat", "OCPBUGS-2")
+
+	original := &models.PullRequest{
+		IssueID:      issue.ID,
+		Number:       10,
+		Repository:   "openshift/hypershift",
+		Title:        "Fix crash",
+		Body:         "Fixes OCPBUGS-1",
+		Labels:       []string{"lgtm"},
+		URL:          "https://github.com/openshift/hypershift/pull/10",
+		Status:       models.PRStatusMerged,
+		TargetBranch: "main",
+		CreatedAt:    base,
+	}
+	require.NoError(t, s.CreatePullRequest(ctx, original))
+	require.NotZero(t, original.ID)
+
+	mergeable := true
+	backport := &models.PullRequest{
+		IssueID:           issue.ID,
+		Number:            11,
+		Repository:        "openshift/hypershift",
+		Title:             "[release-4.16] Fix crash",
+		URL:               "https://github.com/openshift/hypershift/pull/11",
+		Status:            models.PRStatusOpen,
+		TargetBranch:      "release-4.16",
+		Labels:            []string{"backport"},
+		IsBackport:        true,
+		OriginalPRID:      &original.ID,
+		CIStatus:          models.CIStatusFailure,
+		FailingChecks:     []string{"e2e-aws"},
+		RequiredReviewers: []string{"alex"},
+		Approvals:         1,
+		Mergeable:         &mergeable,
+		CreatedAt:         base.Add(time.Hour),
+	}
+	require.NoError(t, s.CreatePullRequest(ctx, backport))
+	// The same pull request fixes another issue
+	require.NoError(t, s.CreatePullRequest(ctx, &models.PullRequest{IssueID: other.ID, Number: 10,
+		Repository: "openshift/hypershift", Title: "Fix crash", URL: original.URL, Status: models.PRStatusMerged}))
+
+	got, err := s.GetPullRequest(ctx, issue.ID, 11)
+	require.NoError(t, err)
+	assertTime(t, backport.CreatedAt, got.CreatedAt, "created_at")
+	assertTime(t, backport.CreatedAt, got.UpdatedAt, "updated_at defaults to created_at")
+	got.CreatedAt, got.UpdatedAt = backport.CreatedAt, backport.UpdatedAt
+	assert.Equal(t, backport, got)
+
+	got, err = s.GetPullRequest(ctx, issue.ID, 10)
+	require.NoError(t, err)
+	assert.Nil(t, got.OriginalPRID)
+	assert.Nil(t, got.Mergeable)
+	_, err = s.GetPullRequest(ctx, issue.ID, 404)
+	assert.Error(t, err)
+
+	prs, err := s.ListPullRequests(ctx, issue.ID)
+	require.NoError(t, err)
+	require.Len(t, prs, 2)
+	assert.Equal(t, []int{11, 10}, []int{prs[0].Number, prs[1].Number}, "newest first")
+
+	unmerged, err := s.GetUnmergedPullRequests(ctx, issue.ID)
+	require.NoError(t, err)
+	require.Len(t, unmerged, 1)
+	assert.Equal(t, 11, unmerged[0].Number)
+
+	backport.Status = models.PRStatusMerged
+	backport.CIStatus = models.CIStatusSuccess
+	backport.FailingChecks = nil
+	backport.Approvals = 2
+	backport.Mergeable = nil
+	require.NoError(t, s.UpdatePullRequest(ctx, backport))
+	got, err = s.GetPullRequest(ctx, issue.ID, 11)
+	require.NoError(t, err)
+	assert.Equal(t, models.PRStatusMerged, got.Status)
+	assert.Equal(t, models.CIStatusSuccess, got.CIStatus)
+	assert.Empty(t, got.FailingChecks)
+	assert.Equal(t, 2, got.Approvals)
+	assert.Nil(t, got.Mergeable)
+	unmerged, err = s.GetUnmergedPullRequests(ctx, issue.ID)
+	require.NoError(t, err)
+	assert.Empty(t, unmerged)
+
+	byNumber, err := s.ListPullRequestsByNumber(ctx, "openshift/hypershift", 10)
+	require.NoError(t, err)
+	require.Len(t, byNumber, 2)
+	assert.ElementsMatch(t, []int64{issue.ID, other.ID}, []int64{byNumber[0].IssueID, byNumber[1].IssueID})
+	byNumber, err = s.ListPullRequestsByNumber(ctx, "openshift/other", 10)
+	require.NoError(t, err)
+	assert.Empty(t, byNumber)
+}
+
+func testEventsAndAudit(t *testing.T, s storage.Storage) {
+	ctx := context.Background()
+	issue := createIssue(t, s, "redhat", "OCPBUGS-1")
+
+	later := &models.Event{IssueID: issue.ID, Type: models.EventFieldChanged, Field: "assignee", To: "Alex",
+		Author: "Sam", Source: models.EventSourceJiraWebhook, OccurredAt: base.Add(time.Hour)}
+	earlier := &models.Event{IssueID: issue.ID, Type: models.EventStatusChanged, Field: "status", From: "New",
+		To: "POST", Source: models.EventSourcePolling, OccurredAt: base}
+	require.NoError(t, s.CreateEvent(ctx, later))
+	require.NoError(t, s.CreateEvent(ctx, earlier))
+	require.NotZero(t, later.ID)
+
+	events, err := s.ListEvents(ctx, issue.ID)
+	require.NoError(t, err)
+	require.Len(t, events, 2)
+	assert.Equal(t, earlier.ID, events[0].ID, "oldest first")
+	assertTime(t, base, events[0].OccurredAt, "occurred_at")
+	events[0].OccurredAt, events[0].CreatedAt = earlier.OccurredAt, earlier.CreatedAt
+	assert.Equal(t, earlier, events[0])
+
+	entry := &models.AuditEntry{IssueID: issue.ID, Action: models.AuditTransition, Details: "POST",
+		Actor: "cli:alex", DryRun: true, Error: "no such transition", CreatedAt: base}
+	require.NoError(t, s.CreateAuditEntry(ctx, entry))
+	require.NoError(t, s.CreateAuditEntry(ctx, &models.AuditEntry{IssueID: issue.ID, Action: models.AuditComment,
+		CreatedAt: base.Add(time.Minute)}))
+
+	entries, err := s.ListAuditEntries(ctx, issue.ID)
+	require.NoError(t, err)
+	require.Len(t, entries, 2)
+	assertTime(t, base, entries[0].CreatedAt, "created_at")
+	entries[0].CreatedAt = entry.CreatedAt
+	assert.Equal(t, entry, entries[0])
+	assert.Equal(t, models.AuditComment, entries[1].Action)
+}
+
+func testRelations(t *testing.T, s storage.Storage) {
+	ctx := context.Background()
+	issue := createIssue(t, s, "redhat", "OCPBUGS-1")
+
+	children := []models.IssueChild{
+		{Key: "OCPBUGS-12", Kind: models.ChildSubtask, Title: "Second", Status: "New"},
+		{Key: "OCPBUGS-11", Kind: models.ChildEpic, Title: "First", Status: "Closed", StatusCategory: models.StatusCategoryDone},
+		{Key: "OCPBUGS-12", Kind: models.ChildEpic, Title: "Duplicate"},
+	}
+	links := []models.IssueLink{
+		{Type: "Blocks", Relation: "blocks", Outward: true, Key: "OCPBUGS-20"},
+		{Type: "Blocks", Relation: "is blocked by", Key: "OCPBUGS-21", Title: "Blocker", Status: "New"},
+	}
+	require.NoError(t, s.ReplaceIssueRelations(ctx, issue.ID, children, links))
+
+	gotChildren, err := s.ListIssueChildren(ctx, issue.ID)
+	require.NoError(t, err)
+	require.Len(t, gotChildren, 2, "duplicate children are ignored")
+	assert.Equal(t, "OCPBUGS-12", gotChildren[0].Key, "children keep the order Jira listed them")
+	assert.Equal(t, models.ChildSubtask, gotChildren[0].Kind)
+	assert.Equal(t, models.StatusCategoryDone, gotChildren[1].StatusCategory)
+
+	gotLinks, err := s.ListIssueLinks(ctx, issue.ID)
+	require.NoError(t, err)
+	require.Len(t, gotLinks, 2)
+	assert.True(t, gotLinks[0].Outward)
+	gotLinks[1].ID, gotLinks[1].IssueID = 0, 0
+	assert.Equal(t, links[1], gotLinks[1])
+
+	// Relations read later replace the previous ones
+	require.NoError(t, s.ReplaceIssueRelations(ctx, issue.ID, nil, links[1:]))
+	gotChildren, err = s.ListIssueChildren(ctx, issue.ID)
+	require.NoError(t, err)
+	assert.Empty(t, gotChildren)
+	gotLinks, err = s.ListIssueLinks(ctx, issue.ID)
+	require.NoError(t, err)
+	require.Len(t, gotLinks, 1)
+	assert.Equal(t, "OCPBUGS-21", gotLinks[0].Key)
+}
+
+func testCommits(t *testing.T, s storage.Storage) {
+	ctx := context.Background()
+	issue := createIssue(t, s, "redhat", "OCPBUGS-1")
+
+	commit := &models.Commit{IssueID: issue.ID, Repository: "/src/hypershift", SHA: "abc123",
+		Subject: "OCPBUGS-1: fix crash", CommittedAt: base.Add(time.Hour), Branches: []string{"main"}, ScannedAt: base}
+	require.NoError(t, s.UpsertCommit(ctx, commit))
+	require.NoError(t, s.UpsertCommit(ctx, &models.Commit{IssueID: issue.ID, Repository: "/src/hypershift", SHA: "def456",
+		Subject: "OCPBUGS-1: add test", CommittedAt: base, ScannedAt: base}))
+
+	// Scanning again refreshes the branches and tags
+	commit.Branches = []string{"main", "release-4.16"}
+	commit.Tags = []string{"v4.16.0"}
+	commit.ScannedAt = base.Add(24 * time.Hour)
+	require.NoError(t, s.UpsertCommit(ctx, commit))
+
+	commits, err := s.ListCommits(ctx, issue.ID)
+	require.NoError(t, err)
+	require.Len(t, commits, 2)
+	assert.Equal(t, "def456", commits[0].SHA, "oldest commit first")
+	got := commits[1]
+	assert.Equal(t, []string{"main", "release-4.16"}, got.Branches)
+	assert.Equal(t, []string{"v4.16.0"}, got.Tags)
+	assertTime(t, commit.CommittedAt, got.CommittedAt, "committed_at")
+	assertTime(t, commit.ScannedAt, got.ScannedAt, "scanned_at")
+}
+
+func testPrune(t *testing.T, s storage.Storage) {
+	p, ok := s.(pruner)
+	if !ok {
+		t.Skip("backend does not prune history")
+	}
+	ctx := context.Background()
+	issue := createIssue(t, s, "redhat", "OCPBUGS-1")
+
+	for _, at := range []time.Time{base, base.Add(48 * time.Hour)} {
+		require.NoError(t, s.CreateEvent(ctx, &models.Event{IssueID: issue.ID, Type: models.EventStatusChanged,
+			Source: models.EventSourcePolling, OccurredAt: at}))
+		require.NoError(t, s.CreateAuditEntry(ctx, &models.AuditEntry{IssueID: issue.ID, Action: models.AuditComment,
+			CreatedAt: at}))
+	}
+
+	pruned, err := p.PruneEvents(ctx, base.Add(24*time.Hour))
+	require.NoError(t, err)
+	assert.EqualValues(t, 1, pruned)
+	pruned, err = p.PruneAuditEntries(ctx, base.Add(24*time.Hour))
+	require.NoError(t, err)
+	assert.EqualValues(t, 1, pruned)
+
+	events, err := s.ListEvents(ctx, issue.ID)
+	require.NoError(t, err)
+	require.Len(t, events, 1)
+	assertTime(t, base.Add(48*time.Hour), events[0].OccurredAt, "occurred_at")
+	entries, err := s.ListAuditEntries(ctx, issue.ID)
+	require.NoError(t, err)
+	assert.Len(t, entries, 1)
+}
@@ -4,6 +4,7 @@ import (
 	"bytes"
 	"encoding/json"
 	"fmt"
+	"io"
 	"net/http"
 	"net/http/httptest"
 	"os"
@@ -163,6 +164,36 @@ func TestListIssues(t *testing.T) {
 	assert.Equal(t, "https://issues.redhat.com/browse/OCPBUGS-48489", issue.JiraURL)
 }
 
+func TestListIssuesCSV(t *testing.T) {
+	server, cleanup := setupTestServer(t, "Closed")
+	defer cleanup()
+
+	jsonBody, err := json.Marshal(createIssueRequest{JiraURL: "https://issues.redhat.com/browse/OCPBUGS-48489"})
+	require.NoError(t, err)
+	_, err = http.Post(fmt.Sprintf("%s/api/v1/issues", server.URL), "application/json", bytes.NewBuffer(jsonBody))
+	require.NoError(t, err)
+
+	// Pedir CSV por cabecera Accept
+	req, err := http.NewRequest(http.MethodGet, fmt.Sprintf("%s/api/v1/issues?columns=key,status,prs", server.URL), nil)
+	require.NoError(t, err)
+	req.Header.Set("Accept", "text/csv")
+	resp, err := http.DefaultClient.Do(req)
+	require.NoError(t, err)
+	defer resp.Body.Close()
+
+	assert.Equal(t, http.StatusOK, resp.StatusCode)
+	assert.Equal(t, "text/csv; charset=utf-8", resp.Header.Get("Content-Type"))
+	body, err := io.ReadAll(resp.Body)
+	require.NoError(t, err)
+	assert.Equal(t, "key,status,prs\nOCPBUGS-48489,Closed,0\n", string(body))
+
+	// Columna desconocida
+	resp, err = http.Get(fmt.Sprintf("%s/api/v1/issues?format=csv&columns=nope", server.URL))
+	require.NoError(t, err)
+	resp.Body.Close()
+	assert.Equal(t, http.StatusBadRequest, resp.StatusCode)
+}
+
 func TestUpdateIssueStatus(t *testing.T) {
 	// Configurar servidor con mock que devuelve estado inicial "Closed"
 	server, cleanup := setupTestServer(t, "Closed")
@@ -0,0 +1,151 @@
+package integration
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"path/filepath"
+	"testing"
+
+	"github.com/jparrill/devtrackr/internal/archive"
+	"github.com/jparrill/devtrackr/internal/jira"
+	"github.com/jparrill/devtrackr/internal/models"
+	"github.com/jparrill/devtrackr/internal/services"
+	"github.com/jparrill/devtrackr/internal/storage"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func newArchiveService(t *testing.T, name string) *services.TrackingService {
+	db, err := storage.NewSQLiteStorage(filepath.Join(t.TempDir(), name))
+	require.NoError(t, err)
+	t.Cleanup(func() { db.Close() })
+	return services.NewTrackingService(db, jira.NewMockClient("In Progress"))
+}
+
+func TestExportImportRoundTrip(t *testing.T) {
+	ctx := context.Background()
+
+	// Tracked on the source instance, with a padding issue so storage IDs
+	// differ between instances
+	source := newArchiveService(t, "source.db")
+	_, err := source.TrackIssue(ctx, "https://issues.redhat.com/browse/OCPBUGS-1")
+	require.NoError(t, err)
+	_, err = source.TrackIssue(ctx, "https://issues.redhat.com/browse/OCPBUGS-2")
+	require.NoError(t, err)
+	original, err := source.AddPullRequest(ctx, "OCPBUGS-2", &models.PullRequest{
+		Repository: "openshift/hypershift", Number: 10, TargetBranch: "main", Status: models.PRStatusMerged,
+	})
+	require.NoError(t, err)
+	_, err = source.AddPullRequest(ctx, "OCPBUGS-2", &models.PullRequest{
+		Repository: "openshift/hypershift", Number: 11, TargetBranch: "release-4.18", Status: models.PRStatusOpen,
+		IsBackport: true, OriginalPRID: &original.ID,
+	})
+	require.NoError(t, err)
+	_, err = source.SubscribeToIssue(ctx, "OCPBUGS-2", 7)
+	require.NoError(t, err)
+	require.NoError(t, source.DeleteIssue(ctx, "OCPBUGS-1"))
+
+	exported, err := source.Export(ctx, models.IssueFilter{})
+	require.NoError(t, err)
+
+	for _, encoding := range []archive.Encoding{archive.EncodingJSON, archive.EncodingNDJSON} {
+		t.Run(string(encoding), func(t *testing.T) {
+			var buf bytes.Buffer
+			require.NoError(t, archive.Write(&buf, exported, encoding))
+			a, err := archive.Read(&buf)
+			require.NoError(t, err)
+
+			target := newArchiveService(t, "target.db")
+			_, err = target.TrackIssue(ctx, "https://issues.redhat.com/browse/OCPBUGS-3")
+			require.NoError(t, err)
+
+			result, err := target.Import(ctx, a, archive.StrategyMerge, archive.ConflictNewer)
+			require.NoError(t, err)
+			assert.Equal(t, 1, result.Created)
+			assert.Equal(t, 2, result.PullRequests)
+			assert.Equal(t, 1, result.Subscriptions)
+
+			prs, err := target.ListPullRequests(ctx, "OCPBUGS-2")
+			require.NoError(t, err)
+			require.Len(t, prs, 2)
+			var main, backport *models.PullRequest
+			for _, pr := range prs {
+				if pr.IsBackport {
+					backport = pr
+				} else {
+					main = pr
+				}
+			}
+			require.NotNil(t, backport)
+			require.NotNil(t, backport.OriginalPRID)
+			assert.Equal(t, main.ID, *backport.OriginalPRID)
+
+			subs, err := target.ListSubscriptions(ctx, 7)
+			require.NoError(t, err)
+			assert.Len(t, subs, 1)
+
+			// Importing again changes nothing
+			result, err = target.Import(ctx, a, archive.StrategyMerge, archive.ConflictNewer)
+			require.NoError(t, err)
+			assert.Equal(t, &services.ImportResult{Unchanged: 1}, result)
+
+			// Replacing drops the issue tracked only on the target
+			result, err = target.Import(ctx, a, archive.StrategyReplace, archive.ConflictTheirs)
+			require.NoError(t, err)
+			assert.Equal(t, 1, result.Deleted)
+			assert.Equal(t, 1, result.Updated)
+			assert.Zero(t, result.PullRequests)
+			issues, err := target.ListIssues(ctx)
+			require.NoError(t, err)
+			require.Len(t, issues, 1)
+			assert.Equal(t, "OCPBUGS-2", issues[0].Key)
+		})
+	}
+}
+
+// failingSubscriptions is a storage failing to create subscriptions, to
+// interrupt imports part-way
+type failingSubscriptions struct {
+	*storage.SQLiteStorage
+}
+
+func (f failingSubscriptions) CreateSubscription(ctx context.Context, sub *models.Subscription) error {
+	return errors.New("disk full")
+}
+
+func TestReplaceImportFailureKeepsIssues(t *testing.T) {
+	ctx := context.Background()
+
+	source := newArchiveService(t, "source.db")
+	_, err := source.TrackIssue(ctx, "https://issues.redhat.com/browse/OCPBUGS-1")
+	require.NoError(t, err)
+	_, err = source.SubscribeToIssue(ctx, "OCPBUGS-1", 7)
+	require.NoError(t, err)
+	a, err := source.Export(ctx, models.IssueFilter{})
+	require.NoError(t, err)
+
+	db, err := storage.NewSQLiteStorage(filepath.Join(t.TempDir(), "target.db"))
+	require.NoError(t, err)
+	defer db.Close()
+	target := services.NewTrackingService(db, jira.NewMockClient("In Progress"))
+	_, err = target.TrackIssue(ctx, "https://issues.redhat.com/browse/OCPBUGS-2")
+	require.NoError(t, err)
+
+	// The issue missing from the archive survives a failed import
+	failing := services.NewTrackingService(failingSubscriptions{db}, jira.NewMockClient("In Progress"))
+	_, err = failing.Import(ctx, a, archive.StrategyReplace, archive.ConflictTheirs)
+	require.ErrorContains(t, err, "disk full")
+	_, err = target.GetIssue(ctx, "OCPBUGS-2")
+	require.NoError(t, err)
+
+	// and is deleted once the import is run again successfully
+	result, err := target.Import(ctx, a, archive.StrategyReplace, archive.ConflictTheirs)
+	require.NoError(t, err)
+	assert.Equal(t, 1, result.Deleted)
+	assert.Equal(t, 1, result.Subscriptions)
+	issues, err := target.ListIssues(ctx)
+	require.NoError(t, err)
+	require.Len(t, issues, 1)
+	assert.Equal(t, "OCPBUGS-1", issues[0].Key)
+}
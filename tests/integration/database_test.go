@@ -0,0 +1,81 @@
+package integration
+
+import (
+	"context"
+	"database/sql"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/jparrill/devtrackr/internal/jira"
+	"github.com/jparrill/devtrackr/internal/models"
+	"github.com/jparrill/devtrackr/internal/services"
+	"github.com/jparrill/devtrackr/internal/storage"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestBackupRestore(t *testing.T) {
+	ctx := context.Background()
+	dir := t.TempDir()
+	path := filepath.Join(dir, "devtrackr.db")
+
+	db, err := storage.NewSQLiteStorage(path)
+	require.NoError(t, err)
+	service := services.NewTrackingService(db, jira.NewMockClient("New"))
+	issue, err := service.TrackIssue(ctx, "https://issues.redhat.com/browse/OCPBUGS-1")
+	require.NoError(t, err)
+
+	// Backed up while open, then changed
+	backup := filepath.Join(dir, "backup.db")
+	require.NoError(t, db.Backup(ctx, backup))
+	_, err = service.TrackIssue(ctx, "https://issues.redhat.com/browse/OCPBUGS-2")
+	require.NoError(t, err)
+
+	old := time.Now().Add(-48 * time.Hour)
+	require.NoError(t, db.CreateEvent(ctx, &models.Event{IssueID: issue.ID, Type: models.EventStatusChanged, Source: "test", OccurredAt: old}))
+	require.NoError(t, db.CreateEvent(ctx, &models.Event{IssueID: issue.ID, Type: models.EventStatusChanged, Source: "test", OccurredAt: time.Now()}))
+	pruned, err := db.PruneEvents(ctx, time.Now().Add(-24*time.Hour))
+	require.NoError(t, err)
+	assert.EqualValues(t, 1, pruned)
+	require.NoError(t, db.Vacuum(ctx))
+	require.NoError(t, db.Close())
+
+	version, err := storage.Restore(backup, path)
+	require.NoError(t, err)
+	assert.Equal(t, storage.SchemaVersion(), version)
+	assert.FileExists(t, path+".pre-restore")
+
+	db, err = storage.NewSQLiteStorage(path)
+	require.NoError(t, err)
+	defer db.Close()
+	issues, err := db.ListIssues()
+	require.NoError(t, err)
+	require.Len(t, issues, 1)
+	assert.Equal(t, "OCPBUGS-1", issues[0].Key)
+}
+
+func TestRestoreRejectsInvalidBackups(t *testing.T) {
+	dir := t.TempDir()
+	path := filepath.Join(dir, "devtrackr.db")
+
+	junk := filepath.Join(dir, "junk.db")
+	require.NoError(t, os.WriteFile(junk, []byte("not a database"), 0o644))
+	_, err := storage.Restore(junk, path)
+	assert.Error(t, err)
+
+	// A backup from a newer build
+	newer := filepath.Join(dir, "newer.db")
+	db, err := storage.NewSQLiteStorage(newer)
+	require.NoError(t, err)
+	require.NoError(t, db.Close())
+	raw, err := sql.Open("sqlite3", newer)
+	require.NoError(t, err)
+	_, err = raw.Exec(`INSERT INTO schema_migrations (version, description) VALUES (?, 'from the future')`, storage.SchemaVersion()+1)
+	require.NoError(t, err)
+	require.NoError(t, raw.Close())
+	_, err = storage.Restore(newer, path)
+	assert.ErrorContains(t, err, "newer")
+	assert.NoFileExists(t, path)
+}